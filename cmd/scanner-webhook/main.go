@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,9 +13,17 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/events"
+	"github.com/sysdig/registry-webhook-scanner/pkg/logging"
 	"github.com/sysdig/registry-webhook-scanner/pkg/queue"
+	"github.com/sysdig/registry-webhook-scanner/pkg/reconciler"
+	pullability "github.com/sysdig/registry-webhook-scanner/pkg/registry/auth"
+	"github.com/sysdig/registry-webhook-scanner/pkg/restart"
 	"github.com/sysdig/registry-webhook-scanner/pkg/scanner"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanneradapter"
 	"github.com/sysdig/registry-webhook-scanner/pkg/shutdown"
+	"github.com/sysdig/registry-webhook-scanner/pkg/sink"
+	"github.com/sysdig/registry-webhook-scanner/pkg/verify"
 	"github.com/sysdig/registry-webhook-scanner/pkg/webhook"
 )
 
@@ -55,17 +65,119 @@ func main() {
 		logger.WithError(err).Fatal("Scanner configuration validation failed")
 	}
 
-	// Create scan queue
-	scanQueue := queue.NewScanQueue(cfg.Queue.BufferSize, logger)
+	// Create the durable queue backend selected by cfg.Queue.Backend
+	// ("memory" by default), so queued scans (including pending
+	// retries) survive a pod restart and are shared across
+	// horizontally-scaled webhook replicas instead of living only in
+	// this process's own in-memory channel.
+	queueBackend, err := queue.NewBackend(context.Background(), cfg, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize queue backend")
+	}
 
-	// Create scan handler that uses scanner factory
-	scanHandler := createScanHandler(cfg, logger)
+	leaseTTL, err := cfg.ParseDuration(cfg.Queue.LeaseTTL)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid queue.lease_ttl")
+	}
+
+	retryConfig, err := queue.RetryConfigFromQueueConfig(cfg.Queue)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid queue retry configuration")
+	}
+	retryManager := queue.NewRetryManager(retryConfig, queueBackend, logger)
+	deadLetterStore := queue.NewDeadLetterStore(cfg)
+
+	// Leader-elects the replica that runs the reaper (only meaningful
+	// for the "redis" backend; see queue.NewLeaderElector), then starts
+	// it requeuing leases a crashed worker never acked or nacked.
+	leaderCtx, leaderCancel := context.WithCancel(context.Background())
+	leaderElector, err := queue.NewLeaderElector(leaderCtx, cfg, logger)
+	if err != nil {
+		leaderCancel()
+		logger.WithError(err).Fatal("Failed to initialize queue leader elector")
+	}
+	reaper := queue.NewReaper(queueBackend, leaderElector, leaseTTL/2, logger)
+	go reaper.Start(leaderCtx)
+
+	// Reconciler walks the catalog of any registry configured with
+	// pull_mode "poll" or "both" directly, rather than waiting for that
+	// registry to deliver a webhook, sharing the same dedup store a
+	// webhook-driven scan request would check so a catalog-discovered
+	// image recently seen via a webhook isn't re-enqueued.
+	dedup, err := queue.NewDeduplicator(cfg, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize scan deduplicator")
+	}
+	reconcilerCtx, reconcilerCancel := context.WithCancel(context.Background())
+	imageReconciler := reconciler.NewReconciler(cfg, queueBackend, dedup, logger)
+	imageReconciler.Start(reconcilerCtx)
+
+	// Build one sink.Dispatcher per registry that configures result
+	// sinks, so createScanHandler can fan a completed scan out to
+	// whichever downstream integrations that registry wants without
+	// rebuilding sink clients (Kafka writers, object store clients, ...)
+	// on every scan.
+	sinkDispatchers, err := buildSinkDispatchers(cfg, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to configure result sinks")
+	}
 
-	// Create worker pool
-	workerPool := queue.NewWorkerPool(scanQueue, cfg.Queue.Workers, scanHandler, logger)
+	// Checks a scan request's image signature (and, where configured,
+	// SBOM attestation) against its registry's config.VerificationConfig
+	// before createScanHandler hands the request to a scanner backend.
+	// Registries with no verification block are passed through
+	// unchecked.
+	verifier, err := verify.NewVerifier(cfg, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to configure signature verification")
+	}
 
-	// Create webhook server
-	webhookServer := webhook.NewServer(cfg, logger)
+	// Shared by every scan this process runs: a scanner.EventPublisher
+	// backend (currently scanner.CLIScanner) publishes its lifecycle
+	// events and stderr log lines here, and webhook.Server's
+	// /scans/{request_id}/events endpoint subscribes to serve them over
+	// SSE.
+	eventBus := events.NewBus(0)
+
+	// Create scan handler that uses scanner factory
+	scanHandler := createScanHandler(cfg, logger, sinkDispatchers, eventBus, verifier)
+
+	// Create worker pool, leasing scan requests from queueBackend
+	// instead of dequeuing from an in-memory channel
+	workerPool := queue.NewBackendWorkerPool(queueBackend, retryManager, deadLetterStore, leaseTTL, cfg.Queue.Workers, scanHandler, logger)
+
+	// Create webhook server. Its logger is built from cfg.Logging so
+	// operators can switch it to the log/slog backend (json/text/logfmt)
+	// independently of the logrus setup the rest of the process still
+	// uses.
+	webhookLogger := logging.NewStructuredLogger(logging.LogLevel(cfg.Logging.Level), logging.Format(cfg.Logging.Format))
+	webhookServer := webhook.NewServer(cfg, webhookLogger)
+	webhookServer.SetEventBus(eventBus)
+	webhookServer.SetQueueBackend(queueBackend)
+
+	// Confirms a webhook-parsed image is still pullable from its
+	// registry (auth challenge succeeds, manifest exists) before it's
+	// enqueued, reusing the same credential resolution a scan itself
+	// would use.
+	webhookServer.SetPullabilityChecker(pullability.NewChecker(scanner.NewCredentialProvider(cfg, logger)))
+
+	// Shares one bounded, coalescing result cache across every scan this
+	// process handles; wired into Shutdown so its janitor goroutine stops
+	// with the rest of the server instead of leaking past process exit.
+	cacheTTL, _ := cfg.ParseDuration(cfg.Scanner.CacheTTL)
+	resultProcessor := scanner.NewResultProcessorWithCacheSize(cacheTTL, cfg.Scanner.CacheMaxEntries, webhookLogger)
+	webhookServer.SetResultProcessor(resultProcessor)
+
+	// Configure TLS when server.tls is set, required for any registry
+	// using auth.type "mtls" since the client certificate is verified
+	// during the handshake itself.
+	tlsConfig, err := webhook.NewTLSConfig(cfg)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to configure webhook TLS")
+	}
+	if tlsConfig != nil {
+		webhookServer.SetTLSConfig(tlsConfig)
+	}
 
 	// Setup graceful shutdown
 	shutdownManager := shutdown.NewManager(logger)
@@ -75,8 +187,29 @@ func main() {
 	shutdownManager.RegisterCleanup("worker-pool", func(ctx context.Context) error {
 		return workerPool.Stop(workerShutdownTimeout)
 	})
-	shutdownManager.RegisterCleanup("scan-queue", func(ctx context.Context) error {
-		scanQueue.Close()
+	shutdownManager.RegisterCleanup("queue-leader-election", func(ctx context.Context) error {
+		leaderCancel()
+		return nil
+	})
+	shutdownManager.RegisterCleanup("reconciler", func(ctx context.Context) error {
+		reconcilerCancel()
+		imageReconciler.Stop()
+		return nil
+	})
+	shutdownManager.RegisterCleanup("result-sinks", func(ctx context.Context) error {
+		var errs []error
+		for registry, d := range sinkDispatchers {
+			if err := d.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("registry %s: %w", registry, err))
+			}
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+		return fmt.Errorf("failed to close result sinks: %v", errs)
+	})
+	shutdownManager.RegisterCleanup("scan-events", func(ctx context.Context) error {
+		eventBus.Close()
 		return nil
 	})
 
@@ -87,23 +220,92 @@ func main() {
 	// Mark server as ready
 	webhookServer.SetReady(true)
 
+	// Drain the webhook server (mark it not-ready so load balancers stop
+	// routing to it) without shutting the process down, e.g. ahead of a
+	// planned deploy.
+	shutdownManager.SetDrainFunc(func() {
+		webhookServer.SetReady(false)
+	})
+
+	// Admin API lets operators introspect shutdown handler progress and
+	// trigger a shutdown or drain out-of-band. Disabled unless a port is
+	// configured.
+	if cfg.Admin.Port != 0 {
+		adminServer := shutdown.NewAdminServer(fmt.Sprintf(":%d", cfg.Admin.Port), cfg.Admin.Token, shutdownManager, logger)
+		go func() {
+			if err := adminServer.Start(); err != nil {
+				logger.WithError(err).Error("Admin API server error")
+			}
+		}()
+	}
+
+	// The Harbor scanner adapter lets Harbor register this webhook as a
+	// Pluggable Scanner, submitting scans of its own rather than relying
+	// on registry push webhooks. Disabled unless a port is configured.
+	if cfg.ScannerAdapter.Port != 0 {
+		adapterServer := scanneradapter.NewServer(cfg, logger)
+		shutdownManager.RegisterCleanup("scanner-adapter", func(ctx context.Context) error {
+			return adapterServer.Shutdown(ctx)
+		})
+		go func() {
+			if err := adapterServer.Start(); err != nil {
+				logger.WithError(err).Error("Scanner adapter server error")
+			}
+		}()
+	}
+
+	// Restart manager hands off listeners to a re-exec'd copy of this
+	// binary on SIGHUP for zero-downtime graceful restarts.
+	restartManager := restart.NewManager(logger, shutdownManager.Listeners)
+
+	// If we were re-exec'd as part of a graceful restart, take over the
+	// inherited listener instead of binding a fresh port.
+	inherited, err := restart.TakeOverListeners()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to take over inherited listeners")
+	}
+
+	webhookListener, ok := inherited["webhook"]
+	if !ok {
+		webhookListener, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to bind webhook listener")
+		}
+	}
+
+	// Register the listener so it can be handed off on the next
+	// SIGHUP-triggered restart.
+	shutdownManager.RegisterListener("webhook", webhookListener)
+
 	// Start HTTP server in goroutine
 	serverErr := make(chan error, 1)
 	go func() {
-		if err := webhookServer.Start(); err != nil {
+		if err := webhookServer.StartOnListener(webhookListener); err != nil {
 			serverErr <- err
 		}
 	}()
 
-	// Wait for interrupt signal or server error
+	// Wait for interrupt signal, restart signal, or server error
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	select {
-	case sig := <-sigChan:
-		logger.WithField("signal", sig).Info("Received shutdown signal")
-	case err := <-serverErr:
-		logger.WithError(err).Error("Server error occurred")
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	for {
+		select {
+		case sig := <-sigChan:
+			if sig == syscall.SIGHUP {
+				logger.Info("Received SIGHUP, starting graceful restart")
+				if err := restartManager.Restart(); err != nil {
+					logger.WithError(err).Error("Graceful restart failed, continuing to serve")
+					continue
+				}
+				logger.Info("Handoff complete, draining in-flight work before exit")
+			} else {
+				logger.WithField("signal", sig).Info("Received shutdown signal")
+			}
+		case err := <-serverErr:
+			logger.WithError(err).Error("Server error occurred")
+		}
+		break
 	}
 
 	// Graceful shutdown
@@ -149,8 +351,29 @@ func validateScannerConfig(cfg *config.Config, logger *logrus.Logger) error {
 	return nil
 }
 
+// buildSinkDispatchers builds a sink.Dispatcher for every registry that
+// configures at least one result sink, keyed by registry name. Registries
+// with no sinks configured are absent from the returned map.
+func buildSinkDispatchers(cfg *config.Config, logger *logrus.Logger) (map[string]*sink.Dispatcher, error) {
+	dispatchers := make(map[string]*sink.Dispatcher)
+
+	for _, reg := range cfg.Registries {
+		if len(reg.Sinks) == 0 {
+			continue
+		}
+
+		d, err := sink.NewDispatcher(context.Background(), reg.Sinks, logger)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", reg.Name, err)
+		}
+		dispatchers[reg.Name] = d
+	}
+
+	return dispatchers, nil
+}
+
 // createScanHandler creates a scan handler function that uses the scanner factory
-func createScanHandler(cfg *config.Config, logger *logrus.Logger) queue.ScanHandler {
+func createScanHandler(cfg *config.Config, logger *logrus.Logger, sinkDispatchers map[string]*sink.Dispatcher, eventBus *events.Bus, verifier *verify.Verifier) queue.ScanHandler {
 	return func(ctx context.Context, req *models.ScanRequest) error {
 		scanLogger := logger.WithFields(logrus.Fields{
 			"request_id": req.RequestID,
@@ -158,6 +381,34 @@ func createScanHandler(cfg *config.Config, logger *logrus.Logger) queue.ScanHand
 			"registry":   req.Registry,
 		})
 
+		// Reject unsigned or invalidly-signed images before they ever
+		// reach a scanner backend. Registries with no verification block
+		// configured pass through unchecked.
+		if err := verifier.Verify(ctx, req); err != nil {
+			var rejected *verify.RejectedError
+			if errors.As(err, &rejected) {
+				scanLogger.WithField("reason", rejected.Reason).Warn("Image rejected by signature verification")
+
+				result := &models.ScanResult{
+					ImageRef:    req.ImageRef,
+					RequestID:   req.RequestID,
+					Status:      models.ScanStatusRejected,
+					Error:       rejected.Reason,
+					StartedAt:   time.Now(),
+					CompletedAt: time.Now(),
+				}
+				if d, ok := sinkDispatchers[req.RegistryName]; ok {
+					if err := d.Publish(ctx, result); err != nil {
+						scanLogger.WithError(err).Warn("Failed to publish rejected scan result to one or more sinks")
+					}
+				}
+				return nil
+			}
+
+			scanLogger.WithError(err).Error("Failed to verify image signature")
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+
 		// Create scanner backend using factory
 		// This automatically selects CLI or Registry scanner based on config
 		backend, err := scanner.NewScannerBackend(cfg, req.Registry, scanLogger)
@@ -166,6 +417,13 @@ func createScanHandler(cfg *config.Config, logger *logrus.Logger) queue.ScanHand
 			return fmt.Errorf("scanner backend creation failed: %w", err)
 		}
 
+		// Backends that support it (currently CLIScanner) stream this
+		// scan's lifecycle/log events to eventBus for
+		// webhook.Server's /scans/{request_id}/events SSE endpoint.
+		if publisher, ok := backend.(scanner.EventPublisher); ok {
+			publisher.SetEventBus(eventBus)
+		}
+
 		scanLogger.WithField("scanner_type", backend.Type()).Info("Initiating scan")
 
 		// Execute scan
@@ -182,11 +440,28 @@ func createScanHandler(cfg *config.Config, logger *logrus.Logger) queue.ScanHand
 			return fmt.Errorf("scan execution failed: %w", err)
 		}
 
-		scanLogger.WithFields(logrus.Fields{
+		fields := logrus.Fields{
 			"duration_ms":  duration.Milliseconds(),
 			"scanner_type": backend.Type(),
 			"result_status": result.Status,
-		}).Info("Scan completed successfully")
+		}
+		if result.Report != nil {
+			fields["total_vulnerabilities"] = result.TotalVulnerabilities()
+			fields["has_critical"] = result.HasCritical()
+			fields["failed_policies"] = result.FailedPolicies()
+		}
+		scanLogger.WithFields(fields).Info("Scan completed successfully")
+
+		// Fan the result out to every sink this registry configures, e.g.
+		// a webhook callback or an S3 bucket for downstream security
+		// tooling. Dispatcher retries and dead-letters on its own, so a
+		// sink failure here is logged rather than turned into a scan
+		// failure the queue would retry.
+		if d, ok := sinkDispatchers[req.RegistryName]; ok {
+			if err := d.Publish(ctx, result); err != nil {
+				scanLogger.WithError(err).Warn("Failed to publish scan result to one or more sinks")
+			}
+		}
 
 		return nil
 	}
@@ -0,0 +1,26 @@
+package models
+
+import "context"
+
+// contextKey is a private type for context keys defined in this package,
+// following the standard library's guidance to avoid collisions with
+// keys defined in other packages.
+type contextKey int
+
+const requestIDContextKey contextKey = iota
+
+// WithRequestID returns a copy of ctx carrying requestID, retrievable via
+// RequestIDFromContext. Used to thread the correlation ID assigned by the
+// webhook server's request-ID middleware down into whatever parses,
+// queues, and scans the request, so ScanRequest.RequestID and every log
+// line along the way agree on the same ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stashed on ctx by
+// WithRequestID, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDContextKey).(string)
+	return requestID, ok
+}
@@ -21,6 +21,11 @@ type ScanRequest struct {
 	// Retry tracking
 	RetryCount int
 	MaxRetries int
+	// FirstAttemptAt is when this request was first handed to a
+	// ScanHandler, zero until then. RetryManager.ShouldRetry uses it to
+	// tell a 401/403 caused by registry auth JWT clock skew, seen soon
+	// after the first attempt, from a permanent auth rejection.
+	FirstAttemptAt time.Time
 
 	// Timestamps
 	ReceivedAt time.Time
@@ -49,6 +54,96 @@ type ScanResult struct {
 
 	// Error information
 	Error string
+
+	// Report holds the structured findings parsed from Output, when it
+	// was recognized as Sysdig CLI Scanner JSON. Nil for scans that
+	// failed before producing output, or whose output wasn't JSON this
+	// repo knows how to parse (see scanner.ParseReport).
+	Report *ScanReport
+}
+
+// HasCritical reports whether the scan found any critical-severity
+// vulnerability. Returns false if Report is nil.
+func (r *ScanResult) HasCritical() bool {
+	return r.Report != nil && r.Report.Summary.Critical > 0
+}
+
+// TotalVulnerabilities returns the total vulnerability count across all
+// severities. Returns 0 if Report is nil.
+func (r *ScanResult) TotalVulnerabilities() int {
+	if r.Report == nil {
+		return 0
+	}
+	return r.Report.Summary.Total()
+}
+
+// FailedPolicies returns the names of every policy evaluation that
+// didn't pass. Returns nil if Report is nil.
+func (r *ScanResult) FailedPolicies() []string {
+	if r.Report == nil {
+		return nil
+	}
+
+	var failed []string
+	for _, p := range r.Report.PolicyEvaluations {
+		if !p.Passed {
+			failed = append(failed, p.Name)
+		}
+	}
+	return failed
+}
+
+// ScanReport is the structured form of a Sysdig CLI Scanner JSON report:
+// per-finding vulnerability detail, policy evaluation outcomes, and the
+// image layers they were attributed to.
+type ScanReport struct {
+	Summary           VulnerabilitySummary
+	Vulnerabilities   []Vulnerability
+	PolicyEvaluations []PolicyEvaluation
+	Layers            []ImageLayer
+}
+
+// VulnerabilitySummary counts a report's vulnerabilities by severity.
+type VulnerabilitySummary struct {
+	Critical   int
+	High       int
+	Medium     int
+	Low        int
+	Negligible int
+}
+
+// Total returns the summary's total vulnerability count across all
+// severities.
+func (s VulnerabilitySummary) Total() int {
+	return s.Critical + s.High + s.Medium + s.Low + s.Negligible
+}
+
+// Vulnerability is a single finding within a ScanReport.
+type Vulnerability struct {
+	ID             string
+	Severity       string
+	PackageName    string
+	PackageVersion string
+	FixedVersion   string
+	Description    string
+	CVSSScore      float64
+	CVSSVector     string
+	Link           string
+	LayerDigest    string
+}
+
+// PolicyEvaluation is one named policy's pass/fail outcome from a scan.
+type PolicyEvaluation struct {
+	Name   string
+	Passed bool
+}
+
+// ImageLayer is one layer of the scanned image, as reported by the
+// scanner.
+type ImageLayer struct {
+	Digest string
+	Size   int64
+	Index  int
 }
 
 // ScanStatus represents the status of a scan
@@ -61,11 +156,17 @@ const (
 	ScanStatusFailed     ScanStatus = "failed"
 	ScanStatusTimeout    ScanStatus = "timeout"
 	ScanStatusRetrying   ScanStatus = "retrying"
+	// ScanStatusRejected marks a request that never reached the scanner
+	// backend because verify.Verifier rejected the image's signature (or
+	// found none), e.g. an unsigned image or one signed by an identity
+	// the registry's config.VerificationConfig doesn't trust. Error holds
+	// the rejection reason.
+	ScanStatusRejected ScanStatus = "rejected"
 )
 
 // IsComplete returns true if the scan has reached a terminal state
 func (s ScanStatus) IsComplete() bool {
-	return s == ScanStatusSuccess || s == ScanStatusFailed || s == ScanStatusTimeout
+	return s == ScanStatusSuccess || s == ScanStatusFailed || s == ScanStatusTimeout || s == ScanStatusRejected
 }
 
 // ShouldRetry determines if a scan failure should be retried
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"strings"
@@ -29,23 +30,9 @@ func VerifyBearerToken(r *http.Request, expectedToken string) error {
 	}
 
 	// Compare tokens (constant-time comparison to prevent timing attacks)
-	if !constantTimeCompare(token, expectedToken) {
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expectedToken)) != 1 {
 		return fmt.Errorf("invalid bearer token")
 	}
 
 	return nil
 }
-
-// constantTimeCompare performs a constant-time string comparison
-func constantTimeCompare(a, b string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-
-	result := 0
-	for i := 0; i < len(a); i++ {
-		result |= int(a[i]) ^ int(b[i])
-	}
-
-	return result == 0
-}
@@ -80,52 +80,3 @@ func TestVerifyBearerToken(t *testing.T) {
 		})
 	}
 }
-
-func TestConstantTimeCompare(t *testing.T) {
-	tests := []struct {
-		name string
-		a    string
-		b    string
-		want bool
-	}{
-		{
-			name: "equal strings",
-			a:    "test-token-123",
-			b:    "test-token-123",
-			want: true,
-		},
-		{
-			name: "different strings same length",
-			a:    "test-token-123",
-			b:    "test-token-456",
-			want: false,
-		},
-		{
-			name: "different lengths",
-			a:    "short",
-			b:    "much-longer-string",
-			want: false,
-		},
-		{
-			name: "empty strings",
-			a:    "",
-			b:    "",
-			want: true,
-		},
-		{
-			name: "one empty",
-			a:    "token",
-			b:    "",
-			want: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := constantTimeCompare(tt.a, tt.b)
-			if got != tt.want {
-				t.Errorf("constantTimeCompare(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
-			}
-		})
-	}
-}
@@ -1,59 +1,185 @@
 package auth
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// VerifyHMAC verifies the HMAC signature in the webhook request
-func VerifyHMAC(r *http.Request, secret string) error {
-	// Read the signature from the header
-	signature := r.Header.Get("X-Hub-Signature-256")
+const (
+	// DefaultSignatureHeader is the header VerifyHMACSignature reads the
+	// "sha256=<hex>" signature from when HMACOptions.SignatureHeader is
+	// empty.
+	DefaultSignatureHeader = "X-Registry-Signature"
+	// DefaultTimestampHeader is the header VerifyHMACSignature reads the
+	// signing Unix timestamp from when HMACOptions.TimestampHeader is
+	// empty.
+	DefaultTimestampHeader = "X-Registry-Timestamp"
+)
+
+// HMACOptions configures VerifyHMACSignature's header names and replay
+// protection window. Zero-value fields fall back to DefaultSignatureHeader
+// and DefaultTimestampHeader; a zero MaxSkew disables timestamp
+// verification entirely, so a request carrying no timestamp header is
+// still accepted.
+type HMACOptions struct {
+	SignatureHeader string
+	TimestampHeader string
+	MaxSkew         time.Duration
+
+	// ReplayProtection, when Enabled, supersedes the MaxSkew check with
+	// timestamp-bound signing and a nonce cache rejecting exact
+	// replays. See ReplayProtectionOptions.
+	ReplayProtection ReplayProtectionOptions
+}
+
+// VerifyHMACSignature verifies the HMAC-SHA256 signature of a webhook
+// request body against a per-registry shared secret. The signature is read
+// from opts.SignatureHeader (default DefaultSignatureHeader) in the form
+// "sha256=<hex>", matching how Harbor, Quay, and GHCR sign their webhook
+// payloads.
+//
+// When opts.MaxSkew is non-zero, the request must also carry
+// opts.TimestampHeader (default DefaultTimestampHeader) set to a Unix
+// timestamp within MaxSkew of the current time, rejecting replayed
+// requests.
+//
+// When opts.ReplayProtection.Enabled instead, the signing timestamp is
+// folded into the HMAC input as "<timestamp>.<body>" and a successful
+// signature is recorded in opts.ReplayProtection.Cache, so a captured
+// request can't be replayed again even within the tolerance window. This
+// supersedes the MaxSkew check for the request.
+func VerifyHMACSignature(r *http.Request, secret string, opts HMACOptions) error {
+	sigHeader := opts.SignatureHeader
+	if sigHeader == "" {
+		sigHeader = DefaultSignatureHeader
+	}
+
+	signature := r.Header.Get(sigHeader)
 	if signature == "" {
-		// Try alternative header names
-		signature = r.Header.Get("X-Signature")
-		if signature == "" {
-			return fmt.Errorf("missing HMAC signature header")
-		}
+		return fmt.Errorf("missing %s header", sigHeader)
 	}
 
-	// Parse signature (format: "sha256=<hex>")
-	parts := strings.SplitN(signature, "=", 2)
-	if len(parts) != 2 {
+	algorithm, providedSignature, ok := strings.Cut(signature, "=")
+	if !ok {
 		return fmt.Errorf("invalid signature format")
 	}
-
-	algorithm := parts[0]
-	providedSignature := parts[1]
-
-	// Only support SHA256
 	if algorithm != "sha256" {
 		return fmt.Errorf("unsupported signature algorithm: %s", algorithm)
 	}
 
-	// Read the request body
+	var timestampValue string
+	if opts.ReplayProtection.Enabled {
+		var err error
+		timestampValue, err = verifyReplayTimestamp(r, opts.ReplayProtection)
+		if err != nil {
+			return err
+		}
+	} else if err := verifyTimestamp(r, opts); err != nil {
+		return err
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read request body: %w", err)
 	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
 
-	// Restore the body for later reading
-	r.Body = io.NopCloser(strings.NewReader(string(body)))
-
-	// Compute HMAC
 	mac := hmac.New(sha256.New, []byte(secret))
+	if opts.ReplayProtection.Enabled {
+		mac.Write([]byte(timestampValue))
+		mac.Write([]byte("."))
+	}
 	mac.Write(body)
 	expectedSignature := hex.EncodeToString(mac.Sum(nil))
 
-	// Compare signatures
-	if !hmac.Equal([]byte(expectedSignature), []byte(providedSignature)) {
+	if subtle.ConstantTimeCompare([]byte(expectedSignature), []byte(providedSignature)) != 1 {
 		return fmt.Errorf("HMAC signature mismatch")
 	}
 
+	if opts.ReplayProtection.Enabled && opts.ReplayProtection.Cache != nil {
+		key := opts.ReplayProtection.RegistryName + ":" + providedSignature
+		if !opts.ReplayProtection.Cache.CheckAndStore(key) {
+			return fmt.Errorf("replayed webhook signature")
+		}
+	}
+
+	return nil
+}
+
+// verifyReplayTimestamp reads and validates the signing timestamp for
+// ReplayProtectionOptions, returning the raw header value so the caller
+// can fold it into the HMAC input exactly as the client signed it.
+func verifyReplayTimestamp(r *http.Request, opts ReplayProtectionOptions) (string, error) {
+	tsHeader := opts.TimestampHeader
+	if tsHeader == "" {
+		tsHeader = DefaultReplayTimestampHeader
+	}
+
+	value := r.Header.Get(tsHeader)
+	if value == "" {
+		return "", fmt.Errorf("missing %s header", tsHeader)
+	}
+
+	ts, err := parseReplayTimestamp(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s header: %w", tsHeader, err)
+	}
+
+	tolerance := opts.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultReplayTolerance
+	}
+
+	skew := time.Since(ts)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > tolerance {
+		return "", fmt.Errorf("%s is outside the allowed %s tolerance", tsHeader, tolerance)
+	}
+
+	return value, nil
+}
+
+// verifyTimestamp rejects requests whose signing timestamp has drifted
+// beyond opts.MaxSkew from the server clock. It is a no-op when MaxSkew is
+// zero, since replay protection is opt-in per registry.
+func verifyTimestamp(r *http.Request, opts HMACOptions) error {
+	if opts.MaxSkew <= 0 {
+		return nil
+	}
+
+	tsHeader := opts.TimestampHeader
+	if tsHeader == "" {
+		tsHeader = DefaultTimestampHeader
+	}
+
+	value := r.Header.Get(tsHeader)
+	if value == "" {
+		return fmt.Errorf("missing %s header", tsHeader)
+	}
+
+	unixSeconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header: %w", tsHeader, err)
+	}
+
+	skew := time.Since(time.Unix(unixSeconds, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > opts.MaxSkew {
+		return fmt.Errorf("%s is outside the allowed %s skew", tsHeader, opts.MaxSkew)
+	}
+
 	return nil
 }
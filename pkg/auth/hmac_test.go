@@ -8,10 +8,12 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 )
 
-func TestVerifyHMAC(t *testing.T) {
+func TestVerifyHMACSignature(t *testing.T) {
 	secret := "test-secret-key"
 	payload := []byte(`{"test":"data"}`)
 
@@ -31,7 +33,7 @@ func TestVerifyHMAC(t *testing.T) {
 				mac := hmac.New(sha256.New, []byte(s))
 				mac.Write(p)
 				sig := hex.EncodeToString(mac.Sum(nil))
-				r.Header.Set("X-Hub-Signature-256", "sha256="+sig)
+				r.Header.Set(DefaultSignatureHeader, "sha256="+sig)
 			},
 			wantErr: false,
 		},
@@ -43,14 +45,14 @@ func TestVerifyHMAC(t *testing.T) {
 				// Don't set header
 			},
 			wantErr:     true,
-			errContains: "missing HMAC signature",
+			errContains: "missing X-Registry-Signature",
 		},
 		{
 			name:    "invalid signature format",
 			payload: payload,
 			secret:  secret,
 			setupHeader: func(r *http.Request, p []byte, s string) {
-				r.Header.Set("X-Hub-Signature-256", "invalid-format")
+				r.Header.Set(DefaultSignatureHeader, "invalid-format")
 			},
 			wantErr:     true,
 			errContains: "invalid signature format",
@@ -60,7 +62,7 @@ func TestVerifyHMAC(t *testing.T) {
 			payload: payload,
 			secret:  secret,
 			setupHeader: func(r *http.Request, p []byte, s string) {
-				r.Header.Set("X-Hub-Signature-256", "sha256=wrongsignature")
+				r.Header.Set(DefaultSignatureHeader, "sha256=wrongsignature")
 			},
 			wantErr:     true,
 			errContains: "signature mismatch",
@@ -70,7 +72,7 @@ func TestVerifyHMAC(t *testing.T) {
 			payload: payload,
 			secret:  secret,
 			setupHeader: func(r *http.Request, p []byte, s string) {
-				r.Header.Set("X-Hub-Signature-256", "md5=somehash")
+				r.Header.Set(DefaultSignatureHeader, "md5=somehash")
 			},
 			wantErr:     true,
 			errContains: "unsupported signature algorithm",
@@ -82,23 +84,23 @@ func TestVerifyHMAC(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(tt.payload))
 			tt.setupHeader(req, tt.payload, tt.secret)
 
-			err := VerifyHMAC(req, tt.secret)
+			err := VerifyHMACSignature(req, tt.secret, HMACOptions{})
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("VerifyHMAC() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("VerifyHMACSignature() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
 			if err != nil && tt.errContains != "" {
 				if !contains(err.Error(), tt.errContains) {
-					t.Errorf("VerifyHMAC() error = %v, want error containing %v", err, tt.errContains)
+					t.Errorf("VerifyHMACSignature() error = %v, want error containing %v", err, tt.errContains)
 				}
 			}
 		})
 	}
 }
 
-func TestVerifyHMAC_AlternativeHeader(t *testing.T) {
+func TestVerifyHMACSignature_CustomHeader(t *testing.T) {
 	secret := "test-secret"
 	payload := []byte(`{"test":"data"}`)
 
@@ -107,15 +109,15 @@ func TestVerifyHMAC_AlternativeHeader(t *testing.T) {
 	sig := hex.EncodeToString(mac.Sum(nil))
 
 	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
-	req.Header.Set("X-Signature", "sha256="+sig)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+sig)
 
-	err := VerifyHMAC(req, secret)
+	err := VerifyHMACSignature(req, secret, HMACOptions{SignatureHeader: "X-Hub-Signature-256"})
 	if err != nil {
-		t.Errorf("VerifyHMAC() with X-Signature header failed: %v", err)
+		t.Errorf("VerifyHMACSignature() with custom header failed: %v", err)
 	}
 }
 
-func TestVerifyHMAC_BodyReusable(t *testing.T) {
+func TestVerifyHMACSignature_BodyReusable(t *testing.T) {
 	secret := "test-secret"
 	payload := []byte(`{"test":"data"}`)
 
@@ -124,15 +126,13 @@ func TestVerifyHMAC_BodyReusable(t *testing.T) {
 	sig := hex.EncodeToString(mac.Sum(nil))
 
 	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
-	req.Header.Set("X-Hub-Signature-256", "sha256="+sig)
+	req.Header.Set(DefaultSignatureHeader, "sha256="+sig)
 
-	// Verify HMAC
-	err := VerifyHMAC(req, secret)
+	err := VerifyHMACSignature(req, secret, HMACOptions{})
 	if err != nil {
-		t.Fatalf("VerifyHMAC() failed: %v", err)
+		t.Fatalf("VerifyHMACSignature() failed: %v", err)
 	}
 
-	// Body should still be readable
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
 		t.Fatalf("Failed to read body after verification: %v", err)
@@ -143,6 +143,83 @@ func TestVerifyHMAC_BodyReusable(t *testing.T) {
 	}
 }
 
+func TestVerifyHMACSignature_Timestamp(t *testing.T) {
+	secret := "test-secret"
+	payload := []byte(`{"test":"data"}`)
+
+	sign := func(r *http.Request) {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		sig := hex.EncodeToString(mac.Sum(nil))
+		r.Header.Set(DefaultSignatureHeader, "sha256="+sig)
+	}
+
+	tests := []struct {
+		name        string
+		timestamp   *time.Time
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:      "fresh timestamp within skew",
+			timestamp: timePtr(time.Now().Add(-1 * time.Minute)),
+			wantErr:   false,
+		},
+		{
+			name:        "stale timestamp outside skew",
+			timestamp:   timePtr(time.Now().Add(-1 * time.Hour)),
+			wantErr:     true,
+			errContains: "outside the allowed",
+		},
+		{
+			name:        "missing timestamp when required",
+			timestamp:   nil,
+			wantErr:     true,
+			errContains: "missing X-Registry-Timestamp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+			sign(req)
+			if tt.timestamp != nil {
+				req.Header.Set(DefaultTimestampHeader, strconv.FormatInt(tt.timestamp.Unix(), 10))
+			}
+
+			err := VerifyHMACSignature(req, secret, HMACOptions{MaxSkew: 5 * time.Minute})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyHMACSignature() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+				t.Errorf("VerifyHMACSignature() error = %v, want error containing %v", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestVerifyHMACSignature_TimestampNotRequiredByDefault(t *testing.T) {
+	secret := "test-secret"
+	payload := []byte(`{"test":"data"}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header.Set(DefaultSignatureHeader, "sha256="+sig)
+
+	if err := VerifyHMACSignature(req, secret, HMACOptions{}); err != nil {
+		t.Errorf("VerifyHMACSignature() without MaxSkew configured should ignore timestamps, got: %v", err)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && containsSubstring(s, substr))
 }
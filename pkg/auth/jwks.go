@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSRefreshInterval is how often a jwksCache re-fetches its JWKS
+// document when JWTConfig.JWKSRefreshInterval is unset.
+const DefaultJWKSRefreshInterval = 15 * time.Minute
+
+// jwksCaches holds one jwksCache per JWKS URL, so repeated VerifyJWT calls
+// against the same registry share a single cache and refresh schedule
+// instead of fetching the JWKS document on every request.
+var jwksCaches sync.Map // map[string]*jwksCache
+
+// jwk is a single entry of a JWKS document's "keys" array. Only the RSA
+// fields are modeled, since RS256 is what GitHub Actions, GitLab CI, and
+// Harbor robot tokens issue in practice.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwksCache fetches a JWKS document over HTTP and caches its keys by
+// `kid`, refetching the whole document once refreshInterval has elapsed
+// since the last fetch, so a provider rotating keys is picked up without
+// a restart.
+type jwksCache struct {
+	url             string
+	refreshInterval time.Duration
+	httpClient      *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// getJWKSCache returns the shared jwksCache for url, creating it on first
+// use.
+func getJWKSCache(url string, refreshInterval time.Duration) *jwksCache {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJWKSRefreshInterval
+	}
+
+	if existing, ok := jwksCaches.Load(url); ok {
+		return existing.(*jwksCache)
+	}
+
+	cache := &jwksCache{
+		url:             url,
+		refreshInterval: refreshInterval,
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+	}
+	actual, _ := jwksCaches.LoadOrStore(url, cache)
+	return actual.(*jwksCache)
+}
+
+// Key returns the RSA public key for kid, fetching (or refreshing) the
+// JWKS document if it hasn't been fetched yet or the cache has gone
+// stale.
+func (c *jwksCache) Key(kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keys == nil || time.Since(c.fetchedAt) > c.refreshInterval {
+		if err := c.refresh(); err != nil {
+			if c.keys == nil {
+				return nil, err
+			}
+			// A stale cache beats a hard failure: keep serving the last
+			// known keys and try again next call.
+		}
+	}
+
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("jwks: no key found for kid %q at %s", kid, c.url)
+	}
+	return key, nil
+}
+
+// refresh fetches c.url and replaces c.keys. Caller must hold c.mu.
+func (c *jwksCache) refresh() error {
+	resp, err := c.httpClient.Get(c.url)
+	if err != nil {
+		return fmt.Errorf("jwks: failed to fetch %s: %w", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks: fetching %s returned status %d", c.url, resp.StatusCode)
+	}
+
+	var doc jwksDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("jwks: failed to decode %s: %w", c.url, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+
+		pubKey, err := rsaPublicKeyFromJWK(k)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pubKey
+	}
+
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+// rsaPublicKeyFromJWK decodes an RSA JWK's base64url-encoded modulus and
+// exponent into an *rsa.PublicKey.
+func rsaPublicKeyFromJWK(k jwk) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: invalid modulus for kid %q: %w", k.Kid, err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: invalid exponent for kid %q: %w", k.Kid, err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// Claims holds the fields VerifyJWT extracts from a verified token, for
+// callers that want to log or authorize on them without depending on the
+// jwt library directly.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+}
+
+// VerifyJWT verifies the bearer token on r against cfg: signature (HMAC
+// shared secret, a static RSA public key, or a JWKS endpoint cached by
+// `kid`), `exp`/`nbf`, and, if configured, `iss`, `aud`, and a `sub`
+// allowlist. This is the auth.Type = "jwt" counterpart to VerifyHMACSignature
+// and VerifyBearerToken, for registries (or the CI systems triggering
+// their webhooks) that authenticate with a signed JWT rather than a
+// shared secret - GitLab/GitHub Actions OIDC tokens, or a Harbor robot
+// account JWT.
+func VerifyJWT(r *http.Request, cfg config.JWTConfig) (*Claims, error) {
+	tokenString, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFunc, err := jwtKeyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	parserOpts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(cfg.Audience))
+	}
+
+	var registered jwt.RegisteredClaims
+	token, err := jwt.ParseWithClaims(tokenString, &registered, keyFunc, parserOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("jwt verification failed: %w", err)
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("jwt verification failed: token not valid")
+	}
+
+	if len(cfg.SubjectAllowlist) > 0 && !subjectAllowed(cfg.SubjectAllowlist, registered.Subject) {
+		return nil, fmt.Errorf("jwt subject %q is not in the configured allowlist", registered.Subject)
+	}
+
+	claims := &Claims{
+		Issuer:   registered.Issuer,
+		Subject:  registered.Subject,
+		Audience: registered.Audience,
+	}
+	if registered.ExpiresAt != nil {
+		claims.ExpiresAt = registered.ExpiresAt.Time
+	}
+	if registered.NotBefore != nil {
+		claims.NotBefore = registered.NotBefore.Time
+	}
+
+	return claims, nil
+}
+
+// jwtKeyFunc builds the jwt.Keyfunc VerifyJWT uses to resolve the key a
+// token was signed with, restricted to the signing method implied by
+// cfg's configured key source so a token can't switch algorithms
+// (e.g. HS256 with the RSA public key as the secret) to bypass
+// verification.
+func jwtKeyFunc(cfg config.JWTConfig) (jwt.Keyfunc, error) {
+	switch {
+	case cfg.Secret != "":
+		return func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(cfg.Secret), nil
+		}, nil
+
+	case cfg.PublicKey != "":
+		publicKey, err := parseRSAPublicKeyPEM(cfg.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth.jwt.public_key: %w", err)
+		}
+		return func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return publicKey, nil
+		}, nil
+
+	case cfg.JWKSURL != "":
+		var refreshInterval time.Duration
+		if cfg.JWKSRefreshInterval != "" {
+			refreshInterval, _ = time.ParseDuration(cfg.JWKSRefreshInterval)
+		}
+		cache := getJWKSCache(cfg.JWKSURL, refreshInterval)
+
+		return func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			kid, ok := token.Header["kid"].(string)
+			if !ok || kid == "" {
+				return nil, fmt.Errorf("token header is missing kid")
+			}
+			return cache.Key(kid)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("auth.jwt requires one of secret, public_key, or jwks_url")
+	}
+}
+
+// parseRSAPublicKeyPEM decodes a PEM-encoded RSA public key, accepting
+// both PKIX ("PUBLIC KEY") and PKCS1 ("RSA PUBLIC KEY") encodings.
+func parseRSAPublicKeyPEM(pemData string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// bearerToken extracts the raw token from r's "Authorization: Bearer
+// <token>" header.
+func bearerToken(r *http.Request) (string, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", fmt.Errorf("missing Authorization header")
+	}
+
+	scheme, token, ok := strings.Cut(authHeader, " ")
+	if !ok {
+		return "", fmt.Errorf("invalid Authorization header format")
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("invalid authorization scheme: %s", scheme)
+	}
+
+	return token, nil
+}
+
+// subjectAllowed reports whether target appears in allowlist.
+func subjectAllowed(allowlist []string, target string) bool {
+	for _, v := range allowlist {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func signHS256(t *testing.T, secret string, claims jwt.RegisteredClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign test token: %v", err)
+	}
+	return signed
+}
+
+func TestVerifyJWT_HMAC(t *testing.T) {
+	secret := "jwt-test-secret"
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		cfg         config.JWTConfig
+		claims      jwt.RegisteredClaims
+		noHeader    bool
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "valid token",
+			cfg:  config.JWTConfig{Secret: secret},
+			claims: jwt.RegisteredClaims{
+				Subject:   "ci-runner",
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+			wantErr: false,
+		},
+		{
+			name: "expired token",
+			cfg:  config.JWTConfig{Secret: secret},
+			claims: jwt.RegisteredClaims{
+				Subject:   "ci-runner",
+				ExpiresAt: jwt.NewNumericDate(now.Add(-time.Hour)),
+			},
+			wantErr:     true,
+			errContains: "jwt verification failed",
+		},
+		{
+			name: "issuer mismatch",
+			cfg:  config.JWTConfig{Secret: secret, Issuer: "https://expected.example.com"},
+			claims: jwt.RegisteredClaims{
+				Issuer:    "https://other.example.com",
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "audience mismatch",
+			cfg:  config.JWTConfig{Secret: secret, Audience: "registry-webhook"},
+			claims: jwt.RegisteredClaims{
+				Audience:  jwt.ClaimStrings{"some-other-service"},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "subject not in allowlist",
+			cfg:  config.JWTConfig{Secret: secret, SubjectAllowlist: []string{"allowed-runner"}},
+			claims: jwt.RegisteredClaims{
+				Subject:   "other-runner",
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+			wantErr:     true,
+			errContains: "allowlist",
+		},
+		{
+			name: "subject in allowlist",
+			cfg:  config.JWTConfig{Secret: secret, SubjectAllowlist: []string{"allowed-runner"}},
+			claims: jwt.RegisteredClaims{
+				Subject:   "allowed-runner",
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+			wantErr: false,
+		},
+		{
+			name:     "missing authorization header",
+			cfg:      config.JWTConfig{Secret: secret},
+			noHeader: true,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			if !tt.noHeader {
+				req.Header.Set("Authorization", "Bearer "+signHS256(t, secret, tt.claims))
+			}
+
+			claims, err := VerifyJWT(req, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.errContains != "" && !containsSubstring(err.Error(), tt.errContains) {
+				t.Errorf("VerifyJWT() error = %v, want containing %q", err, tt.errContains)
+			}
+			if !tt.wantErr && claims.Subject != tt.claims.Subject {
+				t.Errorf("VerifyJWT() claims.Subject = %q, want %q", claims.Subject, tt.claims.Subject)
+			}
+		})
+	}
+}
+
+func TestVerifyJWT_WrongSigningMethod(t *testing.T) {
+	// A token signed with HS256 must not be accepted against an RSA public
+	// key config, and vice versa - otherwise a caller could forge a token
+	// using the RSA public key bytes as an HMAC secret.
+	secret := "jwt-test-secret"
+	token := signHS256(t, secret, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal RSA public key: %v", err)
+	}
+	pubPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	_, err = VerifyJWT(req, config.JWTConfig{PublicKey: pubPEM})
+	if err == nil {
+		t.Fatal("VerifyJWT() expected error for HS256 token against an RSA-only config, got nil")
+	}
+}
@@ -3,27 +3,52 @@ package auth
 import (
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
 )
 
-// Authenticator handles webhook authentication
-type Authenticator struct {
+// RequestAuthenticator handles webhook authentication, picking bearer-token,
+// HMAC-signature, JWT, or mTLS verification per registry based on that
+// registry's configured auth.type.
+type RequestAuthenticator struct {
 	config *config.Config
 	logger *logrus.Logger
+
+	nonceCachesMu sync.Mutex
+	nonceCaches   map[string]*NonceCache
 }
 
-// NewAuthenticator creates a new Authenticator instance
-func NewAuthenticator(cfg *config.Config, logger *logrus.Logger) *Authenticator {
-	return &Authenticator{
+// NewRequestAuthenticator creates a new RequestAuthenticator instance
+func NewRequestAuthenticator(cfg *config.Config, logger *logrus.Logger) *RequestAuthenticator {
+	return &RequestAuthenticator{
 		config: cfg,
 		logger: logger,
 	}
 }
 
+// nonceCacheFor returns the registry's replay-protection NonceCache,
+// creating it on first use. Caches are kept per-registry so each
+// registry's cache_size/tolerance settings apply independently.
+func (a *RequestAuthenticator) nonceCacheFor(registryName string, cacheSize int, tolerance time.Duration) *NonceCache {
+	a.nonceCachesMu.Lock()
+	defer a.nonceCachesMu.Unlock()
+
+	if a.nonceCaches == nil {
+		a.nonceCaches = make(map[string]*NonceCache)
+	}
+	cache, ok := a.nonceCaches[registryName]
+	if !ok {
+		cache = NewNonceCache(cacheSize, tolerance)
+		a.nonceCaches[registryName] = cache
+	}
+	return cache
+}
+
 // Middleware returns an HTTP middleware that authenticates webhook requests
-func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+func (a *RequestAuthenticator) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Determine which registry this webhook is for
 		// For now, we'll try to authenticate against all configured registries
@@ -34,29 +59,11 @@ func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 		var lastError error
 
 		for _, registry := range a.config.Registries {
-			var err error
-
-			switch registry.Auth.Type {
-			case "hmac":
-				err = VerifyHMAC(r, registry.Auth.Secret)
-			case "bearer":
-				err = VerifyBearerToken(r, registry.Auth.Secret)
-			case "none":
-				// No authentication required
-				authenticated = true
-				continue
-			default:
-				a.logger.WithFields(logrus.Fields{
-					"registry": registry.Name,
-					"auth_type": registry.Auth.Type,
-				}).Warn("Unknown authentication type")
-				continue
-			}
-
+			err := a.verify(r, registry.Name, registry.Auth)
 			if err == nil {
 				authenticated = true
 				a.logger.WithFields(logrus.Fields{
-					"registry": registry.Name,
+					"registry":  registry.Name,
 					"auth_type": registry.Auth.Type,
 				}).Debug("Webhook authenticated")
 				break
@@ -68,7 +75,7 @@ func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 		if !authenticated {
 			a.logger.WithFields(logrus.Fields{
 				"remote_addr": r.RemoteAddr,
-				"error": lastError,
+				"error":       lastError,
 			}).Warn("Authentication failed")
 
 			w.WriteHeader(http.StatusUnauthorized)
@@ -81,7 +88,7 @@ func (a *Authenticator) Middleware(next http.Handler) http.Handler {
 }
 
 // AuthenticateRegistry authenticates a request for a specific registry
-func (a *Authenticator) AuthenticateRegistry(r *http.Request, registryName string) error {
+func (a *RequestAuthenticator) AuthenticateRegistry(r *http.Request, registryName string) error {
 	// Find registry config
 	var registryConfig *config.RegistryConfig
 	for i := range a.config.Registries {
@@ -95,15 +102,75 @@ func (a *Authenticator) AuthenticateRegistry(r *http.Request, registryName strin
 		return fmt.Errorf("registry not found: %s", registryName)
 	}
 
-	// Verify based on auth type
-	switch registryConfig.Auth.Type {
+	return a.verify(r, registryName, registryConfig.Auth)
+}
+
+// verify authenticates r against a single registry's auth configuration,
+// dispatching to bearer-token, HMAC-signature, JWT, or mTLS verification
+// depending on auth.Type. When auth.Type is "either", the mechanism is picked by
+// whether the request carries a signature header, so a registry can
+// migrate from bearer tokens to HMAC signing without a config change per
+// webhook delivery.
+func (a *RequestAuthenticator) verify(r *http.Request, registryName string, auth config.AuthConfig) error {
+	switch auth.Type {
 	case "hmac":
-		return VerifyHMAC(r, registryConfig.Auth.Secret)
+		return VerifyHMACSignature(r, auth.Secret, a.hmacOptions(registryName, auth))
 	case "bearer":
-		return VerifyBearerToken(r, registryConfig.Auth.Secret)
+		return VerifyBearerToken(r, auth.Secret)
+	case "jwt":
+		_, err := VerifyJWT(r, auth.JWT)
+		return err
+	case "mtls":
+		return VerifyMTLS(r, auth.MTLS)
+	case "either":
+		opts := a.hmacOptions(registryName, auth)
+		sigHeader := opts.SignatureHeader
+		if sigHeader == "" {
+			sigHeader = DefaultSignatureHeader
+		}
+		if r.Header.Get(sigHeader) != "" {
+			return VerifyHMACSignature(r, auth.Secret, opts)
+		}
+		return VerifyBearerToken(r, auth.Secret)
 	case "none":
 		return nil
 	default:
-		return fmt.Errorf("unsupported auth type: %s", registryConfig.Auth.Type)
+		return fmt.Errorf("unsupported auth type: %s", auth.Type)
+	}
+}
+
+// hmacOptions builds the HMACOptions for auth, parsing MaxSkew and falling
+// back to no replay protection if it is unset or malformed. When
+// auth.ReplayProtection is enabled, it also resolves this registry's
+// NonceCache so repeated deliveries of the same signature are rejected.
+func (a *RequestAuthenticator) hmacOptions(registryName string, auth config.AuthConfig) HMACOptions {
+	opts := HMACOptions{
+		SignatureHeader: auth.SignatureHeader,
+		TimestampHeader: auth.TimestampHeader,
+	}
+
+	if auth.MaxSkew != "" {
+		if skew, err := time.ParseDuration(auth.MaxSkew); err == nil {
+			opts.MaxSkew = skew
+		}
 	}
+
+	if auth.ReplayProtection.Enabled {
+		tolerance := DefaultReplayTolerance
+		if auth.ReplayProtection.Tolerance != "" {
+			if d, err := time.ParseDuration(auth.ReplayProtection.Tolerance); err == nil {
+				tolerance = d
+			}
+		}
+
+		opts.ReplayProtection = ReplayProtectionOptions{
+			Enabled:         true,
+			RegistryName:    registryName,
+			Tolerance:       tolerance,
+			TimestampHeader: auth.ReplayProtection.TimestampHeader,
+			Cache:           a.nonceCacheFor(registryName, auth.ReplayProtection.CacheSize, tolerance),
+		}
+	}
+
+	return opts
 }
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// VerifyMTLS authenticates r using the client certificate chain TLS
+// already verified against the server's configured client CA pool (see
+// webhook.NewTLSConfig, which sets tls.Config{ClientAuth:
+// tls.RequireAndVerifyClientCert}). It additionally matches the leaf
+// certificate's identity against cfg's allowlists, since chaining to a
+// trusted CA only proves the client holds some certificate that CA
+// issued, not that it's the specific workload this registry expects.
+func VerifyMTLS(r *http.Request, cfg config.MTLSConfig) error {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return fmt.Errorf("no verified client certificate presented")
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+
+	now := time.Now()
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return fmt.Errorf("client certificate is not currently valid (not before %s, not after %s)", leaf.NotBefore, leaf.NotAfter)
+	}
+
+	if len(cfg.AllowedDNSNames) == 0 && len(cfg.AllowedURIs) == 0 && len(cfg.AllowedSubjects) == 0 {
+		return fmt.Errorf("mtls auth requires at least one identity allowlist to be configured")
+	}
+
+	if matchesMTLSIdentity(leaf, cfg) {
+		return nil
+	}
+
+	return fmt.Errorf("client certificate identity not in allowlist")
+}
+
+// matchesMTLSIdentity reports whether leaf's DNS SANs, URI SANs, or
+// subject match any entry in cfg's allowlists.
+func matchesMTLSIdentity(leaf *x509.Certificate, cfg config.MTLSConfig) bool {
+	for _, want := range cfg.AllowedDNSNames {
+		for _, got := range leaf.DNSNames {
+			if got == want {
+				return true
+			}
+		}
+	}
+
+	for _, want := range cfg.AllowedURIs {
+		for _, got := range leaf.URIs {
+			if got.String() == want {
+				return true
+			}
+		}
+	}
+
+	for _, want := range cfg.AllowedSubjects {
+		if leaf.Subject.CommonName == want || strings.EqualFold(leaf.Subject.String(), want) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// issueTestCert generates an ephemeral leaf certificate signed by a
+// fresh ephemeral CA, with the given SANs and validity window.
+func issueTestCert(t *testing.T, dnsNames []string, uris []string, commonName string, notBefore, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA cert: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("parse CA cert: %v", err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate leaf key: %v", err)
+	}
+
+	uriSANs := make([]*url.URL, 0, len(uris))
+	for _, u := range uris {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			t.Fatalf("parse URI SAN %q: %v", u, err)
+		}
+		uriSANs = append(uriSANs, parsed)
+	}
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		URIs:         uriSANs,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create leaf cert: %v", err)
+	}
+	leafCert, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatalf("parse leaf cert: %v", err)
+	}
+
+	return leafCert
+}
+
+func requestWithVerifiedLeaf(leaf *x509.Certificate) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{leaf}},
+	}
+	return req
+}
+
+func TestVerifyMTLS(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		leaf        *x509.Certificate
+		cfg         config.MTLSConfig
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "matching DNS SAN",
+			leaf: issueTestCert(t, []string{"harbor.internal"}, nil, "harbor", now.Add(-time.Hour), now.Add(time.Hour)),
+			cfg:  config.MTLSConfig{AllowedDNSNames: []string{"harbor.internal"}},
+		},
+		{
+			name: "matching SPIFFE URI SAN",
+			leaf: issueTestCert(t, nil, []string{"spiffe://example.org/ns/ci/sa/harbor"}, "harbor", now.Add(-time.Hour), now.Add(time.Hour)),
+			cfg:  config.MTLSConfig{AllowedURIs: []string{"spiffe://example.org/ns/ci/sa/harbor"}},
+		},
+		{
+			name: "matching subject common name",
+			leaf: issueTestCert(t, nil, nil, "harbor-ci", now.Add(-time.Hour), now.Add(time.Hour)),
+			cfg:  config.MTLSConfig{AllowedSubjects: []string{"harbor-ci"}},
+		},
+		{
+			name:        "DNS SAN mismatch",
+			leaf:        issueTestCert(t, []string{"other.internal"}, nil, "harbor", now.Add(-time.Hour), now.Add(time.Hour)),
+			cfg:         config.MTLSConfig{AllowedDNSNames: []string{"harbor.internal"}},
+			wantErr:     true,
+			errContains: "not in allowlist",
+		},
+		{
+			name:        "SPIFFE URI SAN mismatch",
+			leaf:        issueTestCert(t, nil, []string{"spiffe://example.org/ns/ci/sa/other"}, "harbor", now.Add(-time.Hour), now.Add(time.Hour)),
+			cfg:         config.MTLSConfig{AllowedURIs: []string{"spiffe://example.org/ns/ci/sa/harbor"}},
+			wantErr:     true,
+			errContains: "not in allowlist",
+		},
+		{
+			name:        "expired certificate",
+			leaf:        issueTestCert(t, []string{"harbor.internal"}, nil, "harbor", now.Add(-48*time.Hour), now.Add(-time.Hour)),
+			cfg:         config.MTLSConfig{AllowedDNSNames: []string{"harbor.internal"}},
+			wantErr:     true,
+			errContains: "not currently valid",
+		},
+		{
+			name:        "not yet valid certificate",
+			leaf:        issueTestCert(t, []string{"harbor.internal"}, nil, "harbor", now.Add(time.Hour), now.Add(48*time.Hour)),
+			cfg:         config.MTLSConfig{AllowedDNSNames: []string{"harbor.internal"}},
+			wantErr:     true,
+			errContains: "not currently valid",
+		},
+		{
+			name:        "no allowlist configured",
+			leaf:        issueTestCert(t, []string{"harbor.internal"}, nil, "harbor", now.Add(-time.Hour), now.Add(time.Hour)),
+			cfg:         config.MTLSConfig{},
+			wantErr:     true,
+			errContains: "requires at least one identity allowlist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := requestWithVerifiedLeaf(tt.leaf)
+
+			err := VerifyMTLS(req, tt.cfg)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyMTLS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+				t.Errorf("VerifyMTLS() error = %v, want error containing %v", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestVerifyMTLS_NoClientCertificate(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+
+	err := VerifyMTLS(req, config.MTLSConfig{AllowedDNSNames: []string{"harbor.internal"}})
+	if err == nil || !contains(err.Error(), "no verified client certificate") {
+		t.Errorf("VerifyMTLS() error = %v, want missing client certificate error", err)
+	}
+}
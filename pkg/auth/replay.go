@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReplayTimestampHeader is the header ReplayProtectionOptions
+	// reads the signing timestamp from when TimestampHeader is empty.
+	DefaultReplayTimestampHeader = "X-Webhook-Timestamp"
+	// DefaultReplayTolerance is how far a signing timestamp may drift
+	// from the server clock when ReplayProtectionOptions.Tolerance is
+	// zero.
+	DefaultReplayTolerance = 5 * time.Minute
+	// DefaultNonceCacheSize bounds a NonceCache created without an
+	// explicit size.
+	DefaultNonceCacheSize = 10000
+)
+
+// ReplayProtectionOptions enables timestamp-bound signing and
+// exact-replay rejection for VerifyHMACSignature, on top of the
+// skew-only check HMACOptions.MaxSkew provides. When Enabled, the HMAC
+// is computed over "<timestamp>.<body>" rather than the raw body alone,
+// and a signature is remembered in Cache so a second delivery of the
+// same payload is rejected within Tolerance.
+type ReplayProtectionOptions struct {
+	Enabled bool
+	// RegistryName scopes Cache entries so the same signature replayed
+	// against a different registry isn't mistaken for a replay.
+	RegistryName    string
+	Tolerance       time.Duration
+	TimestampHeader string
+	Cache           *NonceCache
+}
+
+// parseReplayTimestamp accepts a Unix-seconds or RFC3339 timestamp, the
+// two formats a signing client is likely to produce.
+func parseReplayTimestamp(value string) (time.Time, error) {
+	if unixSeconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unixSeconds, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("must be Unix seconds or RFC3339")
+}
+
+// nonceEntry is the value stored in NonceCache's eviction list.
+type nonceEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+// NonceCache is a bounded, TTL-evicting cache of recently seen HMAC
+// signatures. It rejects an exact replay of a signature that is still
+// within its TTL, and evicts the least-recently-inserted entry once
+// maxSize is exceeded so long-running processes don't grow it
+// unbounded.
+type NonceCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxSize  int
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+// NewNonceCache creates a NonceCache holding at most maxSize entries,
+// each valid for ttl. maxSize <= 0 falls back to DefaultNonceCacheSize.
+func NewNonceCache(maxSize int, ttl time.Duration) *NonceCache {
+	if maxSize <= 0 {
+		maxSize = DefaultNonceCacheSize
+	}
+	return &NonceCache{
+		ttl:      ttl,
+		maxSize:  maxSize,
+		order:    list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+// CheckAndStore records key as seen and reports whether this is the
+// first time it has been seen within its TTL. A false return means key
+// is a replay and the caller should reject the request.
+func (c *NonceCache) CheckAndStore(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if el, ok := c.elements[key]; ok {
+		entry := el.Value.(*nonceEntry)
+		if entry.expiresAt.After(now) {
+			return false
+		}
+		c.order.Remove(el)
+		delete(c.elements, key)
+	}
+
+	el := c.order.PushFront(&nonceEntry{key: key, expiresAt: now.Add(c.ttl)})
+	c.elements[key] = el
+
+	for c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.elements, oldest.Value.(*nonceEntry).key)
+	}
+
+	return true
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func signWithTimestamp(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyHMACSignature_ReplayProtection(t *testing.T) {
+	secret := "test-secret"
+	payload := []byte(`{"test":"data"}`)
+
+	newRequest := func(timestamp string) *http.Request {
+		sig := signWithTimestamp(secret, timestamp, payload)
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+		req.Header.Set(DefaultSignatureHeader, "sha256="+sig)
+		req.Header.Set(DefaultReplayTimestampHeader, timestamp)
+		return req
+	}
+
+	t.Run("fresh timestamp within tolerance", func(t *testing.T) {
+		ts := strconv.FormatInt(time.Now().Add(-1*time.Minute).Unix(), 10)
+		req := newRequest(ts)
+
+		opts := HMACOptions{ReplayProtection: ReplayProtectionOptions{
+			Enabled: true,
+			Cache:   NewNonceCache(10, 5*time.Minute),
+		}}
+		if err := VerifyHMACSignature(req, secret, opts); err != nil {
+			t.Errorf("VerifyHMACSignature() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("stale timestamp outside tolerance", func(t *testing.T) {
+		ts := strconv.FormatInt(time.Now().Add(-1*time.Hour).Unix(), 10)
+		req := newRequest(ts)
+
+		opts := HMACOptions{ReplayProtection: ReplayProtectionOptions{
+			Enabled: true,
+			Cache:   NewNonceCache(10, 5*time.Minute),
+		}}
+		err := VerifyHMACSignature(req, secret, opts)
+		if err == nil || !contains(err.Error(), "outside the allowed") {
+			t.Errorf("VerifyHMACSignature() error = %v, want tolerance error", err)
+		}
+	})
+
+	t.Run("future timestamp outside tolerance", func(t *testing.T) {
+		ts := strconv.FormatInt(time.Now().Add(1*time.Hour).Unix(), 10)
+		req := newRequest(ts)
+
+		opts := HMACOptions{ReplayProtection: ReplayProtectionOptions{
+			Enabled: true,
+			Cache:   NewNonceCache(10, 5*time.Minute),
+		}}
+		err := VerifyHMACSignature(req, secret, opts)
+		if err == nil || !contains(err.Error(), "outside the allowed") {
+			t.Errorf("VerifyHMACSignature() error = %v, want tolerance error", err)
+		}
+	})
+
+	t.Run("RFC3339 timestamp accepted", func(t *testing.T) {
+		ts := time.Now().Add(-1 * time.Minute).Format(time.RFC3339)
+		req := newRequest(ts)
+
+		opts := HMACOptions{ReplayProtection: ReplayProtectionOptions{
+			Enabled: true,
+			Cache:   NewNonceCache(10, 5*time.Minute),
+		}}
+		if err := VerifyHMACSignature(req, secret, opts); err != nil {
+			t.Errorf("VerifyHMACSignature() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("missing timestamp header", func(t *testing.T) {
+		sig := signWithTimestamp(secret, "", payload)
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+		req.Header.Set(DefaultSignatureHeader, "sha256="+sig)
+
+		opts := HMACOptions{ReplayProtection: ReplayProtectionOptions{
+			Enabled: true,
+			Cache:   NewNonceCache(10, 5*time.Minute),
+		}}
+		err := VerifyHMACSignature(req, secret, opts)
+		if err == nil || !contains(err.Error(), "missing "+DefaultReplayTimestampHeader) {
+			t.Errorf("VerifyHMACSignature() error = %v, want missing timestamp error", err)
+		}
+	})
+
+	t.Run("duplicate delivery within tolerance is rejected", func(t *testing.T) {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		cache := NewNonceCache(10, 5*time.Minute)
+		opts := HMACOptions{ReplayProtection: ReplayProtectionOptions{
+			Enabled:      true,
+			RegistryName: "dockerhub-prod",
+			Cache:        cache,
+		}}
+
+		first := newRequest(ts)
+		if err := VerifyHMACSignature(first, secret, opts); err != nil {
+			t.Fatalf("first delivery: VerifyHMACSignature() error = %v, want nil", err)
+		}
+
+		second := newRequest(ts)
+		err := VerifyHMACSignature(second, secret, opts)
+		if err == nil || !contains(err.Error(), "replayed") {
+			t.Errorf("second delivery: VerifyHMACSignature() error = %v, want replay error", err)
+		}
+	})
+
+	t.Run("same signature on a different registry is not a replay", func(t *testing.T) {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		cache := NewNonceCache(10, 5*time.Minute)
+
+		first := newRequest(ts)
+		optsA := HMACOptions{ReplayProtection: ReplayProtectionOptions{Enabled: true, RegistryName: "registry-a", Cache: cache}}
+		if err := VerifyHMACSignature(first, secret, optsA); err != nil {
+			t.Fatalf("registry-a delivery: VerifyHMACSignature() error = %v, want nil", err)
+		}
+
+		second := newRequest(ts)
+		optsB := HMACOptions{ReplayProtection: ReplayProtectionOptions{Enabled: true, RegistryName: "registry-b", Cache: cache}}
+		if err := VerifyHMACSignature(second, secret, optsB); err != nil {
+			t.Errorf("registry-b delivery: VerifyHMACSignature() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestNonceCache_CheckAndStore(t *testing.T) {
+	cache := NewNonceCache(2, time.Hour)
+
+	if !cache.CheckAndStore("a") {
+		t.Error("first insert of \"a\" should report not-seen")
+	}
+	if cache.CheckAndStore("a") {
+		t.Error("second insert of \"a\" should report seen (replay)")
+	}
+
+	if !cache.CheckAndStore("b") {
+		t.Error("first insert of \"b\" should report not-seen")
+	}
+	// Exceeds maxSize of 2; "a" is the least-recently-inserted and should
+	// be evicted, so it's treated as fresh again.
+	if !cache.CheckAndStore("c") {
+		t.Error("first insert of \"c\" should report not-seen")
+	}
+	if !cache.CheckAndStore("a") {
+		t.Error("\"a\" should have been evicted and is no longer a replay")
+	}
+}
+
+func TestNonceCache_ExpiredEntryIsNotAReplay(t *testing.T) {
+	cache := NewNonceCache(10, 10*time.Millisecond)
+
+	if !cache.CheckAndStore("a") {
+		t.Fatal("first insert of \"a\" should report not-seen")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if !cache.CheckAndStore("a") {
+		t.Error("expired entry should no longer be treated as a replay")
+	}
+}
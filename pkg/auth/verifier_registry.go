@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// VerifierRegistry maps registry-type strings to the Verifier used to
+// authenticate that registry's webhook deliveries, populated via
+// RegisterVerifier so a new registry integration can add its own signing
+// scheme without editing RequestAuthenticator. Mirrors
+// parsers.Registry: a handler looks up the registry type from an inbound
+// "?registry=" query param or path segment and resolves both a parser and
+// a Verifier from it.
+type VerifierRegistry struct {
+	mu        sync.RWMutex
+	verifiers map[string]Verifier
+}
+
+// defaultVerifierRegistry is the process-wide VerifierRegistry built-in
+// verifiers register themselves into via the package-level
+// RegisterVerifier.
+var defaultVerifierRegistry = NewVerifierRegistry()
+
+// NewVerifierRegistry creates an empty VerifierRegistry.
+func NewVerifierRegistry() *VerifierRegistry {
+	return &VerifierRegistry{verifiers: make(map[string]Verifier)}
+}
+
+// RegisterVerifier adds verifier under registryType to the default
+// VerifierRegistry. Intended to be called from an init(), so built-in
+// verifiers are available without explicit wiring. Panics on a duplicate
+// registryType, since that indicates two verifiers registering for the
+// same type, not a runtime condition a caller can recover from.
+func RegisterVerifier(registryType string, verifier Verifier) {
+	defaultVerifierRegistry.Register(registryType, verifier)
+}
+
+// Register adds verifier under registryType to vr.
+func (vr *VerifierRegistry) Register(registryType string, verifier Verifier) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+
+	if _, exists := vr.verifiers[registryType]; exists {
+		panic(fmt.Sprintf("auth: verifier already registered for registry type %q", registryType))
+	}
+	vr.verifiers[registryType] = verifier
+}
+
+// Get returns the Verifier registered for registryType.
+func (vr *VerifierRegistry) Get(registryType string) (Verifier, error) {
+	vr.mu.RLock()
+	defer vr.mu.RUnlock()
+
+	verifier, ok := vr.verifiers[registryType]
+	if !ok {
+		return nil, fmt.Errorf("no verifier registered for registry type: %s", registryType)
+	}
+
+	return verifier, nil
+}
+
+// Types returns the registered registry-type strings in sorted order.
+func (vr *VerifierRegistry) Types() []string {
+	vr.mu.RLock()
+	defer vr.mu.RUnlock()
+
+	types := make([]string, 0, len(vr.verifiers))
+	for t := range vr.verifiers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// DefaultVerifierRegistry returns the process-wide VerifierRegistry that
+// built-in verifiers register themselves into.
+func DefaultVerifierRegistry() *VerifierRegistry {
+	return defaultVerifierRegistry
+}
+
+func init() {
+	RegisterVerifier("github", GitHubVerifier)
+	RegisterVerifier("gitlab", GitLabVerifier)
+	RegisterVerifier("harbor", HarborVerifier)
+	RegisterVerifier("hmac", HMACVerifier)
+}
@@ -0,0 +1,44 @@
+package auth
+
+import "testing"
+
+func TestVerifierRegistry_RegisterAndGet(t *testing.T) {
+	vr := NewVerifierRegistry()
+	vr.Register("fake", HMACVerifier)
+
+	if _, err := vr.Get("fake"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if types := vr.Types(); len(types) != 1 || types[0] != "fake" {
+		t.Errorf("Types() = %v, want [fake]", types)
+	}
+}
+
+func TestVerifierRegistry_Get_Unregistered(t *testing.T) {
+	vr := NewVerifierRegistry()
+
+	if _, err := vr.Get("nonexistent"); err == nil {
+		t.Error("Get() expected error for unregistered registry type, got nil")
+	}
+}
+
+func TestVerifierRegistry_Register_DuplicatePanics(t *testing.T) {
+	vr := NewVerifierRegistry()
+	vr.Register("fake", HMACVerifier)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() expected panic on duplicate registryType, got none")
+		}
+	}()
+	vr.Register("fake", HMACVerifier)
+}
+
+func TestDefaultVerifierRegistry_HasBuiltinVerifiers(t *testing.T) {
+	for _, registryType := range []string{"github", "gitlab", "harbor", "hmac"} {
+		if _, err := DefaultVerifierRegistry().Get(registryType); err != nil {
+			t.Errorf("DefaultVerifierRegistry().Get(%q) error = %v, want built-in verifier", registryType, err)
+		}
+	}
+}
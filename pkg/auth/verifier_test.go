@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubVerifier(t *testing.T) {
+	secret := "gh-secret"
+	payload := []byte(`{"action":"push"}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+sig)
+
+	if err := GitHubVerifier.Verify(req, secret); err != nil {
+		t.Errorf("GitHubVerifier.Verify() error = %v", err)
+	}
+}
+
+func TestGitLabVerifier(t *testing.T) {
+	secret := "gl-secret"
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{name: "matching token", header: secret, wantErr: false},
+		{name: "wrong token", header: "wrong", wantErr: true},
+		{name: "missing header", header: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Gitlab-Token", tt.header)
+			}
+
+			err := GitLabVerifier.Verify(req, secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GitLabVerifier.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHarborVerifier(t *testing.T) {
+	secret := "harbor-secret"
+	payload := []byte(`{"type":"PUSH_ARTIFACT"}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header.Set("Authorization", sig)
+
+	if err := HarborVerifier.Verify(req, secret); err != nil {
+		t.Fatalf("HarborVerifier.Verify() error = %v", err)
+	}
+}
+
+func TestHarborVerifier_WrongSignature(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Authorization", "deadbeef")
+
+	if err := HarborVerifier.Verify(req, "secret"); err == nil {
+		t.Error("HarborVerifier.Verify() expected error for wrong signature, got nil")
+	}
+}
+
+func TestHMACVerifier(t *testing.T) {
+	secret := "generic-secret"
+	payload := []byte(`{"test":"data"}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	req.Header.Set(DefaultSignatureHeader, "sha256="+sig)
+
+	if err := HMACVerifier.Verify(req, secret); err != nil {
+		t.Errorf("HMACVerifier.Verify() error = %v", err)
+	}
+}
@@ -1,13 +1,31 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// defaultCacheMaxEntries bounds ResultProcessor's cache when
+// Scanner.CacheMaxEntries is left unset.
+const defaultCacheMaxEntries = 10000
+
+// defaultScannerCacheTTL is how long a cached scan result is reused when
+// Scanner.CacheTTL is left unset.
+const defaultScannerCacheTTL = "5m"
+
+// defaultImageCacheDir is where imagecache.Cache stores blobs when
+// Scanner.ImageCache.Dir is left unset.
+const defaultImageCacheDir = "/var/cache/scanner-webhook/images"
+
+// defaultImageCacheMaxSizeBytes bounds imagecache.Cache's on-disk blob
+// store when Scanner.ImageCache.MaxSizeBytes is left unset.
+const defaultImageCacheMaxSizeBytes = 10 * 1024 * 1024 * 1024 // 10GB
+
 // Load reads and parses the YAML configuration file
 func Load(filename string) (*Config, error) {
 	data, err := os.ReadFile(filename)
@@ -15,6 +33,14 @@ func Load(filename string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return parseConfigBytes(context.Background(), data)
+}
+
+// parseConfigBytes parses, defaults, resolves secret refs against, and
+// validates raw YAML config bytes. It backs both Load's one-shot read
+// and Watch's streamed reloads, so every path into a live *Config goes
+// through the same checks.
+func parseConfigBytes(ctx context.Context, data []byte) (*Config, error) {
 	// Expand environment variables in the config
 	expanded := os.ExpandEnv(string(data))
 
@@ -26,6 +52,13 @@ func Load(filename string) (*Config, error) {
 	// Apply defaults
 	cfg.applyDefaults()
 
+	// Resolve "ref://<key>" secret indirections against the registered
+	// SecretBackend, if any. A no-op when the config carries no ref://
+	// values.
+	if err := resolveSecretRefs(ctx, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve secret refs: %w", err)
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -66,6 +99,30 @@ func (c *Config) applyDefaults() {
 	if c.Scanner.MaxConcurrent == 0 {
 		c.Scanner.MaxConcurrent = 5
 	}
+	if c.Scanner.CacheMaxEntries == 0 {
+		c.Scanner.CacheMaxEntries = defaultCacheMaxEntries
+	}
+	if c.Scanner.CacheTTL == "" {
+		c.Scanner.CacheTTL = defaultScannerCacheTTL
+	}
+
+	// Trivy defaults
+	if c.Scanner.Type == ScannerTypeTrivy && c.Scanner.Trivy == nil {
+		c.Scanner.Trivy = &TrivyScannerConfig{}
+	}
+	if c.Scanner.Trivy != nil && c.Scanner.Trivy.BinaryPath == "" {
+		c.Scanner.Trivy.BinaryPath = "trivy"
+	}
+
+	// Image cache defaults
+	if c.Scanner.ImageCache != nil {
+		if c.Scanner.ImageCache.Dir == "" {
+			c.Scanner.ImageCache.Dir = defaultImageCacheDir
+		}
+		if c.Scanner.ImageCache.MaxSizeBytes <= 0 {
+			c.Scanner.ImageCache.MaxSizeBytes = defaultImageCacheMaxSizeBytes
+		}
+	}
 
 	// Registry Scanner defaults
 	if c.Scanner.Type == ScannerTypeRegistry && c.Scanner.RegistryScanner != nil {
@@ -86,6 +143,54 @@ func (c *Config) applyDefaults() {
 	if c.Queue.Workers == 0 {
 		c.Queue.Workers = 3
 	}
+	if c.Queue.DedupBackend == "" {
+		c.Queue.DedupBackend = "memory"
+	}
+	if c.Queue.DedupTTL == "" {
+		c.Queue.DedupTTL = "5m"
+	}
+	if c.Queue.LeaseTTL == "" {
+		c.Queue.LeaseTTL = "5m"
+	}
+	if c.Queue.NatsMaxDeliveries == 0 {
+		c.Queue.NatsMaxDeliveries = 5
+	}
+	if c.Queue.MaxRetries == 0 {
+		c.Queue.MaxRetries = 3
+	}
+	if c.Queue.RetryInitialBackoff == "" {
+		c.Queue.RetryInitialBackoff = "1s"
+	}
+	if c.Queue.RetryMaxBackoff == "" {
+		c.Queue.RetryMaxBackoff = "1m"
+	}
+	if c.Queue.RetryBackoffMultiplier == 0 {
+		c.Queue.RetryBackoffMultiplier = 2.0
+	}
+	if c.Queue.UnauthorizedRetryWindow == "" {
+		c.Queue.UnauthorizedRetryWindow = "30s"
+	}
+	if c.Queue.LeaderLockKey == "" {
+		c.Queue.LeaderLockKey = "scanner:queue:leader"
+	}
+	if c.Queue.LeaderLockTTL == "" {
+		c.Queue.LeaderLockTTL = "30s"
+	}
+
+	// Sink defaults
+	for i := range c.Registries {
+		for j := range c.Registries[i].Sinks {
+			applySinkRetryDefaults(&c.Registries[i].Sinks[j].Retry)
+		}
+	}
+
+	// Logging defaults
+	if c.Logging.Level == "" {
+		c.Logging.Level = "info"
+	}
+	if c.Logging.Format == "" {
+		c.Logging.Format = "json"
+	}
 }
 
 // Validate checks the configuration for required fields and valid values
@@ -112,16 +217,33 @@ func (c *Config) Validate() error {
 		if err := validateAuthConfig(reg.Auth); err != nil {
 			return fmt.Errorf("registry[%s]: %w", reg.Name, err)
 		}
+
+		for j, sinkCfg := range reg.Sinks {
+			if err := validateSinkConfig(sinkCfg); err != nil {
+				return fmt.Errorf("registry[%s].sinks[%d]: %w", reg.Name, j, err)
+			}
+		}
+
+		if reg.Auth.Type == "mtls" {
+			if c.Server.TLS.CertFile == "" || c.Server.TLS.KeyFile == "" {
+				return fmt.Errorf("server.tls.cert_file and key_file are required when registry[%s] uses auth type 'mtls'", reg.Name)
+			}
+			if c.Server.TLS.ClientCAFile == "" && c.Server.TLS.ClientCAPEM == "" {
+				return fmt.Errorf("server.tls.client_ca_file or client_ca_pem is required when registry[%s] uses auth type 'mtls'", reg.Name)
+			}
+		}
 	}
 
-	// Validate scanner config
-	if c.Scanner.SysdigToken == "" {
+	// Validate scanner config. Trivy doesn't talk to Sysdig at all, so
+	// scanner.sysdig_token isn't required when it's the only configured
+	// backend.
+	if c.Scanner.Type != ScannerTypeTrivy && c.Scanner.SysdigToken == "" {
 		return fmt.Errorf("scanner.sysdig_token is required")
 	}
 
 	// Validate scanner type
-	if c.Scanner.Type != ScannerTypeCLI && c.Scanner.Type != ScannerTypeRegistry {
-		return fmt.Errorf("scanner.type must be 'cli' or 'registry', got: %s", c.Scanner.Type)
+	if c.Scanner.Type != ScannerTypeCLI && c.Scanner.Type != ScannerTypeRegistry && c.Scanner.Type != ScannerTypeComposite && c.Scanner.Type != ScannerTypeTrivy && c.Scanner.Type != ScannerTypeClair {
+		return fmt.Errorf("scanner.type must be 'cli', 'registry', 'composite', 'trivy', or 'clair', got: %s", c.Scanner.Type)
 	}
 
 	// Validate Registry Scanner config if type is registry
@@ -139,12 +261,74 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate Composite config if type is composite
+	if c.Scanner.Type == ScannerTypeComposite {
+		if c.Scanner.Composite == nil || len(c.Scanner.Composite.Backends) == 0 {
+			return fmt.Errorf("scanner.composite.backends must list at least one backend when scanner.type is 'composite'")
+		}
+		for _, backend := range c.Scanner.Composite.Backends {
+			if backend != ScannerTypeCLI && backend != ScannerTypeRegistry && backend != ScannerTypeTrivy && backend != ScannerTypeClair {
+				return fmt.Errorf("scanner.composite.backends must be 'cli', 'registry', 'trivy', or 'clair', got: %s", backend)
+			}
+		}
+	}
+
+	// Validate queue dedup backend
+	if c.Queue.DedupBackend != "" && c.Queue.DedupBackend != "memory" && c.Queue.DedupBackend != "redis" {
+		return fmt.Errorf("queue.dedup_backend must be 'memory' or 'redis', got: %s", c.Queue.DedupBackend)
+	}
+	if c.Queue.DedupBackend == "redis" && c.Queue.RedisAddr == "" {
+		return fmt.Errorf("queue.redis_addr is required when queue.dedup_backend is 'redis'")
+	}
+
+	// Validate queue backend
+	switch c.Queue.Backend {
+	case "", "memory":
+	case "bolt":
+		if c.Queue.BoltPath == "" {
+			return fmt.Errorf("queue.bolt_path is required when queue.backend is 'bolt'")
+		}
+	case "redis":
+		if c.Queue.RedisAddr == "" {
+			return fmt.Errorf("queue.redis_addr is required when queue.backend is 'redis'")
+		}
+	case "gcs", "s3":
+		if c.Queue.ObjectStoreBucket == "" {
+			return fmt.Errorf("queue.object_store_bucket is required when queue.backend is %q", c.Queue.Backend)
+		}
+	case "nats":
+		if c.Queue.NatsURL == "" {
+			return fmt.Errorf("queue.nats_url is required when queue.backend is 'nats'")
+		}
+	default:
+		return fmt.Errorf("queue.backend must be 'memory', 'bolt', 'redis', 'gcs', 's3', or 'nats', got: %s", c.Queue.Backend)
+	}
+
 	// Validate duration strings
 	durations := map[string]string{
-		"server.read_timeout":      c.Server.ReadTimeout,
-		"server.write_timeout":     c.Server.WriteTimeout,
-		"server.shutdown_timeout":  c.Server.ShutdownTimeout,
-		"scanner.default_timeout":  c.Scanner.DefaultTimeout,
+		"server.read_timeout":     c.Server.ReadTimeout,
+		"server.write_timeout":    c.Server.WriteTimeout,
+		"server.shutdown_timeout": c.Server.ShutdownTimeout,
+		"scanner.default_timeout": c.Scanner.DefaultTimeout,
+		"scanner.cache_ttl":       c.Scanner.CacheTTL,
+	}
+	if c.Queue.DedupTTL != "" {
+		durations["queue.dedup_ttl"] = c.Queue.DedupTTL
+	}
+	if c.Queue.LeaseTTL != "" {
+		durations["queue.lease_ttl"] = c.Queue.LeaseTTL
+	}
+	if c.Queue.RetryInitialBackoff != "" {
+		durations["queue.retry_initial_backoff"] = c.Queue.RetryInitialBackoff
+	}
+	if c.Queue.RetryMaxBackoff != "" {
+		durations["queue.retry_max_backoff"] = c.Queue.RetryMaxBackoff
+	}
+	if c.Queue.UnauthorizedRetryWindow != "" {
+		durations["queue.unauthorized_retry_window"] = c.Queue.UnauthorizedRetryWindow
+	}
+	if c.Queue.LeaderLockTTL != "" {
+		durations["queue.leader_lock_ttl"] = c.Queue.LeaderLockTTL
 	}
 
 	for name, value := range durations {
@@ -153,11 +337,16 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate logging format
+	if c.Logging.Format != "" && c.Logging.Format != "json" && c.Logging.Format != "text" && c.Logging.Format != "logfmt" {
+		return fmt.Errorf("logging.format must be 'json', 'text', or 'logfmt', got: %s", c.Logging.Format)
+	}
+
 	return nil
 }
 
 func validateRegistryType(regType string) error {
-	validTypes := []string{"dockerhub", "harbor", "gitlab"}
+	validTypes := []string{"dockerhub", "harbor", "gitlab", "distribution", "quay", "ghcr", "ecr", "acr", "gcr"}
 	for _, valid := range validTypes {
 		if regType == valid {
 			return nil
@@ -167,14 +356,146 @@ func validateRegistryType(regType string) error {
 		regType, strings.Join(validTypes, ", "))
 }
 
+// applySinkRetryDefaults fills in a SinkConfig's retry/backoff fields
+// left unset, matching sink.DefaultRetryConfig.
+func applySinkRetryDefaults(retry *RetryConfig) {
+	if retry.MaxAttempts == 0 {
+		retry.MaxAttempts = 3
+	}
+	if retry.InitialBackoff == "" {
+		retry.InitialBackoff = "1s"
+	}
+	if retry.MaxBackoff == "" {
+		retry.MaxBackoff = "30s"
+	}
+}
+
+func validateSinkConfig(sinkCfg SinkConfig) error {
+	switch sinkCfg.Type {
+	case SinkTypeFile:
+		if sinkCfg.File == nil || sinkCfg.File.Path == "" {
+			return fmt.Errorf("file.path is required when sink type is 'file'")
+		}
+	case SinkTypeHTTP:
+		if sinkCfg.HTTP == nil || sinkCfg.HTTP.URL == "" {
+			return fmt.Errorf("http.url is required when sink type is 'http'")
+		}
+		if sinkCfg.HTTP.Timeout != "" {
+			if _, err := time.ParseDuration(sinkCfg.HTTP.Timeout); err != nil {
+				return fmt.Errorf("invalid http.timeout: %w", err)
+			}
+		}
+	case SinkTypeObject:
+		if sinkCfg.Object == nil || sinkCfg.Object.Bucket == "" {
+			return fmt.Errorf("object.bucket is required when sink type is 'object'")
+		}
+		if sinkCfg.Object.Provider != "s3" && sinkCfg.Object.Provider != "gcs" {
+			return fmt.Errorf("object.provider must be 's3' or 'gcs', got: %s", sinkCfg.Object.Provider)
+		}
+	case SinkTypeKafka:
+		if sinkCfg.Kafka == nil || sinkCfg.Kafka.Topic == "" || len(sinkCfg.Kafka.Brokers) == 0 {
+			return fmt.Errorf("kafka.brokers and kafka.topic are required when sink type is 'kafka'")
+		}
+	default:
+		return fmt.Errorf("invalid sink type '%s', must be 'file', 'http', 'object', or 'kafka'", sinkCfg.Type)
+	}
+
+	if sinkCfg.Retry.InitialBackoff != "" {
+		if _, err := time.ParseDuration(sinkCfg.Retry.InitialBackoff); err != nil {
+			return fmt.Errorf("invalid retry.initial_backoff: %w", err)
+		}
+	}
+	if sinkCfg.Retry.MaxBackoff != "" {
+		if _, err := time.ParseDuration(sinkCfg.Retry.MaxBackoff); err != nil {
+			return fmt.Errorf("invalid retry.max_backoff: %w", err)
+		}
+	}
+	if sinkCfg.Retry.MaxAttempts < 0 {
+		return fmt.Errorf("retry.max_attempts must be zero or positive, got: %d", sinkCfg.Retry.MaxAttempts)
+	}
+
+	return nil
+}
+
 func validateAuthConfig(auth AuthConfig) error {
-	if auth.Type != "hmac" && auth.Type != "bearer" && auth.Type != "none" {
-		return fmt.Errorf("invalid auth type '%s', must be 'hmac', 'bearer', or 'none'", auth.Type)
+	if auth.Type != "hmac" && auth.Type != "bearer" && auth.Type != "jwt" && auth.Type != "mtls" && auth.Type != "either" && auth.Type != "none" {
+		return fmt.Errorf("invalid auth type '%s', must be 'hmac', 'bearer', 'jwt', 'mtls', 'either', or 'none'", auth.Type)
 	}
 
-	if (auth.Type == "hmac" || auth.Type == "bearer") && auth.Secret == "" {
+	if auth.Type != "none" && auth.Type != "jwt" && auth.Type != "mtls" && auth.Secret == "" {
 		return fmt.Errorf("auth.secret is required when auth type is '%s'", auth.Type)
 	}
 
+	if auth.Type == "jwt" {
+		if err := validateJWTConfig(auth.JWT); err != nil {
+			return fmt.Errorf("auth.jwt: %w", err)
+		}
+	}
+
+	if auth.Type == "mtls" {
+		if err := validateMTLSConfig(auth.MTLS); err != nil {
+			return fmt.Errorf("auth.mtls: %w", err)
+		}
+	}
+
+	if auth.MaxSkew != "" {
+		if _, err := time.ParseDuration(auth.MaxSkew); err != nil {
+			return fmt.Errorf("invalid auth.max_skew: %w", err)
+		}
+	}
+
+	if auth.ReplayProtection.Enabled {
+		if err := validateReplayProtectionConfig(auth.ReplayProtection); err != nil {
+			return fmt.Errorf("auth.replay_protection: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func validateReplayProtectionConfig(rp ReplayProtectionConfig) error {
+	if rp.Tolerance != "" {
+		if _, err := time.ParseDuration(rp.Tolerance); err != nil {
+			return fmt.Errorf("invalid tolerance: %w", err)
+		}
+	}
+	if rp.CacheSize < 0 {
+		return fmt.Errorf("cache_size must be non-negative")
+	}
+	return nil
+}
+
+func validateMTLSConfig(mtlsCfg MTLSConfig) error {
+	if len(mtlsCfg.AllowedDNSNames) == 0 && len(mtlsCfg.AllowedURIs) == 0 && len(mtlsCfg.AllowedSubjects) == 0 {
+		return fmt.Errorf("at least one of allowed_dns_names, allowed_uris, or allowed_subjects is required")
+	}
+	return nil
+}
+
+func validateJWTConfig(jwtCfg JWTConfig) error {
+	keySources := 0
+	if jwtCfg.Secret != "" {
+		keySources++
+	}
+	if jwtCfg.PublicKey != "" {
+		keySources++
+	}
+	if jwtCfg.JWKSURL != "" {
+		keySources++
+	}
+
+	if keySources == 0 {
+		return fmt.Errorf("exactly one of secret, public_key, or jwks_url is required")
+	}
+	if keySources > 1 {
+		return fmt.Errorf("secret, public_key, and jwks_url are mutually exclusive")
+	}
+
+	if jwtCfg.JWKSRefreshInterval != "" {
+		if _, err := time.ParseDuration(jwtCfg.JWKSRefreshInterval); err != nil {
+			return fmt.Errorf("invalid jwks_refresh_interval: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -310,6 +310,62 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "sink with invalid http.timeout",
+			config: &Config{
+				Registries: []RegistryConfig{
+					{
+						Name: "test", Type: "dockerhub", Auth: AuthConfig{Type: "none"},
+						Sinks: []SinkConfig{
+							{Type: SinkTypeHTTP, HTTP: &HTTPSinkConfig{URL: "https://example.com/hook", Timeout: "not-a-duration"}},
+						},
+					},
+				},
+				Scanner: ScannerConfig{
+					SysdigToken:    "token",
+					DefaultTimeout: "300s",
+				},
+			},
+			wantErr:     true,
+			errContains: "invalid http.timeout",
+		},
+		{
+			name: "sink with negative retry.max_attempts",
+			config: &Config{
+				Registries: []RegistryConfig{
+					{
+						Name: "test", Type: "dockerhub", Auth: AuthConfig{Type: "none"},
+						Sinks: []SinkConfig{
+							{Type: SinkTypeFile, File: &FileSinkConfig{Path: "/tmp/results.jsonl"}, Retry: RetryConfig{MaxAttempts: -1}},
+						},
+					},
+				},
+				Scanner: ScannerConfig{
+					SysdigToken:    "token",
+					DefaultTimeout: "300s",
+				},
+			},
+			wantErr:     true,
+			errContains: "retry.max_attempts must be zero or positive",
+		},
+		{
+			name: "valid file sink",
+			config: &Config{
+				Registries: []RegistryConfig{
+					{
+						Name: "test", Type: "dockerhub", Auth: AuthConfig{Type: "none"},
+						Sinks: []SinkConfig{
+							{Type: SinkTypeFile, File: &FileSinkConfig{Path: "/tmp/results.jsonl"}},
+						},
+					},
+				},
+				Scanner: ScannerConfig{
+					SysdigToken:    "token",
+					DefaultTimeout: "300s",
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // LoadSecretsFromFiles loads secrets from mounted Kubernetes Secret volumes
@@ -49,6 +51,7 @@ func InjectSecretsIntoConfig(cfg *Config, secrets map[string]string) {
 	// Inject secrets into registry auth
 	for i := range cfg.Registries {
 		cfg.Registries[i].Auth.Secret = resolveSecret(cfg.Registries[i].Auth.Secret, secrets)
+		cfg.Registries[i].WebhookAuthHeader = resolveSecret(cfg.Registries[i].WebhookAuthHeader, secrets)
 
 		// Inject registry credentials
 		if cfg.Registries[i].Scanner.Credentials.Username != "" {
@@ -59,6 +62,16 @@ func InjectSecretsIntoConfig(cfg *Config, secrets map[string]string) {
 			cfg.Registries[i].Scanner.Credentials.Password =
 				resolveSecret(cfg.Registries[i].Scanner.Credentials.Password, secrets)
 		}
+
+		// Inject signature verification key material
+		if v := cfg.Registries[i].Verification; v != nil {
+			for j, key := range v.Keys {
+				v.Keys[j] = resolveSecret(key, secrets)
+			}
+			if v.Notation != nil && v.Notation.TrustStore != "" {
+				v.Notation.TrustStore = resolveSecret(v.Notation.TrustStore, secrets)
+			}
+		}
 	}
 
 	// Inject Sysdig token
@@ -80,3 +93,73 @@ func resolveSecret(value string, secrets map[string]string) string {
 
 	return value
 }
+
+// secretRefPrefix marks a secret field for indirection through the
+// registered SecretBackend rather than being read from the config file
+// (or a mounted ${FILE:...} volume) directly. Unlike those, a ref:// is
+// re-resolved on every config.Watch reload, so rotating the underlying
+// secret doesn't require a process restart.
+const secretRefPrefix = "ref://"
+
+// SecretBackend resolves the key portion of a "ref://<key>" secret field
+// against an external secret store (e.g. Vault, AWS Secrets Manager) at
+// config load/reload time.
+type SecretBackend interface {
+	Resolve(ctx context.Context, key string) (string, error)
+}
+
+var (
+	secretBackendMu sync.RWMutex
+	secretBackend   SecretBackend
+)
+
+// SetSecretBackend registers the SecretBackend used to resolve "ref://"
+// secret fields. Passing nil disables ref:// resolution again; a config
+// containing a ref:// value with no backend registered fails to load.
+func SetSecretBackend(backend SecretBackend) {
+	secretBackendMu.Lock()
+	defer secretBackendMu.Unlock()
+	secretBackend = backend
+}
+
+// resolveSecretRefs walks the config's secret-bearing fields (Auth.Secret,
+// Scanner.SysdigToken, and each registry's Scanner.Credentials.Password)
+// and replaces any "ref://<key>" value with the result of resolving it
+// against the registered SecretBackend.
+func resolveSecretRefs(ctx context.Context, cfg *Config) error {
+	secretBackendMu.RLock()
+	backend := secretBackend
+	secretBackendMu.RUnlock()
+
+	resolve := func(field *string) error {
+		if !strings.HasPrefix(*field, secretRefPrefix) {
+			return nil
+		}
+		if backend == nil {
+			return fmt.Errorf("no secret backend registered to resolve %q", *field)
+		}
+
+		key := strings.TrimPrefix(*field, secretRefPrefix)
+		value, err := backend.Resolve(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %q: %w", *field, err)
+		}
+		*field = value
+		return nil
+	}
+
+	if err := resolve(&cfg.Scanner.SysdigToken); err != nil {
+		return err
+	}
+
+	for i := range cfg.Registries {
+		if err := resolve(&cfg.Registries[i].Auth.Secret); err != nil {
+			return fmt.Errorf("registry[%s]: %w", cfg.Registries[i].Name, err)
+		}
+		if err := resolve(&cfg.Registries[i].Scanner.Credentials.Password); err != nil {
+			return fmt.Errorf("registry[%s]: %w", cfg.Registries[i].Name, err)
+		}
+	}
+
+	return nil
+}
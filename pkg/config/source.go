@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ConfigSource supplies raw YAML configuration and streams it again
+// whenever it changes, decoupling Watch from any one backing store (a
+// local file, Consul KV, etcd).
+type ConfigSource interface {
+	// Load returns the source's current raw YAML configuration.
+	Load(ctx context.Context) ([]byte, error)
+	// Watch streams raw YAML configuration every time the source
+	// changes. Implementations may or may not emit the source's current
+	// value immediately on subscribe; callers that need a guaranteed
+	// initial snapshot should call Load first. The channel is closed
+	// when ctx is done or the source's connection can't be recovered.
+	Watch(ctx context.Context) (<-chan []byte, error)
+}
+
+// Watch streams updates from source, parsing each one the same way Load
+// does (defaults applied, secret refs resolved, Validate run) before
+// invoking onChange. An update that fails to parse or validate is
+// dropped so the caller keeps serving its last-known-good *Config rather
+// than crashing or falling back to zero values. Watch returns when ctx
+// is canceled or the source's change channel closes.
+func Watch(ctx context.Context, source ConfigSource, onChange func(*Config)) error {
+	changes, err := source.Watch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start watching config source: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case data, ok := <-changes:
+			if !ok {
+				return nil
+			}
+
+			cfg, err := parseConfigBytes(ctx, data)
+			if err != nil {
+				continue
+			}
+
+			onChange(cfg)
+		}
+	}
+}
+
+// FileConfigSource implements ConfigSource by reading a path from local
+// disk, the default "file" backend (see ConsulKVConfigSource and
+// EtcdConfigSource for the KV-store backends). Watch polls the file's mtime rather than relying on inotify, so it
+// behaves the same across local filesystems and the network mounts
+// (ConfigMaps, NFS) container platforms commonly use for config files.
+type FileConfigSource struct {
+	Path string
+	// PollInterval is how often the file's mtime is checked. Zero
+	// defaults to 5s.
+	PollInterval time.Duration
+}
+
+// NewFileConfigSource creates a FileConfigSource for path with the
+// default poll interval.
+func NewFileConfigSource(path string) *FileConfigSource {
+	return &FileConfigSource{Path: path}
+}
+
+// Load reads the file's current contents.
+func (s *FileConfigSource) Load(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return data, nil
+}
+
+// Watch polls s.Path's mtime and sends its contents on the returned
+// channel whenever it changes. The contents at subscribe time are not
+// emitted.
+func (s *FileConfigSource) Watch(ctx context.Context) (<-chan []byte, error) {
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	ch := make(chan []byte)
+
+	var lastModTime time.Time
+	if info, err := os.Stat(s.Path); err == nil {
+		lastModTime = info.ModTime()
+	}
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				info, err := os.Stat(s.Path)
+				if err != nil || info.ModTime().Equal(lastModTime) {
+					continue
+				}
+				lastModTime = info.ModTime()
+
+				data, err := os.ReadFile(s.Path)
+				if err != nil {
+					continue
+				}
+
+				select {
+				case ch <- data:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
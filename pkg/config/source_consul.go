@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// ConsulKVConfigSource implements ConfigSource by reading the raw YAML
+// config from a single Consul KV key, and streaming updates via Consul's
+// blocking queries (long-polling on the key's ModifyIndex) so a change
+// is observed within one round trip rather than on a fixed poll
+// interval.
+type ConsulKVConfigSource struct {
+	client *consulapi.Client
+	key    string
+}
+
+// NewConsulKVConfigSource creates a ConsulKVConfigSource reading key from
+// the Consul agent at addr (e.g. "127.0.0.1:8500").
+func NewConsulKVConfigSource(addr, key string) (*ConsulKVConfigSource, error) {
+	clientCfg := consulapi.DefaultConfig()
+	clientCfg.Address = addr
+
+	client, err := consulapi.NewClient(clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client: %w", err)
+	}
+
+	return &ConsulKVConfigSource{client: client, key: key}, nil
+}
+
+// Load fetches the key's current value.
+func (s *ConsulKVConfigSource) Load(ctx context.Context) ([]byte, error) {
+	pair, _, err := s.client.KV().Get(s.key, (&consulapi.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read consul key %q: %w", s.key, err)
+	}
+	if pair == nil {
+		return nil, fmt.Errorf("consul key %q not found", s.key)
+	}
+	return pair.Value, nil
+}
+
+// Watch long-polls the key via Consul blocking queries, sending its
+// value on the returned channel each time its ModifyIndex changes.
+func (s *ConsulKVConfigSource) Watch(ctx context.Context) (<-chan []byte, error) {
+	ch := make(chan []byte)
+
+	go func() {
+		defer close(ch)
+
+		var lastIndex uint64
+		for {
+			opts := (&consulapi.QueryOptions{
+				WaitIndex: lastIndex,
+				WaitTime:  5 * time.Minute,
+			}).WithContext(ctx)
+
+			pair, meta, err := s.client.KV().Get(s.key, opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				// Transient lookup failure; back off briefly before the
+				// next blocking query rather than spinning.
+				select {
+				case <-time.After(time.Second):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			if pair == nil {
+				lastIndex = meta.LastIndex
+				continue
+			}
+
+			// A blocking query can return with no actual change once
+			// its WaitTime elapses; only emit when the index moved.
+			if meta.LastIndex == lastIndex {
+				continue
+			}
+			lastIndex = meta.LastIndex
+
+			select {
+			case ch <- pair.Value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
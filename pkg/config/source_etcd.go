@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdConfigSource implements ConfigSource by reading the raw YAML
+// config from a single etcd key and streaming updates via etcd's native
+// Watch API.
+type EtcdConfigSource struct {
+	client *clientv3.Client
+	key    string
+}
+
+// NewEtcdConfigSource creates an EtcdConfigSource reading key from the
+// etcd cluster at endpoints.
+func NewEtcdConfigSource(endpoints []string, key string) (*EtcdConfigSource, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client: %w", err)
+	}
+
+	return &EtcdConfigSource{client: client, key: key}, nil
+}
+
+// Load fetches the key's current value.
+func (s *EtcdConfigSource) Load(ctx context.Context) ([]byte, error) {
+	resp, err := s.client.Get(ctx, s.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read etcd key %q: %w", s.key, err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("etcd key %q not found", s.key)
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+// Watch streams the key's value on every PUT event, closing the
+// returned channel if the watch is canceled or etcd reports the
+// revision was compacted out from under it.
+func (s *EtcdConfigSource) Watch(ctx context.Context) (<-chan []byte, error) {
+	ch := make(chan []byte)
+	watchCh := s.client.Watch(ctx, s.key)
+
+	go func() {
+		defer close(ch)
+
+		for resp := range watchCh {
+			if resp.Err() != nil {
+				return
+			}
+
+			for _, event := range resp.Events {
+				if event.Type != clientv3.EventTypePut {
+					continue
+				}
+
+				select {
+				case ch <- event.Kv.Value:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileConfigSource_Load(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: 9090\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	source := NewFileConfigSource(path)
+	data, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if string(data) != "server:\n  port: 9090\n" {
+		t.Errorf("Load() = %q, want file contents", data)
+	}
+}
+
+func TestFileConfigSource_Watch(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: 9090\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	source := &FileConfigSource{Path: path, PollInterval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := source.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// Give the mtime a chance to differ from the write above before the
+	// next one; some filesystems have coarse mtime resolution.
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("server:\n  port: 9091\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	select {
+	case data := <-changes:
+		if string(data) != "server:\n  port: 9091\n" {
+			t.Errorf("Watch() emitted = %q, want updated contents", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watch() did not emit an update within timeout")
+	}
+}
+
+func TestWatch_AppliesDefaultsAndValidatesBeforeOnChange(t *testing.T) {
+	validConfig := []byte(`
+registries:
+  - name: test-registry
+    type: harbor
+    url: https://harbor.example.com
+    auth:
+      type: bearer
+      secret: test-secret
+
+scanner:
+  sysdig_token: test-token
+`)
+
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(path, validConfig, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	source := &FileConfigSource{Path: path, PollInterval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan *Config, 1)
+	go Watch(ctx, source, func(cfg *Config) {
+		received <- cfg
+	})
+
+	time.Sleep(20 * time.Millisecond)
+	updated := append(validConfig, []byte("\n")...)
+	if err := os.WriteFile(path, updated, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	select {
+	case cfg := <-received:
+		if cfg.Server.Port != 8080 {
+			t.Errorf("cfg.Server.Port = %d, want applyDefaults' 8080", cfg.Server.Port)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watch() did not invoke onChange within timeout")
+	}
+}
+
+func TestWatch_DropsInvalidUpdate(t *testing.T) {
+	validConfig := []byte(`
+registries:
+  - name: test-registry
+    type: harbor
+    url: https://harbor.example.com
+    auth:
+      type: bearer
+      secret: test-secret
+
+scanner:
+  sysdig_token: test-token
+`)
+
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(path, validConfig, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	source := &FileConfigSource{Path: path, PollInterval: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan *Config, 1)
+	go Watch(ctx, source, func(cfg *Config) {
+		received <- cfg
+	})
+
+	time.Sleep(20 * time.Millisecond)
+	// Invalid: no registries configured.
+	if err := os.WriteFile(path, []byte("server:\n  port: 9090\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	select {
+	case cfg := <-received:
+		t.Fatalf("Watch() invoked onChange with invalid config: %+v", cfg)
+	case <-time.After(200 * time.Millisecond):
+		// Expected: the invalid update was dropped.
+	}
+}
@@ -6,16 +6,81 @@ import "time"
 type ScannerType string
 
 const (
-	ScannerTypeCLI      ScannerType = "cli"
-	ScannerTypeRegistry ScannerType = "registry"
+	ScannerTypeCLI       ScannerType = "cli"
+	ScannerTypeRegistry  ScannerType = "registry"
+	ScannerTypeComposite ScannerType = "composite"
+	ScannerTypeTrivy     ScannerType = "trivy"
+	ScannerTypeClair     ScannerType = "clair"
+)
+
+// ReconciliationPolicy determines how CompositeScanner merges the results
+// of its member backends into one models.ScanResult.
+type ReconciliationPolicy string
+
+const (
+	// ReconciliationUnion keeps the highest vulnerability counts
+	// observed across all backends for each severity.
+	ReconciliationUnion ReconciliationPolicy = "union"
+	// ReconciliationIntersection keeps the lowest vulnerability counts
+	// observed across all backends for each severity, i.e. only what
+	// every backend agrees on.
+	ReconciliationIntersection ReconciliationPolicy = "intersection"
+	// ReconciliationPrimaryWithFallback uses the first configured
+	// backend's result, falling back to the next backend in order only
+	// if the primary failed.
+	ReconciliationPrimaryWithFallback ReconciliationPolicy = "primary-with-fallback"
+	// ReconciliationWorstSeverity keeps whichever backend's result has
+	// the highest-severity finding.
+	ReconciliationWorstSeverity ReconciliationPolicy = "worst-severity"
 )
 
 // Config represents the complete application configuration
 type Config struct {
-	Server     ServerConfig     `yaml:"server"`
-	Registries []RegistryConfig `yaml:"registries"`
-	Scanner    ScannerConfig    `yaml:"scanner"`
-	Queue      QueueConfig      `yaml:"queue"`
+	Server         ServerConfig         `yaml:"server"`
+	Admin          AdminConfig          `yaml:"admin"`
+	Registries     []RegistryConfig     `yaml:"registries"`
+	Scanner        ScannerConfig        `yaml:"scanner"`
+	Queue          QueueConfig          `yaml:"queue"`
+	ScannerAdapter ScannerAdapterConfig `yaml:"scanner_adapter,omitempty"`
+	Logging        LoggingConfig        `yaml:"logging,omitempty"`
+	Reconciler     ReconcilerConfig     `yaml:"reconciler,omitempty"`
+}
+
+// ReconcilerConfig holds settings for pkg/reconciler, which periodically
+// walks a registry's catalog directly rather than waiting for its
+// webhooks. Only registries whose RegistryConfig.PollsCatalog is true
+// are walked.
+type ReconcilerConfig struct {
+	// PollInterval is the default interval between catalog walks, used
+	// by any registry that doesn't set its own RegistryConfig.PollInterval.
+	// Empty falls back to reconciler.DefaultPollInterval.
+	PollInterval string `yaml:"poll_interval,omitempty"`
+}
+
+// LoggingConfig selects the logging.Logger backend and wire format used
+// by components that have been migrated off the package-level
+// *logrus.Logger (currently the webhook server and ResultProcessor).
+type LoggingConfig struct {
+	// Level is one of logging.LogLevel's values: "debug", "info", "warn",
+	// "error". Defaults to "info".
+	Level string `yaml:"level,omitempty"`
+	// Format is one of "json", "text", or "logfmt", selecting the
+	// log/slog-backed logging.Logger. Defaults to "json" when unset, so a
+	// config loaded through LoadConfig always selects the slog backend.
+	Format string `yaml:"format,omitempty"`
+}
+
+// ScannerAdapterConfig holds settings for the Harbor Pluggable Scanner
+// adapter API. The adapter is disabled unless Port is non-zero.
+type ScannerAdapterConfig struct {
+	Port  int    `yaml:"port"`
+	Token string `yaml:"token"`
+	// QueueSize and Workers size the adapter's own scan queue and worker
+	// pool, independent of the webhook path's Queue settings, since scans
+	// submitted through the adapter are driven by Harbor rather than by
+	// registry webhooks.
+	QueueSize int `yaml:"queue_size,omitempty"`
+	Workers   int `yaml:"workers,omitempty"`
 }
 
 // ServerConfig holds HTTP server settings
@@ -25,21 +90,347 @@ type ServerConfig struct {
 	WriteTimeout    string `yaml:"write_timeout"`
 	MaxRequestSize  int64  `yaml:"max_request_size"`
 	ShutdownTimeout string `yaml:"shutdown_timeout"`
+
+	// TLS configures the webhook listener to serve HTTPS. Required when
+	// any registry uses auth.type "mtls", since the client certificate is
+	// presented and verified during the TLS handshake itself.
+	TLS TLSConfig `yaml:"tls,omitempty"`
+}
+
+// TLSConfig configures the webhook server's listener certificate and, for
+// mTLS-authenticated registries, the trust bundle used to verify client
+// certificates.
+type TLSConfig struct {
+	// CertFile and KeyFile are the server's own PEM certificate and
+	// private key, required whenever TLS is configured at all.
+	CertFile string `yaml:"cert_file,omitempty"`
+	KeyFile  string `yaml:"key_file,omitempty"`
+
+	// ClientCAFile is a PEM file of CA certificates trusted to sign
+	// client certificates. Mutually exclusive with ClientCAPEM; exactly
+	// one is required when any registry uses auth.type "mtls".
+	ClientCAFile string `yaml:"client_ca_file,omitempty"`
+	// ClientCAPEM is an inline PEM-encoded CA bundle, an alternative to
+	// ClientCAFile for deployments that inject the trust bundle directly
+	// rather than mounting a file.
+	ClientCAPEM string `yaml:"client_ca_pem,omitempty"`
+}
+
+// AdminConfig holds settings for the token-gated shutdown/health admin API.
+// The admin listener is disabled when Port is zero.
+type AdminConfig struct {
+	Port  int    `yaml:"port"`
+	Token string `yaml:"token"`
 }
 
 // RegistryConfig defines settings for a single container registry
 type RegistryConfig struct {
 	Name    string          `yaml:"name"`
-	Type    string          `yaml:"type"` // dockerhub, harbor, gitlab
+	Type    string          `yaml:"type"` // dockerhub, harbor, gitlab, distribution, quay, ghcr, ecr, acr, gcr
 	URL     string          `yaml:"url"`
 	Auth    AuthConfig      `yaml:"auth"`
 	Scanner ScannerOverride `yaml:"scanner,omitempty"`
+	// WebhookSecret verifies that an inbound webhook genuinely came from
+	// this registry, as opposed to anyone who can reach the webhook URL.
+	// Its meaning is parser-specific: an HMAC signing key for registries
+	// that sign their payload (Harbor, GHCR), or a shared secret the
+	// registry is configured to send verbatim (Quay). Empty (the
+	// default) skips verification, preserving prior behavior for
+	// registries configured before signature checking existed.
+	WebhookSecret string `yaml:"webhook_secret,omitempty"`
+	// WebhookAuthHeader is an alternative to WebhookSecret for registries
+	// (currently Harbor) that let an administrator configure a fixed
+	// "Auth Header" value sent verbatim in every webhook delivery's
+	// Authorization header, rather than signing the payload. A registry
+	// may configure WebhookSecret, WebhookAuthHeader, both, or neither.
+	WebhookAuthHeader string `yaml:"webhook_auth_header,omitempty"`
+	// Sinks lists where this registry's scan results are published once
+	// a scan completes, in addition to the structured logging
+	// ResultProcessor always does. Each sink publishes independently: one
+	// sink's failure doesn't block the others.
+	Sinks []SinkConfig `yaml:"sinks,omitempty"`
+	// Verification configures cosign/notation signature verification,
+	// performed by verify.Verifier before a scan reaches
+	// scanner.ScannerBackend.Scan. Nil (the default) skips verification,
+	// preserving prior behavior of trusting whatever the webhook claims
+	// got pushed.
+	Verification *VerificationConfig `yaml:"verification,omitempty"`
+	// PullMode controls how this registry's images reach the scan queue:
+	// PullModeWebhook (the default) relies solely on inbound registry
+	// webhooks, PullModePoll relies solely on pkg/reconciler's periodic
+	// catalog walk, and PullModeBoth runs both. Poll mode is useful for
+	// registries whose webhook delivery is unreliable or not configured.
+	PullMode string `yaml:"pull_mode,omitempty"`
+	// PollInterval overrides how often the reconciler walks this
+	// registry's catalog when PullMode is PullModePoll or PullModeBoth.
+	// Empty falls back to ReconcilerConfig.PollInterval.
+	PollInterval string `yaml:"poll_interval,omitempty"`
+	// PullCredentials authenticates the reconciler's catalog/tag-list
+	// calls against this registry, independent of Scanner.Credentials
+	// (used in the Sysdig scan request payload) and Auth (used to verify
+	// inbound webhooks). Nil attempts catalog calls unauthenticated.
+	PullCredentials *RegistryAuthConfig `yaml:"pull_credentials,omitempty"`
+	// Priority controls how this registry's requests are scheduled
+	// against other registries sharing the same scanner adapter worker
+	// pool: "high", "normal" (the default), or "low". Unrecognized
+	// values fall back to "normal".
+	Priority string `yaml:"priority,omitempty"`
+	// MaxConcurrent caps how many of this registry's scans the scanner
+	// adapter worker pool will run at once, regardless of how many
+	// workers it has overall. 0 (the default) means unlimited, i.e. this
+	// registry may use every worker in the pool.
+	MaxConcurrent int `yaml:"max_concurrent,omitempty"`
+}
+
+// Pull mode values for RegistryConfig.PullMode.
+const (
+	PullModeWebhook = "webhook"
+	PullModePoll    = "poll"
+	PullModeBoth    = "both"
+)
+
+// PollsCatalog reports whether the reconciler should walk this
+// registry's catalog directly, i.e. PullMode is PullModePoll or
+// PullModeBoth.
+func (r RegistryConfig) PollsCatalog() bool {
+	return r.PullMode == PullModePoll || r.PullMode == PullModeBoth
+}
+
+// VerificationConfig configures signature and SBOM attestation
+// verification for a single registry. An image that fails verification
+// is rejected (models.ScanStatusRejected) before it ever reaches a
+// scanner backend.
+type VerificationConfig struct {
+	// Enabled turns on verification for this registry. False (or a nil
+	// VerificationConfig) skips verification entirely.
+	Enabled bool `yaml:"enabled"`
+	// Keys lists PEM-encoded public keys, any one of which may have
+	// signed the image. Used for cosign's static key-pair mode. Mutually
+	// exclusive with KeylessIdentities in practice, though both may be
+	// set to accept either.
+	Keys []string `yaml:"keys,omitempty"`
+	// KeylessIdentities authorizes keyless (Fulcio-issued) signing
+	// certificates whose subject and OIDC issuer match one of these
+	// entries. Used for cosign's keyless mode.
+	KeylessIdentities []KeylessIdentity `yaml:"keyless_identities,omitempty"`
+	// FulcioRoot is the Fulcio root certificate bundle (PEM) used to
+	// validate keyless signing certificates. Empty falls back to
+	// sigstore's public-good root.
+	FulcioRoot string `yaml:"fulcio_root,omitempty"`
+	// RekorURL is the Rekor transparency log queried to confirm a
+	// signature was publicly logged. Empty falls back to sigstore's
+	// public-good Rekor instance.
+	RekorURL string `yaml:"rekor_url,omitempty"`
+	// RequireAttestations also requires a valid SBOM attestation
+	// alongside the image signature.
+	RequireAttestations bool `yaml:"require_attestations,omitempty"`
+	// RequiredAnnotations must all be present, with matching values, on
+	// the signature for it to be accepted, e.g. pinning a CI pipeline ID.
+	RequiredAnnotations map[string]string `yaml:"required_annotations,omitempty"`
+	// Notation configures Notary v2 verification as an alternative to
+	// the Keys/KeylessIdentities cosign flow above, for registries whose
+	// images are signed with notation instead. Mutually exclusive with
+	// Keys/KeylessIdentities.
+	Notation *NotationConfig `yaml:"notation,omitempty"`
+}
+
+// NotationConfig configures Notary v2 (notation) signature verification.
+// Unlike cosign's sha256-<digest>.sig tag convention, notation signatures
+// are fetched via the OCI Distribution Spec's referrers API. This covers
+// notation's common "trust our own signing cert" deployment rather than
+// its full plugin/trust-policy schema.
+type NotationConfig struct {
+	// TrustStore is a PEM bundle of the CA certificates a notation
+	// signing certificate must chain to. Loadable via the
+	// ${FILE:<secret-name>} mechanism InjectSecretsIntoConfig resolves.
+	TrustStore string `yaml:"trust_store"`
+	// TrustedIdentitySubjects lists regular expressions matched against
+	// a verified signing certificate's subject; at least one must match
+	// for the image to be accepted.
+	TrustedIdentitySubjects []string `yaml:"trusted_identity_subjects"`
+}
+
+// KeylessIdentity authorizes one keyless signing identity: the
+// certificate subject (typically a CI job URI or signer email) and the
+// OIDC issuer that vouched for it, each matched as a regular expression
+// against the values in the signing certificate.
+type KeylessIdentity struct {
+	SubjectRegex string `yaml:"subject_regex"`
+	IssuerRegex  string `yaml:"issuer_regex"`
+}
+
+// SinkConfig configures one destination a registry's scan results are
+// published to. Exactly one of File/HTTP/Object/Kafka should be set,
+// matching Type.
+type SinkConfig struct {
+	Type SinkType `yaml:"type"`
+
+	File   *FileSinkConfig   `yaml:"file,omitempty"`
+	HTTP   *HTTPSinkConfig   `yaml:"http,omitempty"`
+	Object *ObjectSinkConfig `yaml:"object,omitempty"`
+	Kafka  *KafkaSinkConfig  `yaml:"kafka,omitempty"`
+
+	// Retry configures how many times and how long a sink publish is
+	// retried before the result is handed to DeadLetterPath. Empty
+	// RetryConfig falls back to sink.DefaultRetryConfig.
+	Retry RetryConfig `yaml:"retry,omitempty"`
+	// DeadLetterPath, if set, receives one JSON line per scan result
+	// this sink failed to publish after exhausting its retries.
+	DeadLetterPath string `yaml:"dead_letter_path,omitempty"`
+}
+
+// SinkType identifies which concrete ResultSink a SinkConfig builds.
+type SinkType string
+
+const (
+	SinkTypeFile   SinkType = "file"
+	SinkTypeHTTP   SinkType = "http"
+	SinkTypeObject SinkType = "object"
+	SinkTypeKafka  SinkType = "kafka"
+)
+
+// FileSinkConfig configures a sink.FileSink, which appends one JSON line
+// per scan result to Path.
+type FileSinkConfig struct {
+	Path string `yaml:"path"`
+}
+
+// HTTPSinkConfig configures a sink.HTTPSink, which POSTs one JSON body
+// per scan result to URL, HMAC-signed with Secret when set.
+type HTTPSinkConfig struct {
+	URL     string `yaml:"url"`
+	Secret  string `yaml:"secret,omitempty"`
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// ObjectSinkConfig configures a sink.ObjectSink, which uploads one
+// object per scan result (keyed by digest when available, otherwise
+// ImageRef and RequestID) to an S3- or GCS-style bucket.
+type ObjectSinkConfig struct {
+	// Provider selects the backing object store: "s3" or "gcs".
+	Provider string `yaml:"provider"`
+	Bucket   string `yaml:"bucket"`
+	// Prefix is prepended to every object key, e.g. "scan-reports/".
+	Prefix string `yaml:"prefix,omitempty"`
+	Region string `yaml:"region,omitempty"`
+}
+
+// KafkaSinkConfig configures a sink.KafkaSink, which produces one
+// message per scan result to Topic.
+type KafkaSinkConfig struct {
+	Brokers []string `yaml:"brokers"`
+	Topic   string   `yaml:"topic"`
+}
+
+// RetryConfig bounds a sink's retry/backoff behavior.
+type RetryConfig struct {
+	MaxAttempts    int    `yaml:"max_attempts,omitempty"`
+	InitialBackoff string `yaml:"initial_backoff,omitempty"`
+	MaxBackoff     string `yaml:"max_backoff,omitempty"`
 }
 
 // AuthConfig defines authentication settings for webhooks
 type AuthConfig struct {
-	Type   string `yaml:"type"`   // hmac or bearer
+	Type   string `yaml:"type"`   // hmac, bearer, jwt, mtls, either, or none
 	Secret string `yaml:"secret"` // HMAC secret or bearer token
+
+	// SignatureHeader is the header carrying the HMAC signature (format
+	// "sha256=<hex>"), used when Type is "hmac" or "either". Empty falls
+	// back to auth.DefaultSignatureHeader ("X-Registry-Signature").
+	SignatureHeader string `yaml:"signature_header,omitempty"`
+	// TimestampHeader is the header carrying the Unix timestamp the
+	// payload was signed at, used for replay protection alongside HMAC
+	// verification. Empty falls back to auth.DefaultTimestampHeader
+	// ("X-Registry-Timestamp").
+	TimestampHeader string `yaml:"timestamp_header,omitempty"`
+	// MaxSkew bounds how far the timestamp in TimestampHeader may drift
+	// from the server's clock before a request is rejected as a replay.
+	// Empty disables timestamp verification; a present header is then
+	// ignored.
+	MaxSkew string `yaml:"max_skew,omitempty"`
+
+	// JWT configures signed-JWT verification, used when Type is "jwt".
+	JWT JWTConfig `yaml:"jwt,omitempty"`
+
+	// ReplayProtection hardens "hmac"/"either" auth against a captured
+	// signature being replayed: it folds a timestamp into the signed
+	// input and remembers recently seen signatures. See
+	// ReplayProtectionConfig.
+	ReplayProtection ReplayProtectionConfig `yaml:"replay_protection,omitempty"`
+
+	// MTLS configures client-certificate identity matching, used when
+	// Type is "mtls". See MTLSConfig.
+	MTLS MTLSConfig `yaml:"mtls,omitempty"`
+}
+
+// MTLSConfig configures auth.VerifyMTLS for a registry authenticating via
+// mutual TLS client certificates instead of a shared secret, e.g. one
+// fronted by a service mesh sidecar (Istio, Consul Connect, Linkerd)
+// issuing SPIFFE-ID certs, or an internal PKI. The server.tls trust
+// bundle establishes that the client certificate chains to a trusted CA;
+// these allowlists additionally pin which specific identity is accepted.
+type MTLSConfig struct {
+	// AllowedDNSNames lists acceptable DNS SANs on the client leaf
+	// certificate.
+	AllowedDNSNames []string `yaml:"allowed_dns_names,omitempty"`
+	// AllowedURIs lists acceptable URI SANs, e.g. SPIFFE IDs like
+	// "spiffe://example.org/ns/ci/sa/harbor".
+	AllowedURIs []string `yaml:"allowed_uris,omitempty"`
+	// AllowedSubjects lists acceptable certificate subject patterns,
+	// matched against both the full subject distinguished name and the
+	// subject's Common Name.
+	AllowedSubjects []string `yaml:"allowed_subjects,omitempty"`
+}
+
+// ReplayProtectionConfig enables timestamp-bound HMAC signing and an
+// in-process nonce cache for a registry's webhook auth, so a captured
+// valid request can't be replayed indefinitely. When Enabled, the HMAC
+// is computed over "<timestamp>.<body>" instead of the raw body, and the
+// signature is rejected a second time if seen again within Tolerance.
+type ReplayProtectionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Tolerance bounds how far the signing timestamp may drift from the
+	// server clock. Empty defaults to auth.DefaultReplayTolerance (5m).
+	Tolerance string `yaml:"tolerance,omitempty"`
+	// TimestampHeader carries the signing timestamp, as Unix seconds or
+	// RFC3339. Empty falls back to auth.DefaultReplayTimestampHeader
+	// ("X-Webhook-Timestamp").
+	TimestampHeader string `yaml:"timestamp_header,omitempty"`
+	// CacheSize bounds the number of recently seen signatures kept in
+	// memory. Empty/zero defaults to auth.DefaultNonceCacheSize.
+	CacheSize int `yaml:"cache_size,omitempty"`
+}
+
+// JWTConfig configures auth.VerifyJWT for a registry whose webhook
+// deliveries (or the CI system triggering them) authenticate with a
+// signed JWT rather than a shared secret: GitLab and GitHub Actions OIDC
+// tokens, or a Harbor robot account JWT.
+type JWTConfig struct {
+	// Issuer is the required `iss` claim. Empty skips the issuer check.
+	Issuer string `yaml:"issuer,omitempty"`
+	// Audience is the required `aud` claim. Empty skips the audience
+	// check.
+	Audience string `yaml:"audience,omitempty"`
+
+	// Secret is a static HMAC shared secret, used to verify HS256-signed
+	// tokens. Mutually exclusive with PublicKey and JWKSURL.
+	Secret string `yaml:"secret,omitempty"`
+	// PublicKey is a static RSA public key in PEM format, used to verify
+	// RS256-signed tokens. Mutually exclusive with Secret and JWKSURL.
+	PublicKey string `yaml:"public_key,omitempty"`
+	// JWKSURL is a JWKS endpoint (e.g. an OIDC provider's
+	// jwks_uri) whose keys are fetched and cached by `kid`, refreshed
+	// every JWKSRefreshInterval. Mutually exclusive with Secret and
+	// PublicKey.
+	JWKSURL string `yaml:"jwks_url,omitempty"`
+	// JWKSRefreshInterval is how often cached JWKS keys are re-fetched.
+	// Empty defaults to auth.DefaultJWKSRefreshInterval.
+	JWKSRefreshInterval string `yaml:"jwks_refresh_interval,omitempty"`
+
+	// SubjectAllowlist, if non-empty, restricts accepted tokens to these
+	// `sub` claim values, e.g. pinning a registry to one GitHub repo's
+	// OIDC subject or one GitLab project's CI job subject.
+	SubjectAllowlist []string `yaml:"subject_allowlist,omitempty"`
 }
 
 // ScannerOverride holds registry-specific scanner settings
@@ -47,6 +438,38 @@ type ScannerOverride struct {
 	Type        ScannerType         `yaml:"type,omitempty"`
 	Timeout     string              `yaml:"timeout,omitempty"`
 	Credentials RegistryCredentials `yaml:"credentials,omitempty"`
+	// DockerConfigPath points at a Docker/OCI config.json used to resolve
+	// this registry's credentials via pkg/registryauth.Resolver (static
+	// "auths" entries, "credHelpers", or "credsStore"), tried after the
+	// static Credentials above. When empty, scanner.CredentialProvider
+	// uses Scanner.AuthFile, and ~/.docker/config.json if that is also
+	// unset; the other callers use ~/.docker/config.json directly.
+	DockerConfigPath string `yaml:"docker_config_path,omitempty"`
+	// Helper names an external credential helper binary
+	// (docker-credential-<Helper>) invoked directly for this registry,
+	// tried after DockerConfigPath, for registries whose credentials come
+	// from a helper (ECR, GCR, ACR) with no surrounding config.json.
+	Helper string `yaml:"helper,omitempty"`
+	// AuthSoftFail downgrades a credential-resolution failure (a
+	// malformed auth file, a credential helper that errors) from a hard
+	// error to a warning, falling back to no credentials. Set this for
+	// registries that serve known-public images, where a broken
+	// credential source shouldn't block the scan.
+	AuthSoftFail bool `yaml:"auth_soft_fail,omitempty"`
+	// ImagePullSecrets names kubernetes.io/dockerconfigjson Secrets, in
+	// the running Pod's own namespace, to resolve this registry's
+	// credentials from when running in-cluster - the same secrets a
+	// Pod's own imagePullSecrets would reference. Tried after Helper, so
+	// it only applies when neither a static credential, an auth file,
+	// nor a helper resolved anything for this registry.
+	ImagePullSecrets []string `yaml:"image_pull_secrets,omitempty"`
+	// ScanPlatforms restricts which platforms a manifest list / OCI image
+	// index fans out into when RegistryScanner encounters one, each
+	// written "os/arch" (e.g. "linux/amd64"), or the single value "all"
+	// to scan every platform the index lists. Empty (the default)
+	// behaves like "all", so operators only need to set this to narrow
+	// scanning to the platforms they actually run.
+	ScanPlatforms []string `yaml:"scan_platforms,omitempty"`
 }
 
 // RegistryCredentials stores registry authentication for pulling images
@@ -63,20 +486,275 @@ type ScannerConfig struct {
 	DefaultTimeout  string                 `yaml:"default_timeout"`
 	MaxConcurrent   int                    `yaml:"max_concurrent"`
 	RegistryScanner *RegistryScannerConfig `yaml:"registry_scanner,omitempty"`
+	// Composite configures CompositeScanner, used when Type is
+	// ScannerTypeComposite to fan a scan out across multiple backends
+	// (e.g. shadow-scanning with both "cli" and "registry" while
+	// migrating between them).
+	Composite *CompositeConfig `yaml:"composite,omitempty"`
+	// Trivy configures the Trivy CLI backend, used when Type is
+	// ScannerTypeTrivy (or as a CompositeConfig.Backends member).
+	Trivy *TrivyScannerConfig `yaml:"trivy,omitempty"`
+	// Clair configures the Clair v4 Indexer/Matcher backend, used when
+	// Type is ScannerTypeClair (or as a CompositeConfig.Backends member).
+	Clair *ClairScannerConfig `yaml:"clair,omitempty"`
+	// AuthFile is the default Docker/Podman-style auth.json (or
+	// config.json) path scanner.CredentialProvider uses to resolve
+	// registry credentials when a registry doesn't set its own
+	// ScannerOverride.DockerConfigPath. Empty falls back to
+	// ~/.docker/config.json. Other callers of DockerConfigPath do not
+	// read it.
+	AuthFile string `yaml:"auth_file,omitempty"`
+	// CacheMaxEntries bounds how many scan results ResultProcessor keeps
+	// in its in-memory cache before evicting the oldest one. Zero or
+	// negative falls back to DefaultCacheMaxEntries.
+	CacheMaxEntries int `yaml:"cache_max_entries,omitempty"`
+	// CacheTTL is how long a cached scan result may be reused before
+	// ResultProcessor's janitor sweeps it. Empty falls back to
+	// defaultScannerCacheTTL.
+	CacheTTL string `yaml:"cache_ttl,omitempty"`
+	// ImageCache configures imagecache.Cache, a shared on-disk pull
+	// cache CLIScanner consults before invoking the Sysdig CLI so
+	// back-to-back scans of tags sharing a base image don't redownload
+	// the same layers. Nil disables it, leaving the CLI to pull the
+	// image itself as before.
+	ImageCache *ImageCacheConfig `yaml:"image_cache,omitempty"`
+}
+
+// CompositeConfig configures CompositeScanner: which backends to fan a
+// scan out to, how to reconcile their results, and whether one backend
+// failing should fail the whole scan.
+type CompositeConfig struct {
+	// Backends lists the scanner types to run concurrently for every
+	// scan request. Order matters for ReconciliationPrimaryWithFallback,
+	// where the first entry is the primary backend.
+	Backends []ScannerType `yaml:"backends"`
+	// Policy determines how the backends' results are merged into one
+	// models.ScanResult. Defaults to ReconciliationWorstSeverity.
+	Policy ReconciliationPolicy `yaml:"policy,omitempty"`
+	// FailOnPartialError makes the composite scan fail if any backend
+	// errors, rather than reconciling over the backends that succeeded.
+	FailOnPartialError bool `yaml:"fail_on_partial_error,omitempty"`
+}
+
+// TrivyScannerConfig holds settings for the Trivy CLI scanner backend.
+type TrivyScannerConfig struct {
+	// BinaryPath is the path to the trivy executable. Empty resolves
+	// "trivy" via $PATH.
+	BinaryPath string `yaml:"binary_path,omitempty"`
+}
+
+// ClairScannerConfig holds settings for the Clair v4 Indexer/Matcher
+// scanner backend.
+type ClairScannerConfig struct {
+	// IndexerURL and MatcherURL are the base URLs of Clair v4's Indexer
+	// and Matcher services (e.g. "http://clair-indexer:6060",
+	// "http://clair-matcher:6060"). Clair deploys these as independently
+	// scalable services, so they're configured separately even though a
+	// combo deployment often runs both behind the same URL.
+	IndexerURL string `yaml:"indexer_url"`
+	MatcherURL string `yaml:"matcher_url"`
+	VerifyTLS  bool   `yaml:"verify_tls"`
+	// PollInterval is how often the indexer's index_report is polled for
+	// IndexFinished. Empty falls back to a conservative default.
+	PollInterval string `yaml:"poll_interval,omitempty"`
+	// MaxAttempts bounds how many times a failed Indexer/Matcher API call
+	// is retried before giving up. Zero falls back to
+	// retry.DefaultPolicy's attempt count.
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
+}
+
+// ImageCacheConfig configures imagecache.Cache's on-disk content-addressable
+// blob store.
+type ImageCacheConfig struct {
+	// Enabled turns on pre-pulling the image into the local cache and
+	// passing its OCI layout to the scanner, instead of letting the
+	// scanner pull the image itself.
+	Enabled bool `yaml:"enabled"`
+	// Dir is the root directory the cache stores blobs and ephemeral
+	// per-scan OCI layouts under. Empty falls back to
+	// defaultImageCacheDir.
+	Dir string `yaml:"dir,omitempty"`
+	// MaxSizeBytes bounds the total size of blobs kept in the cache
+	// before the least-recently-used ones are evicted. Zero or negative
+	// falls back to defaultImageCacheMaxSizeBytes.
+	MaxSizeBytes int64 `yaml:"max_size_bytes,omitempty"`
 }
 
 // RegistryScannerConfig holds Sysdig Registry Scanner API settings
 type RegistryScannerConfig struct {
-	APIURL       string `yaml:"api_url"`
+	APIURL string `yaml:"api_url"`
+	// ProjectID is the Sysdig project images are scanned into. When
+	// ProjectRoutes is set, ProjectID instead becomes the fallback project
+	// used for images that don't match any route.
 	ProjectID    string `yaml:"project_id"`
 	VerifyTLS    bool   `yaml:"verify_tls"`
 	PollInterval string `yaml:"poll_interval"`
+	// ProjectRoutes is an ordered list of rules mapping an image's
+	// registry host and/or repository to a Sysdig project, evaluated
+	// top-to-bottom with the first match winning. Images that match no
+	// rule fall back to ProjectID. Leave empty to always use ProjectID.
+	ProjectRoutes []ProjectRoute `yaml:"project_routes,omitempty"`
+	// MaxAttempts bounds how many times a failed API call (initiate or
+	// poll) is retried before giving up. Zero falls back to
+	// retry.DefaultPolicy's attempt count.
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
+	// RequestsPerSecond and Burst bound how fast this process calls the
+	// Sysdig Registry Scanner API, so a pile of concurrent webhook
+	// admissions doesn't stampede it and trigger 429s. Zero values fall
+	// back to a conservative default.
+	RequestsPerSecond float64 `yaml:"requests_per_second,omitempty"`
+	Burst             int     `yaml:"burst,omitempty"`
+	// Registries holds per-hostname registry credentials used for the
+	// pkg/scanner/registryauth pre-flight manifest check, keyed by
+	// registry hostname (e.g. "registry.example.com"). These are
+	// independent of Sysdig's own credentials and of the per-registry
+	// RegistryConfig.Scanner.Credentials used in the scan request payload.
+	Registries map[string]RegistryAuthConfig `yaml:"registries,omitempty"`
+	// CircuitBreaker configures the breaker wrapping calls to the Sysdig
+	// Registry Scanner API, so an outage fails fast instead of retrying
+	// into a dependency that's already down. Nil disables the breaker.
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker,omitempty"`
+	// Backoff configures the decorrelated-jitter backoff retry.Do uses
+	// between failed initiate/poll calls. Nil falls back to
+	// retry.DefaultPolicy.
+	Backoff *BackoffPolicyConfig `yaml:"backoff,omitempty"`
+}
+
+// CircuitBreakerConfig configures a circuitbreaker.Breaker. See
+// circuitbreaker.Config for what each field controls; string fields here
+// follow this package's convention of holding raw duration strings so
+// internal packages stay agnostic of pkg/config.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is how many consecutive retriable failures trip
+	// the breaker. Zero falls back to circuitbreaker.DefaultConfig's.
+	FailureThreshold int `yaml:"failure_threshold,omitempty"`
+	// CooldownDuration is how long the breaker stays open before
+	// allowing a half-open probe, e.g. "30s". Empty falls back to
+	// circuitbreaker.DefaultConfig's.
+	CooldownDuration string `yaml:"cooldown_duration,omitempty"`
+	// HalfOpenProbes is how many calls are let through per half-open
+	// period. Zero falls back to circuitbreaker.DefaultConfig's.
+	HalfOpenProbes int `yaml:"half_open_probes,omitempty"`
+}
+
+// BackoffPolicyConfig configures a retry.Policy. See retry.Policy for
+// what each field controls; string fields here follow this package's
+// convention of holding raw duration strings so internal packages stay
+// agnostic of pkg/config.
+type BackoffPolicyConfig struct {
+	// BaseDelay is the minimum delay before the first retry, e.g.
+	// "500ms". Empty falls back to retry.DefaultPolicy's.
+	BaseDelay string `yaml:"base_delay,omitempty"`
+	// MaxDelay caps the delay between retries, e.g. "30s". Empty falls
+	// back to retry.DefaultPolicy's.
+	MaxDelay string `yaml:"max_delay,omitempty"`
+	// MaxAttempts bounds how many times a failed call is retried before
+	// giving up. Zero falls back to retry.DefaultPolicy's.
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
+	// DisableJitter makes backoff delays deterministic (always the
+	// decorrelated-jitter upper bound) instead of randomized. Defaults
+	// to false, i.e. jitter enabled.
+	DisableJitter bool `yaml:"disable_jitter,omitempty"`
+}
+
+// ProjectRoute maps images matching Registry and/or Repository to a Sysdig
+// project. Registry matches against the image's registry host and
+// Repository against its repository path, both as shell-style
+// path.Match globs (e.g. "team-a/*"); either may be left empty to match
+// any value for that field.
+type ProjectRoute struct {
+	Registry   string `yaml:"registry,omitempty"`
+	Repository string `yaml:"repository,omitempty"`
+	ProjectID  string `yaml:"project_id"`
+}
+
+// RegistryAuthConfig holds the credentials used to authenticate against a
+// single registry host when resolving an image's digest before handing it
+// off to Sysdig.
+type RegistryAuthConfig struct {
+	Username      string `yaml:"username,omitempty"`
+	Password      string `yaml:"password,omitempty"`
+	IdentityToken string `yaml:"identity_token,omitempty"`
 }
 
 // QueueConfig holds event queue settings
 type QueueConfig struct {
 	BufferSize int `yaml:"buffer_size"`
 	Workers    int `yaml:"workers"`
+
+	// DedupBackend selects the queue.DedupStore implementation used to
+	// detect duplicate scan requests: "memory" (default, process-local)
+	// or "redis" (shared across horizontally-scaled webhook replicas).
+	DedupBackend string `yaml:"dedup_backend,omitempty"`
+	// RedisAddr is the Redis server address (host:port) used when
+	// DedupBackend is "redis".
+	RedisAddr string `yaml:"redis_addr,omitempty"`
+	// DedupTTL is how long a scan key is considered a duplicate after
+	// being marked.
+	DedupTTL string `yaml:"dedup_ttl,omitempty"`
+
+	// Backend selects the queue.Backend implementation used to persist
+	// queued scan requests (including pending retries): "memory"
+	// (default, process-local), "bolt" (single-node, surviving a pod
+	// restart without any external dependency), "redis" (shared across
+	// replicas, using BRPOPLPUSH), "gcs", "s3" (object-storage, surviving
+	// a full restart of every replica), or "nats" (JetStream, using a
+	// durable pull consumer).
+	Backend string `yaml:"backend,omitempty"`
+	// LeaseTTL is how long a worker may hold a request leased from
+	// Backend before ReapExpiredLeases returns it to pending. For the
+	// "nats" backend this instead sets the underlying consumer's
+	// AckWait; see NatsBackend's doc comment.
+	LeaseTTL string `yaml:"lease_ttl,omitempty"`
+	// ObjectStoreBucket is the GCS or S3 bucket name used when Backend
+	// is "gcs" or "s3".
+	ObjectStoreBucket string `yaml:"object_store_bucket,omitempty"`
+
+	// BoltPath is the file path of the BoltDB database used when Backend
+	// is "bolt". The file (and its parent directories) is created on
+	// first use if it doesn't exist.
+	BoltPath string `yaml:"bolt_path,omitempty"`
+
+	// NatsURL is the NATS server URL used when Backend is "nats", e.g.
+	// "nats://localhost:4222".
+	NatsURL string `yaml:"nats_url,omitempty"`
+	// NatsMaxDeliveries caps how many times JetStream may redeliver a
+	// message before NatsBackend moves it to a dead-letter subject
+	// instead of leasing it again. Defaults to 5.
+	NatsMaxDeliveries int `yaml:"nats_max_deliveries,omitempty"`
+
+	// MaxRetries, RetryInitialBackoff, RetryMaxBackoff and
+	// RetryBackoffMultiplier configure the RetryManager a
+	// BackendWorkerPool uses to decide whether a failed scan gets
+	// requeued with exponential backoff or dead-lettered.
+	MaxRetries             int     `yaml:"max_retries,omitempty"`
+	RetryInitialBackoff    string  `yaml:"retry_initial_backoff,omitempty"`
+	RetryMaxBackoff        string  `yaml:"retry_max_backoff,omitempty"`
+	RetryBackoffMultiplier float64 `yaml:"retry_backoff_multiplier,omitempty"`
+	// RetryJitterFraction randomizes each computed backoff by up to
+	// +/-this fraction (e.g. 0.2 for +/-20%), so retries from many
+	// requests that failed at the same instant don't all come back to
+	// life in the same instant. Zero disables jitter. See
+	// RetryManager.calculateBackoff.
+	RetryJitterFraction float64 `yaml:"retry_jitter_fraction,omitempty"`
+	// UnauthorizedRetryWindow bounds how long after a request's first
+	// attempt a 401/403 is treated as registry auth JWT clock skew
+	// (retried immediately, then once more after a short fixed delay)
+	// rather than a permanent rejection. Empty falls back to "30s". See
+	// RetryManager.ShouldRetry.
+	UnauthorizedRetryWindow string `yaml:"unauthorized_retry_window,omitempty"`
+
+	// DeadLetterPath, if set, receives one JSON line per scan request
+	// RetryManager has given up retrying. Unset drops them, matching
+	// BackendWorkerPool's behavior before this field existed.
+	DeadLetterPath string `yaml:"dead_letter_path,omitempty"`
+
+	// LeaderLockKey and LeaderLockTTL configure the Redis-backed lock a
+	// RedisLeaderElector contests when Backend is "redis", so only one
+	// horizontally-scaled webhook replica reaps expired leases at a
+	// time. Unused for other backends; see queue.NewLeaderElector.
+	LeaderLockKey string `yaml:"leader_lock_key,omitempty"`
+	LeaderLockTTL string `yaml:"leader_lock_ttl,omitempty"`
 }
 
 // ParseDuration converts string duration to time.Duration
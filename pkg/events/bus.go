@@ -0,0 +1,288 @@
+// Package events implements a small in-memory pub/sub bus for per-scan
+// lifecycle events and log lines, keyed by RequestID. webhook.Server's
+// Server-Sent Events endpoint subscribes to it so a caller can watch a
+// long-running scan in real time instead of only learning the outcome
+// once it completes; scanner.CLIScanner and scanner.RegistryScanner (see
+// their eventBus fields) publish to it.
+package events
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event types a scan's stream can carry. Stage events use the
+// "stage:<name>" form (e.g. "stage:pulling") rather than one constant per
+// stage, since whichever backend is producing them names its own stages;
+// see scanner.ScanProgressEvent.Stage for the equivalent on the NDJSON
+// progress stream that scanner.APIClient.StreamAPIRequest decodes.
+const (
+	TypeQueued    = "queued"
+	TypeStarted   = "started"
+	TypeCompleted = "completed"
+	TypeFailed    = "failed"
+	TypeTimeout   = "timeout"
+	TypeLog       = "log"
+)
+
+// Event is one entry in a scan's lifecycle/log event stream.
+type Event struct {
+	Type      string    `json:"type"`
+	RequestID string    `json:"request_id"`
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DefaultBufferSize bounds a scan's replay ring buffer when NewBus is
+// called with bufSize <= 0.
+const DefaultBufferSize = 200
+
+// streamTTL bounds how long an unsubscribed scan's event stream (its
+// ring buffer of recent events) is kept around before the janitor evicts
+// it, the same tradeoff scanneradapter.resultStore's ttl makes for its
+// own per-scan state, so a long-running process doesn't accumulate one
+// stream per RequestID it has ever scanned.
+const streamTTL = 1 * time.Hour
+
+// Bus fans a scan's lifecycle events and log lines out to subscribers,
+// keyed by RequestID. Each scan keeps a bounded ring buffer of its most
+// recent events so a subscriber that connects after the scan has already
+// started - or even completed - still sees recent history instead of
+// only events published from the moment it subscribed.
+type Bus struct {
+	bufSize int
+
+	mu      sync.Mutex
+	streams map[string]*stream
+
+	janitorStop chan struct{}
+	janitorDone chan struct{}
+}
+
+// stream is one scan's ring buffer of recent events plus its live
+// subscriber channels.
+type stream struct {
+	mu           sync.Mutex
+	buffer       []Event
+	next         int
+	filled       bool
+	subs         []chan Event
+	lastActivity time.Time
+}
+
+// NewBus creates a Bus whose per-scan ring buffers hold bufSize events,
+// falling back to DefaultBufferSize when bufSize <= 0. A background
+// janitor goroutine sweeps streams that have had no subscribers for
+// streamTTL; call Close once the Bus is no longer needed to stop it.
+func NewBus(bufSize int) *Bus {
+	if bufSize <= 0 {
+		bufSize = DefaultBufferSize
+	}
+	b := &Bus{
+		bufSize:     bufSize,
+		streams:     make(map[string]*stream),
+		janitorStop: make(chan struct{}),
+		janitorDone: make(chan struct{}),
+	}
+	go b.runJanitor()
+	return b
+}
+
+// Close stops the background janitor goroutine. Safe to call once;
+// callers that own a long-lived Bus should wire this into their shutdown
+// path, the same way scanner.ResultProcessor.Close is.
+func (b *Bus) Close() {
+	select {
+	case <-b.janitorStop:
+		// already closed
+	default:
+		close(b.janitorStop)
+	}
+	<-b.janitorDone
+}
+
+// runJanitor periodically sweeps streams that have gone unsubscribed for
+// longer than streamTTL.
+func (b *Bus) runJanitor() {
+	defer close(b.janitorDone)
+
+	ticker := time.NewTicker(streamTTL / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.sweepExpired()
+		case <-b.janitorStop:
+			return
+		}
+	}
+}
+
+// sweepExpired removes every stream with no live subscribers whose
+// lastActivity is older than streamTTL.
+func (b *Bus) sweepExpired() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for requestID, st := range b.streams {
+		st.mu.Lock()
+		expired := len(st.subs) == 0 && now.Sub(st.lastActivity) > streamTTL
+		st.mu.Unlock()
+
+		if expired {
+			delete(b.streams, requestID)
+		}
+	}
+}
+
+// streamFor returns requestID's stream, creating it on first use.
+func (b *Bus) streamFor(requestID string) *stream {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st, ok := b.streams[requestID]
+	if !ok {
+		st = &stream{buffer: make([]Event, b.bufSize), lastActivity: time.Now()}
+		b.streams[requestID] = st
+	}
+	return st
+}
+
+// Publish appends event to its scan's ring buffer and forwards it to
+// every live subscriber for that RequestID. Safe to call with no
+// subscribers attached yet - the event is simply buffered for later
+// replay. Held for the whole call (including the subscriber sends below)
+// so it can't interleave with an unsubscribe closing one of those same
+// channels, which would otherwise panic with a send on a closed channel.
+func (b *Bus) Publish(event Event) {
+	st := b.streamFor(event.RequestID)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.buffer[st.next] = event
+	st.next = (st.next + 1) % len(st.buffer)
+	if st.next == 0 {
+		st.filled = true
+	}
+	st.lastActivity = time.Now()
+
+	for _, ch := range st.subs {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber: drop rather than block Publish (and the
+			// scan producing the event) on a stalled SSE connection.
+		}
+	}
+}
+
+// Subscribe replays requestID's buffered events (oldest first) onto the
+// returned channel, registers it for any events published afterward, and
+// returns an unsubscribe function the caller must call exactly once when
+// done listening.
+func (b *Bus) Subscribe(requestID string) (<-chan Event, func()) {
+	st := b.streamFor(requestID)
+	ch := make(chan Event, b.bufSize)
+
+	st.mu.Lock()
+	for _, event := range st.replayLocked() {
+		ch <- event
+	}
+	st.subs = append(st.subs, ch)
+	st.mu.Unlock()
+
+	unsubscribe := func() {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+
+		for i, s := range st.subs {
+			if s == ch {
+				st.subs = append(st.subs[:i], st.subs[i+1:]...)
+				break
+			}
+		}
+		st.lastActivity = time.Now()
+		close(ch)
+	}
+
+	return ch, unsubscribe
+}
+
+// replayLocked returns st's buffered events in publish order. Callers
+// must hold st.mu.
+func (st *stream) replayLocked() []Event {
+	if !st.filled {
+		out := make([]Event, st.next)
+		copy(out, st.buffer[:st.next])
+		return out
+	}
+
+	out := make([]Event, len(st.buffer))
+	n := copy(out, st.buffer[st.next:])
+	copy(out[n:], st.buffer[:st.next])
+	return out
+}
+
+// Writer returns an io.WriteCloser that publishes each newline-terminated
+// line written to it as a TypeLog event for requestID. Wired into a
+// scanner backend's exec.Cmd.Stderr, this lets raw scanner log output
+// stream live to SSE subscribers instead of only being visible in
+// ScanResult.ErrorOutput once the scan completes. Close publishes
+// whatever partial line is left unterminated.
+func (b *Bus) Writer(requestID string) io.WriteCloser {
+	return &logWriter{bus: b, requestID: requestID}
+}
+
+// logWriter buffers partial lines between Write calls so a log line split
+// across two writes (as a process's stderr pipe commonly does) is still
+// published as a single event.
+type logWriter struct {
+	bus       *Bus
+	requestID string
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		line := string(w.pending[:idx])
+		w.pending = w.pending[idx+1:]
+		w.publishLocked(line)
+	}
+
+	return len(p), nil
+}
+
+func (w *logWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) > 0 {
+		w.publishLocked(string(w.pending))
+		w.pending = nil
+	}
+	return nil
+}
+
+func (w *logWriter) publishLocked(line string) {
+	w.bus.Publish(Event{
+		Type:      TypeLog,
+		RequestID: w.requestID,
+		Message:   line,
+		Timestamp: time.Now(),
+	})
+}
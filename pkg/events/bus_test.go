@@ -0,0 +1,127 @@
+package events
+
+import "testing"
+
+func TestBus_PublishToSubscriber(t *testing.T) {
+	b := NewBus(0)
+
+	ch, unsubscribe := b.Subscribe("scan-1")
+	defer unsubscribe()
+
+	b.Publish(Event{Type: TypeStarted, RequestID: "scan-1"})
+
+	select {
+	case event := <-ch:
+		if event.Type != TypeStarted {
+			t.Errorf("Publish() delivered %+v, want type=%s", event, TypeStarted)
+		}
+	default:
+		t.Error("Publish() did not deliver to subscriber")
+	}
+}
+
+func TestBus_PublishWithNoSubscribers(t *testing.T) {
+	b := NewBus(0)
+
+	// Should not panic or block when nobody is listening yet.
+	b.Publish(Event{Type: TypeStarted, RequestID: "scan-1"})
+}
+
+func TestBus_SubscribeReplaysBufferedEvents(t *testing.T) {
+	b := NewBus(2)
+
+	b.Publish(Event{Type: TypeQueued, RequestID: "scan-1"})
+	b.Publish(Event{Type: TypeStarted, RequestID: "scan-1"})
+
+	ch, unsubscribe := b.Subscribe("scan-1")
+	defer unsubscribe()
+
+	want := []string{TypeQueued, TypeStarted}
+	for _, w := range want {
+		select {
+		case event := <-ch:
+			if event.Type != w {
+				t.Errorf("replayed event type = %s, want %s", event.Type, w)
+			}
+		default:
+			t.Errorf("Subscribe() did not replay buffered event %s", w)
+		}
+	}
+}
+
+func TestBus_SubscribeReplayWrapsRingBuffer(t *testing.T) {
+	b := NewBus(2)
+
+	// With a buffer of 2, the first event should be evicted once a third
+	// is published.
+	b.Publish(Event{Type: TypeQueued, RequestID: "scan-1"})
+	b.Publish(Event{Type: TypeStarted, RequestID: "scan-1"})
+	b.Publish(Event{Type: TypeCompleted, RequestID: "scan-1"})
+
+	ch, unsubscribe := b.Subscribe("scan-1")
+	defer unsubscribe()
+
+	want := []string{TypeStarted, TypeCompleted}
+	for _, w := range want {
+		event := <-ch
+		if event.Type != w {
+			t.Errorf("replayed event type = %s, want %s", event.Type, w)
+		}
+	}
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	b := NewBus(0)
+
+	ch, unsubscribe := b.Subscribe("scan-1")
+	unsubscribe()
+
+	if _, ok := <-ch; ok {
+		t.Error("unsubscribe() expected channel to be closed")
+	}
+
+	// Publish after unsubscribe should be a no-op, not a panic.
+	b.Publish(Event{Type: TypeStarted, RequestID: "scan-1"})
+}
+
+func TestBus_WriterPublishesCompleteLines(t *testing.T) {
+	b := NewBus(0)
+
+	ch, unsubscribe := b.Subscribe("scan-1")
+	defer unsubscribe()
+
+	w := b.Writer("scan-1")
+	w.Write([]byte("pulling image\nscanning "))
+	w.Write([]byte("layers\n"))
+
+	want := []string{"pulling image", "scanning layers"}
+	for _, line := range want {
+		event := <-ch
+		if event.Type != TypeLog || event.Message != line {
+			t.Errorf("got %+v, want log message %q", event, line)
+		}
+	}
+}
+
+func TestBus_CloseStopsJanitor(t *testing.T) {
+	b := NewBus(0)
+
+	// Safe to call once, and should return instead of hanging.
+	b.Close()
+}
+
+func TestBus_WriterCloseFlushesPartialLine(t *testing.T) {
+	b := NewBus(0)
+
+	ch, unsubscribe := b.Subscribe("scan-1")
+	defer unsubscribe()
+
+	w := b.Writer("scan-1")
+	w.Write([]byte("no trailing newline"))
+	w.Close()
+
+	event := <-ch
+	if event.Type != TypeLog || event.Message != "no trailing newline" {
+		t.Errorf("got %+v, want log message %q", event, "no trailing newline")
+	}
+}
@@ -0,0 +1,281 @@
+// Package imagecache pulls an image's manifest and layers through the
+// same Docker Registry HTTP API v2 authentication dance
+// pkg/scanner/registryauth implements for RegistryScanner's pre-flight
+// checks, storing blobs in a content-addressable store on disk so
+// back-to-back scans of tags sharing a base image (the common case for a
+// series of CI builds off one base) don't redownload identical layers.
+// Concurrent pulls of the same blob digest are coalesced with
+// singleflight, and the store evicts its least-recently-used blobs once
+// it exceeds its configured disk size budget.
+package imagecache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/registryauth"
+	scannerauth "github.com/sysdig/registry-webhook-scanner/pkg/scanner/registryauth"
+	"golang.org/x/sync/singleflight"
+)
+
+// manifestAcceptHeader lists the manifest media types this package
+// understands, including the multi-arch list/index types it fans out
+// into a single-platform manifest.
+const manifestAcceptHeader = "application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.index.v1+json"
+
+// preferredPlatform is the platform selected out of a multi-arch manifest
+// list, matching what a scanner host typically runs as.
+const preferredPlatform = "linux/amd64"
+
+// PulledImage is the result of a successful Cache.Pull: a local OCI
+// image layout a scanner can read directly instead of pulling the image
+// itself.
+type PulledImage struct {
+	// Dir is the path to the ephemeral OCI image layout directory.
+	Dir string
+	// Cleanup removes Dir. It does not touch the underlying blob store,
+	// so other layouts referencing the same blobs are unaffected.
+	Cleanup func()
+}
+
+// Cache pulls images into a shared, disk-backed, content-addressable
+// blob store, handing callers a per-pull OCI layout directory built from
+// hardlinks into that store.
+type Cache struct {
+	logger *logrus.Logger
+
+	store    *blobStore
+	resolver *registryauth.Resolver
+
+	layoutsDir string
+
+	pullGroup singleflight.Group
+
+	clientsMu sync.Mutex
+	clients   map[string]*http.Client
+}
+
+// NewCache creates a Cache rooted at cfg.Dir, bounded to cfg.MaxSizeBytes.
+// Callers should only construct one when cfg != nil (imagecache is
+// disabled entirely otherwise).
+func NewCache(cfg *config.ImageCacheConfig, logger *logrus.Logger) (*Cache, error) {
+	blobsDir := filepath.Join(cfg.Dir, "blobs", "sha256")
+	layoutsDir := filepath.Join(cfg.Dir, "layouts")
+
+	if err := os.MkdirAll(blobsDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create image cache blobs dir: %w", err)
+	}
+	if err := os.MkdirAll(layoutsDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create image cache layouts dir: %w", err)
+	}
+
+	store, err := newBlobStore(blobsDir, cfg.MaxSizeBytes, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		logger:     logger,
+		store:      store,
+		resolver:   registryauth.NewResolver(0),
+		layoutsDir: layoutsDir,
+		clients:    make(map[string]*http.Client),
+	}, nil
+}
+
+// Pull resolves imageRef's manifest, fetches any blob missing from the
+// local store, and returns an ephemeral OCI layout directory containing
+// it - the config blob, every layer, and the manifest itself -
+// hardlinked from the shared store. Callers must call the returned
+// PulledImage's Cleanup once the scanner is done reading it.
+func (c *Cache) Pull(ctx context.Context, imageRef, registryName string, registries []config.RegistryConfig) (*PulledImage, error) {
+	host, repository, reference, err := parseImageRef(imageRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image reference: %w", err)
+	}
+
+	client := c.registryClient(host, repository, c.credentials(registryName, host, registries))
+
+	manifest, err := c.fetchManifest(ctx, client, host, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := make([]digestSize, 0, len(manifest.Layers)+1)
+	digests = append(digests, digestSize{digest: manifest.Config.Digest, size: manifest.Config.Size})
+	for _, layer := range manifest.Layers {
+		digests = append(digests, digestSize{digest: layer.Digest, size: layer.Size})
+	}
+
+	for _, d := range digests {
+		if err := c.ensureBlob(ctx, client, host, repository, d); err != nil {
+			return nil, fmt.Errorf("failed to fetch blob %s: %w", d.digest, err)
+		}
+	}
+
+	layoutDir, err := c.writeLayout(manifest, digests)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store.evictExcept(pinnedDigests(digests))
+
+	return &PulledImage{
+		Dir:     layoutDir,
+		Cleanup: func() { os.RemoveAll(layoutDir) },
+	}, nil
+}
+
+// pinnedDigests returns the set of digests a just-completed Pull depends
+// on, so evictExcept doesn't reclaim a blob out from under the layout
+// this Pull just handed back.
+func pinnedDigests(digests []digestSize) map[string]struct{} {
+	pinned := make(map[string]struct{}, len(digests))
+	for _, d := range digests {
+		pinned[d.digest] = struct{}{}
+	}
+	return pinned
+}
+
+// ensureBlob fetches digest into the shared store if it isn't already
+// present, coalescing concurrent requests for the same digest across
+// every in-flight Pull call.
+func (c *Cache) ensureBlob(ctx context.Context, client *http.Client, host, repository string, d digestSize) error {
+	if c.store.has(d.digest) {
+		return nil
+	}
+
+	_, err, _ := c.pullGroup.Do(d.digest, func() (interface{}, error) {
+		if c.store.touch(d.digest) {
+			return nil, nil
+		}
+
+		endpoint := fmt.Sprintf("https://%s/v2/%s/blobs/%s", host, repository, d.digest)
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reach registry %s: %w", host, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("registry %s returned status %d for blob %s", host, resp.StatusCode, d.digest)
+		}
+
+		return nil, c.store.put(d.digest, resp.Body)
+	})
+	return err
+}
+
+// registryClient returns an http.Client that transparently authenticates
+// against host, scoped to pull access on repository, reusing a cached
+// bearer token across calls for the same (host, repository) pair.
+func (c *Cache) registryClient(host, repository string, creds registryauth.Credentials) *http.Client {
+	key := host + "|" + repository
+
+	c.clientsMu.Lock()
+	defer c.clientsMu.Unlock()
+
+	if client, ok := c.clients[key]; ok {
+		return client
+	}
+
+	store := &scannerauth.StaticCredentialStore{
+		Username:      creds.Username,
+		Password:      creds.Password,
+		IdentityToken: creds.IdentityToken,
+	}
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+
+	transport := scannerauth.NewTransport(
+		http.DefaultTransport,
+		scannerauth.NewChallengeManager(),
+		scannerauth.NewTokenHandler(http.DefaultTransport, store, scope),
+		scannerauth.NewBasicHandler(store),
+	)
+
+	client := &http.Client{Transport: transport, Timeout: 60 * time.Second}
+	c.clients[key] = client
+	return client
+}
+
+// credentials resolves registryName's pull credentials the same way
+// cli_scanner.CLIScanner.buildScanArgs does: a static username/password
+// configured on the registry wins, otherwise falling back to Docker/OCI
+// credential resolution. Duplicated here (rather than imported) since
+// pkg/scanner can't be a dependency of this package without creating an
+// import cycle once CLIScanner wires imagecache in.
+func (c *Cache) credentials(registryName, host string, registries []config.RegistryConfig) registryauth.Credentials {
+	for _, reg := range registries {
+		if reg.Name != registryName {
+			continue
+		}
+
+		if reg.Scanner.Credentials.Username != "" {
+			return registryauth.Credentials{
+				Username: reg.Scanner.Credentials.Username,
+				Password: reg.Scanner.Credentials.Password,
+			}
+		}
+
+		creds, err := c.resolver.Resolve(host, reg.Scanner.DockerConfigPath)
+		if err != nil {
+			c.logger.WithError(err).WithField("registry", reg.Name).Warn("Failed to resolve Docker credentials for image cache pull")
+			return registryauth.Credentials{}
+		}
+		return creds
+	}
+
+	return registryauth.Credentials{}
+}
+
+// digestSize is a blob digest paired with its expected size, taken
+// straight from a manifest's config/layer descriptors.
+type digestSize struct {
+	digest string
+	size   int64
+}
+
+// parseImageRef splits an image reference like
+// "registry.example.com/team/app:v1.0.0" or
+// "registry.example.com/team/app@sha256:..." into its registry host,
+// repository path, and tag/digest reference. Duplicates
+// registry_scanner.parseImageRef for the same import-cycle reason
+// credentials does.
+func parseImageRef(imageRef string) (host, repository, reference string, err error) {
+	namePart := imageRef
+	reference = "latest"
+
+	if at := strings.LastIndex(imageRef, "@"); at != -1 {
+		namePart = imageRef[:at]
+		reference = imageRef[at+1:]
+	} else if colon := strings.LastIndex(imageRef, ":"); colon != -1 && !strings.Contains(imageRef[colon:], "/") {
+		namePart = imageRef[:colon]
+		reference = imageRef[colon+1:]
+	}
+
+	slash := strings.Index(namePart, "/")
+	if slash == -1 {
+		return "", "", "", fmt.Errorf("image ref %q has no registry host", imageRef)
+	}
+
+	host = namePart[:slash]
+	repository = namePart[slash+1:]
+	if host == "" || repository == "" {
+		return "", "", "", fmt.Errorf("invalid image ref: %q", imageRef)
+	}
+
+	return host, repository, reference, nil
+}
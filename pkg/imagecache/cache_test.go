@@ -0,0 +1,90 @@
+package imagecache
+
+import "testing"
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		name           string
+		ref            string
+		wantHost       string
+		wantRepository string
+		wantReference  string
+		wantErr        bool
+	}{
+		{
+			name:           "tag",
+			ref:            "registry.example.com/team/app:v1.0.0",
+			wantHost:       "registry.example.com",
+			wantRepository: "team/app",
+			wantReference:  "v1.0.0",
+		},
+		{
+			name:           "digest",
+			ref:            "registry.example.com/team/app@sha256:abcd",
+			wantHost:       "registry.example.com",
+			wantRepository: "team/app",
+			wantReference:  "sha256:abcd",
+		},
+		{
+			name:           "host with port and no tag",
+			ref:            "registry.example.com:5000/team/app",
+			wantHost:       "registry.example.com:5000",
+			wantRepository: "team/app",
+			wantReference:  "latest",
+		},
+		{
+			name:    "no registry host",
+			ref:     "app:v1.0.0",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, repository, reference, err := parseImageRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImageRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if host != tt.wantHost || repository != tt.wantRepository || reference != tt.wantReference {
+				t.Errorf("parseImageRef() = (%q, %q, %q), want (%q, %q, %q)",
+					host, repository, reference, tt.wantHost, tt.wantRepository, tt.wantReference)
+			}
+		})
+	}
+}
+
+func TestSelectPlatform(t *testing.T) {
+	body := []byte(`{
+		"manifests": [
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:arm64digest", "platform": {"architecture": "arm64", "os": "linux"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:amd64digest", "platform": {"architecture": "amd64", "os": "linux"}}
+		]
+	}`)
+
+	digest, err := selectPlatform(body)
+	if err != nil {
+		t.Fatalf("selectPlatform() error = %v", err)
+	}
+	if digest != "sha256:amd64digest" {
+		t.Errorf("selectPlatform() = %q, want %q", digest, "sha256:amd64digest")
+	}
+}
+
+func TestSelectPlatform_fallsBackToFirstEntry(t *testing.T) {
+	body := []byte(`{
+		"manifests": [
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:onlydigest", "platform": {"architecture": "s390x", "os": "linux"}}
+		]
+	}`)
+
+	digest, err := selectPlatform(body)
+	if err != nil {
+		t.Fatalf("selectPlatform() error = %v", err)
+	}
+	if digest != "sha256:onlydigest" {
+		t.Errorf("selectPlatform() = %q, want %q", digest, "sha256:onlydigest")
+	}
+}
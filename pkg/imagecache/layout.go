@@ -0,0 +1,91 @@
+package imagecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ociLayoutMarker is the fixed contents of an OCI image layout's
+// "oci-layout" file.
+const ociLayoutMarker = `{"imageLayoutVersion":"1.0.0"}`
+
+// ociIndex is the minimal "index.json" an OCI image layout needs to point
+// a reader at the single manifest it contains.
+type ociIndex struct {
+	SchemaVersion int                  `json:"schemaVersion"`
+	MediaType     string               `json:"mediaType"`
+	Manifests     []manifestDescriptor `json:"manifests"`
+}
+
+// writeLayout assembles an ephemeral OCI image layout directory
+// containing m's manifest and every blob in digests, hardlinked from the
+// shared blob store so building a layout never copies data already on
+// disk.
+func (c *Cache) writeLayout(m *manifest, digests []digestSize) (string, error) {
+	layoutDir, err := os.MkdirTemp(c.layoutsDir, "layout-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create OCI layout dir: %w", err)
+	}
+
+	blobsDir := filepath.Join(layoutDir, "blobs", "sha256")
+	if err := os.MkdirAll(blobsDir, 0o755); err != nil {
+		os.RemoveAll(layoutDir)
+		return "", fmt.Errorf("failed to create OCI layout blobs dir: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(layoutDir, "oci-layout"), []byte(ociLayoutMarker), 0o644); err != nil {
+		os.RemoveAll(layoutDir)
+		return "", fmt.Errorf("failed to write oci-layout: %w", err)
+	}
+
+	for _, d := range digests {
+		if err := c.store.link(d.digest, blobsDir); err != nil {
+			os.RemoveAll(layoutDir)
+			return "", fmt.Errorf("failed to link blob %s into OCI layout: %w", d.digest, err)
+		}
+	}
+
+	manifestDigest := m.digest
+	if manifestDigest == "" {
+		manifestDigest = digestOf(m.raw)
+	}
+	if err := c.store.putBytes(manifestDigest, m.raw); err != nil {
+		os.RemoveAll(layoutDir)
+		return "", fmt.Errorf("failed to store manifest blob: %w", err)
+	}
+	if err := c.store.link(manifestDigest, blobsDir); err != nil {
+		os.RemoveAll(layoutDir)
+		return "", fmt.Errorf("failed to link manifest into OCI layout: %w", err)
+	}
+
+	index := ociIndex{
+		SchemaVersion: 2,
+		MediaType:     "application/vnd.oci.image.index.v1+json",
+		Manifests: []manifestDescriptor{
+			{MediaType: m.MediaType, Digest: manifestDigest, Size: int64(len(m.raw))},
+		},
+	}
+	indexBytes, err := json.Marshal(index)
+	if err != nil {
+		os.RemoveAll(layoutDir)
+		return "", fmt.Errorf("failed to marshal OCI layout index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(layoutDir, "index.json"), indexBytes, 0o644); err != nil {
+		os.RemoveAll(layoutDir)
+		return "", fmt.Errorf("failed to write OCI layout index: %w", err)
+	}
+
+	return layoutDir, nil
+}
+
+// digestOf computes the sha256 digest of b in "sha256:<hex>" form, used
+// as a fallback when a manifest fetch's response carried no
+// Docker-Content-Digest header.
+func digestOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
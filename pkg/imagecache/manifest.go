@@ -0,0 +1,127 @@
+package imagecache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// manifest is the subset of a Docker v2 / OCI image manifest this
+// package needs: enough to enumerate every blob a scan of the image
+// requires.
+type manifest struct {
+	MediaType string             `json:"mediaType"`
+	Config    manifestDescriptor `json:"config"`
+	Layers    []manifestDescriptor `json:"layers"`
+
+	// raw holds the exact bytes fetched from the registry, so they can
+	// be stored into the OCI layout byte-for-byte rather than
+	// re-marshaled.
+	raw []byte
+	// digest is the manifest's own content digest, from the registry's
+	// Docker-Content-Digest response header.
+	digest string
+}
+
+type manifestDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// manifestList is the subset of a manifest list / OCI image index this
+// package needs to pick out a single platform's manifest.
+type manifestList struct {
+	Manifests []manifestListEntry `json:"manifests"`
+}
+
+type manifestListEntry struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Platform  struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform"`
+}
+
+// fetchManifest GETs reference's manifest, resolving a multi-arch
+// manifest list/index down to preferredPlatform's single-platform
+// manifest before returning.
+func (c *Cache) fetchManifest(ctx context.Context, client *http.Client, host, repository, reference string) (*manifest, error) {
+	mt, digest, body, err := c.getManifest(ctx, client, host, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mt {
+	case "application/vnd.docker.distribution.manifest.list.v2+json", "application/vnd.oci.image.index.v1+json":
+		childDigest, err := selectPlatform(body)
+		if err != nil {
+			return nil, err
+		}
+		return c.fetchManifest(ctx, client, host, repository, childDigest)
+	default:
+		var m manifest
+		if err := json.Unmarshal(body, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		m.raw = body
+		m.digest = digest
+		return &m, nil
+	}
+}
+
+// getManifest performs the actual GET, returning the response's media
+// type, content digest, and raw body.
+func (c *Cache) getManifest(ctx context.Context, client *http.Client, host, repository, reference string) (mediaType, digest string, body []byte, err error) {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, reference)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", "", nil, err
+	}
+	httpReq.Header.Set("Accept", manifestAcceptHeader)
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to reach registry %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", nil, fmt.Errorf("registry %s returned status %d for manifest %s/%s", host, resp.StatusCode, repository, reference)
+	}
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to read manifest body: %w", err)
+	}
+
+	digest = resp.Header.Get("Docker-Content-Digest")
+	mediaType = resp.Header.Get("Content-Type")
+
+	return mediaType, digest, body, nil
+}
+
+// selectPlatform picks preferredPlatform's manifest digest out of a
+// manifest list/index body, falling back to the first entry if no exact
+// match is found (e.g. a single-arch image published under a list media
+// type anyway).
+func selectPlatform(body []byte) (string, error) {
+	var list manifestList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return "", fmt.Errorf("failed to parse manifest list: %w", err)
+	}
+	if len(list.Manifests) == 0 {
+		return "", fmt.Errorf("manifest list has no entries")
+	}
+
+	for _, m := range list.Manifests {
+		if fmt.Sprintf("%s/%s", m.Platform.OS, m.Platform.Architecture) == preferredPlatform {
+			return m.Digest, nil
+		}
+	}
+
+	return list.Manifests[0].Digest, nil
+}
@@ -0,0 +1,234 @@
+package imagecache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+)
+
+// blobStore is the shared, on-disk, content-addressable store
+// underlying Cache: one file per digest under dir, with an in-memory LRU
+// tracking access order so the store can evict down to a size budget.
+type blobStore struct {
+	dir          string
+	maxSizeBytes int64
+	logger       *logrus.Logger
+
+	mu         sync.Mutex
+	order      *list.List
+	elements   map[string]*list.Element
+	totalBytes int64
+}
+
+// blobEntry is the value stored in blobStore's LRU list.
+type blobEntry struct {
+	digest string
+	size   int64
+}
+
+// newBlobStore creates a blobStore rooted at dir, rehydrating its LRU
+// bookkeeping from whatever blobs are already on disk (e.g. left over
+// from a prior process) ordered by file modification time, oldest first.
+func newBlobStore(dir string, maxSizeBytes int64, logger *logrus.Logger) (*blobStore, error) {
+	s := &blobStore{
+		dir:          dir,
+		maxSizeBytes: maxSizeBytes,
+		logger:       logger,
+		order:        list.New(),
+		elements:     make(map[string]*list.Element),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list image cache store: %w", err)
+	}
+
+	var found []existingBlob
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), ".tmp") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, existingBlob{digest: "sha256:" + e.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+	sortByModTime(found)
+
+	for _, e := range found {
+		s.elements[e.digest] = s.order.PushFront(&blobEntry{digest: e.digest, size: e.size})
+		s.totalBytes += e.size
+	}
+	metrics.SetImageCacheBytes(s.totalBytes)
+
+	return s, nil
+}
+
+// existingBlob is a blob found already on disk when a blobStore is
+// created, used only to seed its LRU order.
+type existingBlob struct {
+	digest  string
+	size    int64
+	modTime time.Time
+}
+
+// sortByModTime orders entries oldest-first, in place. container/list
+// gives us the LRU mechanics; this just seeds it in the right order on
+// startup.
+func sortByModTime(entries []existingBlob) {
+	for i := 1; i < len(entries); i++ {
+		for j := i; j > 0 && entries[j].modTime.Before(entries[j-1].modTime); j-- {
+			entries[j], entries[j-1] = entries[j-1], entries[j]
+		}
+	}
+}
+
+// has reports whether digest is already present, marking it as the most
+// recently used entry if so, and recording a cache hit/miss metric.
+func (s *blobStore) has(digest string) bool {
+	present := s.touch(digest)
+	if present {
+		metrics.RecordImageCacheHit()
+	} else {
+		metrics.RecordImageCacheMiss()
+	}
+	return present
+}
+
+// touch reports whether digest is already present, marking it as the
+// most recently used entry if so, without affecting cache hit/miss
+// metrics - used for the re-check inside ensureBlob's singleflight
+// callback, where a "miss" just means another goroutine already won the
+// race to fetch it.
+func (s *blobStore) touch(digest string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.elements[digest]
+	if !ok {
+		return false
+	}
+
+	s.order.MoveToFront(el)
+	return true
+}
+
+// put streams body into the store under digest, verifying it hashes to
+// digest before it's made visible to other callers.
+func (s *blobStore) put(digest string, body io.Reader) error {
+	tmp, err := os.CreateTemp(s.dir, "pull-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp blob file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	hasher := sha256.New()
+	size, err := io.Copy(tmp, io.TeeReader(body, hasher))
+	tmp.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	gotDigest := "sha256:" + hex.EncodeToString(hasher.Sum(nil))
+	if gotDigest != digest {
+		return fmt.Errorf("blob digest mismatch: expected %s, got %s", digest, gotDigest)
+	}
+
+	finalPath := s.path(digest)
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	s.mu.Lock()
+	s.elements[digest] = s.order.PushFront(&blobEntry{digest: digest, size: size})
+	s.totalBytes += size
+	metrics.SetImageCacheBytes(s.totalBytes)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// path returns the on-disk path for digest, e.g.
+// "<dir>/sha256:deadbeef..." -> "<dir>/deadbeef...".
+func (s *blobStore) path(digest string) string {
+	return filepath.Join(s.dir, strings.TrimPrefix(digest, "sha256:"))
+}
+
+// putBytes stores b under digest like put, but from an in-memory buffer -
+// used for the manifest itself, which Cache already holds in full rather
+// than streaming from a response body.
+func (s *blobStore) putBytes(digest string, b []byte) error {
+	if s.touch(digest) {
+		return nil
+	}
+	return s.put(digest, bytes.NewReader(b))
+}
+
+// link hardlinks digest's blob from the store into destDir, named after
+// its hex digest the way an OCI image layout's blobs/sha256/ directory
+// expects. Marks digest as recently used.
+func (s *blobStore) link(digest, destDir string) error {
+	s.mu.Lock()
+	if el, ok := s.elements[digest]; ok {
+		s.order.MoveToFront(el)
+	}
+	s.mu.Unlock()
+
+	dest := filepath.Join(destDir, strings.TrimPrefix(digest, "sha256:"))
+	if err := os.Link(s.path(digest), dest); err != nil {
+		return err
+	}
+	return nil
+}
+
+// evictExcept removes the least-recently-used blobs until the store is
+// back under maxSizeBytes, never evicting a digest in pinned (the set a
+// just-completed Pull still needs).
+func (s *blobStore) evictExcept(pinned map[string]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.totalBytes > s.maxSizeBytes {
+		el := s.oldestEvictable(pinned)
+		if el == nil {
+			break
+		}
+
+		entry := el.Value.(*blobEntry)
+		if err := os.Remove(s.path(entry.digest)); err != nil && !os.IsNotExist(err) {
+			s.logger.WithError(err).WithField("digest", entry.digest).Warn("Failed to evict image cache blob")
+			break
+		}
+
+		s.order.Remove(el)
+		delete(s.elements, entry.digest)
+		s.totalBytes -= entry.size
+		metrics.RecordImageCacheEviction()
+	}
+	metrics.SetImageCacheBytes(s.totalBytes)
+}
+
+// oldestEvictable walks the LRU list back-to-front (oldest first) for
+// the first entry not in pinned.
+func (s *blobStore) oldestEvictable(pinned map[string]struct{}) *list.Element {
+	for el := s.order.Back(); el != nil; el = el.Prev() {
+		entry := el.Value.(*blobEntry)
+		if _, skip := pinned[entry.digest]; !skip {
+			return el
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package imagecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func digestFor(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestBlobStore_PutAndHas(t *testing.T) {
+	dir := t.TempDir()
+	store, err := newBlobStore(dir, 1024*1024, logrus.New())
+	if err != nil {
+		t.Fatalf("newBlobStore() error = %v", err)
+	}
+
+	content := []byte("layer-one-contents")
+	digest := digestFor(content)
+
+	if store.has(digest) {
+		t.Fatal("has() = true before put")
+	}
+
+	if err := store.put(digest, bytes.NewReader(content)); err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+
+	if !store.has(digest) {
+		t.Fatal("has() = false after put")
+	}
+
+	if _, err := os.Stat(store.path(digest)); err != nil {
+		t.Errorf("blob not found on disk: %v", err)
+	}
+}
+
+func TestBlobStore_PutRejectsDigestMismatch(t *testing.T) {
+	dir := t.TempDir()
+	store, err := newBlobStore(dir, 1024*1024, logrus.New())
+	if err != nil {
+		t.Fatalf("newBlobStore() error = %v", err)
+	}
+
+	err = store.put("sha256:doesnotmatch", bytes.NewReader([]byte("content")))
+	if err == nil {
+		t.Fatal("put() expected digest mismatch error, got nil")
+	}
+}
+
+func TestBlobStore_EvictExceptRespectsLRUAndPins(t *testing.T) {
+	dir := t.TempDir()
+	// Budget only large enough for two of the three blobs below.
+	store, err := newBlobStore(dir, 40, logrus.New())
+	if err != nil {
+		t.Fatalf("newBlobStore() error = %v", err)
+	}
+
+	blobs := [][]byte{
+		[]byte("0123456789012345"), // oldest
+		[]byte("1123456789012345"),
+		[]byte("2123456789012345"), // newest, and pinned
+	}
+	digests := make([]string, len(blobs))
+	for i, b := range blobs {
+		digests[i] = digestFor(b)
+		if err := store.put(digests[i], bytes.NewReader(b)); err != nil {
+			t.Fatalf("put() error = %v", err)
+		}
+	}
+
+	store.evictExcept(map[string]struct{}{digests[2]: {}})
+
+	if store.touch(digests[0]) {
+		t.Error("oldest blob should have been evicted")
+	}
+	if !store.touch(digests[2]) {
+		t.Error("pinned blob should not have been evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, digests[0][len("sha256:"):])); !os.IsNotExist(err) {
+		t.Error("evicted blob file should have been removed from disk")
+	}
+}
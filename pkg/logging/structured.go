@@ -0,0 +1,180 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Format selects the wire format a Logger backend renders log lines in.
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatText   Format = "text"
+	FormatLogfmt Format = "logfmt"
+)
+
+// Logger is a small structured-logging facade that lets callers (scanner
+// backends, the webhook server) depend on an interface instead of a
+// concrete *logrus.Logger, so the backend can be swapped for a
+// log/slog-based one (or, later, an OpenTelemetry log exporter) without
+// touching call sites. Methods take context.Context so a future backend
+// can pull trace/span IDs out of it; neither backend below uses it yet
+// beyond passing it through to slog.
+type Logger interface {
+	Debug(ctx context.Context, msg string, kv ...any)
+	Info(ctx context.Context, msg string, kv ...any)
+	Warn(ctx context.Context, msg string, kv ...any)
+	Error(ctx context.Context, msg string, kv ...any)
+
+	// With returns a Logger that includes kv (alternating key, value) on
+	// every subsequent log call, in addition to any already attached by
+	// a prior With.
+	With(kv ...any) Logger
+
+	// WithRequestID is shorthand for With("request_id", id), used at the
+	// point a request ID becomes known (e.g. requestIDMiddleware) so
+	// every log line for that request carries it without repeating the
+	// key at every call site.
+	WithRequestID(id string) Logger
+}
+
+// NewStructuredLogger builds the Logger backend selected by format: the
+// slog-based backend for "json" and "text" (slog's TextHandler already
+// renders key=value pairs, so "logfmt" is served by the same handler),
+// or the existing logrus-based backend for an empty or unrecognised
+// format.
+func NewStructuredLogger(level LogLevel, format Format) Logger {
+	switch format {
+	case FormatJSON, FormatText, FormatLogfmt:
+		return newSlogLogger(level, format)
+	default:
+		return WrapLogrus(NewLogger(level))
+	}
+}
+
+// slogLogger implements Logger on top of the standard library's
+// log/slog, for deployments whose log pipeline keys off slog's handler
+// interface (or a JSON/text shape compatible with it) rather than
+// logrus's.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func newSlogLogger(level LogLevel, format Format) *slogLogger {
+	opts := &slog.HandlerOptions{Level: slogLevel(level)}
+
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		// slog has no distinct "logfmt" handler; TextHandler's
+		// key=value output already is logfmt.
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	return &slogLogger{l: slog.New(handler)}
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func (s *slogLogger) Debug(ctx context.Context, msg string, kv ...any) {
+	s.l.DebugContext(ctx, msg, kv...)
+}
+
+func (s *slogLogger) Info(ctx context.Context, msg string, kv ...any) {
+	s.l.InfoContext(ctx, msg, kv...)
+}
+
+func (s *slogLogger) Warn(ctx context.Context, msg string, kv ...any) {
+	s.l.WarnContext(ctx, msg, kv...)
+}
+
+func (s *slogLogger) Error(ctx context.Context, msg string, kv ...any) {
+	s.l.ErrorContext(ctx, msg, kv...)
+}
+
+func (s *slogLogger) With(kv ...any) Logger {
+	return &slogLogger{l: s.l.With(kv...)}
+}
+
+func (s *slogLogger) WithRequestID(id string) Logger {
+	return s.With("request_id", id)
+}
+
+// logrusLogger implements Logger on top of the existing logrus setup, so
+// deployments that haven't opted into logging.format: json|text|logfmt
+// keep today's output unchanged.
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+// WrapLogrus adapts an existing *logrus.Logger to the Logger interface,
+// letting code that's been migrated to depend on logging.Logger keep
+// running on top of a caller-configured logrus.Logger (e.g. one with
+// hooks or an output destination already set up) instead of requiring
+// the slog backend.
+func WrapLogrus(base *logrus.Logger) Logger {
+	return &logrusLogger{entry: logrus.NewEntry(base)}
+}
+
+func (l *logrusLogger) Debug(_ context.Context, msg string, kv ...any) {
+	l.withKV(kv).Debug(msg)
+}
+
+func (l *logrusLogger) Info(_ context.Context, msg string, kv ...any) {
+	l.withKV(kv).Info(msg)
+}
+
+func (l *logrusLogger) Warn(_ context.Context, msg string, kv ...any) {
+	l.withKV(kv).Warn(msg)
+}
+
+func (l *logrusLogger) Error(_ context.Context, msg string, kv ...any) {
+	l.withKV(kv).Error(msg)
+}
+
+func (l *logrusLogger) With(kv ...any) Logger {
+	return &logrusLogger{entry: l.withKV(kv)}
+}
+
+func (l *logrusLogger) WithRequestID(id string) Logger {
+	return l.With("request_id", id)
+}
+
+// withKV flattens an alternating key/value slice into a logrus.Fields
+// entry. A trailing key with no value is logged under the "extra" key so
+// a mismatched call doesn't silently drop data.
+func (l *logrusLogger) withKV(kv []any) *logrus.Entry {
+	if len(kv) == 0 {
+		return l.entry
+	}
+
+	fields := make(logrus.Fields, len(kv)/2+1)
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			continue
+		}
+		fields[key] = kv[i+1]
+	}
+	if len(kv)%2 == 1 {
+		fields["extra"] = kv[len(kv)-1]
+	}
+
+	return l.entry.WithFields(fields)
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// CircuitBreakerTransitions tracks state transitions of the circuit
+// breaker wrapping Sysdig Registry Scanner API calls, by endpoint and
+// the states involved.
+var CircuitBreakerTransitions = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "scanner_circuit_breaker_transitions_total",
+		Help: "Total number of circuit breaker state transitions, by endpoint, from_state, and to_state",
+	},
+	[]string{"endpoint", "from_state", "to_state"},
+)
+
+// RecordCircuitBreakerTransition records one circuitbreaker.Breaker
+// transition for endpoint.
+func RecordCircuitBreakerTransition(endpoint, fromState, toState string) {
+	CircuitBreakerTransitions.WithLabelValues(endpoint, fromState, toState).Inc()
+}
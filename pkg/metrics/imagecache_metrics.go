@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ImageCacheHits tracks imagecache.Cache layer/config blob lookups
+	// that were already present in the on-disk content-addressable
+	// store.
+	ImageCacheHits = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "image_cache_hits_total",
+			Help: "Total number of image layer blobs served from the local image cache",
+		},
+	)
+
+	// ImageCacheMisses tracks imagecache.Cache layer/config blob lookups
+	// that had to be pulled from the registry.
+	ImageCacheMisses = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "image_cache_misses_total",
+			Help: "Total number of image layer blobs pulled from the registry into the local image cache",
+		},
+	)
+
+	// ImageCacheEvictions tracks blobs removed from the image cache's
+	// content-addressable store to stay within its configured disk size
+	// budget.
+	ImageCacheEvictions = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "image_cache_evictions_total",
+			Help: "Total number of blobs evicted from the local image cache to stay under its size budget",
+		},
+	)
+
+	// ImageCacheBytes tracks the current total size in bytes of the
+	// image cache's content-addressable store.
+	ImageCacheBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "image_cache_bytes",
+			Help: "Current total size in bytes of blobs held in the local image cache",
+		},
+	)
+)
+
+// RecordImageCacheHit records a blob served from the local image cache.
+func RecordImageCacheHit() {
+	ImageCacheHits.Inc()
+}
+
+// RecordImageCacheMiss records a blob pulled from the registry into the
+// local image cache.
+func RecordImageCacheMiss() {
+	ImageCacheMisses.Inc()
+}
+
+// RecordImageCacheEviction records a blob evicted from the local image
+// cache.
+func RecordImageCacheEviction() {
+	ImageCacheEvictions.Inc()
+}
+
+// SetImageCacheBytes records the image cache's current total size.
+func SetImageCacheBytes(bytes int64) {
+	ImageCacheBytes.Set(float64(bytes))
+}
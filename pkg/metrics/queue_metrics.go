@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RetriesTotal tracks scans RetryManager.ScheduleRetry requeued with a
+// backoff delay, by registry.
+var RetriesTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "queue_retries_total",
+		Help: "Total number of scan requests requeued for a backoff retry, by registry",
+	},
+	[]string{"registry"},
+)
+
+// RecordRetry records one RetryManager.ScheduleRetry call.
+func RecordRetry(registry string) {
+	RetriesTotal.WithLabelValues(registry).Inc()
+}
+
+// DeadLetterTotal tracks scans a BackendWorkerPool gave up retrying and
+// handed to a DeadLetterStore, by registry.
+var DeadLetterTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "queue_deadletter_total",
+		Help: "Total number of scan requests recorded to the dead letter store, by registry",
+	},
+	[]string{"registry"},
+)
+
+// RecordDeadLetter records one DeadLetterStore.Record call.
+func RecordDeadLetter(registry string) {
+	DeadLetterTotal.WithLabelValues(registry).Inc()
+}
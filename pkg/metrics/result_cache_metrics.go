@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ScanCacheHits tracks ResultProcessor cache lookups that found a
+	// live (unexpired) entry.
+	ScanCacheHits = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "scan_cache_hits_total",
+			Help: "Total number of ResultProcessor cache lookups that hit",
+		},
+	)
+
+	// ScanCacheMisses tracks ResultProcessor cache lookups that found no
+	// entry, or an expired one.
+	ScanCacheMisses = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "scan_cache_misses_total",
+			Help: "Total number of ResultProcessor cache lookups that missed",
+		},
+	)
+
+	// ScanCacheEvictions tracks entries removed from the ResultProcessor
+	// cache, broken down by why: "ttl" for the background janitor sweep,
+	// "lru" for an insert that pushed the cache past its configured size.
+	ScanCacheEvictions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scan_cache_evictions_total",
+			Help: "Total number of entries evicted from the ResultProcessor cache by reason",
+		},
+		[]string{"reason"},
+	)
+)
+
+// RecordScanCacheHit records a ResultProcessor cache lookup that hit.
+func RecordScanCacheHit() {
+	ScanCacheHits.Inc()
+}
+
+// RecordScanCacheMiss records a ResultProcessor cache lookup that missed.
+func RecordScanCacheMiss() {
+	ScanCacheMisses.Inc()
+}
+
+// RecordScanCacheEviction records a ResultProcessor cache entry evicted
+// for the given reason ("ttl" or "lru").
+func RecordScanCacheEviction(reason string) {
+	ScanCacheEvictions.WithLabelValues(reason).Inc()
+}
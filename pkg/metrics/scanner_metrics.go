@@ -63,6 +63,17 @@ var (
 		},
 		[]string{"scanner_type", "registry", "status"},
 	)
+
+	// ScanProgressStage tracks streamed scan progress events by stage, so
+	// a dashboard can see where in-flight scans are spending their time
+	// (e.g. stuck pulling a large layer vs. stuck analyzing packages).
+	ScanProgressStage = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scanner_scan_progress_stage",
+			Help: "Total number of streamed scan progress events observed by stage",
+		},
+		[]string{"stage"},
+	)
 )
 
 // RecordScannerAPIDuration records the duration of a Registry Scanner API call
@@ -94,3 +105,8 @@ func RecordScanDuration(scannerType, status string, duration float64) {
 func RecordScan(scannerType, registry, status string) {
 	ScanTotal.WithLabelValues(scannerType, registry, status).Inc()
 }
+
+// RecordScanProgressStage records one streamed scan progress event for stage
+func RecordScanProgressStage(stage string) {
+	ScanProgressStage.WithLabelValues(stage).Inc()
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ActiveConnections tracks the current number of active HTTP
+	// connections, as observed by shutdown.IdleTracker.
+	ActiveConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "active_conns",
+		Help: "Current number of active HTTP connections tracked for graceful shutdown",
+	})
+
+	// IdleSince tracks the Unix timestamp (seconds) of when the server
+	// last transitioned to having zero active connections and in-flight
+	// requests.
+	IdleSince = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "idle_since",
+		Help: "Unix timestamp in seconds of when the server last became idle",
+	})
+
+	// ActiveScans tracks the current number of scans registered with
+	// shutdown.IdleTracker via RegisterScan, i.e. scan work dispatched
+	// from a webhook handler that may still be running after the HTTP
+	// response that triggered it has completed.
+	ActiveScans = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "active_scans",
+		Help: "Current number of in-flight scans tracked for graceful shutdown",
+	})
+)
+
+// SetActiveConnections records the current active connection count.
+func SetActiveConnections(count int) {
+	ActiveConnections.Set(float64(count))
+}
+
+// SetIdleSince records when the server last became idle.
+func SetIdleSince(t time.Time) {
+	IdleSince.Set(float64(t.Unix()))
+}
+
+// SetActiveScans records the current in-flight scan count.
+func SetActiveScans(count int) {
+	ActiveScans.Set(float64(count))
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// SinkPublishTotal tracks sink.Dispatcher publish attempts by sink
+	// and final outcome ("success", "failed", "dead_letter").
+	SinkPublishTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sink_publish_total",
+			Help: "Total number of ResultSink publish attempts by sink and outcome",
+		},
+		[]string{"sink", "outcome"},
+	)
+
+	// SinkPublishDuration tracks how long a sink.Dispatcher.Publish call
+	// spent on one sink, including retries.
+	SinkPublishDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sink_publish_duration_seconds",
+			Help:    "Duration of ResultSink publish calls in seconds, including retries",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"sink"},
+	)
+)
+
+// RecordSinkPublish records a sink.Dispatcher publish attempt's final
+// outcome and duration for sinkName.
+func RecordSinkPublish(sinkName, outcome string, durationSeconds float64) {
+	SinkPublishTotal.WithLabelValues(sinkName, outcome).Inc()
+	SinkPublishDuration.WithLabelValues(sinkName).Observe(durationSeconds)
+}
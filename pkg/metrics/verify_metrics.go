@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// VerificationTotal tracks verify.Verifier outcomes by registry and
+// outcome ("verified", "rejected", "error").
+var VerificationTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "image_verification_total",
+		Help: "Total number of image signature verification checks by registry and outcome",
+	},
+	[]string{"registry", "outcome"},
+)
+
+// RecordVerification records one verify.Verifier.Verify outcome.
+func RecordVerification(registry, outcome string) {
+	VerificationTotal.WithLabelValues(registry, outcome).Inc()
+}
@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// WebhookPanics tracks panics recovered from webhook HTTP handlers by
+// panicRecoveryMiddleware, broken down by request path.
+var WebhookPanics = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "webhook_panics_total",
+		Help: "Total number of panics recovered from webhook HTTP handlers",
+	},
+	[]string{"path"},
+)
+
+// RecordWebhookPanic records a recovered panic for path.
+func RecordWebhookPanic(path string) {
+	WebhookPanics.WithLabelValues(path).Inc()
+}
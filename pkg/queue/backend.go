@@ -0,0 +1,76 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// Backend is a durable store for queued scan requests, used in place of
+// ScanQueue's in-memory channel so queued work (including pending
+// retries) survives a pod restart and isn't duplicated across
+// horizontally-scaled webhook replicas. MemoryBackend, BoltBackend,
+// RedisBackend, ObjectStoreBackend and NatsBackend implement it.
+type Backend interface {
+	// Enqueue persists req as pending work. availableAt is when the
+	// request becomes eligible for Lease; the zero Time means
+	// immediately. Retry scheduling uses a future availableAt so the
+	// backoff survives a restart instead of living in an in-process
+	// timer.
+	Enqueue(ctx context.Context, req *models.ScanRequest, availableAt time.Time) error
+
+	// Lease claims one pending, available request for up to leaseTTL,
+	// moving it out of the pending set so no other worker also claims
+	// it. ok is false if nothing is currently available.
+	Lease(ctx context.Context, leaseTTL time.Duration) (item *LeasedItem, ok bool, err error)
+
+	// Ack permanently removes a leased request, marking it done.
+	Ack(ctx context.Context, leaseID string) error
+
+	// Nack returns a leased request to pending, available at
+	// availableAt, persisting req's current state (e.g. an incremented
+	// RetryCount).
+	Nack(ctx context.Context, leaseID string, req *models.ScanRequest, availableAt time.Time) error
+
+	// ReapExpiredLeases returns every lease whose TTL has elapsed to
+	// pending, available immediately, so a worker that died mid-scan
+	// doesn't strand its request forever. Returns the number reaped.
+	ReapExpiredLeases(ctx context.Context) (int, error)
+
+	// Depth returns the number of pending (not leased) requests,
+	// best-effort.
+	Depth() int
+
+	// Stats returns a best-effort breakdown of how many requests this
+	// Backend currently has pending, leased, and (for backends that
+	// dead-letter internally, independent of DeadLetterStore) given up
+	// on. Backends without visibility into a given count report it as 0.
+	Stats() BackendStats
+}
+
+// BackendStats is a Backend's best-effort view of its own queue depth,
+// broken down by state, for operational visibility beyond the single
+// Depth() number.
+type BackendStats struct {
+	Pending      int
+	InFlight     int
+	DeadLettered int
+}
+
+// LeasedItem is a request claimed by Lease, along with the lease ID a
+// worker passes back to Ack or Nack it.
+type LeasedItem struct {
+	LeaseID string
+	Request *models.ScanRequest
+}
+
+// generateLeaseID generates a unique lease ID, same convention as
+// webhook/parsers.generateRequestID.
+func generateLeaseID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
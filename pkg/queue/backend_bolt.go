@@ -0,0 +1,233 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+var (
+	boltPendingBucket = []byte("pending")
+	boltLeasedBucket  = []byte("leased")
+)
+
+// newBoltQueueDB opens (creating if needed) the BoltDB file at path,
+// with the buckets BoltBackend needs already present.
+func newBoltQueueDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(boltPendingBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(boltLeasedBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// boltPendingEntry is the value stored in boltPendingBucket for a
+// queued scan request.
+type boltPendingEntry struct {
+	Request     *models.ScanRequest `json:"request"`
+	AvailableAt time.Time           `json:"available_at"`
+}
+
+// boltLeasedEntry is the value stored in boltLeasedBucket for a request
+// a worker currently holds a lease on.
+type boltLeasedEntry struct {
+	Request  *models.ScanRequest `json:"request"`
+	Deadline time.Time           `json:"deadline"`
+}
+
+// BoltBackend is a Backend backed by a single-node BoltDB file, so
+// queued scan requests (including pending retries) survive a process
+// restart without needing an external datastore. Unlike RedisBackend
+// and ObjectStoreBackend it isn't shared across replicas - it's meant
+// for a single-replica deployment that still wants crash-safety.
+//
+// Both buckets are keyed by the same ID: Lease moves an entry from
+// boltPendingBucket to boltLeasedBucket under its existing key rather
+// than generating a new one, and Nack/ReapExpiredLeases move it back
+// the same way, so an item never needs re-keying as it moves between
+// the two states.
+type BoltBackend struct {
+	db     *bolt.DB
+	logger *logrus.Logger
+}
+
+// NewBoltBackend creates a BoltBackend using db, which must already
+// have boltPendingBucket and boltLeasedBucket (see newBoltQueueDB).
+func NewBoltBackend(db *bolt.DB, logger *logrus.Logger) (*BoltBackend, error) {
+	return &BoltBackend{db: db, logger: logger}, nil
+}
+
+// Enqueue implements Backend.
+func (b *BoltBackend) Enqueue(ctx context.Context, req *models.ScanRequest, availableAt time.Time) error {
+	data, err := json.Marshal(boltPendingEntry{Request: req, AvailableAt: availableAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan request: %w", err)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltPendingBucket).Put([]byte(generateLeaseID()), data)
+	})
+}
+
+// Lease implements Backend.
+func (b *BoltBackend) Lease(ctx context.Context, leaseTTL time.Duration) (*LeasedItem, bool, error) {
+	var item *LeasedItem
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(boltPendingBucket)
+		leased := tx.Bucket(boltLeasedBucket)
+		now := time.Now()
+
+		c := pending.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var entry boltPendingEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("bolt backend: failed to decode pending entry: %w", err)
+			}
+			if entry.AvailableAt.After(now) {
+				continue
+			}
+
+			leaseID := append([]byte{}, k...)
+			if err := pending.Delete(k); err != nil {
+				return fmt.Errorf("bolt backend: failed to delete pending entry: %w", err)
+			}
+
+			leasedData, err := json.Marshal(boltLeasedEntry{Request: entry.Request, Deadline: now.Add(leaseTTL)})
+			if err != nil {
+				return fmt.Errorf("failed to marshal leased entry: %w", err)
+			}
+			if err := leased.Put(leaseID, leasedData); err != nil {
+				return fmt.Errorf("bolt backend: failed to write leased entry: %w", err)
+			}
+
+			item = &LeasedItem{LeaseID: string(leaseID), Request: entry.Request}
+			return nil
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if item == nil {
+		return nil, false, nil
+	}
+
+	return item, true, nil
+}
+
+// Ack implements Backend.
+func (b *BoltBackend) Ack(ctx context.Context, leaseID string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltLeasedBucket).Delete([]byte(leaseID))
+	})
+}
+
+// Nack implements Backend.
+func (b *BoltBackend) Nack(ctx context.Context, leaseID string, req *models.ScanRequest, availableAt time.Time) error {
+	data, err := json.Marshal(boltPendingEntry{Request: req, AvailableAt: availableAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan request: %w", err)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltLeasedBucket).Delete([]byte(leaseID)); err != nil {
+			return fmt.Errorf("bolt backend: failed to delete leased entry: %w", err)
+		}
+		return tx.Bucket(boltPendingBucket).Put([]byte(leaseID), data)
+	})
+}
+
+// ReapExpiredLeases implements Backend.
+func (b *BoltBackend) ReapExpiredLeases(ctx context.Context) (int, error) {
+	reaped := 0
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		leased := tx.Bucket(boltLeasedBucket)
+		pending := tx.Bucket(boltPendingBucket)
+		now := time.Now()
+
+		var expiredKeys [][]byte
+		var expiredEntries []boltPendingEntry
+
+		c := leased.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var entry boltLeasedEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("bolt backend: failed to decode leased entry: %w", err)
+			}
+			if entry.Deadline.After(now) {
+				continue
+			}
+
+			expiredKeys = append(expiredKeys, append([]byte{}, k...))
+			expiredEntries = append(expiredEntries, boltPendingEntry{Request: entry.Request, AvailableAt: now})
+		}
+
+		for i, key := range expiredKeys {
+			data, err := json.Marshal(expiredEntries[i])
+			if err != nil {
+				return fmt.Errorf("failed to marshal pending entry: %w", err)
+			}
+			if err := leased.Delete(key); err != nil {
+				return fmt.Errorf("bolt backend: failed to delete leased entry: %w", err)
+			}
+			if err := pending.Put(key, data); err != nil {
+				return fmt.Errorf("bolt backend: failed to write pending entry: %w", err)
+			}
+			reaped++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return reaped, err
+	}
+
+	if reaped > 0 && b.logger != nil {
+		b.logger.WithField("count", reaped).Warn("Reaped expired queue leases")
+	}
+
+	return reaped, nil
+}
+
+// Depth implements Backend.
+func (b *BoltBackend) Depth() int {
+	depth := 0
+	_ = b.db.View(func(tx *bolt.Tx) error {
+		depth = tx.Bucket(boltPendingBucket).Stats().KeyN
+		return nil
+	})
+	return depth
+}
+
+// Stats implements Backend.
+func (b *BoltBackend) Stats() BackendStats {
+	var stats BackendStats
+	_ = b.db.View(func(tx *bolt.Tx) error {
+		stats.Pending = tx.Bucket(boltPendingBucket).Stats().KeyN
+		stats.InFlight = tx.Bucket(boltLeasedBucket).Stats().KeyN
+		return nil
+	})
+	return stats
+}
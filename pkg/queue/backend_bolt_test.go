@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+func newTestBoltBackend(t *testing.T) *BoltBackend {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "queue.db")
+	db, err := newBoltQueueDB(path)
+	if err != nil {
+		t.Fatalf("newBoltQueueDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	logger := logrus.New()
+	backend, err := NewBoltBackend(db, logger)
+	if err != nil {
+		t.Fatalf("NewBoltBackend() error = %v", err)
+	}
+	return backend
+}
+
+func TestBoltBackend_EnqueueLeaseAck(t *testing.T) {
+	backend := newTestBoltBackend(t)
+	ctx := context.Background()
+
+	req := &models.ScanRequest{ImageRef: "test:latest", RequestID: "bolt-test-001"}
+	if err := backend.Enqueue(ctx, req, time.Time{}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	if depth := backend.Depth(); depth != 1 {
+		t.Fatalf("Depth() = %d, want 1", depth)
+	}
+
+	item, ok, err := backend.Lease(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("Lease() error = %v", err)
+	}
+	if !ok {
+		t.Fatal("Lease() ok = false, want true")
+	}
+	if item.Request.RequestID != req.RequestID {
+		t.Errorf("Lease() request = %+v, want %+v", item.Request, req)
+	}
+
+	stats := backend.Stats()
+	if stats.Pending != 0 || stats.InFlight != 1 {
+		t.Errorf("Stats() = %+v, want Pending=0 InFlight=1", stats)
+	}
+
+	if err := backend.Ack(ctx, item.LeaseID); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+
+	stats = backend.Stats()
+	if stats.Pending != 0 || stats.InFlight != 0 {
+		t.Errorf("Stats() after Ack = %+v, want all zero", stats)
+	}
+}
+
+func TestBoltBackend_NackRequeues(t *testing.T) {
+	backend := newTestBoltBackend(t)
+	ctx := context.Background()
+
+	req := &models.ScanRequest{ImageRef: "test:latest", RequestID: "bolt-test-002"}
+	if err := backend.Enqueue(ctx, req, time.Time{}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	item, ok, err := backend.Lease(ctx, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Lease() = %v, %v, %v", item, ok, err)
+	}
+
+	item.Request.RetryCount++
+	if err := backend.Nack(ctx, item.LeaseID, item.Request, time.Time{}); err != nil {
+		t.Fatalf("Nack() error = %v", err)
+	}
+
+	requeued, ok, err := backend.Lease(ctx, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Lease() after Nack = %v, %v, %v", requeued, ok, err)
+	}
+	if requeued.Request.RetryCount != 1 {
+		t.Errorf("requeued RetryCount = %d, want 1", requeued.Request.RetryCount)
+	}
+}
+
+func TestBoltBackend_ReapExpiredLeases(t *testing.T) {
+	backend := newTestBoltBackend(t)
+	ctx := context.Background()
+
+	req := &models.ScanRequest{ImageRef: "test:latest", RequestID: "bolt-test-003"}
+	if err := backend.Enqueue(ctx, req, time.Time{}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	if _, ok, err := backend.Lease(ctx, -time.Second); err != nil || !ok {
+		t.Fatalf("Lease() = ok=%v err=%v", ok, err)
+	}
+
+	reaped, err := backend.ReapExpiredLeases(ctx)
+	if err != nil {
+		t.Fatalf("ReapExpiredLeases() error = %v", err)
+	}
+	if reaped != 1 {
+		t.Fatalf("ReapExpiredLeases() = %d, want 1", reaped)
+	}
+
+	if depth := backend.Depth(); depth != 1 {
+		t.Errorf("Depth() after reap = %d, want 1", depth)
+	}
+}
+
+func TestBoltBackend_LeaseRespectsAvailableAt(t *testing.T) {
+	backend := newTestBoltBackend(t)
+	ctx := context.Background()
+
+	req := &models.ScanRequest{ImageRef: "test:latest", RequestID: "bolt-test-004"}
+	if err := backend.Enqueue(ctx, req, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	_, ok, err := backend.Lease(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("Lease() error = %v", err)
+	}
+	if ok {
+		t.Fatal("Lease() ok = true, want false for a request not yet available")
+	}
+}
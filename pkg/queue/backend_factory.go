@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// NewBackend creates the Backend selected by cfg.Queue.Backend
+// ("memory", "bolt", "redis", "gcs", "s3", or "nats").
+func NewBackend(ctx context.Context, cfg *config.Config, logger *logrus.Logger) (Backend, error) {
+	switch cfg.Queue.Backend {
+	case "", "memory":
+		return NewMemoryBackend(cfg.Queue.BufferSize, logger), nil
+
+	case "bolt":
+		if cfg.Queue.BoltPath == "" {
+			return nil, fmt.Errorf("queue.bolt_path is required for backend %q", cfg.Queue.Backend)
+		}
+		db, err := newBoltQueueDB(cfg.Queue.BoltPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open bolt queue db: %w", err)
+		}
+		return NewBoltBackend(db, logger)
+
+	case "redis":
+		client, err := newRedisQueueClient(cfg.Queue.RedisAddr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		return NewRedisBackend(client, "scanner:queue:", logger), nil
+
+	case "gcs":
+		if cfg.Queue.ObjectStoreBucket == "" {
+			return nil, fmt.Errorf("queue.object_store_bucket is required for backend %q", cfg.Queue.Backend)
+		}
+		store, err := newGCSObjectStore(ctx, cfg.Queue.ObjectStoreBucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS client: %w", err)
+		}
+		return NewObjectStoreBackend(store, logger), nil
+
+	case "s3":
+		if cfg.Queue.ObjectStoreBucket == "" {
+			return nil, fmt.Errorf("queue.object_store_bucket is required for backend %q", cfg.Queue.Backend)
+		}
+		store, err := newS3ObjectStore(ctx, cfg.Queue.ObjectStoreBucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 client: %w", err)
+		}
+		return NewObjectStoreBackend(store, logger), nil
+
+	case "nats":
+		if cfg.Queue.NatsURL == "" {
+			return nil, fmt.Errorf("queue.nats_url is required for backend %q", cfg.Queue.Backend)
+		}
+		ackWait, err := cfg.ParseDuration(cfg.Queue.LeaseTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid queue.lease_ttl: %w", err)
+		}
+		const subjectPrefix = "scanner.queue."
+		client, err := newNatsJetStreamClient(cfg.Queue.NatsURL, "SCANNER_QUEUE", []string{subjectPrefix + ">"}, ackWait)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to nats: %w", err)
+		}
+		return NewNatsBackend(client, subjectPrefix, cfg.Queue.NatsMaxDeliveries, logger), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported queue.backend: %s", cfg.Queue.Backend)
+	}
+}
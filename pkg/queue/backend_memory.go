@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// memoryPendingItem is a request waiting in MemoryBackend, along with
+// when it becomes eligible for Lease.
+type memoryPendingItem struct {
+	request     *models.ScanRequest
+	availableAt time.Time
+}
+
+// memoryLeasedItem is a request MemoryBackend has handed out via Lease,
+// tracked until it's Ack'd, Nack'd, or its lease expires.
+type memoryLeasedItem struct {
+	request  *models.ScanRequest
+	deadline time.Time
+}
+
+// MemoryBackend is a Backend backed by an in-memory slice, scoped to
+// this process. It reproduces ScanQueue's original single-replica
+// behavior behind the Backend interface, so callers that need durable
+// or shared queueing can swap in RedisBackend or ObjectStoreBackend
+// without changing how they enqueue and lease work.
+type MemoryBackend struct {
+	mu       sync.Mutex
+	capacity int
+	pending  []memoryPendingItem
+	leased   map[string]memoryLeasedItem
+	logger   *logrus.Logger
+}
+
+// NewMemoryBackend creates a MemoryBackend with the given pending-queue
+// capacity.
+func NewMemoryBackend(capacity int, logger *logrus.Logger) *MemoryBackend {
+	return &MemoryBackend{
+		capacity: capacity,
+		leased:   make(map[string]memoryLeasedItem),
+		logger:   logger,
+	}
+}
+
+// Enqueue implements Backend.
+func (b *MemoryBackend) Enqueue(ctx context.Context, req *models.ScanRequest, availableAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.pending) >= b.capacity {
+		return fmt.Errorf("queue is full (capacity: %d)", b.capacity)
+	}
+
+	b.pending = append(b.pending, memoryPendingItem{request: req, availableAt: availableAt})
+	return nil
+}
+
+// Lease implements Backend, returning the oldest pending request whose
+// availableAt has passed.
+func (b *MemoryBackend) Lease(ctx context.Context, leaseTTL time.Duration) (*LeasedItem, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for i, item := range b.pending {
+		if item.availableAt.After(now) {
+			continue
+		}
+
+		b.pending = append(b.pending[:i], b.pending[i+1:]...)
+
+		leaseID := generateLeaseID()
+		b.leased[leaseID] = memoryLeasedItem{request: item.request, deadline: now.Add(leaseTTL)}
+
+		return &LeasedItem{LeaseID: leaseID, Request: item.request}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// Ack implements Backend.
+func (b *MemoryBackend) Ack(ctx context.Context, leaseID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.leased, leaseID)
+	return nil
+}
+
+// Nack implements Backend.
+func (b *MemoryBackend) Nack(ctx context.Context, leaseID string, req *models.ScanRequest, availableAt time.Time) error {
+	b.mu.Lock()
+	delete(b.leased, leaseID)
+	b.mu.Unlock()
+
+	return b.Enqueue(ctx, req, availableAt)
+}
+
+// ReapExpiredLeases implements Backend.
+func (b *MemoryBackend) ReapExpiredLeases(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	reaped := 0
+	for leaseID, item := range b.leased {
+		if now.Before(item.deadline) {
+			continue
+		}
+
+		delete(b.leased, leaseID)
+		b.pending = append(b.pending, memoryPendingItem{request: item.request, availableAt: now})
+		reaped++
+	}
+
+	if reaped > 0 && b.logger != nil {
+		b.logger.WithField("count", reaped).Warn("Reaped expired queue leases")
+	}
+
+	return reaped, nil
+}
+
+// Depth implements Backend.
+func (b *MemoryBackend) Depth() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// Stats implements Backend.
+func (b *MemoryBackend) Stats() BackendStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BackendStats{Pending: len(b.pending), InFlight: len(b.leased)}
+}
@@ -0,0 +1,215 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// NatsMsg is the subset of a fetched JetStream message NatsBackend
+// needs to ack, nak, or dead-letter it, so the backend isn't tied to a
+// specific NATS client library's concrete type.
+type NatsMsg interface {
+	// Data returns the message payload.
+	Data() []byte
+	// NumDelivered returns how many times JetStream has (re)delivered
+	// this message, starting at 1.
+	NumDelivered() uint64
+	// Ack acknowledges the message, permanently removing it.
+	Ack() error
+	// NakWithDelay negatively acknowledges the message, asking
+	// JetStream to redeliver it after delay instead of immediately.
+	NakWithDelay(delay time.Duration) error
+	// Term terminates the message, telling JetStream not to redeliver
+	// it again.
+	Term() error
+}
+
+// NatsQueueClient is the subset of a JetStream context NatsBackend
+// needs, so it isn't tied to a specific NATS library's concrete type.
+// natsJetStreamClient (using github.com/nats-io/nats.go) satisfies
+// this.
+type NatsQueueClient interface {
+	// Publish publishes data to subject.
+	Publish(subject string, data []byte) error
+	// Fetch pulls up to one message from the durable pull consumer
+	// bound to subject, waiting up to timeout if none are currently
+	// available. ok is false on timeout.
+	Fetch(subject string, timeout time.Duration) (msg NatsMsg, ok bool, err error)
+	// PendingCount returns the number of messages waiting to be
+	// delivered on subject, best-effort.
+	PendingCount(subject string) int
+}
+
+// natsQueueEnvelope is the JSON value published to JetStream for a
+// pending or retrying scan request. ReadyAt lets Lease defer a retry's
+// redelivery: JetStream has no native delayed-publish, so a
+// not-yet-ready message is simply Nak'd with a delay until it is, the
+// same mechanism used to return a message to the queue at all.
+type natsQueueEnvelope struct {
+	Request *models.ScanRequest `json:"request"`
+	ReadyAt time.Time           `json:"ready_at"`
+}
+
+// NatsBackend is a Backend backed by NATS JetStream, so queued scan
+// requests (including pending retries) are shared across
+// horizontally-scaled webhook replicas and survive a pod restart.
+//
+// Unlike RedisBackend and ObjectStoreBackend, NatsBackend leans on
+// JetStream's own redelivery: Lease pulls from a durable consumer, and
+// a message that's never Ack'd or Nak'd is automatically redelivered
+// once the consumer's AckWait elapses, so ReapExpiredLeases is a no-op
+// here - there's no separate lease-tracking state to reap. The leaseTTL
+// passed to Lease is used only as the Fetch wait, not the redelivery
+// deadline, which is fixed at consumer creation (see
+// newNatsJetStreamClient). A message JetStream has redelivered more
+// than maxDeliveries times (e.g. because a worker crashed before Ack or
+// Nack on every attempt) is moved to a dead-letter subject instead of
+// being leased again - a backstop independent of RetryManager's own
+// retry-count bookkeeping, which governs the normal Ack/Nack path.
+type NatsBackend struct {
+	client        NatsQueueClient
+	subjectPrefix string
+	maxDeliveries int
+	logger        *logrus.Logger
+
+	mu   sync.Mutex
+	msgs map[string]NatsMsg
+}
+
+// NewNatsBackend creates a NatsBackend using client, namespacing
+// subjects under subjectPrefix (e.g. "scanner.queue."). A message
+// JetStream has redelivered more than maxDeliveries times is moved to
+// subjectPrefix+"dead-letter" instead of being leased again; 0 disables
+// this check.
+func NewNatsBackend(client NatsQueueClient, subjectPrefix string, maxDeliveries int, logger *logrus.Logger) *NatsBackend {
+	return &NatsBackend{
+		client:        client,
+		subjectPrefix: subjectPrefix,
+		maxDeliveries: maxDeliveries,
+		logger:        logger,
+		msgs:          make(map[string]NatsMsg),
+	}
+}
+
+func (b *NatsBackend) pendingSubject() string    { return b.subjectPrefix + "pending" }
+func (b *NatsBackend) deadLetterSubject() string { return b.subjectPrefix + "dead-letter" }
+
+// Enqueue implements Backend.
+func (b *NatsBackend) Enqueue(ctx context.Context, req *models.ScanRequest, availableAt time.Time) error {
+	data, err := json.Marshal(natsQueueEnvelope{Request: req, ReadyAt: availableAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan request: %w", err)
+	}
+
+	if err := b.client.Publish(b.pendingSubject(), data); err != nil {
+		return fmt.Errorf("nats backend: publish failed: %w", err)
+	}
+	return nil
+}
+
+// Lease implements Backend. See NatsBackend's doc comment for how
+// leaseTTL is used here and why redelivery timing is otherwise governed
+// by the consumer's AckWait.
+func (b *NatsBackend) Lease(ctx context.Context, leaseTTL time.Duration) (*LeasedItem, bool, error) {
+	for {
+		msg, ok, err := b.client.Fetch(b.pendingSubject(), leaseTTL)
+		if err != nil {
+			return nil, false, fmt.Errorf("nats backend: fetch failed: %w", err)
+		}
+		if !ok {
+			return nil, false, nil
+		}
+
+		var envelope natsQueueEnvelope
+		if err := json.Unmarshal(msg.Data(), &envelope); err != nil {
+			_ = msg.Term()
+			return nil, false, fmt.Errorf("nats backend: failed to decode leased request: %w", err)
+		}
+
+		if !envelope.ReadyAt.IsZero() && envelope.ReadyAt.After(time.Now()) {
+			if err := msg.NakWithDelay(time.Until(envelope.ReadyAt)); err != nil {
+				return nil, false, fmt.Errorf("nats backend: nak (defer) failed: %w", err)
+			}
+			continue
+		}
+
+		if b.maxDeliveries > 0 && msg.NumDelivered() > uint64(b.maxDeliveries) {
+			if err := b.client.Publish(b.deadLetterSubject(), msg.Data()); err != nil {
+				b.logger.WithError(err).Error("Failed to publish scan request to dead-letter subject")
+			}
+			_ = msg.Term()
+			b.logger.WithFields(logrus.Fields{
+				"request_id": envelope.Request.RequestID,
+				"deliveries": msg.NumDelivered(),
+			}).Warn("Scan request exceeded max JetStream deliveries, moved to dead-letter subject")
+			continue
+		}
+
+		leaseID := generateLeaseID()
+		b.mu.Lock()
+		b.msgs[leaseID] = msg
+		b.mu.Unlock()
+
+		return &LeasedItem{LeaseID: leaseID, Request: envelope.Request}, true, nil
+	}
+}
+
+// Ack implements Backend.
+func (b *NatsBackend) Ack(ctx context.Context, leaseID string) error {
+	b.mu.Lock()
+	msg, ok := b.msgs[leaseID]
+	delete(b.msgs, leaseID)
+	b.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return msg.Ack()
+}
+
+// Nack implements Backend. It acks the original JetStream delivery and
+// re-publishes req with the new availableAt, the same Ack-then-Enqueue
+// approach RedisBackend uses, so the updated RetryCount persists even
+// if this process restarts before the next Lease.
+func (b *NatsBackend) Nack(ctx context.Context, leaseID string, req *models.ScanRequest, availableAt time.Time) error {
+	if err := b.Ack(ctx, leaseID); err != nil {
+		return err
+	}
+	return b.Enqueue(ctx, req, availableAt)
+}
+
+// ReapExpiredLeases implements Backend. It's a no-op: JetStream
+// redelivers unacknowledged messages itself once the consumer's
+// AckWait elapses, so there's no separate lease state to reap here.
+func (b *NatsBackend) ReapExpiredLeases(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// Depth implements Backend.
+func (b *NatsBackend) Depth() int {
+	return b.client.PendingCount(b.pendingSubject())
+}
+
+// Stats implements Backend. InFlight only reflects messages this
+// process currently holds a lease on (see msgs), not the cluster-wide
+// total, since JetStream tracks per-consumer delivery state rather than
+// exposing it through NatsQueueClient. DeadLettered is the number of
+// messages NatsBackend has itself moved to its dead-letter subject,
+// independent of whatever DeadLetterStore the BackendWorkerPool uses.
+func (b *NatsBackend) Stats() BackendStats {
+	b.mu.Lock()
+	inFlight := len(b.msgs)
+	b.mu.Unlock()
+
+	return BackendStats{
+		Pending:      b.Depth(),
+		InFlight:     inFlight,
+		DeadLettered: b.client.PendingCount(b.deadLetterSubject()),
+	}
+}
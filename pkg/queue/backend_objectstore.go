@@ -0,0 +1,237 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// ErrObjectNotFound is returned by ObjectStore.Get when key doesn't
+// exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectStore is the subset of a GCS/S3 bucket client
+// ObjectStoreBackend needs, so it isn't tied to a specific cloud SDK.
+// gcsObjectStore and s3ObjectStore adapt the two providers to it.
+type ObjectStore interface {
+	// Put writes data to key, storing metadata alongside it (object
+	// metadata headers on S3, custom metadata on GCS).
+	Put(ctx context.Context, key string, data []byte, metadata map[string]string) error
+	// Get reads the object at key along with its metadata. Returns an
+	// error satisfying errors.Is(err, ErrObjectNotFound) if key doesn't
+	// exist.
+	Get(ctx context.Context, key string) ([]byte, map[string]string, error)
+	// Delete removes the object at key. Deleting a key that doesn't
+	// exist is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns the keys currently stored under prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+const (
+	objectStorePendingPrefix    = "pending/"
+	objectStoreProcessingPrefix = "processing/"
+
+	objectStoreMetaAvailableAt    = "available_at"
+	objectStoreMetaLeaseDeadline  = "lease_deadline"
+)
+
+// ObjectStoreBackend is a Backend backed by a GCS or S3 bucket (via
+// ObjectStore), so queued scan requests survive a pod restart and are
+// shared across horizontally-scaled webhook replicas without needing a
+// separate datastore. Each request is one object under pending/<id>.json;
+// Lease moves it to processing/<id>.json, storing the lease deadline as
+// object metadata, and ReapExpiredLeases moves expired ones back.
+//
+// Object stores have no atomic "pop" primitive, so two workers racing
+// to Lease the same pending object can both succeed (each overwrites
+// processing/<id>.json and deletes pending/<id>.json); like RedisBackend,
+// this backend gives at-least-once, not exactly-once, delivery.
+type ObjectStoreBackend struct {
+	store  ObjectStore
+	logger *logrus.Logger
+}
+
+// NewObjectStoreBackend creates an ObjectStoreBackend using store.
+func NewObjectStoreBackend(store ObjectStore, logger *logrus.Logger) *ObjectStoreBackend {
+	return &ObjectStoreBackend{store: store, logger: logger}
+}
+
+// Enqueue implements Backend.
+func (b *ObjectStoreBackend) Enqueue(ctx context.Context, req *models.ScanRequest, availableAt time.Time) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan request: %w", err)
+	}
+
+	key := objectStorePendingPrefix + generateLeaseID() + ".json"
+	metadata := map[string]string{}
+	if !availableAt.IsZero() {
+		metadata[objectStoreMetaAvailableAt] = strconv.FormatInt(availableAt.Unix(), 10)
+	}
+
+	if err := b.store.Put(ctx, key, data, metadata); err != nil {
+		return fmt.Errorf("object store backend: failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// Lease implements Backend.
+func (b *ObjectStoreBackend) Lease(ctx context.Context, leaseTTL time.Duration) (*LeasedItem, bool, error) {
+	keys, err := b.store.List(ctx, objectStorePendingPrefix)
+	if err != nil {
+		return nil, false, fmt.Errorf("object store backend: failed to list pending: %w", err)
+	}
+
+	now := time.Now()
+
+	for _, key := range keys {
+		data, metadata, err := b.store.Get(ctx, key)
+		if err != nil {
+			if errors.Is(err, ErrObjectNotFound) {
+				// Raced with another worker or a reap; try the next key.
+				continue
+			}
+			return nil, false, fmt.Errorf("object store backend: failed to read %s: %w", key, err)
+		}
+
+		if availableAtStr, ok := metadata[objectStoreMetaAvailableAt]; ok {
+			availableAt, err := strconv.ParseInt(availableAtStr, 10, 64)
+			if err == nil && time.Unix(availableAt, 0).After(now) {
+				continue
+			}
+		}
+
+		var req models.ScanRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return nil, false, fmt.Errorf("object store backend: failed to decode %s: %w", key, err)
+		}
+
+		id := strings.TrimSuffix(strings.TrimPrefix(key, objectStorePendingPrefix), ".json")
+		processingKey := objectStoreProcessingPrefix + id + ".json"
+		processingMeta := map[string]string{
+			objectStoreMetaLeaseDeadline: strconv.FormatInt(now.Add(leaseTTL).Unix(), 10),
+		}
+
+		if err := b.store.Put(ctx, processingKey, data, processingMeta); err != nil {
+			return nil, false, fmt.Errorf("object store backend: failed to write %s: %w", processingKey, err)
+		}
+		if err := b.store.Delete(ctx, key); err != nil {
+			return nil, false, fmt.Errorf("object store backend: failed to delete %s: %w", key, err)
+		}
+
+		return &LeasedItem{LeaseID: id, Request: &req}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// Ack implements Backend.
+func (b *ObjectStoreBackend) Ack(ctx context.Context, leaseID string) error {
+	key := objectStoreProcessingPrefix + leaseID + ".json"
+	if err := b.store.Delete(ctx, key); err != nil {
+		return fmt.Errorf("object store backend: failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Nack implements Backend.
+func (b *ObjectStoreBackend) Nack(ctx context.Context, leaseID string, req *models.ScanRequest, availableAt time.Time) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan request: %w", err)
+	}
+
+	pendingKey := objectStorePendingPrefix + leaseID + ".json"
+	metadata := map[string]string{}
+	if !availableAt.IsZero() {
+		metadata[objectStoreMetaAvailableAt] = strconv.FormatInt(availableAt.Unix(), 10)
+	}
+
+	if err := b.store.Put(ctx, pendingKey, data, metadata); err != nil {
+		return fmt.Errorf("object store backend: failed to write %s: %w", pendingKey, err)
+	}
+
+	processingKey := objectStoreProcessingPrefix + leaseID + ".json"
+	if err := b.store.Delete(ctx, processingKey); err != nil {
+		return fmt.Errorf("object store backend: failed to delete %s: %w", processingKey, err)
+	}
+	return nil
+}
+
+// ReapExpiredLeases implements Backend.
+func (b *ObjectStoreBackend) ReapExpiredLeases(ctx context.Context) (int, error) {
+	keys, err := b.store.List(ctx, objectStoreProcessingPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("object store backend: failed to list processing: %w", err)
+	}
+
+	now := time.Now()
+	reaped := 0
+
+	for _, key := range keys {
+		data, metadata, err := b.store.Get(ctx, key)
+		if err != nil {
+			if errors.Is(err, ErrObjectNotFound) {
+				continue
+			}
+			return reaped, fmt.Errorf("object store backend: failed to read %s: %w", key, err)
+		}
+
+		deadlineStr, ok := metadata[objectStoreMetaLeaseDeadline]
+		if !ok {
+			continue
+		}
+		deadline, err := strconv.ParseInt(deadlineStr, 10, 64)
+		if err != nil || time.Unix(deadline, 0).After(now) {
+			continue
+		}
+
+		id := strings.TrimSuffix(strings.TrimPrefix(key, objectStoreProcessingPrefix), ".json")
+		pendingKey := objectStorePendingPrefix + id + ".json"
+
+		if err := b.store.Put(ctx, pendingKey, data, map[string]string{}); err != nil {
+			return reaped, fmt.Errorf("object store backend: failed to write %s: %w", pendingKey, err)
+		}
+		if err := b.store.Delete(ctx, key); err != nil {
+			return reaped, fmt.Errorf("object store backend: failed to delete %s: %w", key, err)
+		}
+
+		reaped++
+	}
+
+	if reaped > 0 && b.logger != nil {
+		b.logger.WithField("count", reaped).Warn("Reaped expired queue leases")
+	}
+
+	return reaped, nil
+}
+
+// Depth implements Backend. It's best-effort: a failed List is reported
+// as 0 rather than propagating an error, matching ScanQueue's Depth(),
+// which also can't fail.
+func (b *ObjectStoreBackend) Depth() int {
+	keys, err := b.store.List(context.Background(), objectStorePendingPrefix)
+	if err != nil {
+		return 0
+	}
+	return len(keys)
+}
+
+// Stats implements Backend. Like Depth, it's best-effort.
+func (b *ObjectStoreBackend) Stats() BackendStats {
+	processing, err := b.store.List(context.Background(), objectStoreProcessingPrefix)
+	inFlight := 0
+	if err == nil {
+		inFlight = len(processing)
+	}
+
+	return BackendStats{Pending: b.Depth(), InFlight: inFlight}
+}
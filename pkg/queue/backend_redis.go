@@ -0,0 +1,251 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// RedisQueueClient is the subset of a Redis client's commands
+// RedisBackend needs, so it isn't tied to a specific Redis library's
+// concrete type. github.com/redis/go-redis/v9's *redis.Client satisfies
+// this via redisQueueClient below.
+type RedisQueueClient interface {
+	// LPush pushes value onto the head of the list at key.
+	LPush(key, value string) error
+	// BRPopLPush atomically pops the tail of src and pushes it onto the
+	// head of dst, blocking up to timeout if src is empty. ok is false
+	// on timeout.
+	BRPopLPush(src, dst string, timeout time.Duration) (value string, ok bool, err error)
+	// LRem removes the first count occurrences of value from the list
+	// at key.
+	LRem(key string, count int, value string) error
+	// LLen returns the length of the list at key.
+	LLen(key string) (int64, error)
+	// ZAdd adds member to the sorted set at key with the given score.
+	ZAdd(key string, score float64, member string) error
+	// ZRangeByScoreLTE returns members of the sorted set at key with
+	// score <= max.
+	ZRangeByScoreLTE(key string, max float64) ([]string, error)
+	// ZRem removes member from the sorted set at key.
+	ZRem(key string, member string) error
+	// ZCard returns the number of members in the sorted set at key.
+	ZCard(key string) (int64, error)
+	// HSet sets field on the hash at key to value.
+	HSet(key, field, value string) error
+	// HGet returns field's value from the hash at key, and whether it
+	// existed.
+	HGet(key, field string) (value string, ok bool, err error)
+	// HGetAll returns all fields and values of the hash at key.
+	HGetAll(key string) (map[string]string, error)
+	// HDel removes field from the hash at key.
+	HDel(key, field string) error
+	// HLen returns the number of fields in the hash at key.
+	HLen(key string) (int64, error)
+}
+
+// redisQueueEnvelope is the JSON value stored in Redis for a pending or
+// in-flight scan request.
+type redisQueueEnvelope struct {
+	Request *models.ScanRequest `json:"request"`
+}
+
+// RedisBackend is a Backend backed by Redis, so queued scan requests
+// (including pending retries) are shared across horizontally-scaled
+// webhook replicas and survive a pod restart instead of living only in
+// one process's memory.
+//
+// Ready work sits in a "pending" list; Lease uses BRPOPLPUSH to move an
+// item into a "processing" list atomically, so a worker that dies
+// mid-scan doesn't lose the item outright (ReapExpiredLeases recovers
+// it once its lease expires). Requests enqueued with a future
+// availableAt (retry backoff) sit in a "delayed" sorted set keyed by
+// their ready time and are promoted to pending as they become due.
+type RedisBackend struct {
+	client RedisQueueClient
+	prefix string
+	logger *logrus.Logger
+}
+
+// NewRedisBackend creates a RedisBackend using client, namespacing all
+// keys under keyPrefix (e.g. "scanner:queue:").
+func NewRedisBackend(client RedisQueueClient, keyPrefix string, logger *logrus.Logger) *RedisBackend {
+	return &RedisBackend{client: client, prefix: keyPrefix, logger: logger}
+}
+
+func (b *RedisBackend) pendingKey() string   { return b.prefix + "pending" }
+func (b *RedisBackend) processingKey() string { return b.prefix + "processing" }
+func (b *RedisBackend) delayedKey() string   { return b.prefix + "delayed" }
+func (b *RedisBackend) leaseDeadlinesKey() string { return b.prefix + "lease_deadlines" }
+func (b *RedisBackend) leasedItemsKey() string    { return b.prefix + "leased_items" }
+
+// Enqueue implements Backend.
+func (b *RedisBackend) Enqueue(ctx context.Context, req *models.ScanRequest, availableAt time.Time) error {
+	data, err := json.Marshal(redisQueueEnvelope{Request: req})
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan request: %w", err)
+	}
+
+	if availableAt.IsZero() || !availableAt.After(time.Now()) {
+		if err := b.client.LPush(b.pendingKey(), string(data)); err != nil {
+			return fmt.Errorf("redis backend: LPUSH failed: %w", err)
+		}
+		return nil
+	}
+
+	if err := b.client.ZAdd(b.delayedKey(), float64(availableAt.Unix()), string(data)); err != nil {
+		return fmt.Errorf("redis backend: ZADD failed: %w", err)
+	}
+	return nil
+}
+
+// promoteDueDelayed moves delayed items whose availableAt has passed
+// into the pending list.
+func (b *RedisBackend) promoteDueDelayed() error {
+	due, err := b.client.ZRangeByScoreLTE(b.delayedKey(), float64(time.Now().Unix()))
+	if err != nil {
+		return fmt.Errorf("redis backend: ZRANGEBYSCORE failed: %w", err)
+	}
+
+	for _, member := range due {
+		if err := b.client.LPush(b.pendingKey(), member); err != nil {
+			return fmt.Errorf("redis backend: LPUSH (promote) failed: %w", err)
+		}
+		if err := b.client.ZRem(b.delayedKey(), member); err != nil {
+			return fmt.Errorf("redis backend: ZREM (promote) failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Lease implements Backend.
+func (b *RedisBackend) Lease(ctx context.Context, leaseTTL time.Duration) (*LeasedItem, bool, error) {
+	if err := b.promoteDueDelayed(); err != nil {
+		return nil, false, err
+	}
+
+	// A zero timeout would block forever; wait at most a second so an
+	// empty queue reports ok=false and due delayed items keep getting
+	// promoted on the next call.
+	data, ok, err := b.client.BRPopLPush(b.pendingKey(), b.processingKey(), time.Second)
+	if err != nil {
+		return nil, false, fmt.Errorf("redis backend: BRPOPLPUSH failed: %w", err)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	var envelope redisQueueEnvelope
+	if err := json.Unmarshal([]byte(data), &envelope); err != nil {
+		return nil, false, fmt.Errorf("redis backend: failed to decode leased request: %w", err)
+	}
+
+	leaseID := generateLeaseID()
+	deadline := strconv.FormatInt(time.Now().Add(leaseTTL).Unix(), 10)
+
+	if err := b.client.HSet(b.leaseDeadlinesKey(), leaseID, deadline); err != nil {
+		return nil, false, fmt.Errorf("redis backend: HSET lease deadline failed: %w", err)
+	}
+	if err := b.client.HSet(b.leasedItemsKey(), leaseID, data); err != nil {
+		return nil, false, fmt.Errorf("redis backend: HSET leased item failed: %w", err)
+	}
+	if err := b.client.LRem(b.processingKey(), 1, data); err != nil {
+		return nil, false, fmt.Errorf("redis backend: LREM failed: %w", err)
+	}
+
+	return &LeasedItem{LeaseID: leaseID, Request: envelope.Request}, true, nil
+}
+
+// Ack implements Backend.
+func (b *RedisBackend) Ack(ctx context.Context, leaseID string) error {
+	if err := b.client.HDel(b.leaseDeadlinesKey(), leaseID); err != nil {
+		return fmt.Errorf("redis backend: HDEL lease deadline failed: %w", err)
+	}
+	if err := b.client.HDel(b.leasedItemsKey(), leaseID); err != nil {
+		return fmt.Errorf("redis backend: HDEL leased item failed: %w", err)
+	}
+	return nil
+}
+
+// Nack implements Backend.
+func (b *RedisBackend) Nack(ctx context.Context, leaseID string, req *models.ScanRequest, availableAt time.Time) error {
+	if err := b.Ack(ctx, leaseID); err != nil {
+		return err
+	}
+	return b.Enqueue(ctx, req, availableAt)
+}
+
+// ReapExpiredLeases implements Backend.
+func (b *RedisBackend) ReapExpiredLeases(ctx context.Context) (int, error) {
+	deadlines, err := b.client.HGetAll(b.leaseDeadlinesKey())
+	if err != nil {
+		return 0, fmt.Errorf("redis backend: HGETALL lease deadlines failed: %w", err)
+	}
+
+	now := time.Now().Unix()
+	reaped := 0
+
+	for leaseID, deadlineStr := range deadlines {
+		deadline, err := strconv.ParseInt(deadlineStr, 10, 64)
+		if err != nil || deadline > now {
+			continue
+		}
+
+		data, ok, err := b.client.HGet(b.leasedItemsKey(), leaseID)
+		if err != nil {
+			return reaped, fmt.Errorf("redis backend: HGET leased item failed: %w", err)
+		}
+		if !ok {
+			_ = b.client.HDel(b.leaseDeadlinesKey(), leaseID)
+			continue
+		}
+
+		if err := b.client.LPush(b.pendingKey(), data); err != nil {
+			return reaped, fmt.Errorf("redis backend: LPUSH (reap) failed: %w", err)
+		}
+		_ = b.client.LRem(b.processingKey(), 1, data)
+		_ = b.client.HDel(b.leaseDeadlinesKey(), leaseID)
+		_ = b.client.HDel(b.leasedItemsKey(), leaseID)
+
+		reaped++
+	}
+
+	if reaped > 0 && b.logger != nil {
+		b.logger.WithField("count", reaped).Warn("Reaped expired queue leases")
+	}
+
+	return reaped, nil
+}
+
+// Depth implements Backend. It's best-effort: a failed LLEN/ZCARD is
+// reported as 0 rather than propagating an error, matching ScanQueue's
+// Depth(), which also can't fail.
+func (b *RedisBackend) Depth() int {
+	pending, err := b.client.LLen(b.pendingKey())
+	if err != nil {
+		return 0
+	}
+
+	delayed, err := b.client.ZCard(b.delayedKey())
+	if err != nil {
+		return int(pending)
+	}
+
+	return int(pending + delayed)
+}
+
+// Stats implements Backend.
+func (b *RedisBackend) Stats() BackendStats {
+	inFlight, err := b.client.HLen(b.leaseDeadlinesKey())
+	if err != nil {
+		inFlight = 0
+	}
+
+	return BackendStats{Pending: b.Depth(), InFlight: int(inFlight)}
+}
@@ -0,0 +1,224 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+)
+
+// BackendWorkerPool manages a pool of worker goroutines that lease scan
+// requests from a Backend and process them with a ScanHandler: acking
+// on success, and otherwise asking RetryManager to schedule a backoff
+// retry or, once retries are exhausted, recording the request to a
+// DeadLetterStore before acking it off the queue for good.
+//
+// Unlike WorkerPool, which dequeues from an in-memory ScanQueue scoped
+// to this process, BackendWorkerPool leases from a Backend so multiple
+// webhook replicas can share the same pending work and survive restarts
+// without losing it.
+type BackendWorkerPool struct {
+	backend    Backend
+	retry      *RetryManager
+	deadLetter DeadLetterStore
+	leaseTTL   time.Duration
+	workers    int
+	handler    ScanHandler
+	logger     *logrus.Logger
+
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+}
+
+// NewBackendWorkerPool creates a BackendWorkerPool with workers
+// goroutines, each leasing requests from backend for up to leaseTTL at
+// a time and handing them to handler.
+func NewBackendWorkerPool(backend Backend, retry *RetryManager, deadLetter DeadLetterStore, leaseTTL time.Duration, workers int, handler ScanHandler, logger *logrus.Logger) *BackendWorkerPool {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &BackendWorkerPool{
+		backend:    backend,
+		retry:      retry,
+		deadLetter: deadLetter,
+		leaseTTL:   leaseTTL,
+		workers:    workers,
+		handler:    handler,
+		logger:     logger,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start starts all worker goroutines.
+func (wp *BackendWorkerPool) Start() {
+	wp.logger.WithField("workers", wp.workers).Info("Starting backend worker pool")
+
+	for i := 0; i < wp.workers; i++ {
+		wp.wg.Add(1)
+		go wp.worker(i)
+	}
+}
+
+// Stop gracefully stops the worker pool, waiting for in-flight scans to
+// complete with the given timeout.
+func (wp *BackendWorkerPool) Stop(timeout time.Duration) error {
+	var stopErr error
+
+	wp.stopOnce.Do(func() {
+		wp.logger.Info("Stopping backend worker pool")
+		wp.cancel()
+
+		done := make(chan struct{})
+		go func() {
+			wp.wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			wp.logger.Info("All backend workers stopped gracefully")
+		case <-time.After(timeout):
+			stopErr = fmt.Errorf("backend worker pool shutdown timeout after %v", timeout)
+			wp.logger.Warn("Backend worker pool shutdown timeout, some workers may still be running")
+		}
+	})
+
+	return stopErr
+}
+
+// worker leases and processes requests from backend until the pool is
+// stopped.
+func (wp *BackendWorkerPool) worker(id int) {
+	defer wp.wg.Done()
+
+	workerLogger := wp.logger.WithField("worker_id", id)
+	workerLogger.Debug("Backend worker started")
+
+	for {
+		select {
+		case <-wp.ctx.Done():
+			workerLogger.Debug("Backend worker stopping")
+			return
+		default:
+		}
+
+		item, ok, err := wp.backend.Lease(wp.ctx, wp.leaseTTL)
+		if err != nil {
+			if wp.ctx.Err() != nil {
+				return
+			}
+			workerLogger.WithError(err).Warn("Lease failed")
+			time.Sleep(time.Second)
+			continue
+		}
+		if !ok {
+			// MemoryBackend, BoltBackend and ObjectStoreBackend return
+			// immediately, and RedisBackend within a second, when
+			// nothing is available (unlike NatsBackend's Fetch, which
+			// blocks up to leaseTTL), so back off briefly here to avoid
+			// a tight busy loop against those backends.
+			select {
+			case <-wp.ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		wp.processLeased(workerLogger, item)
+	}
+}
+
+// processLeased runs handler against item.Request, then acks it on
+// success or routes it to a backoff retry or the dead letter store on
+// failure.
+func (wp *BackendWorkerPool) processLeased(logger *logrus.Entry, item *LeasedItem) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.WithFields(logrus.Fields{
+				"image_ref":  item.Request.ImageRef,
+				"request_id": item.Request.RequestID,
+				"panic":      r,
+			}).Error("Worker panic recovered")
+		}
+	}()
+
+	logger.WithFields(logrus.Fields{
+		"image_ref":  item.Request.ImageRef,
+		"request_id": item.Request.RequestID,
+	}).Info("Processing scan request")
+
+	if item.Request.FirstAttemptAt.IsZero() {
+		item.Request.FirstAttemptAt = time.Now()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	scanErr := wp.handler(ctx, item.Request)
+	if scanErr == nil {
+		logger.WithFields(logrus.Fields{
+			"image_ref":  item.Request.ImageRef,
+			"request_id": item.Request.RequestID,
+		}).Info("Scan processing completed")
+
+		if err := wp.backend.Ack(context.Background(), item.LeaseID); err != nil {
+			logger.WithError(err).Warn("Failed to ack completed scan request")
+		}
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"image_ref":  item.Request.ImageRef,
+		"request_id": item.Request.RequestID,
+		"error":      scanErr.Error(),
+	}).Error("Scan processing failed")
+
+	if wp.retry.ShouldRetry(item.Request, scanErr) {
+		if err := wp.retry.ScheduleRetry(context.Background(), item.LeaseID, item.Request, scanErr); err != nil {
+			logger.WithError(err).Error("Failed to schedule scan retry")
+		}
+		return
+	}
+
+	if wp.deadLetter != nil {
+		if err := wp.deadLetter.Record(context.Background(), item.Request, scanErr); err != nil {
+			logger.WithError(err).Error("Failed to record scan request to dead letter store")
+		} else {
+			metrics.RecordDeadLetter(item.Request.RegistryName)
+		}
+	}
+	if err := wp.backend.Ack(context.Background(), item.LeaseID); err != nil {
+		logger.WithError(err).Warn("Failed to ack dead-lettered scan request")
+	}
+}
+
+// Stats returns a best-effort snapshot of the pool's worker count and
+// the underlying Backend's queue depth, broken down by state. InFlight
+// comes from Backend.Stats, which already counts currently-leased
+// requests, and a request stays leased for exactly the span
+// processLeased is running it.
+func (wp *BackendWorkerPool) Stats() BackendWorkerPoolStats {
+	backendStats := wp.backend.Stats()
+	return BackendWorkerPoolStats{
+		Workers:      wp.workers,
+		Pending:      backendStats.Pending,
+		InFlight:     backendStats.InFlight,
+		DeadLettered: backendStats.DeadLettered,
+	}
+}
+
+// BackendWorkerPoolStats is a BackendWorkerPool's snapshot of its own
+// configured worker count plus its Backend's queue depth.
+type BackendWorkerPoolStats struct {
+	Workers      int
+	Pending      int
+	InFlight     int
+	DeadLettered int
+}
@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// NewDeadLetterStore creates the DeadLetterStore selected by
+// cfg.Queue.DeadLetterPath: a FileDeadLetterStore appending to it if
+// set, otherwise a NoopDeadLetterStore.
+func NewDeadLetterStore(cfg *config.Config) DeadLetterStore {
+	if cfg.Queue.DeadLetterPath == "" {
+		return NoopDeadLetterStore{}
+	}
+	return NewFileDeadLetterStore(cfg.Queue.DeadLetterPath)
+}
+
+// DeadLetterStore records a scan request RetryManager has given up
+// retrying, so operators can inspect and manually replay it instead of
+// it silently disappearing once BackendWorkerPool acks it off the
+// queue.
+type DeadLetterStore interface {
+	// Record persists req along with the error that doomed its final
+	// attempt.
+	Record(ctx context.Context, req *models.ScanRequest, cause error) error
+}
+
+// NoopDeadLetterStore discards dead-lettered requests, matching
+// BackendWorkerPool's behavior before this feature existed: exhausted
+// requests are simply dropped. Used when queue.dead_letter_path isn't
+// configured.
+type NoopDeadLetterStore struct{}
+
+// Record implements DeadLetterStore.
+func (NoopDeadLetterStore) Record(ctx context.Context, req *models.ScanRequest, cause error) error {
+	return nil
+}
+
+// deadLetterEntry is one line appended to a FileDeadLetterStore's file,
+// matching sink.Dispatcher's deadLetterEntry shape (request instead of
+// result, since a queue dead-letter happens before a scan ever
+// produces one).
+type deadLetterEntry struct {
+	Request   *models.ScanRequest `json:"request"`
+	Error     string              `json:"error"`
+	Timestamp time.Time           `json:"timestamp"`
+}
+
+// FileDeadLetterStore is a DeadLetterStore that appends one JSON line
+// per dead-lettered request to a file, the same append-only convention
+// sink.Dispatcher uses for its own dead letter files.
+type FileDeadLetterStore struct {
+	path string
+}
+
+// NewFileDeadLetterStore creates a FileDeadLetterStore appending to
+// path.
+func NewFileDeadLetterStore(path string) *FileDeadLetterStore {
+	return &FileDeadLetterStore{path: path}
+}
+
+// Record implements DeadLetterStore.
+func (s *FileDeadLetterStore) Record(ctx context.Context, req *models.ScanRequest, cause error) error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file %q: %w", s.path, err)
+	}
+	defer f.Close()
+
+	entry := deadLetterEntry{Request: req, Error: cause.Error(), Timestamp: time.Now()}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter entry: %w", err)
+	}
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
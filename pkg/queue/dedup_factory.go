@@ -0,0 +1,34 @@
+package queue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// NewDeduplicator creates a StoreBackedDeduplicator using the backend
+// selected by cfg.Queue.DedupBackend ("memory" or "redis").
+func NewDeduplicator(cfg *config.Config, logger *logrus.Logger) (*StoreBackedDeduplicator, error) {
+	ttl, err := time.ParseDuration(cfg.Queue.DedupTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queue.dedup_ttl: %w", err)
+	}
+
+	var store DedupStore
+	switch cfg.Queue.DedupBackend {
+	case "", "memory":
+		store = NewMemoryDedupStore(ttl / 2)
+	case "redis":
+		client, err := newRedisClient(cfg.Queue.RedisAddr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		store = NewRedisDedupStore(client, "scanner:dedup:")
+	default:
+		return nil, fmt.Errorf("unsupported queue.dedup_backend: %s", cfg.Queue.DedupBackend)
+	}
+
+	return NewStoreBackedDeduplicator(store, ttl, logger), nil
+}
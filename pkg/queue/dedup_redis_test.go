@@ -0,0 +1,127 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// fakeRedisClient is a minimal in-memory stand-in for RedisClient, used
+// to exercise RedisDedupStore's SETNX-based atomicity across concurrent
+// callers without a real Redis server.
+type fakeRedisClient struct {
+	mu     sync.Mutex
+	values map[string]string
+	ints   map[string]int64
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{values: make(map[string]string), ints: make(map[string]int64)}
+}
+
+func (c *fakeRedisClient) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.values[key]; exists {
+		return false, nil
+	}
+	c.values[key] = value
+	return true, nil
+}
+
+func (c *fakeRedisClient) Exists(key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, exists := c.values[key]
+	return exists, nil
+}
+
+func (c *fakeRedisClient) Incr(key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ints[key]++
+	return c.ints[key], nil
+}
+
+func (c *fakeRedisClient) GetInt64(key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ints[key], nil
+}
+
+// TestRedisDedupStore_CheckAndMark_ConcurrentReplicasOnlyOneWins races
+// many RedisDedupStore instances (standing in for independent webhook
+// replicas) sharing one backing client against the same key, the
+// scenario a separate Seen-then-Mark pair gets wrong.
+func TestRedisDedupStore_CheckAndMark_ConcurrentReplicasOnlyOneWins(t *testing.T) {
+	client := newFakeRedisClient()
+
+	const replicas = 20
+	var wg sync.WaitGroup
+	var notSeenCount int64
+	var mu sync.Mutex
+
+	wg.Add(replicas)
+	for i := 0; i < replicas; i++ {
+		store := NewRedisDedupStore(client, "scanner:dedup:")
+		go func(s *RedisDedupStore) {
+			defer wg.Done()
+			seen, err := s.CheckAndMark("sha256:shared-digest", time.Minute)
+			if err != nil {
+				t.Errorf("CheckAndMark() error = %v", err)
+				return
+			}
+			if !seen {
+				mu.Lock()
+				notSeenCount++
+				mu.Unlock()
+			}
+		}(store)
+	}
+	wg.Wait()
+
+	if notSeenCount != 1 {
+		t.Errorf("notSeenCount = %d, want exactly 1 winner across %d racing replicas sharing one Redis backend", notSeenCount, replicas)
+	}
+}
+
+// TestStoreBackedDeduplicator_ConcurrentInstancesShareBackingStore is
+// the scenario chunk8-6 claimed was already covered: independent
+// StoreBackedDeduplicator instances (each with its own singleflight.Group,
+// as they would be on separate webhook replicas) racing IsDuplicate for
+// the same digest against one shared RedisDedupStore-backed client.
+// Exactly one must report "not duplicate".
+func TestStoreBackedDeduplicator_ConcurrentInstancesShareBackingStore(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(&discardWriter{})
+
+	client := newFakeRedisClient()
+	req := &models.ScanRequest{ImageRef: "nginx:latest", Digest: "sha256:shared-digest"}
+
+	const replicas = 20
+	var wg sync.WaitGroup
+	var notDuplicateCount int64
+	var mu sync.Mutex
+
+	wg.Add(replicas)
+	for i := 0; i < replicas; i++ {
+		store := NewRedisDedupStore(client, "scanner:dedup:")
+		dedup := NewStoreBackedDeduplicator(store, time.Minute, logger)
+		go func(d *StoreBackedDeduplicator) {
+			defer wg.Done()
+			if !d.IsDuplicate(req) {
+				mu.Lock()
+				notDuplicateCount++
+				mu.Unlock()
+			}
+		}(dedup)
+	}
+	wg.Wait()
+
+	if notDuplicateCount != 1 {
+		t.Errorf("notDuplicateCount = %d, want exactly 1 across %d replicas racing the same digest", notDuplicateCount, replicas)
+	}
+}
@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"golang.org/x/sync/singleflight"
+)
+
+// StoreBackedDeduplicator is a DeduplicationCache-compatible dedup check
+// backed by a pluggable DedupStore, so the same digest-preferring
+// IsDuplicate semantics work against either MemoryDedupStore or
+// RedisDedupStore. A singleflight.Group keyed by the same dedup key
+// collapses concurrent in-flight scans of the same image within this
+// process into a single store round-trip, so a burst of webhook
+// deliveries for one push doesn't hammer the store with redundant
+// CheckAndMark calls.
+type StoreBackedDeduplicator struct {
+	store  DedupStore
+	ttl    time.Duration
+	logger *logrus.Logger
+	group  singleflight.Group
+}
+
+// NewStoreBackedDeduplicator creates a StoreBackedDeduplicator using
+// store for persistence and ttl as the deduplication window.
+func NewStoreBackedDeduplicator(store DedupStore, ttl time.Duration, logger *logrus.Logger) *StoreBackedDeduplicator {
+	return &StoreBackedDeduplicator{
+		store:  store,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// IsDuplicate checks if a scan request is a duplicate, coalescing
+// concurrent callers for the same key into a single store round-trip.
+// Returns true if the request was seen within the TTL window.
+func (d *StoreBackedDeduplicator) IsDuplicate(req *models.ScanRequest) bool {
+	key := d.generateKey(req)
+
+	result, _, _ := d.group.Do(key, func() (interface{}, error) {
+		seen, err := d.store.CheckAndMark(key, d.ttl)
+		if err != nil {
+			d.logger.WithError(err).WithField("key", key).Warn("Dedup store CheckAndMark failed, treating as not duplicate")
+			return false, nil
+		}
+		return seen, nil
+	})
+
+	duplicate := result.(bool)
+	if duplicate {
+		d.logger.WithFields(logrus.Fields{
+			"image_ref":  req.ImageRef,
+			"request_id": req.RequestID,
+			"key":        key,
+		}).Debug("Duplicate scan request detected")
+	}
+
+	return duplicate
+}
+
+// generateKey creates a deduplication key for a scan request. Prefers
+// digest over ImageRef, matching DeduplicationCache.generateKey.
+func (d *StoreBackedDeduplicator) generateKey(req *models.ScanRequest) string {
+	if req.Digest != "" {
+		return fmt.Sprintf("digest:%s", req.Digest)
+	}
+
+	hash := sha256.Sum256([]byte(req.ImageRef))
+	return fmt.Sprintf("ref:%x", hash[:16])
+}
+
+// Stats returns the underlying store's statistics.
+func (d *StoreBackedDeduplicator) Stats() DedupStoreStats {
+	return d.store.Stats()
+}
@@ -0,0 +1,174 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+func TestStoreBackedDeduplicator_IsDuplicate(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(&discardWriter{})
+
+	store := NewMemoryDedupStore(0)
+	defer store.Stop()
+
+	dedup := NewStoreBackedDeduplicator(store, 100*time.Millisecond, logger)
+
+	req1 := &models.ScanRequest{ImageRef: "nginx:latest", RequestID: "req-1"}
+	req2 := &models.ScanRequest{ImageRef: "nginx:latest", RequestID: "req-2"}
+	req3 := &models.ScanRequest{ImageRef: "redis:latest", RequestID: "req-3"}
+
+	if dedup.IsDuplicate(req1) {
+		t.Error("First request should not be duplicate")
+	}
+	if !dedup.IsDuplicate(req2) {
+		t.Error("Second request for same image should be duplicate")
+	}
+	if dedup.IsDuplicate(req3) {
+		t.Error("Request for different image should not be duplicate")
+	}
+}
+
+func TestStoreBackedDeduplicator_DigestBased(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(&discardWriter{})
+
+	store := NewMemoryDedupStore(0)
+	defer store.Stop()
+
+	dedup := NewStoreBackedDeduplicator(store, time.Minute, logger)
+
+	req1 := &models.ScanRequest{ImageRef: "nginx:latest", Digest: "sha256:abc123"}
+	req2 := &models.ScanRequest{ImageRef: "nginx:v1.0", Digest: "sha256:abc123"} // Same digest, different tag
+
+	if dedup.IsDuplicate(req1) {
+		t.Error("First request should not be duplicate")
+	}
+	if !dedup.IsDuplicate(req2) {
+		t.Error("Request with same digest should be duplicate")
+	}
+}
+
+func TestMemoryDedupStore_SeenAndMark(t *testing.T) {
+	store := NewMemoryDedupStore(0)
+	defer store.Stop()
+
+	seen, err := store.Seen("key-1")
+	if err != nil {
+		t.Fatalf("Seen() error = %v", err)
+	}
+	if seen {
+		t.Error("Seen() = true before Mark, want false")
+	}
+
+	if err := store.Mark("key-1", 50*time.Millisecond); err != nil {
+		t.Fatalf("Mark() error = %v", err)
+	}
+
+	seen, err = store.Seen("key-1")
+	if err != nil {
+		t.Fatalf("Seen() error = %v", err)
+	}
+	if !seen {
+		t.Error("Seen() = false after Mark, want true")
+	}
+
+	time.Sleep(75 * time.Millisecond)
+
+	seen, err = store.Seen("key-1")
+	if err != nil {
+		t.Fatalf("Seen() error = %v", err)
+	}
+	if seen {
+		t.Error("Seen() = true after TTL expiry, want false")
+	}
+}
+
+func TestMemoryDedupStore_CheckAndMark(t *testing.T) {
+	store := NewMemoryDedupStore(0)
+	defer store.Stop()
+
+	seen, err := store.CheckAndMark("key-1", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("CheckAndMark() error = %v", err)
+	}
+	if seen {
+		t.Error("CheckAndMark() seen = true on first call, want false")
+	}
+
+	seen, err = store.CheckAndMark("key-1", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("CheckAndMark() error = %v", err)
+	}
+	if !seen {
+		t.Error("CheckAndMark() seen = false on second call, want true")
+	}
+
+	time.Sleep(75 * time.Millisecond)
+
+	seen, err = store.CheckAndMark("key-1", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("CheckAndMark() error = %v", err)
+	}
+	if seen {
+		t.Error("CheckAndMark() seen = true after TTL expiry, want false")
+	}
+}
+
+// TestMemoryDedupStore_CheckAndMark_ConcurrentCallersOnlyOneWins
+// exercises two "replicas" racing CheckAndMark for the same key at the
+// same time, the scenario a separate Seen-then-Mark pair gets wrong:
+// exactly one of them must observe seen=false.
+func TestMemoryDedupStore_CheckAndMark_ConcurrentCallersOnlyOneWins(t *testing.T) {
+	store := NewMemoryDedupStore(0)
+	defer store.Stop()
+
+	const racers = 50
+	var wg sync.WaitGroup
+	var notSeenCount int64
+	var mu sync.Mutex
+
+	wg.Add(racers)
+	for i := 0; i < racers; i++ {
+		go func() {
+			defer wg.Done()
+			seen, err := store.CheckAndMark("race-key", time.Minute)
+			if err != nil {
+				t.Errorf("CheckAndMark() error = %v", err)
+				return
+			}
+			if !seen {
+				mu.Lock()
+				notSeenCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if notSeenCount != 1 {
+		t.Errorf("notSeenCount = %d, want exactly 1 winner across %d concurrent callers", notSeenCount, racers)
+	}
+}
+
+func TestMemoryDedupStore_Stats(t *testing.T) {
+	store := NewMemoryDedupStore(0)
+	defer store.Stop()
+
+	_, _ = store.Seen("key-1") // Miss
+	_ = store.Mark("key-1", time.Minute)
+	_, _ = store.Seen("key-1") // Hit
+	_, _ = store.Seen("key-2") // Miss
+
+	stats := store.Stats()
+	if stats.Hits != 1 {
+		t.Errorf("Stats.Hits = %d, want 1", stats.Hits)
+	}
+	if stats.Misses != 2 {
+		t.Errorf("Stats.Misses = %d, want 2", stats.Misses)
+	}
+}
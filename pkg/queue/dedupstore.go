@@ -0,0 +1,265 @@
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DedupStore is the backend a deduplication cache checks/records seen
+// keys against. Separating storage from key selection lets the same
+// digest-preferring logic in DeduplicationCache run against either an
+// in-memory map (single replica) or a shared store like Redis, so
+// duplicate scans don't slip through across horizontally-scaled webhook
+// replicas that would otherwise each hold their own view.
+type DedupStore interface {
+	// Seen reports whether key is currently marked (i.e. was Mark'd and
+	// hasn't expired).
+	Seen(key string) (bool, error)
+	// Mark records key as seen for ttl.
+	Mark(key string, ttl time.Duration) error
+	// CheckAndMark atomically checks whether key is already marked and,
+	// if not, marks it for ttl in the same operation. Unlike calling
+	// Seen and then Mark separately, this leaves no window in which two
+	// concurrent callers (e.g. two webhook replicas racing the same
+	// push) can both observe "not seen" and both go on to mark it,
+	// each believing itself first. seen reports whether another caller
+	// had already marked key.
+	CheckAndMark(key string, ttl time.Duration) (seen bool, err error)
+	// Stats returns backend statistics for observability.
+	Stats() DedupStoreStats
+}
+
+// DedupStoreStats represents deduplication store statistics. Size is
+// best-effort: backends that don't track key counts (e.g. Redis, where
+// counting live keys would require a keyspace scan) report 0.
+type DedupStoreStats struct {
+	Size    int
+	Hits    int64
+	Misses  int64
+	HitRate float64 // Percentage
+}
+
+// MemoryDedupStore is a DedupStore backed by an in-memory map, scoped to
+// this process. It's DeduplicationCache's original storage logic,
+// extracted so it can sit behind DedupStore alongside RedisDedupStore.
+type MemoryDedupStore struct {
+	mu        sync.RWMutex
+	entries   map[string]time.Time
+	hitCount  int64
+	missCount int64
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// NewMemoryDedupStore creates a MemoryDedupStore and starts its
+// background cleanup loop, which sweeps expired entries every interval.
+func NewMemoryDedupStore(cleanupInterval time.Duration) *MemoryDedupStore {
+	s := &MemoryDedupStore{
+		entries:  make(map[string]time.Time),
+		stopChan: make(chan struct{}),
+	}
+
+	if cleanupInterval > 0 {
+		go s.cleanupLoop(cleanupInterval)
+	}
+
+	return s
+}
+
+// Seen implements DedupStore.
+func (s *MemoryDedupStore) Seen(key string) (bool, error) {
+	s.mu.RLock()
+	expiresAt, exists := s.entries[key]
+	s.mu.RUnlock()
+
+	seen := exists && time.Now().Before(expiresAt)
+	if seen {
+		atomic.AddInt64(&s.hitCount, 1)
+	} else {
+		atomic.AddInt64(&s.missCount, 1)
+	}
+
+	return seen, nil
+}
+
+// Mark implements DedupStore.
+func (s *MemoryDedupStore) Mark(key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = time.Now().Add(ttl)
+	return nil
+}
+
+// CheckAndMark implements DedupStore, holding s.mu across the check and
+// the mark so concurrent callers within this process can't race the
+// way separate Seen and Mark calls can.
+func (s *MemoryDedupStore) CheckAndMark(key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	expiresAt, exists := s.entries[key]
+	seen := exists && time.Now().Before(expiresAt)
+	if !seen {
+		s.entries[key] = time.Now().Add(ttl)
+	}
+	s.mu.Unlock()
+
+	if seen {
+		atomic.AddInt64(&s.hitCount, 1)
+	} else {
+		atomic.AddInt64(&s.missCount, 1)
+	}
+	return seen, nil
+}
+
+// Stats implements DedupStore.
+func (s *MemoryDedupStore) Stats() DedupStoreStats {
+	s.mu.RLock()
+	size := len(s.entries)
+	s.mu.RUnlock()
+
+	hits := atomic.LoadInt64(&s.hitCount)
+	misses := atomic.LoadInt64(&s.missCount)
+	total := hits + misses
+
+	var hitRate float64
+	if total > 0 {
+		hitRate = float64(hits) / float64(total) * 100
+	}
+
+	return DedupStoreStats{Size: size, Hits: hits, Misses: misses, HitRate: hitRate}
+}
+
+// Stop stops the background cleanup loop.
+func (s *MemoryDedupStore) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+}
+
+func (s *MemoryDedupStore) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.cleanup()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+func (s *MemoryDedupStore) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, expiresAt := range s.entries {
+		if now.After(expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// RedisClient is the subset of a Redis client's commands RedisDedupStore
+// needs, so it isn't tied to a specific Redis library's concrete type.
+// github.com/redis/go-redis/v9's *redis.Client satisfies this directly.
+type RedisClient interface {
+	// SetNX sets key to value with the given expiration only if key
+	// doesn't already exist (SET key value NX EX ttl), returning whether
+	// the key was set.
+	SetNX(key, value string, ttl time.Duration) (bool, error)
+	// Exists reports whether key is currently present (and unexpired).
+	Exists(key string) (bool, error)
+	// Incr atomically increments key and returns its new value.
+	Incr(key string) (int64, error)
+	// GetInt64 returns the integer value stored at key, or 0 if it
+	// doesn't exist.
+	GetInt64(key string) (int64, error)
+}
+
+// RedisDedupStore is a DedupStore backed by Redis, so deduplication
+// state is shared across horizontally-scaled webhook replicas instead of
+// each holding its own in-memory view.
+type RedisDedupStore struct {
+	client    RedisClient
+	keyPrefix string
+	hitsKey   string
+	missesKey string
+}
+
+// NewRedisDedupStore creates a RedisDedupStore using client, namespacing
+// all keys under keyPrefix (e.g. "scanner:dedup:").
+func NewRedisDedupStore(client RedisClient, keyPrefix string) *RedisDedupStore {
+	return &RedisDedupStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+		hitsKey:   keyPrefix + "stats:hits",
+		missesKey: keyPrefix + "stats:misses",
+	}
+}
+
+// Seen implements DedupStore.
+func (s *RedisDedupStore) Seen(key string) (bool, error) {
+	seen, err := s.client.Exists(s.keyPrefix + key)
+	if err != nil {
+		return false, fmt.Errorf("redis dedup store: EXISTS failed: %w", err)
+	}
+
+	if seen {
+		_, _ = s.client.Incr(s.hitsKey)
+	} else {
+		_, _ = s.client.Incr(s.missesKey)
+	}
+
+	return seen, nil
+}
+
+// Mark implements DedupStore using SET key value NX EX ttl, so
+// concurrent Mark calls for the same key across replicas race safely:
+// only the first one actually sets the key.
+func (s *RedisDedupStore) Mark(key string, ttl time.Duration) error {
+	if _, err := s.client.SetNX(s.keyPrefix+key, "1", ttl); err != nil {
+		return fmt.Errorf("redis dedup store: SETNX failed: %w", err)
+	}
+	return nil
+}
+
+// CheckAndMark implements DedupStore using SETNX's own reply to decide
+// seen-or-not in the single round-trip SETNX already makes atomic,
+// instead of a separate Seen call followed by a racy Mark: two replicas
+// calling CheckAndMark for the same key can't both get seen=false, since
+// Redis only lets one SETNX actually set the key.
+func (s *RedisDedupStore) CheckAndMark(key string, ttl time.Duration) (bool, error) {
+	set, err := s.client.SetNX(s.keyPrefix+key, "1", ttl)
+	if err != nil {
+		return false, fmt.Errorf("redis dedup store: SETNX failed: %w", err)
+	}
+	seen := !set
+
+	if seen {
+		_, _ = s.client.Incr(s.hitsKey)
+	} else {
+		_, _ = s.client.Incr(s.missesKey)
+	}
+	return seen, nil
+}
+
+// Stats implements DedupStore. Size is always 0: counting live keys
+// under keyPrefix would require a SCAN across the keyspace, which this
+// store avoids doing on every Stats() call.
+func (s *RedisDedupStore) Stats() DedupStoreStats {
+	hits, _ := s.client.GetInt64(s.hitsKey)
+	misses, _ := s.client.GetInt64(s.missesKey)
+	total := hits + misses
+
+	var hitRate float64
+	if total > 0 {
+		hitRate = float64(hits) / float64(total) * 100
+	}
+
+	return DedupStoreStats{Hits: hits, Misses: misses, HitRate: hitRate}
+}
@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LeaderElector reports whether the calling process currently holds
+// leadership of some cluster-wide role. Reaper uses it so
+// ReapExpiredLeases runs on exactly one horizontally-scaled webhook
+// replica at a time rather than every replica racing to reap the same
+// expired leases.
+type LeaderElector interface {
+	// IsLeader reports whether this process currently holds leadership.
+	// It's safe to call frequently; implementations cache their last
+	// known state between renewals rather than round-tripping on every
+	// call.
+	IsLeader(ctx context.Context) bool
+}
+
+// SingleLeader is a LeaderElector that always reports leadership,
+// correct when exactly one replica of the webhook runs (the common case
+// for MemoryBackend, where there's nothing to coordinate across
+// replicas anyway).
+type SingleLeader struct{}
+
+// IsLeader implements LeaderElector.
+func (SingleLeader) IsLeader(ctx context.Context) bool { return true }
+
+// LeaderLockClient is the subset of a Redis client RedisLeaderElector
+// needs, so it isn't tied to a specific Redis library's concrete type.
+// redisLeaderLockClient (using github.com/redis/go-redis/v9) satisfies
+// this.
+type LeaderLockClient interface {
+	// TryAcquire sets key to value with the given TTL only if key is
+	// currently unset, reporting whether this call won the lock.
+	TryAcquire(key, value string, ttl time.Duration) (bool, error)
+	// Renew extends key's TTL only if its current value still equals
+	// value, reporting whether the renewal applied. A false result
+	// means another replica already won the lock since this one's last
+	// successful acquire or renew.
+	Renew(key, value string, ttl time.Duration) (bool, error)
+}
+
+// RedisLeaderElector is a LeaderElector backed by a Redis lock with a
+// TTL, renewed on a timer so a crashed leader's term expires and
+// another replica can take over instead of the role being stuck
+// forever.
+type RedisLeaderElector struct {
+	client LeaderLockClient
+	key    string
+	id     string
+	ttl    time.Duration
+
+	mu       sync.RWMutex
+	isLeader bool
+}
+
+// NewRedisLeaderElector creates a RedisLeaderElector that contests key
+// using client, identifying this replica's lock value as id (e.g. a pod
+// name or hostname, so operators can tell who holds the lock from
+// `redis-cli GET key`). Call Start to begin contesting and renewing the
+// lock; IsLeader reflects the outcome of the most recent attempt.
+func NewRedisLeaderElector(client LeaderLockClient, key, id string, ttl time.Duration) *RedisLeaderElector {
+	return &RedisLeaderElector{client: client, key: key, id: id, ttl: ttl}
+}
+
+// Start contests and renews the leader lock every ttl/2 until ctx is
+// done.
+func (e *RedisLeaderElector) Start(ctx context.Context) {
+	e.tryAcquireOrRenew()
+
+	ticker := time.NewTicker(e.ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.tryAcquireOrRenew()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// tryAcquireOrRenew renews the lock if this replica already holds it,
+// otherwise attempts to acquire it fresh, recording the outcome for
+// IsLeader to read.
+func (e *RedisLeaderElector) tryAcquireOrRenew() {
+	won, err := func() (bool, error) {
+		if e.IsLeader(context.Background()) {
+			return e.client.Renew(e.key, e.id, e.ttl)
+		}
+		return e.client.TryAcquire(e.key, e.id, e.ttl)
+	}()
+
+	e.mu.Lock()
+	e.isLeader = err == nil && won
+	e.mu.Unlock()
+}
+
+// IsLeader implements LeaderElector.
+func (e *RedisLeaderElector) IsLeader(ctx context.Context) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// NewLeaderElector creates the LeaderElector Reaper uses so only one
+// replica reaps expired leases at a time. Only cfg.Queue.Backend
+// "redis" gets real election, contesting a Redis lock: "memory" has no
+// other replica to coordinate with, and "gcs"/"s3"/"nats" are left
+// reaping independently for now (ObjectStoreBackend already documents
+// itself as at-least-once-tolerant, and NatsBackend's
+// ReapExpiredLeases is a no-op). ctx governs the lock-renewal
+// goroutine's lifetime; cancel it as part of shutdown.
+func NewLeaderElector(ctx context.Context, cfg *config.Config, logger *logrus.Logger) (LeaderElector, error) {
+	if cfg.Queue.Backend != "redis" {
+		return SingleLeader{}, nil
+	}
+
+	client, err := newRedisLeaderLockClient(cfg.Queue.RedisAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	ttl, err := cfg.ParseDuration(cfg.Queue.LeaderLockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queue.leader_lock_ttl: %w", err)
+	}
+
+	id, err := os.Hostname()
+	if err != nil || id == "" {
+		id = generateLeaseID()
+	}
+
+	elector := NewRedisLeaderElector(client, cfg.Queue.LeaderLockKey, id, ttl)
+	go elector.Start(ctx)
+
+	return elector, nil
+}
@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renewLockScript extends key's TTL only if its current value still
+// matches the caller's, so a replica can't accidentally renew a lock
+// another replica has since won after this one's term lapsed.
+const renewLockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+	return 0
+end
+`
+
+// redisLeaderLockClient adapts *redis.Client to the LeaderLockClient
+// interface RedisLeaderElector depends on, so the elector itself stays
+// decoupled from the concrete Redis library in use.
+type redisLeaderLockClient struct {
+	client *redis.Client
+}
+
+// newRedisLeaderLockClient connects to the Redis server at addr.
+func newRedisLeaderLockClient(addr string) (*redisLeaderLockClient, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &redisLeaderLockClient{client: client}, nil
+}
+
+// TryAcquire implements LeaderLockClient.
+func (c *redisLeaderLockClient) TryAcquire(key, value string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(context.Background(), key, value, ttl).Result()
+}
+
+// Renew implements LeaderLockClient.
+func (c *redisLeaderLockClient) Renew(key, value string, ttl time.Duration) (bool, error) {
+	result, err := c.client.Eval(context.Background(), renewLockScript, []string{key}, value, ttl.Milliseconds()).Result()
+	if err != nil {
+		return false, err
+	}
+
+	renewed, ok := result.(int64)
+	return ok && renewed == 1, nil
+}
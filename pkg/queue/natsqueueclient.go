@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsJetStreamMsg adapts a *nats.Msg to the NatsMsg interface
+// NatsBackend depends on.
+type natsJetStreamMsg struct {
+	msg *nats.Msg
+}
+
+// Data implements NatsMsg.
+func (m *natsJetStreamMsg) Data() []byte { return m.msg.Data }
+
+// NumDelivered implements NatsMsg.
+func (m *natsJetStreamMsg) NumDelivered() uint64 {
+	meta, err := m.msg.Metadata()
+	if err != nil {
+		return 1
+	}
+	return meta.NumDelivered
+}
+
+// Ack implements NatsMsg.
+func (m *natsJetStreamMsg) Ack() error { return m.msg.Ack() }
+
+// NakWithDelay implements NatsMsg.
+func (m *natsJetStreamMsg) NakWithDelay(delay time.Duration) error {
+	return m.msg.NakWithDelay(delay)
+}
+
+// Term implements NatsMsg.
+func (m *natsJetStreamMsg) Term() error { return m.msg.Term() }
+
+// natsJetStreamClient adapts a nats.JetStreamContext to the
+// NatsQueueClient interface NatsBackend depends on, so the backend
+// itself stays decoupled from the concrete NATS library in use.
+//
+// It lazily creates one durable pull consumer per subject on first
+// Fetch, named after the subject so every replica's client binds back
+// to the same shared consumer instead of each creating its own.
+type natsJetStreamClient struct {
+	js      nats.JetStreamContext
+	ackWait time.Duration
+
+	mu   sync.Mutex
+	subs map[string]*nats.Subscription
+}
+
+// newNatsJetStreamClient connects to the NATS server at url, ensures
+// streamName exists covering subjects, and returns a client whose
+// Fetch-created consumers use ackWait as their AckWait - the interval
+// after which JetStream redelivers a message neither Ack'd nor Nak'd.
+func newNatsJetStreamClient(url, streamName string, subjects []string, ackWait time.Duration) (*natsJetStreamClient, error) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := js.AddStream(&nats.StreamConfig{Name: streamName, Subjects: subjects}); err != nil && !errors.Is(err, nats.ErrStreamNameAlreadyInUse) {
+		return nil, err
+	}
+
+	return &natsJetStreamClient{js: js, ackWait: ackWait, subs: make(map[string]*nats.Subscription)}, nil
+}
+
+// Publish implements NatsQueueClient.
+func (c *natsJetStreamClient) Publish(subject string, data []byte) error {
+	_, err := c.js.Publish(subject, data)
+	return err
+}
+
+// subscription returns the durable pull subscription bound to subject,
+// creating it on first use.
+func (c *natsJetStreamClient) subscription(subject string) (*nats.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if sub, ok := c.subs[subject]; ok {
+		return sub, nil
+	}
+
+	sub, err := c.js.PullSubscribe(subject, subject+"-consumer", nats.AckWait(c.ackWait))
+	if err != nil {
+		return nil, err
+	}
+
+	c.subs[subject] = sub
+	return sub, nil
+}
+
+// Fetch implements NatsQueueClient.
+func (c *natsJetStreamClient) Fetch(subject string, timeout time.Duration) (NatsMsg, bool, error) {
+	sub, err := c.subscription(subject)
+	if err != nil {
+		return nil, false, err
+	}
+
+	msgs, err := sub.Fetch(1, nats.MaxWait(timeout))
+	if errors.Is(err, nats.ErrTimeout) || len(msgs) == 0 {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &natsJetStreamMsg{msg: msgs[0]}, true, nil
+}
+
+// PendingCount implements NatsQueueClient.
+func (c *natsJetStreamClient) PendingCount(subject string) int {
+	sub, err := c.subscription(subject)
+	if err != nil {
+		return 0
+	}
+
+	info, err := sub.ConsumerInfo()
+	if err != nil {
+		return 0
+	}
+
+	return int(info.NumPending)
+}
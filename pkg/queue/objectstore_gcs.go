@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// gcsObjectStore adapts a Google Cloud Storage bucket to the
+// ObjectStore interface ObjectStoreBackend depends on.
+type gcsObjectStore struct {
+	bucket *storage.BucketHandle
+}
+
+// newGCSObjectStore creates a gcsObjectStore backed by bucketName.
+func newGCSObjectStore(ctx context.Context, bucketName string) (*gcsObjectStore, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcsObjectStore{bucket: client.Bucket(bucketName)}, nil
+}
+
+// Put implements ObjectStore.
+func (s *gcsObjectStore) Put(ctx context.Context, key string, data []byte, metadata map[string]string) error {
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.Metadata = metadata
+
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Get implements ObjectStore.
+func (s *gcsObjectStore) Get(ctx context.Context, key string) ([]byte, map[string]string, error) {
+	obj := s.bucket.Object(key)
+
+	attrs, err := obj.Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := obj.NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, attrs.Metadata, nil
+}
+
+// Delete implements ObjectStore.
+func (s *gcsObjectStore) Delete(ctx context.Context, key string) error {
+	err := s.bucket.Object(key).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+// List implements ObjectStore.
+func (s *gcsObjectStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+
+	it := s.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, attrs.Name)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,33 @@
+package queue
+
+// Priority is the scheduling priority assigned to an enqueued scan
+// request. ScanQueue dequeues higher-priority requests ahead of
+// lower-priority ones regardless of arrival order, so a slow or noisy
+// registry's backlog can't delay latency-sensitive scans queued for a
+// different registry sharing the same WorkerPool.
+type Priority int
+
+// Priority levels. Values are explicit, not iota, so PriorityNormal -
+// the default a caller gets if it forgets to set one - is the zero
+// value, while still preserving PriorityLow < PriorityNormal <
+// PriorityHigh for priorityHeap's ordering.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// ParsePriority maps a config.RegistryConfig.Priority string onto a
+// Priority, defaulting to PriorityNormal for an empty or unrecognized
+// value so registries configured before Priority existed keep today's
+// FIFO-within-priority behavior.
+func ParsePriority(s string) Priority {
+	switch s {
+	case "high":
+		return PriorityHigh
+	case "low":
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
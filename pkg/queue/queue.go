@@ -1,80 +1,221 @@
 package queue
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
 	"sync"
-	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
 )
 
-// ScanQueue represents an in-memory queue for scan requests
+// dequeueLookahead bounds how many queued items Dequeue will skip past
+// looking for one whose registry still has spare concurrency, so a
+// deeply saturated registry at the front of the queue can't make every
+// Dequeue call scan the entire backlog.
+const dequeueLookahead = 32
+
+// queueItem is one request waiting in ScanQueue, along with the
+// bookkeeping priorityHeap needs to order it.
+type queueItem struct {
+	req        *models.ScanRequest
+	priority   Priority
+	enqueuedAt time.Time
+	index      int
+}
+
+// priorityHeap orders queueItems by priority (highest first), then by
+// enqueuedAt (earliest first) so requests of equal priority are still
+// served FIFO. It implements container/heap.Interface.
+type priorityHeap []*queueItem
+
+func (h priorityHeap) Len() int { return len(h) }
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].enqueuedAt.Before(h[j].enqueuedAt)
+}
+
+func (h priorityHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *priorityHeap) Push(x interface{}) {
+	item := x.(*queueItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// ScanQueue is an in-memory, priority-ordered queue of scan requests. It
+// optionally enforces a per-registry concurrency limit so one saturated
+// or slow-scanning registry can't consume every worker in a shared
+// WorkerPool and starve requests queued for others.
 type ScanQueue struct {
-	queue      chan *models.ScanRequest
-	capacity   int
-	depth      int64 // atomic counter for current queue depth
-	logger     *logrus.Logger
-	mu         sync.RWMutex
-	closed     bool
+	mu       sync.Mutex
+	items    priorityHeap
+	capacity int
+	closed   bool
+	logger   *logrus.Logger
+
+	// registryLimits holds each registry's configured MaxConcurrent; a
+	// registry absent from the map, or mapped to 0, has no limit.
+	registryLimits   map[string]int
+	registryInFlight map[string]int
+
+	// wake is closed and replaced under mu whenever something changes
+	// that might make a blocked Dequeue dispatchable: an Enqueue, a
+	// Release, or Close. Waiters read the current channel under mu,
+	// then select on it outside the lock so they observe every
+	// broadcast after the one they last missed.
+	wake chan struct{}
 }
 
-// NewScanQueue creates a new scan queue with the specified capacity
-func NewScanQueue(capacity int, logger *logrus.Logger) *ScanQueue {
+// NewScanQueue creates a scan queue with the given capacity and
+// per-registry concurrency limits (registry name -> MaxConcurrent; 0 or
+// absent means unlimited).
+func NewScanQueue(capacity int, registryLimits map[string]int, logger *logrus.Logger) *ScanQueue {
+	if registryLimits == nil {
+		registryLimits = map[string]int{}
+	}
+
 	return &ScanQueue{
-		queue:    make(chan *models.ScanRequest, capacity),
-		capacity: capacity,
-		depth:    0,
-		logger:   logger,
-		closed:   false,
+		capacity:         capacity,
+		logger:           logger,
+		registryLimits:   registryLimits,
+		registryInFlight: make(map[string]int),
+		wake:             make(chan struct{}),
 	}
 }
 
-// Enqueue adds a scan request to the queue
-// Returns error if queue is full or closed
-func (q *ScanQueue) Enqueue(ctx context.Context, req *models.ScanRequest) error {
-	q.mu.RLock()
+// Enqueue adds a scan request to the queue at the given priority.
+// Returns an error if the queue is full or closed.
+func (q *ScanQueue) Enqueue(ctx context.Context, req *models.ScanRequest, priority Priority) error {
+	q.mu.Lock()
 	if q.closed {
-		q.mu.RUnlock()
+		q.mu.Unlock()
 		return fmt.Errorf("queue is closed")
 	}
-	q.mu.RUnlock()
-
-	select {
-	case q.queue <- req:
-		atomic.AddInt64(&q.depth, 1)
-		q.logger.WithFields(logrus.Fields{
-			"image_ref":  req.ImageRef,
-			"request_id": req.RequestID,
-			"queue_depth": atomic.LoadInt64(&q.depth),
-		}).Debug("Scan request enqueued")
-		return nil
-	case <-ctx.Done():
-		return fmt.Errorf("enqueue cancelled: %w", ctx.Err())
-	default:
+	if len(q.items) >= q.capacity {
+		q.mu.Unlock()
 		return fmt.Errorf("queue is full (capacity: %d)", q.capacity)
 	}
+
+	heap.Push(&q.items, &queueItem{req: req, priority: priority, enqueuedAt: time.Now()})
+	depth := len(q.items)
+	q.broadcast()
+	q.mu.Unlock()
+
+	q.logger.WithFields(logrus.Fields{
+		"image_ref":   req.ImageRef,
+		"request_id":  req.RequestID,
+		"priority":    priority,
+		"queue_depth": depth,
+	}).Debug("Scan request enqueued")
+	return nil
 }
 
-// Dequeue removes and returns a scan request from the queue (FIFO)
-// Blocks until a request is available or context is cancelled
+// Dequeue removes and returns the highest-priority dispatchable request
+// (see popDispatchable), blocking until one is available or ctx is
+// cancelled.
 func (q *ScanQueue) Dequeue(ctx context.Context) (*models.ScanRequest, error) {
-	select {
-	case req, ok := <-q.queue:
-		if !ok {
+	for {
+		q.mu.Lock()
+		if item, ok := q.popDispatchable(); ok {
+			q.mu.Unlock()
+			return item.req, nil
+		}
+		if q.closed {
+			q.mu.Unlock()
 			return nil, fmt.Errorf("queue is closed")
 		}
-		atomic.AddInt64(&q.depth, -1)
-		return req, nil
-	case <-ctx.Done():
-		return nil, fmt.Errorf("dequeue cancelled: %w", ctx.Err())
+		wake := q.wake
+		q.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("dequeue cancelled: %w", ctx.Err())
+		}
 	}
 }
 
-// Depth returns the current number of items in the queue
+// popDispatchable scans up to dequeueLookahead queued items in priority
+// order for the first whose registry still has spare concurrency,
+// removing it and reserving that concurrency slot. Items it skips over
+// are left in the queue. ok is false if nothing within the lookahead
+// window is currently dispatchable. Callers must hold q.mu and must
+// Release the returned request's registry slot once it finishes
+// processing.
+func (q *ScanQueue) popDispatchable() (*queueItem, bool) {
+	var skipped []*queueItem
+	defer func() {
+		for _, item := range skipped {
+			heap.Push(&q.items, item)
+		}
+	}()
+
+	for i := 0; i < dequeueLookahead && q.items.Len() > 0; i++ {
+		item := heap.Pop(&q.items).(*queueItem)
+		if q.hasCapacity(item.req.RegistryName) {
+			q.registryInFlight[item.req.RegistryName]++
+			return item, true
+		}
+		skipped = append(skipped, item)
+	}
+	return nil, false
+}
+
+// hasCapacity reports whether registry has a free concurrency slot.
+// Callers must hold q.mu.
+func (q *ScanQueue) hasCapacity(registry string) bool {
+	limit := q.registryLimits[registry]
+	if limit <= 0 {
+		return true
+	}
+	return q.registryInFlight[registry] < limit
+}
+
+// Release frees the registry concurrency slot Dequeue reserved for req,
+// letting a queued request for the same registry be dispatched. Every
+// request returned by Dequeue must be Released exactly once, once it
+// finishes processing.
+func (q *ScanQueue) Release(req *models.ScanRequest) {
+	q.mu.Lock()
+	if q.registryInFlight[req.RegistryName] > 0 {
+		q.registryInFlight[req.RegistryName]--
+	}
+	q.broadcast()
+	q.mu.Unlock()
+}
+
+// broadcast wakes every goroutine blocked in Dequeue. Callers must hold
+// q.mu.
+func (q *ScanQueue) broadcast() {
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
+
+// Depth returns the current number of items in the queue.
 func (q *ScanQueue) Depth() int {
-	return int(atomic.LoadInt64(&q.depth))
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len()
 }
 
 // Capacity returns the maximum capacity of the queue
@@ -84,12 +225,16 @@ func (q *ScanQueue) Capacity() int {
 
 // IsFull returns true if the queue is at capacity
 func (q *ScanQueue) IsFull() bool {
-	return q.Depth() >= q.capacity
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len() >= q.capacity
 }
 
 // IsEmpty returns true if the queue is empty
 func (q *ScanQueue) IsEmpty() bool {
-	return q.Depth() == 0
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len() == 0
 }
 
 // Close closes the queue, preventing new enqueues
@@ -100,27 +245,41 @@ func (q *ScanQueue) Close() {
 
 	if !q.closed {
 		q.closed = true
-		close(q.queue)
+		q.broadcast()
 		q.logger.Info("Scan queue closed")
 	}
 }
 
 // IsClosed returns true if the queue has been closed
 func (q *ScanQueue) IsClosed() bool {
-	q.mu.RLock()
-	defer q.mu.RUnlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	return q.closed
 }
 
-// Stats returns queue statistics
+// Stats returns queue statistics, including a per-registry saturation
+// breakdown so an operator can see which registry, if any, is
+// consuming its configured concurrency limit.
 func (q *ScanQueue) Stats() QueueStats {
-	depth := q.Depth()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	depth := q.items.Len()
+	saturation := make(map[string]RegistrySaturation, len(q.registryInFlight))
+	for registry, inFlight := range q.registryInFlight {
+		saturation[registry] = RegistrySaturation{
+			InFlight: inFlight,
+			Limit:    q.registryLimits[registry],
+		}
+	}
+
 	return QueueStats{
-		Depth:       depth,
-		Capacity:    q.capacity,
-		Utilization: float64(depth) / float64(q.capacity) * 100,
-		IsFull:      depth >= q.capacity,
-		IsEmpty:     depth == 0,
+		Depth:              depth,
+		Capacity:           q.capacity,
+		Utilization:        float64(depth) / float64(q.capacity) * 100,
+		IsFull:             depth >= q.capacity,
+		IsEmpty:            depth == 0,
+		RegistrySaturation: saturation,
 	}
 }
 
@@ -131,4 +290,17 @@ type QueueStats struct {
 	Utilization float64 // Percentage (0-100)
 	IsFull      bool
 	IsEmpty     bool
+	// RegistrySaturation breaks down current in-flight usage against
+	// each registry's configured concurrency limit. A registry with no
+	// configured limit still appears here (Limit 0) once it has had at
+	// least one request dispatched.
+	RegistrySaturation map[string]RegistrySaturation
+}
+
+// RegistrySaturation is one registry's current share of its configured
+// concurrency limit. Limit is 0 for registries with no configured
+// MaxConcurrent.
+type RegistrySaturation struct {
+	InFlight int
+	Limit    int
 }
@@ -0,0 +1,163 @@
+package queue
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestScanQueue_DequeuesHighestPriorityFirst(t *testing.T) {
+	q := NewScanQueue(10, nil, testLogger())
+	ctx := context.Background()
+
+	low := &models.ScanRequest{RequestID: "low"}
+	high := &models.ScanRequest{RequestID: "high"}
+	normal := &models.ScanRequest{RequestID: "normal"}
+
+	if err := q.Enqueue(ctx, low, PriorityLow); err != nil {
+		t.Fatalf("Enqueue(low) error = %v", err)
+	}
+	if err := q.Enqueue(ctx, normal, PriorityNormal); err != nil {
+		t.Fatalf("Enqueue(normal) error = %v", err)
+	}
+	if err := q.Enqueue(ctx, high, PriorityHigh); err != nil {
+		t.Fatalf("Enqueue(high) error = %v", err)
+	}
+
+	for _, want := range []string{"high", "normal", "low"} {
+		req, err := q.Dequeue(ctx)
+		if err != nil {
+			t.Fatalf("Dequeue() error = %v", err)
+		}
+		if req.RequestID != want {
+			t.Fatalf("Dequeue() = %q, want %q", req.RequestID, want)
+		}
+		q.Release(req)
+	}
+}
+
+func TestScanQueue_PerRegistryLimitDoesNotStarveOtherRegistries(t *testing.T) {
+	q := NewScanQueue(10, map[string]int{"slow-registry": 1}, testLogger())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		req := &models.ScanRequest{RequestID: "slow", RegistryName: "slow-registry"}
+		if err := q.Enqueue(ctx, req, PriorityNormal); err != nil {
+			t.Fatalf("Enqueue(slow) error = %v", err)
+		}
+	}
+	fast := &models.ScanRequest{RequestID: "fast", RegistryName: "fast-registry"}
+	if err := q.Enqueue(ctx, fast, PriorityNormal); err != nil {
+		t.Fatalf("Enqueue(fast) error = %v", err)
+	}
+
+	// The slow registry's first scan occupies its only concurrency
+	// slot; its two other queued scans must not block fast-registry's
+	// request from being dispatched next.
+	first, err := q.Dequeue(ctx)
+	if err != nil || first.RequestID != "slow" {
+		t.Fatalf("Dequeue() = %v, %v, want slow", first, err)
+	}
+
+	second, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue() error = %v", err)
+	}
+	if second.RequestID != "fast" {
+		t.Fatalf("Dequeue() = %q, want fast-registry's request to not be starved by slow-registry's saturation", second.RequestID)
+	}
+}
+
+func TestScanQueue_ReleaseFreesRegistrySlot(t *testing.T) {
+	q := NewScanQueue(10, map[string]int{"registry": 1}, testLogger())
+	ctx := context.Background()
+
+	first := &models.ScanRequest{RequestID: "first", RegistryName: "registry"}
+	second := &models.ScanRequest{RequestID: "second", RegistryName: "registry"}
+	if err := q.Enqueue(ctx, first, PriorityNormal); err != nil {
+		t.Fatalf("Enqueue(first) error = %v", err)
+	}
+	if err := q.Enqueue(ctx, second, PriorityNormal); err != nil {
+		t.Fatalf("Enqueue(second) error = %v", err)
+	}
+
+	req, err := q.Dequeue(ctx)
+	if err != nil || req.RequestID != "first" {
+		t.Fatalf("Dequeue() = %v, %v, want first", req, err)
+	}
+
+	dequeueCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := q.Dequeue(dequeueCtx); err == nil {
+		t.Fatal("Dequeue() error = nil, want a saturated registry to block the second request")
+	}
+
+	q.Release(req)
+
+	req, err = q.Dequeue(ctx)
+	if err != nil || req.RequestID != "second" {
+		t.Fatalf("Dequeue() after Release = %v, %v, want second", req, err)
+	}
+}
+
+func TestScanQueue_EnqueueRejectsWhenFull(t *testing.T) {
+	q := NewScanQueue(1, nil, testLogger())
+	ctx := context.Background()
+
+	if err := q.Enqueue(ctx, &models.ScanRequest{RequestID: "a"}, PriorityNormal); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := q.Enqueue(ctx, &models.ScanRequest{RequestID: "b"}, PriorityNormal); err == nil {
+		t.Fatal("Enqueue() error = nil, want error for a full queue")
+	}
+}
+
+func TestScanQueue_StatsReportsRegistrySaturation(t *testing.T) {
+	q := NewScanQueue(10, map[string]int{"registry": 2}, testLogger())
+	ctx := context.Background()
+
+	if err := q.Enqueue(ctx, &models.ScanRequest{RequestID: "a", RegistryName: "registry"}, PriorityNormal); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if _, err := q.Dequeue(ctx); err != nil {
+		t.Fatalf("Dequeue() error = %v", err)
+	}
+
+	stats := q.Stats()
+	saturation, ok := stats.RegistrySaturation["registry"]
+	if !ok {
+		t.Fatalf("Stats().RegistrySaturation = %+v, want an entry for \"registry\"", stats.RegistrySaturation)
+	}
+	if saturation.InFlight != 1 || saturation.Limit != 2 {
+		t.Errorf("Stats().RegistrySaturation[registry] = %+v, want InFlight=1 Limit=2", saturation)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Priority
+	}{
+		{"high", PriorityHigh},
+		{"low", PriorityLow},
+		{"normal", PriorityNormal},
+		{"", PriorityNormal},
+		{"urgent", PriorityNormal},
+	}
+
+	for _, tt := range tests {
+		if got := ParsePriority(tt.in); got != tt.want {
+			t.Errorf("ParsePriority(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
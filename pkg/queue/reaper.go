@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Reaper periodically calls Backend.ReapExpiredLeases, so a request
+// whose worker died mid-scan (pod crash, OOM kill) doesn't stay stuck
+// on a dead lease forever. When elector is set, only the replica
+// currently holding leadership actually reaps - every other replica's
+// tick is a no-op - so horizontally-scaled replicas don't all reap (and
+// log about) the same expired leases at once.
+type Reaper struct {
+	backend  Backend
+	elector  LeaderElector
+	interval time.Duration
+	logger   *logrus.Logger
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// NewReaper creates a Reaper that checks backend for expired leases
+// every interval, reaping only while elector reports this replica as
+// leader. Pass SingleLeader{} when running a single replica. Call Start
+// to begin.
+func NewReaper(backend Backend, elector LeaderElector, interval time.Duration, logger *logrus.Logger) *Reaper {
+	return &Reaper{
+		backend:  backend,
+		elector:  elector,
+		interval: interval,
+		logger:   logger,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the reap loop until Stop is called.
+func (r *Reaper) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !r.elector.IsLeader(ctx) {
+				continue
+			}
+			if _, err := r.backend.ReapExpiredLeases(ctx); err != nil {
+				r.logger.WithError(err).Warn("Failed to reap expired queue leases")
+			}
+		case <-ctx.Done():
+			return
+		case <-r.stopChan:
+			return
+		}
+	}
+}
+
+// Stop stops the reap loop.
+func (r *Reaper) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+	})
+}
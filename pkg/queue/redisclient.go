@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// goRedisClient adapts *redis.Client to the RedisClient interface
+// RedisDedupStore depends on, so the dedup store itself stays decoupled
+// from the concrete Redis library in use.
+type goRedisClient struct {
+	client *redis.Client
+}
+
+// newRedisClient connects to the Redis server at addr.
+func newRedisClient(addr string) (*goRedisClient, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &goRedisClient{client: client}, nil
+}
+
+// SetNX implements RedisClient.
+func (c *goRedisClient) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(context.Background(), key, value, ttl).Result()
+}
+
+// Exists implements RedisClient.
+func (c *goRedisClient) Exists(key string) (bool, error) {
+	n, err := c.client.Exists(context.Background(), key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Incr implements RedisClient.
+func (c *goRedisClient) Incr(key string) (int64, error) {
+	return c.client.Incr(context.Background(), key).Result()
+}
+
+// GetInt64 implements RedisClient.
+func (c *goRedisClient) GetInt64(key string) (int64, error) {
+	n, err := c.client.Get(context.Background(), key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
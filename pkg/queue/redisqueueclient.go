@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisQueueClient adapts *redis.Client to the RedisQueueClient
+// interface RedisBackend depends on, so the backend itself stays
+// decoupled from the concrete Redis library in use.
+type redisQueueClient struct {
+	client *redis.Client
+}
+
+// newRedisQueueClient connects to the Redis server at addr.
+func newRedisQueueClient(addr string) (*redisQueueClient, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &redisQueueClient{client: client}, nil
+}
+
+// LPush implements RedisQueueClient.
+func (c *redisQueueClient) LPush(key, value string) error {
+	return c.client.LPush(context.Background(), key, value).Err()
+}
+
+// BRPopLPush implements RedisQueueClient.
+func (c *redisQueueClient) BRPopLPush(src, dst string, timeout time.Duration) (string, bool, error) {
+	value, err := c.client.BRPopLPush(context.Background(), src, dst, timeout).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// LRem implements RedisQueueClient.
+func (c *redisQueueClient) LRem(key string, count int, value string) error {
+	return c.client.LRem(context.Background(), key, int64(count), value).Err()
+}
+
+// LLen implements RedisQueueClient.
+func (c *redisQueueClient) LLen(key string) (int64, error) {
+	return c.client.LLen(context.Background(), key).Result()
+}
+
+// ZAdd implements RedisQueueClient.
+func (c *redisQueueClient) ZAdd(key string, score float64, member string) error {
+	return c.client.ZAdd(context.Background(), key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// ZRangeByScoreLTE implements RedisQueueClient.
+func (c *redisQueueClient) ZRangeByScoreLTE(key string, max float64) ([]string, error) {
+	return c.client.ZRangeByScore(context.Background(), key, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatFloat(max, 'f', -1, 64),
+	}).Result()
+}
+
+// ZRem implements RedisQueueClient.
+func (c *redisQueueClient) ZRem(key string, member string) error {
+	return c.client.ZRem(context.Background(), key, member).Err()
+}
+
+// ZCard implements RedisQueueClient.
+func (c *redisQueueClient) ZCard(key string) (int64, error) {
+	return c.client.ZCard(context.Background(), key).Result()
+}
+
+// HSet implements RedisQueueClient.
+func (c *redisQueueClient) HSet(key, field, value string) error {
+	return c.client.HSet(context.Background(), key, field, value).Err()
+}
+
+// HGet implements RedisQueueClient.
+func (c *redisQueueClient) HGet(key, field string) (string, bool, error) {
+	value, err := c.client.HGet(context.Background(), key, field).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// HGetAll implements RedisQueueClient.
+func (c *redisQueueClient) HGetAll(key string) (map[string]string, error) {
+	return c.client.HGetAll(context.Background(), key).Result()
+}
+
+// HDel implements RedisQueueClient.
+func (c *redisQueueClient) HDel(key, field string) error {
+	return c.client.HDel(context.Background(), key, field).Err()
+}
+
+// HLen implements RedisQueueClient.
+func (c *redisQueueClient) HLen(key string) (int64, error) {
+	return c.client.HLen(context.Background(), key).Result()
+}
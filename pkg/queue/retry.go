@@ -1,49 +1,110 @@
 package queue
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
 )
 
 // RetryConfig holds retry logic configuration
 type RetryConfig struct {
-	MaxRetries      int
-	InitialBackoff  time.Duration
-	MaxBackoff      time.Duration
+	MaxRetries        int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
 	BackoffMultiplier float64
+	// UnauthorizedRetryWindow bounds how long after a request's
+	// FirstAttemptAt a 401/403 is treated as registry auth JWT clock
+	// skew (see ShouldRetry) rather than a permanent rejection.
+	UnauthorizedRetryWindow time.Duration
+	// JitterFraction randomizes calculateBackoff's result by up to
+	// +/-this fraction, so retries from a batch of requests that failed
+	// together don't all come back to life at the same instant and
+	// thunder against the dependency that just recovered. Zero (the
+	// zero value) disables jitter.
+	JitterFraction float64
 }
 
+// unauthorizedMaxRetries caps how many of the short clock-skew retries
+// ShouldRetry/ScheduleRetry hand out for a single request, regardless of
+// how much of UnauthorizedRetryWindow remains.
+const unauthorizedMaxRetries = 2
+
+// unauthorizedRetryDelay is the fixed delay used for the second
+// clock-skew retry; the first is immediate since the skew causing it is
+// usually gone by the time the request is re-leased.
+const unauthorizedRetryDelay = 2 * time.Second
+
 // DefaultRetryConfig returns default retry configuration
 func DefaultRetryConfig() RetryConfig {
 	return RetryConfig{
-		MaxRetries:      3,
-		InitialBackoff:  time.Second,
-		MaxBackoff:      time.Minute,
-		BackoffMultiplier: 2.0,
+		MaxRetries:              3,
+		InitialBackoff:          time.Second,
+		MaxBackoff:              time.Minute,
+		BackoffMultiplier:       2.0,
+		UnauthorizedRetryWindow: 30 * time.Second,
+		JitterFraction:          0.2,
+	}
+}
+
+// RetryConfigFromQueueConfig builds a RetryConfig from cfg's queue.*
+// retry fields, applied via applyDefaults so callers get
+// DefaultRetryConfig's values when cfg leaves them unset. The one
+// exception is JitterFraction: applyDefaults leaves an unset
+// queue.retry_jitter_fraction at zero, which disables jitter.
+func RetryConfigFromQueueConfig(cfg config.QueueConfig) (RetryConfig, error) {
+	initialBackoff, err := time.ParseDuration(cfg.RetryInitialBackoff)
+	if err != nil {
+		return RetryConfig{}, fmt.Errorf("invalid queue.retry_initial_backoff: %w", err)
+	}
+
+	maxBackoff, err := time.ParseDuration(cfg.RetryMaxBackoff)
+	if err != nil {
+		return RetryConfig{}, fmt.Errorf("invalid queue.retry_max_backoff: %w", err)
 	}
+
+	unauthorizedRetryWindow, err := time.ParseDuration(cfg.UnauthorizedRetryWindow)
+	if err != nil {
+		return RetryConfig{}, fmt.Errorf("invalid queue.unauthorized_retry_window: %w", err)
+	}
+
+	return RetryConfig{
+		MaxRetries:              cfg.MaxRetries,
+		InitialBackoff:          initialBackoff,
+		MaxBackoff:              maxBackoff,
+		BackoffMultiplier:       cfg.RetryBackoffMultiplier,
+		UnauthorizedRetryWindow: unauthorizedRetryWindow,
+		JitterFraction:          cfg.RetryJitterFraction,
+	}, nil
 }
 
 // RetryManager manages retry logic for failed scans
 type RetryManager struct {
-	config RetryConfig
-	queue  *ScanQueue
-	logger *logrus.Logger
+	config  RetryConfig
+	backend Backend
+	logger  *logrus.Logger
 }
 
 // NewRetryManager creates a new retry manager
-func NewRetryManager(config RetryConfig, queue *ScanQueue, logger *logrus.Logger) *RetryManager {
+func NewRetryManager(config RetryConfig, backend Backend, logger *logrus.Logger) *RetryManager {
 	return &RetryManager{
-		config: config,
-		queue:  queue,
-		logger: logger,
+		config:  config,
+		backend: backend,
+		logger:  logger,
 	}
 }
 
 // ShouldRetry determines if a scan should be retried based on the error and retry count
 func (rm *RetryManager) ShouldRetry(req *models.ScanRequest, err error) bool {
+	if isUnauthorizedError(err) && rm.withinUnauthorizedRetryWindow(req) {
+		return true
+	}
+
 	// Check if max retries exceeded
 	if req.RetryCount >= rm.config.MaxRetries {
 		rm.logger.WithFields(logrus.Fields{
@@ -67,6 +128,31 @@ func (rm *RetryManager) ShouldRetry(req *models.ScanRequest, err error) bool {
 	return true
 }
 
+// withinUnauthorizedRetryWindow reports whether req is still eligible
+// for one of the short clock-skew retries ScheduleRetry hands out for a
+// 401/403: its first attempt was less than UnauthorizedRetryWindow ago,
+// and it hasn't already used both of them.
+func (rm *RetryManager) withinUnauthorizedRetryWindow(req *models.ScanRequest) bool {
+	if req.RetryCount >= unauthorizedMaxRetries {
+		return false
+	}
+	if req.FirstAttemptAt.IsZero() {
+		return true
+	}
+
+	elapsed := time.Since(req.FirstAttemptAt)
+	withinWindow := elapsed < rm.config.UnauthorizedRetryWindow
+	if !withinWindow {
+		rm.logger.WithFields(logrus.Fields{
+			"image_ref":  req.ImageRef,
+			"request_id": req.RequestID,
+			"elapsed":    elapsed,
+		}).Warn("Unauthorized retry window exceeded, treating as permanent auth failure")
+	}
+
+	return withinWindow
+}
+
 // isRetriableError determines if an error should trigger a retry
 func (rm *RetryManager) isRetriableError(err error) bool {
 	if err == nil {
@@ -114,35 +200,72 @@ func (rm *RetryManager) isRetriableError(err error) bool {
 	return true
 }
 
-// ScheduleRetry schedules a scan request for retry with exponential backoff
-func (rm *RetryManager) ScheduleRetry(req *models.ScanRequest, err error) error {
+// isUnauthorizedError reports whether err looks like a 401/403 from a
+// registry or scanner API, the class of error ShouldRetry gives a
+// bounded number of clock-skew retries before treating as permanent.
+func isUnauthorizedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	errorMsg := err.Error()
+	return contains(errorMsg, "401") || contains(errorMsg, "403") ||
+		contains(errorMsg, "unauthorized") || contains(errorMsg, "forbidden")
+}
+
+// ScheduleRetry schedules a leased scan request for retry with
+// exponential backoff. Rather than blocking the caller for the backoff
+// duration, it persists the next-attempt timestamp in the backend via
+// Nack, so the request becomes eligible for Lease again once the
+// backoff elapses even if this pod restarts in the meantime.
+func (rm *RetryManager) ScheduleRetry(ctx context.Context, leaseID string, req *models.ScanRequest, scanErr error) error {
 	// Increment retry count
 	req.RetryCount++
 
-	// Calculate backoff duration
-	backoff := rm.calculateBackoff(req.RetryCount)
+	// Calculate backoff duration. A 401/403 within UnauthorizedRetryWindow
+	// gets its own short fixed delay instead of the exponential backoff,
+	// since it's suspected registry auth JWT clock skew rather than a
+	// slow-to-recover dependency.
+	var backoff time.Duration
+	if isUnauthorizedError(scanErr) {
+		backoff = rm.unauthorizedBackoff(req.RetryCount)
+	} else {
+		backoff = rm.calculateBackoff(req.RetryCount)
+	}
+	nextAttempt := time.Now().Add(backoff)
 
 	rm.logger.WithFields(logrus.Fields{
-		"image_ref":   req.ImageRef,
-		"request_id":  req.RequestID,
-		"retry_count": req.RetryCount,
-		"backoff":     backoff,
-		"error":       err.Error(),
+		"image_ref":    req.ImageRef,
+		"request_id":   req.RequestID,
+		"retry_count":  req.RetryCount,
+		"backoff":      backoff,
+		"next_attempt": nextAttempt,
+		"error":        scanErr.Error(),
 	}).Info("Scheduling scan retry")
 
-	// Wait for backoff duration
-	time.Sleep(backoff)
-
-	// Re-enqueue the request
-	ctx := time.Now().Add(30 * time.Second) // 30s timeout for enqueue
-	if err := rm.queue.Enqueue(contextWithDeadline(ctx), req); err != nil {
+	if err := rm.backend.Nack(ctx, leaseID, req, nextAttempt); err != nil {
 		return fmt.Errorf("failed to re-enqueue scan: %w", err)
 	}
 
+	metrics.RecordRetry(req.RegistryName)
+
 	return nil
 }
 
-// calculateBackoff calculates the backoff duration using exponential backoff
+// unauthorizedBackoff returns the fixed delay for a request's Nth
+// clock-skew retry: immediate for the first, since the skew has often
+// already passed by the time the request is re-leased, and a short
+// fixed delay for the second.
+func (rm *RetryManager) unauthorizedBackoff(retryCount int) time.Duration {
+	if retryCount <= 1 {
+		return 0
+	}
+	return unauthorizedRetryDelay
+}
+
+// calculateBackoff calculates the backoff duration using exponential
+// backoff, capped at MaxBackoff and then randomized by up to
+// +/-JitterFraction so a batch of requests that failed together don't
+// all retry at exactly the same instant.
 func (rm *RetryManager) calculateBackoff(retryCount int) time.Duration {
 	// Calculate exponential backoff: initialBackoff * (multiplier ^ retryCount)
 	backoff := float64(rm.config.InitialBackoff)
@@ -158,7 +281,20 @@ func (rm *RetryManager) calculateBackoff(retryCount int) time.Duration {
 		duration = rm.config.MaxBackoff
 	}
 
-	return duration
+	return applyJitter(duration, rm.config.JitterFraction)
+}
+
+// applyJitter randomizes duration by up to +/-fraction, e.g. fraction
+// 0.2 returns a value uniformly distributed in [0.8*duration,
+// 1.2*duration]. fraction <= 0 returns duration unchanged.
+func applyJitter(duration time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return duration
+	}
+
+	delta := float64(duration) * fraction
+	offset := (rand.Float64()*2 - 1) * delta
+	return time.Duration(float64(duration) + offset)
 }
 
 // GetBackoffDurations returns the backoff durations for each retry attempt
@@ -196,36 +332,3 @@ func toLower(s string) string {
 	}
 	return string(b)
 }
-
-// contextWithDeadline creates a simple context with deadline
-func contextWithDeadline(deadline time.Time) contextDeadline {
-	return contextDeadline{deadline: deadline}
-}
-
-type contextDeadline struct {
-	deadline time.Time
-}
-
-func (c contextDeadline) Deadline() (time.Time, bool) {
-	return c.deadline, true
-}
-
-func (c contextDeadline) Done() <-chan struct{} {
-	ch := make(chan struct{})
-	go func() {
-		time.Sleep(time.Until(c.deadline))
-		close(ch)
-	}()
-	return ch
-}
-
-func (c contextDeadline) Err() error {
-	if time.Now().After(c.deadline) {
-		return fmt.Errorf("deadline exceeded")
-	}
-	return nil
-}
-
-func (c contextDeadline) Value(key interface{}) interface{} {
-	return nil
-}
@@ -1,7 +1,9 @@
 package queue
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"testing"
 	"time"
 
@@ -20,8 +22,8 @@ func TestRetryManager_ShouldRetry(t *testing.T) {
 	logger := logrus.New()
 	logger.SetOutput(io.Discard)
 
-	queue := NewScanQueue(100, logger)
-	rm := NewRetryManager(config, queue, logger)
+	backend := NewMemoryBackend(100, logger)
+	rm := NewRetryManager(config, backend, logger)
 
 	tests := []struct {
 		name        string
@@ -77,6 +79,99 @@ func TestRetryManager_ShouldRetry(t *testing.T) {
 	}
 }
 
+func TestRetryManager_ShouldRetry_UnauthorizedWindow(t *testing.T) {
+	config := RetryConfig{
+		MaxRetries:              3,
+		InitialBackoff:          time.Second,
+		MaxBackoff:              time.Minute,
+		BackoffMultiplier:       2.0,
+		UnauthorizedRetryWindow: 30 * time.Second,
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	backend := NewMemoryBackend(100, logger)
+	rm := NewRetryManager(config, backend, logger)
+
+	tests := []struct {
+		name           string
+		retryCount     int
+		firstAttemptAt time.Time
+		wantRetry      bool
+	}{
+		{
+			name:           "first 401, within window",
+			retryCount:     0,
+			firstAttemptAt: time.Now().Add(-time.Second),
+			wantRetry:      true,
+		},
+		{
+			name:           "second 401, still within window",
+			retryCount:     1,
+			firstAttemptAt: time.Now().Add(-5 * time.Second),
+			wantRetry:      true,
+		},
+		{
+			name:           "third 401, clock-skew retries exhausted",
+			retryCount:     unauthorizedMaxRetries,
+			firstAttemptAt: time.Now().Add(-5 * time.Second),
+			wantRetry:      false,
+		},
+		{
+			name:           "401 persists past window",
+			retryCount:     1,
+			firstAttemptAt: time.Now().Add(-time.Minute),
+			wantRetry:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.ScanRequest{
+				ImageRef:       "test:latest",
+				RetryCount:     tt.retryCount,
+				FirstAttemptAt: tt.firstAttemptAt,
+			}
+
+			got := rm.ShouldRetry(req, fmt.Errorf("401 unauthorized"))
+
+			if got != tt.wantRetry {
+				t.Errorf("ShouldRetry() = %v, want %v", got, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestRetryManager_ScheduleRetry_Unauthorized(t *testing.T) {
+	config := DefaultRetryConfig()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	backend := NewMemoryBackend(100, logger)
+	rm := NewRetryManager(config, backend, logger)
+
+	req := &models.ScanRequest{
+		ImageRef:       "test:latest",
+		RequestID:      "req-1",
+		FirstAttemptAt: time.Now(),
+	}
+
+	if err := rm.ScheduleRetry(context.Background(), "lease-1", req, fmt.Errorf("401 unauthorized")); err != nil {
+		t.Fatalf("ScheduleRetry() error = %v", err)
+	}
+	if req.RetryCount != 1 {
+		t.Fatalf("RetryCount after first unauthorized retry = %d, want 1", req.RetryCount)
+	}
+
+	if err := rm.ScheduleRetry(context.Background(), "lease-1", req, fmt.Errorf("401 unauthorized")); err != nil {
+		t.Fatalf("ScheduleRetry() error = %v", err)
+	}
+	if req.RetryCount != 2 {
+		t.Fatalf("RetryCount after second unauthorized retry = %d, want 2", req.RetryCount)
+	}
+}
+
 func TestRetryManager_calculateBackoff(t *testing.T) {
 	config := RetryConfig{
 		MaxRetries:        5,
@@ -86,8 +181,8 @@ func TestRetryManager_calculateBackoff(t *testing.T) {
 	}
 
 	logger := logrus.New()
-	queue := NewScanQueue(100, logger)
-	rm := NewRetryManager(config, queue, logger)
+	backend := NewMemoryBackend(100, logger)
+	rm := NewRetryManager(config, backend, logger)
 
 	tests := []struct {
 		name       string
@@ -135,8 +230,8 @@ func TestRetryManager_calculateBackoff(t *testing.T) {
 func TestRetryManager_isRetriableError(t *testing.T) {
 	config := DefaultRetryConfig()
 	logger := logrus.New()
-	queue := NewScanQueue(100, logger)
-	rm := NewRetryManager(config, queue, logger)
+	backend := NewMemoryBackend(100, logger)
+	rm := NewRetryManager(config, backend, logger)
 
 	tests := []struct {
 		name  string
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -15,15 +16,16 @@ type ScanHandler func(ctx context.Context, req *models.ScanRequest) error
 
 // WorkerPool manages a pool of worker goroutines that process scan requests
 type WorkerPool struct {
-	queue       *ScanQueue
-	workers     int
-	handler     ScanHandler
-	logger      *logrus.Logger
-	wg          sync.WaitGroup
-	ctx         context.Context
-	cancel      context.CancelFunc
-	stopOnce    sync.Once
-	inFlight    int64 // atomic counter for in-flight scans
+	queue              *ScanQueue
+	workers            int
+	handler            ScanHandler
+	logger             *logrus.Logger
+	wg                 sync.WaitGroup
+	ctx                context.Context
+	cancel             context.CancelFunc
+	stopOnce           sync.Once
+	inFlight           int64 // atomic counter for in-flight scans
+	lastQueueWaitNanos int64 // atomic: most recently observed enqueue-to-dequeue latency
 }
 
 // NewWorkerPool creates a new worker pool
@@ -116,6 +118,14 @@ func (wp *WorkerPool) worker(id int) {
 
 // processScan processes a single scan request with error handling and recovery
 func (wp *WorkerPool) processScan(logger *logrus.Logger, req *models.ScanRequest) {
+	if !req.QueuedAt.IsZero() {
+		atomic.StoreInt64(&wp.lastQueueWaitNanos, int64(time.Since(req.QueuedAt)))
+	}
+
+	atomic.AddInt64(&wp.inFlight, 1)
+	defer atomic.AddInt64(&wp.inFlight, -1)
+	defer wp.queue.Release(req)
+
 	// Recover from panics in scan handler
 	defer func() {
 		if r := recover(); r != nil {
@@ -150,12 +160,18 @@ func (wp *WorkerPool) processScan(logger *logrus.Logger, req *models.ScanRequest
 	}
 }
 
-// Stats returns worker pool statistics
+// Stats returns worker pool statistics, including the underlying
+// ScanQueue's per-registry saturation and the most recently observed
+// enqueue-to-dequeue wait, so an operator can tell a slow registry
+// apart from a generally overloaded pool.
 func (wp *WorkerPool) Stats() WorkerPoolStats {
+	queueStats := wp.queue.Stats()
 	return WorkerPoolStats{
-		Workers:    wp.workers,
-		InFlight:   0, // TODO: track in-flight count
-		QueueDepth: wp.queue.Depth(),
+		Workers:            wp.workers,
+		InFlight:           int(atomic.LoadInt64(&wp.inFlight)),
+		QueueDepth:         queueStats.Depth,
+		QueueWaitSeconds:   time.Duration(atomic.LoadInt64(&wp.lastQueueWaitNanos)).Seconds(),
+		RegistrySaturation: queueStats.RegistrySaturation,
 	}
 }
 
@@ -164,4 +180,13 @@ type WorkerPoolStats struct {
 	Workers    int
 	InFlight   int
 	QueueDepth int
+	// QueueWaitSeconds is the most recently observed span between a
+	// request's QueuedAt and its dispatch to a worker, not an average;
+	// it's a cheap, always-fresh signal of current queueing latency
+	// rather than a full histogram.
+	QueueWaitSeconds float64
+	// RegistrySaturation mirrors QueueStats.RegistrySaturation: each
+	// registry's current in-flight count against its configured
+	// MaxConcurrent limit.
+	RegistrySaturation map[string]RegistrySaturation
 }
@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+func TestWorkerPool_StatsReflectsInFlightAndQueueDepth(t *testing.T) {
+	q := NewScanQueue(10, nil, testLogger())
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	pool := NewWorkerPool(q, 1, func(ctx context.Context, req *models.ScanRequest) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, testLogger())
+	pool.Start()
+	defer func() {
+		close(release)
+		pool.Stop(time.Second)
+	}()
+
+	if err := q.Enqueue(context.Background(), &models.ScanRequest{RequestID: "a"}, PriorityNormal); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("handler never started")
+	}
+
+	if stats := pool.Stats(); stats.InFlight != 1 {
+		t.Errorf("Stats().InFlight = %d, want 1 while the handler is blocked", stats.InFlight)
+	}
+}
+
+func TestWorkerPool_ReleasesRegistrySlotAfterHandlerCompletes(t *testing.T) {
+	q := NewScanQueue(10, map[string]int{"registry": 1}, testLogger())
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	pool := NewWorkerPool(q, 2, func(ctx context.Context, req *models.ScanRequest) error {
+		wg.Done()
+		return nil
+	}, testLogger())
+	pool.Start()
+	defer pool.Stop(time.Second)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := q.Enqueue(ctx, &models.ScanRequest{RequestID: "req", RegistryName: "registry"}, PriorityNormal); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("registry's saturated second request never got dispatched after the first released its slot")
+	}
+}
@@ -0,0 +1,45 @@
+package reconciler
+
+import (
+	"strings"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/registryauth"
+)
+
+// credentialStoreFor adapts a registry's optional PullCredentials into
+// the registryauth.CredentialStore the Bearer/Basic auth handlers need.
+// A nil creds yields an empty store, matching an unauthenticated catalog
+// call.
+func credentialStoreFor(creds *config.RegistryAuthConfig) registryauth.CredentialStore {
+	if creds == nil {
+		return &registryauth.StaticCredentialStore{}
+	}
+	return &registryauth.StaticCredentialStore{
+		Username:      creds.Username,
+		Password:      creds.Password,
+		IdentityToken: creds.IdentityToken,
+	}
+}
+
+// parseNextLink extracts the "next" relation target from a Distribution
+// API Link header (RFC 5988), e.g.
+// `</v2/_catalog?last=abc&n=100>; rel="next"`, resolving a path-only
+// value against baseURL. Returns "" if header is empty or has no "next"
+// relation, signaling the caller has reached the last page.
+func parseNextLink(baseURL, header string) string {
+	if header == "" {
+		return ""
+	}
+
+	parts := strings.SplitN(header, ";", 2)
+	if len(parts) != 2 || !strings.Contains(parts[1], `rel="next"`) {
+		return ""
+	}
+
+	link := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+	if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+		return link
+	}
+	return baseURL + link
+}
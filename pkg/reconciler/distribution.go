@@ -0,0 +1,117 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/registryauth"
+)
+
+func init() {
+	for _, registryType := range []string{"distribution", "quay", "ghcr", "gitlab"} {
+		Default().Register(registryType, NewDistributionLister)
+	}
+}
+
+// DistributionLister walks the catalog of any registry implementing the
+// Docker Registry HTTP API V2 (GET /v2/_catalog, GET
+// /v2/<name>/tags/list), which in practice covers plain Distribution,
+// Quay, GHCR, and GitLab's container registry.
+type DistributionLister struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewDistributionLister creates a DistributionLister for cfg, registered
+// as a ListerFactory for every registry type above.
+func NewDistributionLister(cfg config.RegistryConfig) (CatalogLister, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("registry %s: url is required for catalog polling", cfg.Name)
+	}
+
+	creds := credentialStoreFor(cfg.PullCredentials)
+	transport := registryauth.NewTransport(
+		http.DefaultTransport,
+		registryauth.NewChallengeManager(),
+		registryauth.NewTokenHandler(http.DefaultTransport, creds, "registry:catalog:*"),
+		registryauth.NewBasicHandler(creds),
+	)
+
+	return &DistributionLister{
+		baseURL: strings.TrimSuffix(cfg.URL, "/"),
+		client:  &http.Client{Transport: transport, Timeout: 15 * time.Second},
+	}, nil
+}
+
+// ListRepositories implements CatalogLister via GET /v2/_catalog,
+// following Link-header pagination until exhausted.
+func (l *DistributionLister) ListRepositories(ctx context.Context) ([]string, error) {
+	var repos []string
+	next := l.baseURL + "/v2/_catalog?n=100"
+
+	for next != "" {
+		var page struct {
+			Repositories []string `json:"repositories"`
+		}
+		link, err := l.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("list repositories: %w", err)
+		}
+		repos = append(repos, page.Repositories...)
+		next = link
+	}
+
+	return repos, nil
+}
+
+// ListTags implements CatalogLister via GET /v2/<name>/tags/list,
+// following Link-header pagination until exhausted.
+func (l *DistributionLister) ListTags(ctx context.Context, repository string) ([]string, error) {
+	var tags []string
+	next := fmt.Sprintf("%s/v2/%s/tags/list?n=100", l.baseURL, repository)
+
+	for next != "" {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		link, err := l.getJSON(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("list tags for %s: %w", repository, err)
+		}
+		tags = append(tags, page.Tags...)
+		next = link
+	}
+
+	return tags, nil
+}
+
+// getJSON issues a GET against rawURL, decodes the JSON response body
+// into out, and returns the next page's URL from the response's Link
+// header, or "" once the last page is reached.
+func (l *DistributionLister) getJSON(ctx context.Context, rawURL string, out interface{}) (string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := l.client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d from %s", resp.StatusCode, rawURL)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return "", fmt.Errorf("decode response: %w", err)
+	}
+
+	return parseNextLink(l.baseURL, resp.Header.Get("Link")), nil
+}
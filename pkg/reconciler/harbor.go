@@ -0,0 +1,170 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func init() {
+	Default().Register("harbor", NewHarborLister)
+}
+
+// harborPageSize is the page_size sent to Harbor's v2.0 API; a response
+// page shorter than this signals the last page.
+const harborPageSize = 100
+
+// HarborLister walks Harbor's own Projects/Repositories/Artifacts API
+// (/api/v2.0/...) - the same catalog Harbor's native replication adapter
+// walks - rather than Harbor's Distribution-compatible /v2/ endpoint,
+// which only exposes one project's repositories at a time and has no
+// notion of "list every project".
+type HarborLister struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewHarborLister creates a HarborLister for cfg, registered as the
+// ListerFactory for registry type "harbor". Harbor's API uses plain HTTP
+// Basic auth (typically a robot account), not the Bearer challenge dance
+// pkg/scanner/registryauth implements for pulling blobs/manifests.
+func NewHarborLister(cfg config.RegistryConfig) (CatalogLister, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("registry %s: url is required for catalog polling", cfg.Name)
+	}
+
+	l := &HarborLister{
+		baseURL: strings.TrimSuffix(cfg.URL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+	if cfg.PullCredentials != nil {
+		l.username = cfg.PullCredentials.Username
+		l.password = cfg.PullCredentials.Password
+	}
+	return l, nil
+}
+
+// ListRepositories returns every repository, as "project/repo", across
+// every project visible to these credentials.
+func (l *HarborLister) ListRepositories(ctx context.Context) ([]string, error) {
+	var repos []string
+
+	for page := 1; ; page++ {
+		var projects []struct {
+			Name string `json:"name"`
+		}
+		u := fmt.Sprintf("%s/api/v2.0/projects?page=%d&page_size=%d", l.baseURL, page, harborPageSize)
+		if err := l.getJSON(ctx, u, &projects); err != nil {
+			return nil, fmt.Errorf("list projects: %w", err)
+		}
+
+		for _, p := range projects {
+			projectRepos, err := l.listProjectRepositories(ctx, p.Name)
+			if err != nil {
+				return nil, err
+			}
+			repos = append(repos, projectRepos...)
+		}
+
+		if len(projects) < harborPageSize {
+			break
+		}
+	}
+
+	return repos, nil
+}
+
+// listProjectRepositories returns every repository (as "project/repo")
+// within a single Harbor project.
+func (l *HarborLister) listProjectRepositories(ctx context.Context, project string) ([]string, error) {
+	var repos []string
+
+	for page := 1; ; page++ {
+		var repositories []struct {
+			Name string `json:"name"`
+		}
+		u := fmt.Sprintf("%s/api/v2.0/projects/%s/repositories?page=%d&page_size=%d", l.baseURL, url.PathEscape(project), page, harborPageSize)
+		if err := l.getJSON(ctx, u, &repositories); err != nil {
+			return nil, fmt.Errorf("list repositories for project %s: %w", project, err)
+		}
+
+		for _, r := range repositories {
+			repos = append(repos, r.Name)
+		}
+
+		if len(repositories) < harborPageSize {
+			break
+		}
+	}
+
+	return repos, nil
+}
+
+// ListTags returns every tag on every artifact in repository (a
+// "project/repo" path, as returned by ListRepositories).
+func (l *HarborLister) ListTags(ctx context.Context, repository string) ([]string, error) {
+	project, repoName, ok := strings.Cut(repository, "/")
+	if !ok {
+		return nil, fmt.Errorf("repository %q is not in \"project/repo\" form", repository)
+	}
+
+	var tags []string
+
+	for page := 1; ; page++ {
+		var artifacts []struct {
+			Tags []struct {
+				Name string `json:"name"`
+			} `json:"tags"`
+		}
+		u := fmt.Sprintf("%s/api/v2.0/projects/%s/repositories/%s/artifacts?page=%d&page_size=%d&with_tag=true",
+			l.baseURL, url.PathEscape(project), url.PathEscape(repoName), page, harborPageSize)
+		if err := l.getJSON(ctx, u, &artifacts); err != nil {
+			return nil, fmt.Errorf("list artifacts for %s: %w", repository, err)
+		}
+
+		for _, a := range artifacts {
+			for _, t := range a.Tags {
+				tags = append(tags, t.Name)
+			}
+		}
+
+		if len(artifacts) < harborPageSize {
+			break
+		}
+	}
+
+	return tags, nil
+}
+
+// getJSON issues a GET against rawURL, authenticated with Basic auth
+// when credentials are configured, and decodes the JSON response body
+// into out.
+func (l *HarborLister) getJSON(ctx context.Context, rawURL string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	if l.username != "" {
+		httpReq.SetBasicAuth(l.username, l.password)
+	}
+
+	resp, err := l.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, rawURL)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
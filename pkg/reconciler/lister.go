@@ -0,0 +1,92 @@
+// Package reconciler periodically walks a registry's own catalog
+// directly, the way Harbor's native replication adapter does, rather
+// than relying solely on the registry to deliver a webhook for every
+// push. This covers registries whose webhooks are unreliable or were
+// never configured.
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// CatalogLister walks a single registry's repository/tag catalog.
+type CatalogLister interface {
+	// ListRepositories returns every repository path currently in the
+	// registry's catalog (e.g. "team/app").
+	ListRepositories(ctx context.Context) ([]string, error)
+	// ListTags returns every tag pushed to repository.
+	ListTags(ctx context.Context, repository string) ([]string, error)
+}
+
+// ListerFactory constructs a CatalogLister from a registry's
+// configuration. Implementations register themselves against a registry
+// type with Register, mirroring pkg/webhook/parsers' ParserFactory
+// registration.
+type ListerFactory func(cfg config.RegistryConfig) (CatalogLister, error)
+
+// Registry holds one ListerFactory per registry type.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]ListerFactory
+}
+
+// defaultRegistry is the process-wide Registry built-in listers register
+// themselves into via their own init(), the same convention
+// pkg/webhook/parsers uses for ParserFactory.
+var defaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]ListerFactory)}
+}
+
+// Default returns the process-wide Registry every built-in lister
+// registers itself against.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds factory under registryType to r. Panics on a duplicate
+// registryType, since that indicates two listers registering for the
+// same type, not a runtime condition a caller can recover from.
+func (r *Registry) Register(registryType string, factory ListerFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[registryType]; exists {
+		panic(fmt.Sprintf("reconciler: factory already registered for registry type %q", registryType))
+	}
+	r.factories[registryType] = factory
+}
+
+// New builds a CatalogLister for cfg using the factory registered for
+// cfg.Type, or an error if that registry type doesn't support reconciler
+// polling.
+func (r *Registry) New(cfg config.RegistryConfig) (CatalogLister, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[cfg.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("registry type %q does not support reconciler polling", cfg.Type)
+	}
+	return factory(cfg)
+}
+
+// Types returns the registered registry-type strings in sorted order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.factories))
+	for t := range r.factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
@@ -0,0 +1,70 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+type fakeLister struct{}
+
+func (fakeLister) ListRepositories(ctx context.Context) ([]string, error) { return nil, nil }
+func (fakeLister) ListTags(ctx context.Context, repository string) ([]string, error) {
+	return nil, nil
+}
+
+func TestRegistry_NewAndTypes(t *testing.T) {
+	r := NewRegistry()
+	r.Register("fake", func(cfg config.RegistryConfig) (CatalogLister, error) {
+		return fakeLister{}, nil
+	})
+
+	lister, err := r.New(config.RegistryConfig{Type: "fake"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if lister == nil {
+		t.Fatal("New() returned nil lister")
+	}
+
+	if got := r.Types(); len(got) != 1 || got[0] != "fake" {
+		t.Errorf("Types() = %v, want [fake]", got)
+	}
+}
+
+func TestRegistry_New_Unregistered(t *testing.T) {
+	r := NewRegistry()
+
+	if _, err := r.New(config.RegistryConfig{Type: "nonexistent"}); err == nil {
+		t.Error("New() expected error for unregistered registry type, got nil")
+	}
+}
+
+func TestRegistry_Register_DuplicatePanics(t *testing.T) {
+	r := NewRegistry()
+	r.Register("fake", func(config.RegistryConfig) (CatalogLister, error) { return nil, nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() expected panic on duplicate registry type, got none")
+		}
+	}()
+	r.Register("fake", func(config.RegistryConfig) (CatalogLister, error) { return nil, nil })
+}
+
+func TestDefaultRegistry_HasBuiltinListers(t *testing.T) {
+	want := []string{"distribution", "ghcr", "gitlab", "harbor", "quay"}
+
+	got := Default().Types()
+	gotSet := make(map[string]bool, len(got))
+	for _, t := range got {
+		gotSet[t] = true
+	}
+
+	for _, registryType := range want {
+		if !gotSet[registryType] {
+			t.Errorf("Default().Types() missing %q, got %v", registryType, got)
+		}
+	}
+}
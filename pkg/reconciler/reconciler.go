@@ -0,0 +1,185 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/queue"
+)
+
+// DefaultPollInterval is used by a registry that opts into polling but
+// leaves both RegistryConfig.PollInterval and
+// config.ReconcilerConfig.PollInterval unset.
+const DefaultPollInterval = 15 * time.Minute
+
+// Reconciler periodically walks every configured registry whose
+// RegistryConfig.PullMode is "poll" or "both", synthesizing a
+// models.ScanRequest for each repository/tag pair and enqueuing it the
+// same way webhook.Server's handleWebhook does, skipping anything the
+// shared dedup store already marked as seen recently via the webhook
+// path (or an earlier poll).
+type Reconciler struct {
+	logger     *logrus.Logger
+	backend    queue.Backend
+	dedup      *queue.StoreBackedDeduplicator
+	registries []registryTarget
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+// registryTarget pairs a polled registry's configuration with its
+// resolved CatalogLister and effective poll interval.
+type registryTarget struct {
+	config.RegistryConfig
+	lister       CatalogLister
+	pollInterval time.Duration
+}
+
+// NewReconciler builds a Reconciler for every registry in cfg.Registries
+// that opts into polling (see config.RegistryConfig.PollsCatalog) and
+// whose type has a registered CatalogLister. A registry of an
+// unsupported type is skipped with a warning, mirroring
+// webhook.NewDispatcher's "skip unknown registry types" behavior.
+func NewReconciler(cfg *config.Config, backend queue.Backend, dedup *queue.StoreBackedDeduplicator, logger *logrus.Logger) *Reconciler {
+	defaultInterval := DefaultPollInterval
+	if cfg.Reconciler.PollInterval != "" {
+		if d, err := time.ParseDuration(cfg.Reconciler.PollInterval); err == nil {
+			defaultInterval = d
+		}
+	}
+
+	var targets []registryTarget
+	for _, reg := range cfg.Registries {
+		if !reg.PollsCatalog() {
+			continue
+		}
+
+		lister, err := Default().New(reg)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"registry": reg.Name,
+				"type":     reg.Type,
+			}).WithError(err).Warn("Reconciler: skipping registry, catalog polling unsupported for this type")
+			continue
+		}
+
+		interval := defaultInterval
+		if reg.PollInterval != "" {
+			if d, err := time.ParseDuration(reg.PollInterval); err == nil {
+				interval = d
+			}
+		}
+
+		targets = append(targets, registryTarget{RegistryConfig: reg, lister: lister, pollInterval: interval})
+	}
+
+	return &Reconciler{
+		logger:     logger,
+		backend:    backend,
+		dedup:      dedup,
+		registries: targets,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start begins one catalog poll loop per configured registry, each on
+// its own ticker, and returns immediately. Call Stop to end the loops.
+func (r *Reconciler) Start(ctx context.Context) {
+	for _, target := range r.registries {
+		r.wg.Add(1)
+		go r.pollLoop(ctx, target)
+	}
+}
+
+// Stop ends every poll loop and waits for any in-flight catalog walk to
+// finish.
+func (r *Reconciler) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+func (r *Reconciler) pollLoop(ctx context.Context, target registryTarget) {
+	defer r.wg.Done()
+
+	logger := r.logger.WithFields(logrus.Fields{"registry": target.Name, "type": target.Type})
+	logger.WithField("poll_interval", target.pollInterval).Info("Reconciler: starting catalog poll loop")
+
+	ticker := time.NewTicker(target.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.reconcileOnce(ctx, target, logger); err != nil {
+				logger.WithError(err).Warn("Reconciler: catalog walk failed")
+			}
+		}
+	}
+}
+
+// reconcileOnce walks target's catalog once, enqueuing a scan for every
+// repository/tag the dedup store hasn't already marked as seen.
+func (r *Reconciler) reconcileOnce(ctx context.Context, target registryTarget, logger *logrus.Entry) error {
+	repositories, err := target.lister.ListRepositories(ctx)
+	if err != nil {
+		return fmt.Errorf("list repositories: %w", err)
+	}
+
+	enqueued := 0
+	for _, repository := range repositories {
+		tags, err := target.lister.ListTags(ctx, repository)
+		if err != nil {
+			logger.WithError(err).WithField("repository", repository).Warn("Reconciler: failed to list tags, skipping repository")
+			continue
+		}
+
+		for _, tag := range tags {
+			req := synthesizeScanRequest(target.RegistryConfig, repository, tag)
+
+			if r.dedup != nil && r.dedup.IsDuplicate(req) {
+				continue
+			}
+
+			if err := r.backend.Enqueue(ctx, req, time.Time{}); err != nil {
+				logger.WithError(err).WithField("image_ref", req.ImageRef).Warn("Reconciler: failed to enqueue scan request")
+				continue
+			}
+			enqueued++
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"repositories": len(repositories),
+		"enqueued":     enqueued,
+	}).Info("Reconciler: catalog walk complete")
+	return nil
+}
+
+// synthesizeScanRequest builds the same shape of models.ScanRequest a
+// webhook parser would, so reconciler-discovered images flow through
+// verification, scanning, and result sinks identically to webhook-driven
+// ones.
+func synthesizeScanRequest(reg config.RegistryConfig, repository, tag string) *models.ScanRequest {
+	host := strings.TrimPrefix(strings.TrimPrefix(reg.URL, "https://"), "http://")
+
+	return &models.ScanRequest{
+		ImageRef:     fmt.Sprintf("%s/%s:%s", host, repository, tag),
+		RegistryName: reg.Name,
+		Registry:     host,
+		Repository:   repository,
+		Tag:          tag,
+		ReceivedAt:   time.Now(),
+		RequestID:    fmt.Sprintf("reconciler-%s-%s-%s", reg.Name, repository, tag),
+	}
+}
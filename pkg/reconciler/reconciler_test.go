@@ -0,0 +1,112 @@
+package reconciler
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/queue"
+)
+
+type stubLister struct {
+	repositories []string
+	tags         map[string][]string
+}
+
+func (s stubLister) ListRepositories(ctx context.Context) ([]string, error) {
+	return s.repositories, nil
+}
+
+func (s stubLister) ListTags(ctx context.Context, repository string) ([]string, error) {
+	return s.tags[repository], nil
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestNewReconciler_SkipsRegistriesNotPolling(t *testing.T) {
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{Name: "webhook-only", Type: "harbor", PullMode: config.PullModeWebhook},
+			{Name: "polled", Type: "harbor", PullMode: config.PullModePoll, URL: "https://harbor.example.com"},
+		},
+	}
+
+	r := NewReconciler(cfg, queue.NewMemoryBackend(10, testLogger()), nil, testLogger())
+
+	if len(r.registries) != 1 {
+		t.Fatalf("len(registries) = %d, want 1", len(r.registries))
+	}
+	if r.registries[0].Name != "polled" {
+		t.Errorf("registries[0].Name = %q, want %q", r.registries[0].Name, "polled")
+	}
+}
+
+func TestNewReconciler_SkipsUnsupportedType(t *testing.T) {
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{Name: "unsupported", Type: "ecr", PullMode: config.PullModePoll},
+		},
+	}
+
+	r := NewReconciler(cfg, queue.NewMemoryBackend(10, testLogger()), nil, testLogger())
+
+	if len(r.registries) != 0 {
+		t.Errorf("len(registries) = %d, want 0 for an unsupported registry type", len(r.registries))
+	}
+}
+
+func TestReconcileOnce_EnqueuesEveryRepositoryTag(t *testing.T) {
+	backend := queue.NewMemoryBackend(10, testLogger())
+	r := &Reconciler{logger: testLogger(), backend: backend, stop: make(chan struct{})}
+
+	target := registryTarget{
+		RegistryConfig: config.RegistryConfig{Name: "test-registry", URL: "https://registry.example.com"},
+		lister: stubLister{
+			repositories: []string{"team/app"},
+			tags:         map[string][]string{"team/app": {"v1", "v2"}},
+		},
+		pollInterval: time.Minute,
+	}
+
+	if err := r.reconcileOnce(context.Background(), target, testLogger().WithField("test", true)); err != nil {
+		t.Fatalf("reconcileOnce() error = %v", err)
+	}
+
+	if got := backend.Depth(); got != 2 {
+		t.Errorf("backend has %d queued requests, want 2", got)
+	}
+}
+
+func TestReconcileOnce_SkipsDuplicates(t *testing.T) {
+	backend := queue.NewMemoryBackend(10, testLogger())
+	dedup := queue.NewStoreBackedDeduplicator(queue.NewMemoryDedupStore(time.Minute), time.Hour, testLogger())
+	r := &Reconciler{logger: testLogger(), backend: backend, dedup: dedup, stop: make(chan struct{})}
+
+	target := registryTarget{
+		RegistryConfig: config.RegistryConfig{Name: "test-registry", URL: "https://registry.example.com"},
+		lister: stubLister{
+			repositories: []string{"team/app"},
+			tags:         map[string][]string{"team/app": {"v1"}},
+		},
+		pollInterval: time.Minute,
+	}
+
+	logger := testLogger().WithField("test", true)
+	if err := r.reconcileOnce(context.Background(), target, logger); err != nil {
+		t.Fatalf("reconcileOnce() first call error = %v", err)
+	}
+	if err := r.reconcileOnce(context.Background(), target, logger); err != nil {
+		t.Fatalf("reconcileOnce() second call error = %v", err)
+	}
+
+	if got := backend.Depth(); got != 1 {
+		t.Errorf("backend has %d queued requests after a repeated walk, want 1 (duplicate skipped)", got)
+	}
+}
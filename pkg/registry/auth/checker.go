@@ -0,0 +1,150 @@
+// Package auth validates that an image referenced by a webhook-triggered
+// scan request is actually pullable from its registry before the scan is
+// enqueued. It performs the same Docker Registry HTTP API v2 challenge/
+// token dance as pkg/scanner/registryauth's pre-flight digest resolution,
+// reusing that package's ChallengeManager, TokenHandler, and BasicHandler
+// rather than re-parsing WWW-Authenticate challenges itself.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/registryauth"
+)
+
+// manifestAcceptHeader lists the manifest media types the checker accepts
+// when HEADing an image: the two single-image manifest types that
+// registry_scanner.go's resolveImageDigest also requests. Manifest lists
+// and OCI image indexes, which resolveImageDigest additionally accepts,
+// are not listed.
+const manifestAcceptHeader = "application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.manifest.v1+json"
+
+// Checker validates image pullability against a registry by performing an
+// unauthenticated GET /v2/ ping, solving whatever WWW-Authenticate
+// challenge comes back (bearer or basic) using credentials from a
+// CredentialSource, and then HEADing the image manifest. A Checker's
+// authenticated clients are cached per (registry, scope), so repeated
+// checks against the same repository reuse a cached bearer token instead
+// of re-solving the challenge every time.
+type Checker struct {
+	creds CredentialSource
+
+	clientsMu sync.Mutex
+	clients   map[string]*http.Client
+}
+
+// NewChecker creates a Checker that resolves registry credentials through
+// creds.
+func NewChecker(creds CredentialSource) *Checker {
+	return &Checker{
+		creds:   creds,
+		clients: make(map[string]*http.Client),
+	}
+}
+
+// CheckPullable confirms that req's image can be pulled from its
+// registry, returning an error describing why it can't (unreachable
+// registry, failed auth challenge, missing image) otherwise.
+func (c *Checker) CheckPullable(ctx context.Context, req *models.ScanRequest) error {
+	if req.Registry == "" {
+		return fmt.Errorf("scan request for %s has no registry host", req.ImageRef)
+	}
+	if req.Repository == "" {
+		return fmt.Errorf("scan request for %s has no repository", req.ImageRef)
+	}
+
+	client := c.client(req)
+
+	if err := c.ping(ctx, client, req.Registry); err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://%s/v2/%s/manifests/%s", req.Registry, req.Repository, manifestReference(req))
+	manifestReq, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build manifest request: %w", err)
+	}
+	manifestReq.Header.Set("Accept", manifestAcceptHeader)
+
+	resp, err := client.Do(manifestReq)
+	if err != nil {
+		return fmt.Errorf("failed to reach registry %s: %w", req.Registry, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("image %s is not pullable: registry returned status %d", req.ImageRef, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ping performs the unauthenticated GET /v2/ request that bootstraps the
+// auth dance: if the registry requires auth, this is what the Transport's
+// ChallengeManager learns the WWW-Authenticate challenge from, so
+// subsequent requests to host (including the manifest HEAD right after
+// it) are authorized pre-emptively instead of needing their own 401
+// round-trip.
+func (c *Checker) ping(ctx context.Context, client *http.Client, host string) error {
+	pingReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/v2/", host), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build registry ping request: %w", err)
+	}
+
+	resp, err := client.Do(pingReq)
+	if err != nil {
+		return fmt.Errorf("failed to reach registry %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry %s rejected authentication: status %d", host, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// client returns an http.Client that transparently authenticates against
+// req's registry, scoped to pull access on req's repository, reusing any
+// cached bearer token across calls for the same (registry, scope) pair.
+func (c *Checker) client(req *models.ScanRequest) *http.Client {
+	key := req.Registry + "|" + req.Repository
+
+	c.clientsMu.Lock()
+	defer c.clientsMu.Unlock()
+
+	if client, ok := c.clients[key]; ok {
+		return client
+	}
+
+	creds := &credentialStore{source: c.creds, req: req}
+	scope := fmt.Sprintf("repository:%s:pull", req.Repository)
+
+	transport := registryauth.NewTransport(
+		http.DefaultTransport,
+		registryauth.NewChallengeManager(),
+		registryauth.NewTokenHandler(http.DefaultTransport, creds, scope),
+		registryauth.NewBasicHandler(creds),
+	)
+
+	client := &http.Client{Transport: transport, Timeout: 15 * time.Second}
+	c.clients[key] = client
+	return client
+}
+
+// manifestReference picks the most specific manifest reference available
+// for req: its digest if known, else its tag, else "latest".
+func manifestReference(req *models.ScanRequest) string {
+	if req.Digest != "" {
+		return req.Digest
+	}
+	if req.Tag != "" {
+		return req.Tag
+	}
+	return "latest"
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner"
+)
+
+var errRegistryNotFound = errors.New("registry not found")
+
+func TestManifestReference(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *models.ScanRequest
+		want string
+	}{
+		{
+			name: "prefers digest",
+			req:  &models.ScanRequest{Digest: "sha256:abc", Tag: "v1.0.0"},
+			want: "sha256:abc",
+		},
+		{
+			name: "falls back to tag",
+			req:  &models.ScanRequest{Tag: "v1.0.0"},
+			want: "v1.0.0",
+		},
+		{
+			name: "falls back to latest",
+			req:  &models.ScanRequest{},
+			want: "latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := manifestReference(tt.req); got != tt.want {
+				t.Errorf("manifestReference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeCredentialSource struct {
+	creds *scanner.RegistryCredentials
+	err   error
+}
+
+func (f *fakeCredentialSource) GetRegistryCredentials(*models.ScanRequest) (*scanner.RegistryCredentials, error) {
+	return f.creds, f.err
+}
+
+func TestCredentialStore_Basic(t *testing.T) {
+	tests := []struct {
+		name         string
+		source       CredentialSource
+		wantUsername string
+		wantPassword string
+	}{
+		{
+			name:         "credentials configured",
+			source:       &fakeCredentialSource{creds: &scanner.RegistryCredentials{Username: "user", Password: "pass"}},
+			wantUsername: "user",
+			wantPassword: "pass",
+		},
+		{
+			name:   "no credentials falls back to anonymous",
+			source: &fakeCredentialSource{creds: nil},
+		},
+		{
+			name:   "lookup error falls back to anonymous",
+			source: &fakeCredentialSource{err: errRegistryNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &credentialStore{source: tt.source, req: &models.ScanRequest{}}
+
+			username, password := store.Basic(nil)
+			if username != tt.wantUsername || password != tt.wantPassword {
+				t.Errorf("Basic() = (%q, %q), want (%q, %q)", username, password, tt.wantUsername, tt.wantPassword)
+			}
+		})
+	}
+}
+
+func TestCredentialStore_RefreshToken(t *testing.T) {
+	store := &credentialStore{source: &fakeCredentialSource{}, req: &models.ScanRequest{}}
+
+	if got := store.RefreshToken(nil, "registry.example.com"); got != "" {
+		t.Errorf("RefreshToken() before SetRefreshToken = %q, want empty", got)
+	}
+
+	store.SetRefreshToken(nil, "registry.example.com", "refresh-token-value")
+
+	if got := store.RefreshToken(nil, "registry.example.com"); got != "refresh-token-value" {
+		t.Errorf("RefreshToken() after SetRefreshToken = %q, want refresh-token-value", got)
+	}
+	if got := store.RefreshToken(nil, "other-service"); got != "" {
+		t.Errorf("RefreshToken() for unrelated service = %q, want empty", got)
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net/url"
+	"sync"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/registryauth"
+)
+
+// CredentialSource supplies the registry credentials for a scan request,
+// implemented by *scanner.CredentialProvider.
+type CredentialSource interface {
+	GetRegistryCredentials(req *models.ScanRequest) (*scanner.RegistryCredentials, error)
+}
+
+// credentialStore adapts a CredentialSource into a
+// registryauth.CredentialStore for req, falling back to anonymous auth
+// when the source has no credentials configured for req's registry.
+type credentialStore struct {
+	source CredentialSource
+	req    *models.ScanRequest
+
+	mu            sync.Mutex
+	refreshTokens map[string]string
+}
+
+// Basic implements registryauth.CredentialStore.
+func (c *credentialStore) Basic(*url.URL) (username, password string) {
+	creds, err := c.source.GetRegistryCredentials(c.req)
+	if err != nil || creds == nil {
+		return "", ""
+	}
+	return creds.Username, creds.Password
+}
+
+// RefreshToken implements registryauth.CredentialStore.
+func (c *credentialStore) RefreshToken(_ *url.URL, service string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refreshTokens[service]
+}
+
+// SetRefreshToken implements registryauth.CredentialStore.
+func (c *credentialStore) SetRefreshToken(_ *url.URL, service, token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refreshTokens == nil {
+		c.refreshTokens = make(map[string]string)
+	}
+	c.refreshTokens[service] = token
+}
+
+var (
+	_ registryauth.CredentialStore = (*credentialStore)(nil)
+	_ CredentialSource             = (*scanner.CredentialProvider)(nil)
+)
@@ -0,0 +1,54 @@
+package registryauth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// credHelperResponse is the JSON a docker-credential-<helper> "get"
+// invocation writes to stdout, per the docker-credential-helpers protocol
+// (https://github.com/docker/docker-credential-helpers).
+type credHelperResponse struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// InvokeHelper invokes `docker-credential-<helper> get`, writing host to
+// its stdin and parsing the credential JSON from its stdout, per the
+// docker-credential-helpers stdin/stdout protocol. A helper that reports
+// no stored credentials for host returns an error, which callers should
+// treat as "no credentials available" rather than fatal. Exported so
+// callers that invoke a helper directly (without a surrounding
+// config.json naming it as a credsStore/credHelpers entry) can reuse the
+// same protocol handling as Resolver.
+func InvokeHelper(helper, host string) (username, password, identityToken string, err error) {
+	cmd := exec.Command(fmt.Sprintf("docker-credential-%s", helper), "get")
+
+	cmd.Stdin = bytes.NewBufferString(host)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", "", "", fmt.Errorf("docker-credential-%s get %s: %w: %s", helper, host, err, stderr.String())
+	}
+
+	var resp credHelperResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return "", "", "", fmt.Errorf("docker-credential-%s get %s: invalid response: %w", helper, host, err)
+	}
+
+	// Credential helpers for registries that issue OAuth2 identity
+	// tokens (e.g. ECR) return the token as the Secret with a sentinel
+	// Username; callers should send it as an identity token rather than
+	// a password.
+	if resp.Username == "<token>" {
+		return "", "", resp.Secret, nil
+	}
+
+	return resp.Username, resp.Secret, "", nil
+}
@@ -0,0 +1,81 @@
+package registryauth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// writeFakeHelper writes a fake docker-credential-<name> binary to dir
+// and puts dir on PATH, so InvokeHelper exercises the real stdin/stdout
+// helper protocol against a script instead of a mock function.
+func writeFakeHelper(t *testing.T, name, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake helper script requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, fmt.Sprintf("docker-credential-%s", name))
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700); err != nil {
+		t.Fatalf("failed to write fake credential helper: %v", err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestInvokeHelper_UsernamePassword(t *testing.T) {
+	writeFakeHelper(t, "fake", `cat <<'EOF'
+{"ServerURL":"registry.example.com","Username":"alice","Secret":"s3cret"}
+EOF
+`)
+
+	username, password, identityToken, err := InvokeHelper("fake", "registry.example.com")
+	if err != nil {
+		t.Fatalf("InvokeHelper() error = %v, want nil", err)
+	}
+	if username != "alice" || password != "s3cret" || identityToken != "" {
+		t.Errorf("InvokeHelper() = (%q, %q, %q), want (alice, s3cret, \"\")", username, password, identityToken)
+	}
+}
+
+func TestInvokeHelper_IdentityToken(t *testing.T) {
+	writeFakeHelper(t, "ecr-login", `cat <<'EOF'
+{"ServerURL":"123456789.dkr.ecr.us-east-1.amazonaws.com","Username":"<token>","Secret":"ecr-token-abc"}
+EOF
+`)
+
+	username, password, identityToken, err := InvokeHelper("ecr-login", "123456789.dkr.ecr.us-east-1.amazonaws.com")
+	if err != nil {
+		t.Fatalf("InvokeHelper() error = %v, want nil", err)
+	}
+	if identityToken != "ecr-token-abc" || username != "" || password != "" {
+		t.Errorf("InvokeHelper() = (%q, %q, %q), want (\"\", \"\", ecr-token-abc)", username, password, identityToken)
+	}
+}
+
+func TestInvokeHelper_NonZeroExitIsError(t *testing.T) {
+	writeFakeHelper(t, "broken", `echo "credentials not found" >&2
+exit 1
+`)
+
+	if _, _, _, err := InvokeHelper("broken", "registry.example.com"); err == nil {
+		t.Error("InvokeHelper() error = nil, want non-nil for a helper that exits non-zero")
+	}
+}
+
+func TestInvokeHelper_ReceivesHostOnStdin(t *testing.T) {
+	writeFakeHelper(t, "echo-host", `host=$(cat)
+echo "{\"ServerURL\":\"$host\",\"Username\":\"user-$host\",\"Secret\":\"pw\"}"
+`)
+
+	username, _, _, err := InvokeHelper("echo-host", "registry.example.com")
+	if err != nil {
+		t.Fatalf("InvokeHelper() error = %v, want nil", err)
+	}
+	if want := "user-registry.example.com"; username != want {
+		t.Errorf("InvokeHelper() username = %q, want %q (helper did not receive host on stdin)", username, want)
+	}
+}
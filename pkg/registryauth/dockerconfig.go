@@ -0,0 +1,110 @@
+package registryauth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// dockerConfig is the subset of ~/.docker/config.json this package
+// understands: per-registry static credentials under "auths", a global
+// credential-helper binary under "credsStore", and per-registry helper
+// overrides under "credHelpers".
+type dockerConfig struct {
+	Auths       map[string]dockerAuthEntry `json:"auths"`
+	CredsStore  string                     `json:"credsStore"`
+	CredHelpers map[string]string          `json:"credHelpers"`
+}
+
+// dockerAuthEntry is one entry of the "auths" map: either a base64
+// "username:password" blob under Auth, or a short-lived OAuth2 identity
+// token under IdentityToken (set by credential helpers that already ran
+// once and persisted their result).
+type dockerAuthEntry struct {
+	Auth          string `json:"auth"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// loadDockerConfig reads and parses the Docker/OCI config.json at path. A
+// missing file is not an error: it just means no static credentials or
+// credential helpers are configured.
+func loadDockerConfig(path string) (*dockerConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &dockerConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docker config %s: %w", path, err)
+	}
+
+	return parseDockerConfig(data)
+}
+
+// parseDockerConfig parses already-read config.json bytes, for callers
+// whose config doesn't live at a path loadDockerConfig can open directly
+// (e.g. a Kubernetes Secret's dockerconfigjson).
+func parseDockerConfig(data []byte) (*dockerConfig, error) {
+	var cfg dockerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// credentialHelper returns the name of the docker-credential-<name> helper
+// that should be used for host, preferring a per-registry credHelpers
+// entry over the global credsStore. Returns "" if neither is configured.
+func (c *dockerConfig) credentialHelper(host string) string {
+	if helper, ok := c.CredHelpers[host]; ok && helper != "" {
+		return helper
+	}
+	return c.CredsStore
+}
+
+// staticAuth decodes the base64 "username:password" auth entry for host,
+// if one is configured. Docker normalizes Docker Hub's hostname to
+// "https://index.docker.io/v1/" in config.json, so that alias is checked
+// too.
+func (c *dockerConfig) staticAuth(host string) (username, password, identityToken string, ok bool) {
+	entry, found := c.Auths[host]
+	if !found && isDockerHub(host) {
+		entry, found = c.Auths["https://index.docker.io/v1/"]
+	}
+	if !found {
+		return "", "", "", false
+	}
+
+	if entry.IdentityToken != "" {
+		return "", "", entry.IdentityToken, true
+	}
+
+	if entry.Auth == "" {
+		return "", "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return "", "", "", false
+	}
+
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return "", "", "", false
+	}
+
+	return parts[0], parts[1], "", true
+}
+
+// isDockerHub reports whether host refers to Docker Hub under any of its
+// common aliases.
+func isDockerHub(host string) bool {
+	switch host {
+	case "docker.io", "registry-1.docker.io", "index.docker.io":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,161 @@
+// Package registryauth resolves the username/password or identity token a
+// container runtime would use to pull from a given registry host, the way
+// the Docker/OCI ecosystem does it: static credentials from a
+// ~/.docker/config.json-style file, or a credential helper
+// (docker-credential-<store>) invoked per the stdin/stdout JSON protocol.
+// This lets scanners authenticate against ECR, GCR, ACR, etc. using the
+// same credential helpers a cluster's nodes already run, instead of
+// embedding long-lived registry secrets in chart values.
+package registryauth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL bounds how long a resolved credential is reused before
+// Resolve re-reads the config file / re-invokes the credential helper,
+// so a rotated credential helper secret or an edited config.json is
+// picked up without a process restart.
+const defaultCacheTTL = 5 * time.Minute
+
+// Credentials is the result of resolving a registry hostname: either a
+// Username/Password pair, or a short-lived IdentityToken (OAuth2 bearer),
+// mutually exclusive per the docker-credential-helpers convention.
+type Credentials struct {
+	Username      string
+	Password      string
+	IdentityToken string
+}
+
+// Resolver resolves registry credentials from a Docker/OCI config.json,
+// caching results per (configPath, host) for a TTL so repeated scans of
+// the same registry don't re-invoke a credential helper subprocess every
+// time.
+type Resolver struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	cache map[string]resolverCacheEntry
+}
+
+type resolverCacheEntry struct {
+	creds     Credentials
+	expiresAt time.Time
+}
+
+// NewResolver creates a Resolver that caches resolved credentials for ttl.
+// A zero ttl falls back to defaultCacheTTL.
+func NewResolver(ttl time.Duration) *Resolver {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &Resolver{
+		ttl:   ttl,
+		cache: make(map[string]resolverCacheEntry),
+	}
+}
+
+// Resolve returns the credentials configured for host in the Docker/OCI
+// config.json at configPath, resolving "auths" static credentials,
+// per-registry "credHelpers", and finally the global "credsStore", in
+// that order. An empty configPath falls back to ~/.docker/config.json. A
+// host with no configured credentials returns a zero Credentials and a
+// nil error: callers should treat that as "nothing configured", not a
+// failure.
+func (r *Resolver) Resolve(host, configPath string) (Credentials, error) {
+	path, err := resolveConfigPath(configPath)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	key := path + "|" + host
+
+	r.mu.Lock()
+	if entry, ok := r.cache[key]; ok && time.Now().Before(entry.expiresAt) {
+		r.mu.Unlock()
+		return entry.creds, nil
+	}
+	r.mu.Unlock()
+
+	creds, err := r.resolveUncached(host, path)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	r.mu.Lock()
+	r.cache[key] = resolverCacheEntry{creds: creds, expiresAt: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+
+	return creds, nil
+}
+
+func (r *Resolver) resolveUncached(host, path string) (Credentials, error) {
+	cfg, err := loadDockerConfig(path)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	if username, password, identityToken, ok := cfg.staticAuth(host); ok {
+		return Credentials{Username: username, Password: password, IdentityToken: identityToken}, nil
+	}
+
+	if helper := cfg.credentialHelper(host); helper != "" {
+		username, password, identityToken, err := InvokeHelper(helper, host)
+		if err != nil {
+			// A helper reporting "no credentials stored" for this host
+			// isn't fatal; fall through to "nothing configured".
+			return Credentials{}, nil
+		}
+		return Credentials{Username: username, Password: password, IdentityToken: identityToken}, nil
+	}
+
+	return Credentials{}, nil
+}
+
+// ResolveBytes applies Resolve's "auths" / "credHelpers" / "credsStore"
+// logic to an already-fetched config.json, for callers whose config
+// doesn't live at a path Resolve can open directly (e.g. a Kubernetes
+// Secret's dockerconfigjson). Unlike Resolve, results aren't cached here:
+// callers that fetch data from somewhere expensive should cache that
+// fetch themselves.
+func (r *Resolver) ResolveBytes(host string, data []byte) (Credentials, error) {
+	cfg, err := parseDockerConfig(data)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	if username, password, identityToken, ok := cfg.staticAuth(host); ok {
+		return Credentials{Username: username, Password: password, IdentityToken: identityToken}, nil
+	}
+
+	if helper := cfg.credentialHelper(host); helper != "" {
+		username, password, identityToken, err := InvokeHelper(helper, host)
+		if err != nil {
+			// A helper reporting "no credentials stored" for this host
+			// isn't fatal; fall through to "nothing configured".
+			return Credentials{}, nil
+		}
+		return Credentials{Username: username, Password: password, IdentityToken: identityToken}, nil
+	}
+
+	return Credentials{}, nil
+}
+
+// resolveConfigPath expands configPath, defaulting to
+// ~/.docker/config.json when empty.
+func resolveConfigPath(configPath string) (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory for default docker config: %w", err)
+	}
+
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
@@ -0,0 +1,154 @@
+package registryauth
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, dir string, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("failed to write test docker config: %v", err)
+	}
+	return path
+}
+
+func TestResolver_StaticAuth(t *testing.T) {
+	dir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+	path := writeConfig(t, dir, `{"auths":{"registry.example.com":{"auth":"`+auth+`"}}}`)
+
+	r := NewResolver(time.Minute)
+
+	creds, err := r.Resolve("registry.example.com", path)
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	if creds.Username != "alice" || creds.Password != "s3cret" {
+		t.Errorf("Resolve() = %+v, want Username=alice Password=s3cret", creds)
+	}
+}
+
+func TestResolver_StaticAuth_DockerHubAlias(t *testing.T) {
+	dir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte("bob:hunter2"))
+	path := writeConfig(t, dir, `{"auths":{"https://index.docker.io/v1/":{"auth":"`+auth+`"}}}`)
+
+	r := NewResolver(time.Minute)
+
+	creds, err := r.Resolve("docker.io", path)
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	if creds.Username != "bob" {
+		t.Errorf("Resolve() = %+v, want Username=bob via docker.io alias", creds)
+	}
+}
+
+func TestResolver_IdentityToken(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, `{"auths":{"123456789.dkr.ecr.us-east-1.amazonaws.com":{"identitytoken":"ecr-token-abc"}}}`)
+
+	r := NewResolver(time.Minute)
+
+	creds, err := r.Resolve("123456789.dkr.ecr.us-east-1.amazonaws.com", path)
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	if creds.IdentityToken != "ecr-token-abc" {
+		t.Errorf("Resolve().IdentityToken = %q, want %q", creds.IdentityToken, "ecr-token-abc")
+	}
+}
+
+func TestResolver_NoCredentialsConfigured(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, `{"auths":{}}`)
+
+	r := NewResolver(time.Minute)
+
+	creds, err := r.Resolve("unconfigured.example.com", path)
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	if creds != (Credentials{}) {
+		t.Errorf("Resolve() = %+v, want zero value", creds)
+	}
+}
+
+func TestResolver_MissingConfigFile(t *testing.T) {
+	r := NewResolver(time.Minute)
+
+	creds, err := r.Resolve("registry.example.com", filepath.Join(t.TempDir(), "does-not-exist.json"))
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error for missing config file: %v", err)
+	}
+	if creds != (Credentials{}) {
+		t.Errorf("Resolve() = %+v, want zero value", creds)
+	}
+}
+
+func TestResolver_CachesResult(t *testing.T) {
+	dir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+	path := writeConfig(t, dir, `{"auths":{"registry.example.com":{"auth":"`+auth+`"}}}`)
+
+	r := NewResolver(time.Minute)
+
+	if _, err := r.Resolve("registry.example.com", path); err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+
+	// Rewrite the config with different credentials; the cached result
+	// should still be returned since the TTL hasn't elapsed.
+	auth2 := base64.StdEncoding.EncodeToString([]byte("mallory:other"))
+	if err := os.WriteFile(path, []byte(`{"auths":{"registry.example.com":{"auth":"`+auth2+`"}}}`), 0o600); err != nil {
+		t.Fatalf("failed to rewrite test docker config: %v", err)
+	}
+
+	creds, err := r.Resolve("registry.example.com", path)
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	if creds.Username != "alice" {
+		t.Errorf("Resolve() = %+v, want cached Username=alice", creds)
+	}
+}
+
+func TestResolver_ResolveBytes_StaticAuth(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+	data := []byte(`{"auths":{"registry.example.com":{"auth":"` + auth + `"}}}`)
+
+	r := NewResolver(time.Minute)
+
+	creds, err := r.ResolveBytes("registry.example.com", data)
+	if err != nil {
+		t.Fatalf("ResolveBytes() returned unexpected error: %v", err)
+	}
+	if creds.Username != "alice" || creds.Password != "s3cret" {
+		t.Errorf("ResolveBytes() = %+v, want Username=alice Password=s3cret", creds)
+	}
+}
+
+func TestResolver_ResolveBytes_NoCredentialsConfigured(t *testing.T) {
+	r := NewResolver(time.Minute)
+
+	creds, err := r.ResolveBytes("registry.example.com", []byte(`{"auths":{}}`))
+	if err != nil {
+		t.Fatalf("ResolveBytes() returned unexpected error: %v", err)
+	}
+	if creds != (Credentials{}) {
+		t.Errorf("ResolveBytes() = %+v, want zero value", creds)
+	}
+}
+
+func TestResolver_ResolveBytes_InvalidJSON(t *testing.T) {
+	r := NewResolver(time.Minute)
+
+	if _, err := r.ResolveBytes("registry.example.com", []byte("not json")); err == nil {
+		t.Error("ResolveBytes() error = nil, want non-nil for malformed config.json bytes")
+	}
+}
@@ -0,0 +1,88 @@
+// Package restart implements zero-downtime graceful restarts by handing off
+// listening sockets to a freshly exec'd copy of the running binary, in the
+// spirit of Gitea's graceful manager. On SIGHUP the current process passes
+// its listener file descriptors to a child via LISTEN_FDS/LISTEN_FDNAMES
+// (the systemd socket-activation convention) and execs it, then drains
+// in-flight work through the normal shutdown.Manager sequence while the
+// child accepts new connections.
+package restart
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EnvListenFDs holds the number of inherited listener file descriptors.
+	EnvListenFDs = "LISTEN_FDS"
+	// EnvListenFDNames holds a colon-separated list of names for the
+	// inherited file descriptors, in the same order as the fds themselves.
+	EnvListenFDNames = "LISTEN_FDNAMES"
+	// EnvListenPID holds the pid the fds were handed off from, for logging.
+	EnvListenPID = "LISTEN_PID"
+
+	// listenFDStart is the first inherited fd number; 0/1/2 are reserved
+	// for stdin/stdout/stderr.
+	listenFDStart = 3
+)
+
+// Manager coordinates handing off registered listeners to a re-exec'd copy
+// of the running binary.
+type Manager struct {
+	logger    *logrus.Logger
+	listeners func() map[string]net.Listener
+}
+
+// NewManager creates a restart Manager. listenerSource is invoked at
+// restart time to obtain the current set of registered listeners, normally
+// (*shutdown.Manager).Listeners.
+func NewManager(logger *logrus.Logger, listenerSource func() map[string]net.Listener) *Manager {
+	return &Manager{
+		logger:    logger,
+		listeners: listenerSource,
+	}
+}
+
+// TakeOverListeners reconstructs net.Listeners from file descriptors
+// inherited from a parent process via LISTEN_FDS/LISTEN_FDNAMES. It returns
+// an empty map if this process was not started as part of a graceful
+// restart, in which case callers should bind fresh listeners as usual.
+func TakeOverListeners() (map[string]net.Listener, error) {
+	countStr := os.Getenv(EnvListenFDs)
+	if countStr == "" {
+		return map[string]net.Listener{}, nil
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvListenFDs, err)
+	}
+
+	var names []string
+	if raw := os.Getenv(EnvListenFDNames); raw != "" {
+		names = strings.Split(raw, ":")
+	}
+
+	listeners := make(map[string]net.Listener, count)
+	for i := 0; i < count; i++ {
+		fd := listenFDStart + i
+
+		name := fmt.Sprintf("fd%d", i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		ln, err := listenerFromFD(fd, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take over listener %q (fd %d): %w", name, fd, err)
+		}
+		listeners[name] = ln
+	}
+
+	return listeners, nil
+}
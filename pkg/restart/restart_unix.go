@@ -0,0 +1,87 @@
+//go:build !windows
+
+package restart
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// Restart execs a fresh copy of the running binary, handing off every
+// currently registered listener via inherited file descriptors. The caller
+// is expected to follow up with a normal Manager.Shutdown() to drain
+// in-flight work while the child process takes over new connections.
+func (m *Manager) Restart() error {
+	listeners := m.listeners()
+
+	names := make([]string, 0, len(listeners))
+	files := make([]*os.File, 0, len(listeners))
+
+	for name, ln := range listeners {
+		f, err := fileFromListener(ln)
+		if err != nil {
+			return fmt.Errorf("failed to extract fd for listener %q: %w", name, err)
+		}
+		names = append(names, name)
+		files = append(files, f)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+
+	env := append(os.Environ(),
+		fmt.Sprintf("%s=%d", EnvListenFDs, len(files)),
+		fmt.Sprintf("%s=%s", EnvListenFDNames, strings.Join(names, ":")),
+		fmt.Sprintf("%s=%d", EnvListenPID, os.Getpid()),
+	)
+
+	procFiles := append([]*os.File{os.Stdin, os.Stdout, os.Stderr}, files...)
+
+	m.logger.WithField("listeners", names).Info("Graceful restart: handing off listeners to new process")
+
+	pid, err := syscall.ForkExec(executable, os.Args, &syscall.ProcAttr{
+		Env:   env,
+		Files: fdsOf(procFiles),
+		Sys:   &syscall.SysProcAttr{Setsid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to exec child process: %w", err)
+	}
+
+	m.logger.WithField("child_pid", pid).Info("Graceful restart: new process started, draining in-flight work")
+	return nil
+}
+
+func fdsOf(files []*os.File) []uintptr {
+	fds := make([]uintptr, len(files))
+	for i, f := range files {
+		fds[i] = f.Fd()
+	}
+	return fds
+}
+
+// fileFromListener extracts the underlying *os.File for a listener so its
+// descriptor can survive across exec. File() duplicates the fd and clears
+// close-on-exec, which is exactly what inheritance requires.
+func fileFromListener(ln net.Listener) (*os.File, error) {
+	type filer interface {
+		File() (*os.File, error)
+	}
+
+	fl, ok := ln.(filer)
+	if !ok {
+		return nil, fmt.Errorf("listener type %T does not support fd extraction", ln)
+	}
+	return fl.File()
+}
+
+func listenerFromFD(fd int, name string) (net.Listener, error) {
+	f := os.NewFile(uintptr(fd), name)
+	defer f.Close()
+	return net.FileListener(f)
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package restart
+
+import (
+	"fmt"
+	"net"
+)
+
+// Restart is a no-op on Windows: there is no fd-passing exec() equivalent,
+// so zero-downtime listener handoff isn't possible. Callers should fall
+// back to a sequential stop+start driven by the process supervisor (e.g. a
+// Windows Service restart or container orchestrator rollout) instead.
+func (m *Manager) Restart() error {
+	m.logger.Warn("Graceful restart via SIGHUP is not supported on Windows; falling back to stop+start")
+	return nil
+}
+
+func listenerFromFD(fd int, name string) (net.Listener, error) {
+	return nil, fmt.Errorf("listener fd inheritance is not supported on Windows")
+}
@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/retry"
+)
+
+// backoffPolicyFromRegistryScannerConfig builds a retry.Policy from cfg's
+// Backoff field, falling back to retry.DefaultPolicy for any field cfg
+// leaves unset. A nil cfg (the field is optional) also yields
+// retry.DefaultPolicy.
+func backoffPolicyFromRegistryScannerConfig(cfg *config.BackoffPolicyConfig) retry.Policy {
+	policy := retry.DefaultPolicy
+	if cfg == nil {
+		return policy
+	}
+
+	if cfg.BaseDelay != "" {
+		if d, err := time.ParseDuration(cfg.BaseDelay); err == nil {
+			policy.BaseDelay = d
+		}
+	}
+	if cfg.MaxDelay != "" {
+		if d, err := time.ParseDuration(cfg.MaxDelay); err == nil {
+			policy.MaxDelay = d
+		}
+	}
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	policy.Jitter = !cfg.DisableJitter
+
+	return policy
+}
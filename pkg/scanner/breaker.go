@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"context"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/circuitbreaker"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/retry"
+)
+
+// breakerConfigFromRegistryScannerConfig builds a circuitbreaker.Config
+// from cfg's CircuitBreaker field, falling back to
+// circuitbreaker.DefaultConfig for any field cfg leaves unset. A nil cfg
+// (the field is optional) also yields circuitbreaker.DefaultConfig.
+func breakerConfigFromRegistryScannerConfig(cfg *config.CircuitBreakerConfig) circuitbreaker.Config {
+	breakerCfg := circuitbreaker.DefaultConfig
+	if cfg == nil {
+		return breakerCfg
+	}
+
+	if cfg.FailureThreshold > 0 {
+		breakerCfg.FailureThreshold = cfg.FailureThreshold
+	}
+	if cfg.CooldownDuration != "" {
+		if d, err := time.ParseDuration(cfg.CooldownDuration); err == nil {
+			breakerCfg.CooldownDuration = d
+		}
+	}
+	if cfg.HalfOpenProbes > 0 {
+		breakerCfg.HalfOpenProbes = cfg.HalfOpenProbes
+	}
+	return breakerCfg
+}
+
+// newBreakerGroup creates the circuitbreaker.Group used to guard calls to
+// the Sysdig Registry Scanner API, reporting every state transition to
+// the scanner_circuit_breaker_transitions_total metric.
+func newBreakerGroup(cfg *config.CircuitBreakerConfig) *circuitbreaker.Group {
+	return circuitbreaker.NewGroup(breakerConfigFromRegistryScannerConfig(cfg), func(endpoint string, from, to circuitbreaker.State) {
+		metrics.RecordCircuitBreakerTransition(endpoint, from.String(), to.String())
+	})
+}
+
+// withBreaker wraps fn so that, before each attempt, it consults breaker
+// and fails fast with a non-retriable *circuitbreaker.CircuitOpenError if
+// the dependency is already known to be down, instead of letting retry.Do
+// run the attempt (and its rate limiter wait) against a dependency that's
+// already tripped the breaker.
+func withBreaker(breaker *circuitbreaker.Breaker, fn retry.Func) retry.Func {
+	return func(ctx context.Context) (retry.Result, error) {
+		if err := breaker.Allow(); err != nil {
+			return retry.Result{}, err
+		}
+
+		result, err := fn(ctx)
+		if err == nil {
+			breaker.RecordSuccess()
+		} else {
+			breaker.RecordFailure(result.Retriable)
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,193 @@
+// Package circuitbreaker implements a classic closed/open/half-open
+// circuit breaker for the Sysdig Registry Scanner HTTP client, so a
+// Sysdig outage makes the client fail fast (CircuitOpenError) instead of
+// retrying every scan request into a dependency that's already down.
+package circuitbreaker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is one of the three states a Breaker can be in.
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config bounds a Breaker's trip/cooldown/probe behavior.
+type Config struct {
+	// FailureThreshold is how many consecutive retriable failures trip
+	// the breaker from closed to open.
+	FailureThreshold int
+	// CooldownDuration is how long the breaker stays open before letting
+	// a single half-open probe through.
+	CooldownDuration time.Duration
+	// HalfOpenProbes is how many calls are allowed through per
+	// half-open period before further calls are rejected pending the
+	// first probe's outcome.
+	HalfOpenProbes int
+}
+
+// DefaultConfig trips after 5 consecutive retriable failures, cools down
+// for 30s, and allows a single half-open probe.
+var DefaultConfig = Config{
+	FailureThreshold: 5,
+	CooldownDuration: 30 * time.Second,
+	HalfOpenProbes:   1,
+}
+
+// CircuitOpenError is returned by Breaker.Allow while the breaker is open
+// (or its half-open probe budget is exhausted), instead of letting the
+// call through to fail against the dependency again. It's deliberately
+// not retriable: the point of the breaker is to stop retrying a
+// dependency that's already known to be down.
+type CircuitOpenError struct {
+	// Endpoint identifies which Breaker rejected the call, for logging.
+	Endpoint string
+	// RetryAfter is how long until the breaker's cooldown elapses and it
+	// allows a half-open probe.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for %s, retry after %s", e.Endpoint, e.RetryAfter.Round(time.Second))
+}
+
+// onStateChange is called whenever a Breaker transitions, so Group can
+// report it to metrics with the breaker's key attached.
+type onStateChange func(from, to State)
+
+// Breaker tracks the health of calls to a single endpoint. The zero
+// value is not usable; construct one with New or Group.Breaker.
+type Breaker struct {
+	cfg      Config
+	endpoint string
+	onChange onStateChange
+
+	mu                  sync.Mutex
+	state               State
+	consecutiveFailures int
+	openedAt            time.Time
+	halfOpenProbesSpent int
+}
+
+// New creates a Breaker for endpoint (used only for CircuitOpenError's
+// message), starting closed.
+func New(endpoint string, cfg Config) *Breaker {
+	return &Breaker{cfg: cfg, endpoint: endpoint, state: StateClosed}
+}
+
+// Allow reports whether a call should proceed, returning a
+// non-retriable *CircuitOpenError if the breaker is currently rejecting
+// calls. A caller that gets a nil error must report the call's outcome
+// back via RecordSuccess or RecordFailure.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		return nil
+
+	case StateOpen:
+		elapsed := time.Since(b.openedAt)
+		if elapsed < b.cfg.CooldownDuration {
+			return &CircuitOpenError{Endpoint: b.endpoint, RetryAfter: b.cfg.CooldownDuration - elapsed}
+		}
+		b.transition(StateHalfOpen)
+		b.halfOpenProbesSpent = 1
+		return nil
+
+	case StateHalfOpen:
+		if b.halfOpenProbesSpent >= b.cfg.HalfOpenProbes {
+			return &CircuitOpenError{Endpoint: b.endpoint, RetryAfter: b.cfg.CooldownDuration}
+		}
+		b.halfOpenProbesSpent++
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess reports that a call Allow let through succeeded. From
+// half-open this closes the breaker; from closed it resets the
+// consecutive-failure count.
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures = 0
+	if b.state != StateClosed {
+		b.transition(StateClosed)
+	}
+}
+
+// RecordFailure reports that a call Allow let through failed. Only a
+// retriable failure counts toward FailureThreshold or re-opens a
+// half-open breaker: a non-retriable failure (e.g. a 400 from a
+// malformed request) reflects a problem with the caller, not the
+// dependency's health, so it's ignored here.
+func (b *Breaker) RecordFailure(retriable bool) {
+	if !retriable {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateHalfOpen:
+		b.transition(StateOpen)
+	case StateClosed:
+		b.consecutiveFailures++
+		if b.consecutiveFailures >= b.cfg.FailureThreshold {
+			b.transition(StateOpen)
+		}
+	}
+}
+
+// transition moves b to next, recording openedAt/halfOpenProbesSpent as
+// needed and notifying onChange. Callers must hold b.mu.
+func (b *Breaker) transition(next State) {
+	prev := b.state
+	b.state = next
+
+	switch next {
+	case StateOpen:
+		b.openedAt = time.Now()
+		b.halfOpenProbesSpent = 0
+	case StateClosed:
+		b.consecutiveFailures = 0
+		b.halfOpenProbesSpent = 0
+	}
+
+	if b.onChange != nil && prev != next {
+		b.onChange(prev, next)
+	}
+}
+
+// State returns the breaker's current state, for logging/diagnostics.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,132 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	return Config{
+		FailureThreshold: 3,
+		CooldownDuration: 20 * time.Millisecond,
+		HalfOpenProbes:   1,
+	}
+}
+
+func TestBreaker_TripsAfterThresholdRetriableFailures(t *testing.T) {
+	b := New("test", testConfig())
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() = %v, want nil before threshold", err)
+		}
+		b.RecordFailure(true)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("State() = %v, want closed before threshold reached", b.State())
+	}
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() = %v, want nil on final pre-trip attempt", err)
+	}
+	b.RecordFailure(true)
+	if b.State() != StateOpen {
+		t.Fatalf("State() = %v, want open after threshold reached", b.State())
+	}
+
+	if err := b.Allow(); err == nil {
+		t.Fatal("Allow() = nil, want CircuitOpenError while open")
+	} else if _, ok := err.(*CircuitOpenError); !ok {
+		t.Fatalf("Allow() error type = %T, want *CircuitOpenError", err)
+	}
+}
+
+func TestBreaker_NonRetriableFailuresDoNotTrip(t *testing.T) {
+	b := New("test", testConfig())
+
+	for i := 0; i < 10; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() = %v, want nil", err)
+		}
+		b.RecordFailure(false)
+	}
+
+	if b.State() != StateClosed {
+		t.Fatalf("State() = %v, want closed: non-retriable failures shouldn't trip the breaker", b.State())
+	}
+}
+
+func TestBreaker_HalfOpenProbeSuccessCloses(t *testing.T) {
+	cfg := testConfig()
+	b := New("test", cfg)
+
+	for i := 0; i < cfg.FailureThreshold; i++ {
+		_ = b.Allow()
+		b.RecordFailure(true)
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("State() = %v, want open", b.State())
+	}
+
+	time.Sleep(cfg.CooldownDuration + 5*time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() = %v, want nil for half-open probe after cooldown", err)
+	}
+	if b.State() != StateHalfOpen {
+		t.Fatalf("State() = %v, want half_open", b.State())
+	}
+
+	b.RecordSuccess()
+	if b.State() != StateClosed {
+		t.Fatalf("State() = %v, want closed after successful probe", b.State())
+	}
+}
+
+func TestBreaker_HalfOpenProbeFailureReopens(t *testing.T) {
+	cfg := testConfig()
+	b := New("test", cfg)
+
+	for i := 0; i < cfg.FailureThreshold; i++ {
+		_ = b.Allow()
+		b.RecordFailure(true)
+	}
+	time.Sleep(cfg.CooldownDuration + 5*time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() = %v, want nil for half-open probe", err)
+	}
+	b.RecordFailure(true)
+
+	if b.State() != StateOpen {
+		t.Fatalf("State() = %v, want open again after failed probe", b.State())
+	}
+	if err := b.Allow(); err == nil {
+		t.Fatal("Allow() = nil, want CircuitOpenError immediately after reopening")
+	}
+}
+
+func TestGroup_CachesBreakerPerEndpoint(t *testing.T) {
+	var transitions []string
+	g := NewGroup(testConfig(), func(endpoint string, from, to State) {
+		transitions = append(transitions, endpoint+":"+from.String()+"->"+to.String())
+	})
+
+	a := g.Breaker("initiate")
+	if a != g.Breaker("initiate") {
+		t.Fatal("Breaker(\"initiate\") returned a different instance on second call")
+	}
+	if a == g.Breaker("poll") {
+		t.Fatal("Breaker(\"poll\") returned the same instance as Breaker(\"initiate\")")
+	}
+
+	cfg := testConfig()
+	for i := 0; i < cfg.FailureThreshold; i++ {
+		_ = a.Allow()
+		a.RecordFailure(true)
+	}
+
+	if len(transitions) != 1 || transitions[0] != "initiate:closed->open" {
+		t.Fatalf("transitions = %v, want [\"initiate:closed->open\"]", transitions)
+	}
+}
@@ -0,0 +1,46 @@
+package circuitbreaker
+
+import "sync"
+
+// Group lazily creates and caches a Breaker per endpoint key, the same
+// way RegistryScanner caches an *http.Client per host: callers ask for
+// the breaker by key and get the same instance back every time.
+type Group struct {
+	cfg      Config
+	onChange func(endpoint string, from, to State)
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewGroup creates a Group whose breakers all share cfg. onChange, if
+// non-nil, is invoked with the endpoint key on every state transition of
+// any breaker in the group, so callers can wire it up to a metrics
+// counter without each Breaker needing to know about endpoint keys.
+func NewGroup(cfg Config, onChange func(endpoint string, from, to State)) *Group {
+	return &Group{
+		cfg:      cfg,
+		onChange: onChange,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Breaker returns the Breaker for endpoint, creating it if this is the
+// first call for that key.
+func (g *Group) Breaker(endpoint string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if b, ok := g.breakers[endpoint]; ok {
+		return b
+	}
+
+	b := New(endpoint, g.cfg)
+	if g.onChange != nil {
+		b.onChange = func(from, to State) {
+			g.onChange(endpoint, from, to)
+		}
+	}
+	g.breakers[endpoint] = b
+	return b
+}
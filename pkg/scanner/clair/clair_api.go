@@ -0,0 +1,292 @@
+package clair
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/retry"
+)
+
+// manifestInfo is what Scan needs from an image's manifest to build
+// Clair's index_report request: the manifest's own digest and the
+// fetchable URI/headers for each of its layers.
+type manifestInfo struct {
+	Hash   string
+	Layers []indexLayer
+}
+
+// indexLayer is one entry of an index_report request's "layers" array, as
+// Clair v4's Indexer API expects it.
+type indexLayer struct {
+	Hash    string              `json:"hash"`
+	URI     string              `json:"uri"`
+	Headers map[string][]string `json:"headers,omitempty"`
+}
+
+// rawManifest is the subset of a Docker/OCI manifest this package reads:
+// just enough to enumerate layer digests.
+type rawManifest struct {
+	Layers []struct {
+		Digest string `json:"digest"`
+	} `json:"layers"`
+}
+
+// resolveManifest GETs imageRef's manifest through a per-registry
+// authenticated client and builds the index_report layer list Clair needs
+// to pull each layer blob itself.
+func (s *Scanner) resolveManifest(ctx context.Context, imageRef string) (*manifestInfo, error) {
+	host, repository, reference, err := parseImageRef(imageRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image reference: %w", err)
+	}
+
+	client := s.registryClient(host, repository)
+
+	endpoint := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, reference)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build manifest request: %w", err)
+	}
+	httpReq.Header.Set("Accept", manifestAcceptHeader)
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry %s returned status %d for %s", host, resp.StatusCode, imageRef)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return nil, fmt.Errorf("registry %s did not return a content digest for %s", host, imageRef)
+	}
+
+	var manifest rawManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	headers := s.layerHeaders(host, repository)
+
+	layers := make([]indexLayer, 0, len(manifest.Layers))
+	for _, l := range manifest.Layers {
+		layers = append(layers, indexLayer{
+			Hash:    l.Digest,
+			URI:     fmt.Sprintf("https://%s/v2/%s/blobs/%s", host, repository, l.Digest),
+			Headers: headers,
+		})
+	}
+
+	return &manifestInfo{Hash: digest, Layers: layers}, nil
+}
+
+// layerHeaders returns the Authorization header Clair's Indexer should
+// send when it pulls each layer blob directly, derived from the static
+// username/password configured for host. Public registries (or those
+// Clair is separately configured to reach) need nothing here, so this
+// returns nil when no credentials are configured.
+func (s *Scanner) layerHeaders(host, repository string) map[string][]string {
+	username, password := s.registryCredentials(host).Basic(nil)
+	if username == "" {
+		return nil
+	}
+	return map[string][]string{
+		"Authorization": {basicAuthHeader(username, password)},
+	}
+}
+
+// submitIndexReport POSTs manifest to the Indexer's index_report
+// endpoint, kicking off indexing. Clair accepts this request
+// asynchronously; pollIndexReport waits for it to finish.
+func (s *Scanner) submitIndexReport(ctx context.Context, manifest *manifestInfo) error {
+	payload := struct {
+		Hash   string       `json:"hash"`
+		Layers []indexLayer `json:"layers"`
+	}{Hash: manifest.Hash, Layers: manifest.Layers}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index report request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/indexer/api/v1/index_report", s.config.Scanner.Clair.IndexerURL)
+
+	return retry.Do(ctx, s.retryPolicy(), func(ctx context.Context) (retry.Result, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return retry.Result{}, fmt.Errorf("failed to build index report request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.httpClient.Do(httpReq)
+		if err != nil {
+			return retry.Result{Retriable: true}, fmt.Errorf("failed to reach clair indexer: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+			apiErr := fmt.Errorf("clair indexer returned status %d: %s", resp.StatusCode, readBody(resp))
+			if retry.IsRetriableStatusCode(resp.StatusCode) {
+				return retry.Result{Retriable: true}, apiErr
+			}
+			return retry.Result{}, apiErr
+		}
+		resp.Body.Close()
+
+		return retry.Result{}, nil
+	})
+}
+
+// pollIndexReport polls the Indexer's index_report endpoint until it
+// reports state=IndexFinished, fails with state=IndexError, or req's scan
+// timeout elapses.
+func (s *Scanner) pollIndexReport(ctx context.Context, req *models.ScanRequest, hash string) error {
+	timeout, err := s.getTimeout(req)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(s.pollInterval())
+	defer ticker.Stop()
+
+	endpoint := fmt.Sprintf("%s/indexer/api/v1/index_report/%s", s.config.Scanner.Clair.IndexerURL, hash)
+
+	for {
+		select {
+		case <-timeoutCtx.Done():
+			return fmt.Errorf("timed out waiting for clair index report")
+
+		case <-ticker.C:
+			var report struct {
+				State string `json:"state"`
+				Err   string `json:"err"`
+			}
+
+			err := retry.Do(timeoutCtx, s.retryPolicy(), func(ctx context.Context) (retry.Result, error) {
+				httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+				if err != nil {
+					return retry.Result{}, fmt.Errorf("failed to build index report poll request: %w", err)
+				}
+
+				resp, err := s.httpClient.Do(httpReq)
+				if err != nil {
+					return retry.Result{Retriable: true}, fmt.Errorf("failed to reach clair indexer: %w", err)
+				}
+				defer resp.Body.Close()
+
+				if resp.StatusCode != http.StatusOK {
+					apiErr := fmt.Errorf("clair indexer returned status %d: %s", resp.StatusCode, readBody(resp))
+					if retry.IsRetriableStatusCode(resp.StatusCode) {
+						return retry.Result{Retriable: true}, apiErr
+					}
+					return retry.Result{}, apiErr
+				}
+
+				if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+					return retry.Result{}, fmt.Errorf("failed to decode index report: %w", err)
+				}
+				return retry.Result{}, nil
+			})
+			if err != nil {
+				return err
+			}
+
+			switch report.State {
+			case "IndexFinished":
+				return nil
+			case "IndexError":
+				return fmt.Errorf("clair indexing failed: %s", report.Err)
+			default:
+				// Still indexing; keep polling.
+			}
+		}
+	}
+}
+
+// fetchVulnerabilityReport GETs the Matcher's vulnerability_report for
+// hash and translates it into a models.ScanReport.
+func (s *Scanner) fetchVulnerabilityReport(ctx context.Context, hash string) (*models.ScanReport, error) {
+	endpoint := fmt.Sprintf("%s/matcher/api/v1/vulnerability_report/%s", s.config.Scanner.Clair.MatcherURL, hash)
+
+	var body []byte
+	err := retry.Do(ctx, s.retryPolicy(), func(ctx context.Context) (retry.Result, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return retry.Result{}, fmt.Errorf("failed to build vulnerability report request: %w", err)
+		}
+
+		resp, err := s.httpClient.Do(httpReq)
+		if err != nil {
+			return retry.Result{Retriable: true}, fmt.Errorf("failed to reach clair matcher: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := fmt.Errorf("clair matcher returned status %d: %s", resp.StatusCode, readBody(resp))
+			if retry.IsRetriableStatusCode(resp.StatusCode) {
+				return retry.Result{Retriable: true}, apiErr
+			}
+			return retry.Result{}, apiErr
+		}
+
+		body, err = io.ReadAll(resp.Body)
+		return retry.Result{}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseReport(body)
+}
+
+// basicAuthHeader builds the "Basic <base64(username:password)>" header
+// value http.Request.SetBasicAuth would set, without needing a
+// *http.Request just to read it back off.
+func basicAuthHeader(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// parseImageRef splits an image reference like
+// "registry.example.com/team/app:v1.0.0" or
+// "registry.example.com/team/app@sha256:..." into its registry host,
+// repository path, and tag/digest reference. Duplicates
+// registry_scanner.go's helper of the same name for the same reason
+// getTimeout does.
+func parseImageRef(imageRef string) (host, repository, reference string, err error) {
+	namePart := imageRef
+	reference = "latest"
+
+	if at := strings.LastIndex(imageRef, "@"); at != -1 {
+		namePart = imageRef[:at]
+		reference = imageRef[at+1:]
+	} else if colon := strings.LastIndex(imageRef, ":"); colon != -1 && !strings.Contains(imageRef[colon:], "/") {
+		namePart = imageRef[:colon]
+		reference = imageRef[colon+1:]
+	}
+
+	slash := strings.Index(namePart, "/")
+	if slash == -1 {
+		return "", "", "", fmt.Errorf("image ref %q has no registry host", imageRef)
+	}
+
+	host = namePart[:slash]
+	repository = namePart[slash+1:]
+	if host == "" || repository == "" {
+		return "", "", "", fmt.Errorf("invalid image ref: %q", imageRef)
+	}
+
+	return host, repository, reference, nil
+}
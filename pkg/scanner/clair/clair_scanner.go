@@ -0,0 +1,254 @@
+// Package clair implements a ScannerBackend that talks to a Clair v4
+// Indexer/Matcher pair: it resolves the image manifest, submits an
+// index_report, polls until indexing finishes, then fetches the
+// vulnerability_report and translates it into the same
+// models.ScanResult/models.ScanReport shape scanner.ParseReport produces
+// for the Sysdig CLI Scanner. It exists so operators without a Sysdig
+// subscription can still use this webhook front-end.
+package clair
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/registryauth"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/retry"
+)
+
+// defaultPollInterval is used when ClairScannerConfig.PollInterval isn't
+// set.
+const defaultPollInterval = 5 * time.Second
+
+// manifestAcceptHeader lists the manifest media types this scanner
+// accepts: the two single-image manifest types registry_scanner.go's
+// resolveImageDigest also requests. Manifest lists and OCI image indexes,
+// which resolveImageDigest additionally accepts, are not listed.
+const manifestAcceptHeader = "application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.manifest.v1+json"
+
+// Scanner implements the ScannerBackend interface against a Clair v4
+// Indexer/Matcher pair.
+type Scanner struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	httpClient *http.Client
+
+	registryClientsMu sync.Mutex
+	registryClients   map[string]*http.Client
+}
+
+// NewScanner creates a new Clair Scanner instance.
+func NewScanner(cfg *config.Config, logger *logrus.Logger) *Scanner {
+	return &Scanner{
+		config:          cfg,
+		logger:          logger,
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		registryClients: make(map[string]*http.Client),
+	}
+}
+
+// Type returns the scanner type identifier
+func (s *Scanner) Type() string {
+	return string(config.ScannerTypeClair)
+}
+
+// ValidateConfig validates that Clair is properly configured
+func (s *Scanner) ValidateConfig() error {
+	if s.config.Scanner.Clair == nil {
+		return fmt.Errorf("clair scanner configuration is missing")
+	}
+	if s.config.Scanner.Clair.IndexerURL == "" {
+		return fmt.Errorf("clair indexer URL is required")
+	}
+	if s.config.Scanner.Clair.MatcherURL == "" {
+		return fmt.Errorf("clair matcher URL is required")
+	}
+	return nil
+}
+
+// Scan resolves req's image manifest, indexes it with Clair's Indexer,
+// and fetches its vulnerability report from Clair's Matcher once indexing
+// finishes.
+func (s *Scanner) Scan(ctx context.Context, req *models.ScanRequest) (*models.ScanResult, error) {
+	startTime := time.Now()
+
+	result := &models.ScanResult{
+		ImageRef:  req.ImageRef,
+		RequestID: req.RequestID,
+		Status:    models.ScanStatusRunning,
+		StartedAt: startTime,
+	}
+
+	if s.config.Scanner.Clair == nil {
+		return s.fail(result, startTime, req, fmt.Errorf("clair scanner configuration is missing"))
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":    req.ImageRef,
+		"request_id":   req.RequestID,
+		"scanner_type": "clair",
+	}).Info("Starting Clair scan")
+
+	manifest, err := s.resolveManifest(ctx, req.ImageRef)
+	if err != nil {
+		return s.fail(result, startTime, req, fmt.Errorf("failed to resolve image manifest: %w", err))
+	}
+
+	if err := s.submitIndexReport(ctx, manifest); err != nil {
+		return s.fail(result, startTime, req, fmt.Errorf("failed to submit index report: %w", err))
+	}
+
+	if err := s.pollIndexReport(ctx, req, manifest.Hash); err != nil {
+		return s.fail(result, startTime, req, fmt.Errorf("failed waiting for index report: %w", err))
+	}
+
+	report, err := s.fetchVulnerabilityReport(ctx, manifest.Hash)
+	if err != nil {
+		return s.fail(result, startTime, req, fmt.Errorf("failed to fetch vulnerability report: %w", err))
+	}
+
+	result.Status = models.ScanStatusSuccess
+	result.CompletedAt = time.Now()
+	result.Duration = result.CompletedAt.Sub(startTime)
+	result.Report = report
+
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":  req.ImageRef,
+		"request_id": req.RequestID,
+		"critical":   report.Summary.Critical,
+		"high":       report.Summary.High,
+		"medium":     report.Summary.Medium,
+		"low":        report.Summary.Low,
+		"total":      report.Summary.Total(),
+		"duration":   result.Duration,
+	}).Info("Clair scan completed successfully")
+
+	metrics.RecordScannerType("clair", "success")
+	metrics.RecordScanDuration("clair", "success", result.Duration.Seconds())
+	metrics.RecordScan("clair", req.RegistryName, "success")
+
+	return result, nil
+}
+
+// fail fills in result's failure fields, records failure metrics, and
+// returns it alongside err, mirroring registry_scanner.go's inline
+// failure paths.
+func (s *Scanner) fail(result *models.ScanResult, startTime time.Time, req *models.ScanRequest, err error) (*models.ScanResult, error) {
+	result.Status = models.ScanStatusFailed
+	result.Error = err.Error()
+	result.CompletedAt = time.Now()
+	result.Duration = result.CompletedAt.Sub(startTime)
+
+	metrics.RecordScannerType("clair", "failed")
+	metrics.RecordScanDuration("clair", "failed", result.Duration.Seconds())
+	metrics.RecordScan("clair", req.RegistryName, "failed")
+
+	return result, err
+}
+
+// retryPolicy builds the retry.Policy used for Indexer/Matcher API calls,
+// honoring ClairScannerConfig.MaxAttempts when configured.
+func (s *Scanner) retryPolicy() retry.Policy {
+	policy := retry.DefaultPolicy
+	if s.config.Scanner.Clair.MaxAttempts > 0 {
+		policy.MaxAttempts = s.config.Scanner.Clair.MaxAttempts
+	}
+	return policy
+}
+
+// pollInterval returns ClairScannerConfig.PollInterval, falling back to
+// defaultPollInterval if unset or invalid.
+func (s *Scanner) pollInterval() time.Duration {
+	if s.config.Scanner.Clair.PollInterval == "" {
+		return defaultPollInterval
+	}
+	d, err := time.ParseDuration(s.config.Scanner.Clair.PollInterval)
+	if err != nil {
+		return defaultPollInterval
+	}
+	return d
+}
+
+// getTimeout returns the timeout duration for a scan request, duplicating
+// registry_scanner.go's getTimeout for the same reason trivy.Scanner's
+// does: the two backends share a config shape but not a package.
+func (s *Scanner) getTimeout(req *models.ScanRequest) (time.Duration, error) {
+	for _, reg := range s.config.Registries {
+		if reg.Name == req.RegistryName && reg.Scanner.Timeout != "" {
+			return time.ParseDuration(reg.Scanner.Timeout)
+		}
+	}
+	return time.ParseDuration(s.config.Scanner.DefaultTimeout)
+}
+
+// registryClient returns an http.Client that transparently authenticates
+// against host, scoped to pull access on repository, reusing any cached
+// bearer token across calls for the same (host, repository) pair.
+// Duplicates registry_scanner.go's registryClient for the same reason
+// getTimeout does.
+func (s *Scanner) registryClient(host, repository string) *http.Client {
+	key := host + "|" + repository
+
+	s.registryClientsMu.Lock()
+	defer s.registryClientsMu.Unlock()
+
+	if client, ok := s.registryClients[key]; ok {
+		return client
+	}
+
+	creds := s.registryCredentials(host)
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+
+	transport := registryauth.NewTransport(
+		http.DefaultTransport,
+		registryauth.NewChallengeManager(),
+		registryauth.NewTokenHandler(http.DefaultTransport, creds, scope),
+		registryauth.NewBasicHandler(creds),
+	)
+
+	client := &http.Client{Transport: transport, Timeout: 15 * time.Second}
+	s.registryClients[key] = client
+	return client
+}
+
+// registryCredentials looks up the static username/password configured
+// for host under the owning registry's Scanner.Credentials, for the
+// layer-fetch headers Clair's Indexer uses to pull blobs from private
+// registries.
+func (s *Scanner) registryCredentials(host string) registryauth.CredentialStore {
+	for _, reg := range s.config.Registries {
+		if registryHostname(reg.URL) == host {
+			return &registryauth.StaticCredentialStore{
+				Username: reg.Scanner.Credentials.Username,
+				Password: reg.Scanner.Credentials.Password,
+			}
+		}
+	}
+	return &registryauth.StaticCredentialStore{}
+}
+
+// registryHostname strips the scheme from a configured registry URL,
+// duplicating cli_scanner.go's helper of the same name for the same
+// reason getTimeout does.
+func registryHostname(registryURL string) string {
+	host := strings.TrimPrefix(registryURL, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return host
+}
+
+// readBody reads and closes resp.Body, returning its content for error
+// messages.
+func readBody(resp *http.Response) string {
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	return string(body)
+}
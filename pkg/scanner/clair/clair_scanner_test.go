@@ -0,0 +1,260 @@
+package clair
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func TestScanner_Type(t *testing.T) {
+	cfg := &config.Config{}
+	scanner := NewScanner(cfg, logrus.New())
+
+	got := scanner.Type()
+	want := "clair"
+
+	if got != want {
+		t.Errorf("Type() = %v, want %v", got, want)
+	}
+}
+
+func TestScanner_ValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *config.Config
+		wantErr bool
+	}{
+		{
+			name: "valid config",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					Clair: &config.ClairScannerConfig{
+						IndexerURL: "http://clair-indexer:6060",
+						MatcherURL: "http://clair-matcher:6060",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing indexer URL",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					Clair: &config.ClairScannerConfig{MatcherURL: "http://clair-matcher:6060"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing matcher URL",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					Clair: &config.ClairScannerConfig{IndexerURL: "http://clair-indexer:6060"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "nil Clair config",
+			config:  &config.Config{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner(tt.config, logrus.New())
+			err := scanner.ValidateConfig()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScanner_getTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *config.Config
+		req     *models.ScanRequest
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name: "use default timeout",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{DefaultTimeout: "300s"},
+			},
+			req:     &models.ScanRequest{RegistryName: "test-registry"},
+			want:    300 * time.Second,
+			wantErr: false,
+		},
+		{
+			name: "use registry-specific timeout",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{DefaultTimeout: "300s"},
+				Registries: []config.RegistryConfig{
+					{
+						Name:    "test-registry",
+						Scanner: config.ScannerOverride{Timeout: "600s"},
+					},
+				},
+			},
+			req:     &models.ScanRequest{RegistryName: "test-registry"},
+			want:    600 * time.Second,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner(tt.config, logrus.New())
+			got, err := scanner.getTimeout(tt.req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getTimeout() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("getTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_pollInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.Config
+		want   time.Duration
+	}{
+		{
+			name:   "falls back to default when unset",
+			config: &config.Config{Scanner: config.ScannerConfig{Clair: &config.ClairScannerConfig{}}},
+			want:   defaultPollInterval,
+		},
+		{
+			name: "falls back to default when invalid",
+			config: &config.Config{Scanner: config.ScannerConfig{
+				Clair: &config.ClairScannerConfig{PollInterval: "not-a-duration"},
+			}},
+			want: defaultPollInterval,
+		},
+		{
+			name: "uses configured interval",
+			config: &config.Config{Scanner: config.ScannerConfig{
+				Clair: &config.ClairScannerConfig{PollInterval: "2s"},
+			}},
+			want: 2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner(tt.config, logrus.New())
+			if got := scanner.pollInterval(); got != tt.want {
+				t.Errorf("pollInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryHostname(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://registry.example.com", "registry.example.com"},
+		{"http://localhost:5000", "localhost:5000"},
+		{"registry.example.com", "registry.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := registryHostname(tt.in); got != tt.want {
+			t.Errorf("registryHostname(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		name           string
+		ref            string
+		wantHost       string
+		wantRepository string
+		wantReference  string
+		wantErr        bool
+	}{
+		{
+			name:           "tag reference",
+			ref:            "registry.example.com/team/app:v1.0.0",
+			wantHost:       "registry.example.com",
+			wantRepository: "team/app",
+			wantReference:  "v1.0.0",
+		},
+		{
+			name:           "digest reference",
+			ref:            "registry.example.com/team/app@sha256:abc123",
+			wantHost:       "registry.example.com",
+			wantRepository: "team/app",
+			wantReference:  "sha256:abc123",
+		},
+		{
+			name:           "no tag defaults to latest",
+			ref:            "registry.example.com/team/app",
+			wantHost:       "registry.example.com",
+			wantRepository: "team/app",
+			wantReference:  "latest",
+		},
+		{
+			name:    "missing registry host",
+			ref:     "app:v1.0.0",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, repository, reference, err := parseImageRef(tt.ref)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseImageRef() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if host != tt.wantHost || repository != tt.wantRepository || reference != tt.wantReference {
+				t.Errorf("parseImageRef() = (%v, %v, %v), want (%v, %v, %v)", host, repository, reference, tt.wantHost, tt.wantRepository, tt.wantReference)
+			}
+		})
+	}
+}
+
+func TestNewScanner(t *testing.T) {
+	cfg := &config.Config{
+		Scanner: config.ScannerConfig{
+			Clair: &config.ClairScannerConfig{IndexerURL: "http://indexer", MatcherURL: "http://matcher"},
+		},
+	}
+
+	logger := logrus.New()
+	scanner := NewScanner(cfg, logger)
+
+	if scanner == nil {
+		t.Fatal("NewScanner() returned nil")
+	}
+	if scanner.config != cfg {
+		t.Error("NewScanner() did not set config correctly")
+	}
+	if scanner.logger != logger {
+		t.Error("NewScanner() did not set logger correctly")
+	}
+	if scanner.Type() != "clair" {
+		t.Errorf("NewScanner() Type() = %v, want 'clair'", scanner.Type())
+	}
+}
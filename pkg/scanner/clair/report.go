@@ -0,0 +1,105 @@
+package clair
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// rawVulnerabilityReport is the subset of Clair v4's
+// GET /matcher/api/v1/vulnerability_report/{manifest} response this
+// package understands: a map of vulnerability ID to vulnerability detail,
+// plus a map from package ID to the vulnerability IDs affecting it.
+type rawVulnerabilityReport struct {
+	Vulnerabilities        map[string]rawVulnerability `json:"vulnerabilities"`
+	PackageVulnerabilities map[string][]string         `json:"package_vulnerabilities"`
+	Packages               map[string]rawPackage       `json:"packages"`
+}
+
+type rawVulnerability struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Description        string `json:"description"`
+	FixedInVersion     string `json:"fixed_in_version"`
+	Links              string `json:"links"`
+	NormalizedSeverity string `json:"normalized_severity"`
+}
+
+type rawPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ParseReport unmarshals body as a Clair v4 vulnerability_report,
+// returning a models.ScanReport with per-finding detail. An empty body
+// isn't an error: it means the image has no vulnerability_report content
+// this package recognizes, so callers get a nil report.
+func ParseReport(body []byte) (*models.ScanReport, error) {
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var raw rawVulnerabilityReport
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse clair vulnerability report: %w", err)
+	}
+
+	report := &models.ScanReport{}
+
+	for pkgID, vulnIDs := range raw.PackageVulnerabilities {
+		pkg := raw.Packages[pkgID]
+
+		for _, vulnID := range vulnIDs {
+			v, ok := raw.Vulnerabilities[vulnID]
+			if !ok {
+				continue
+			}
+
+			severity := normalizeSeverity(v.NormalizedSeverity)
+
+			report.Vulnerabilities = append(report.Vulnerabilities, models.Vulnerability{
+				ID:             v.Name,
+				Severity:       severity,
+				PackageName:    pkg.Name,
+				PackageVersion: pkg.Version,
+				FixedVersion:   v.FixedInVersion,
+				Description:    v.Description,
+				Link:           v.Links,
+			})
+
+			switch severity {
+			case "Critical":
+				report.Summary.Critical++
+			case "High":
+				report.Summary.High++
+			case "Medium":
+				report.Summary.Medium++
+			case "Low":
+				report.Summary.Low++
+			default:
+				report.Summary.Negligible++
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// normalizeSeverity maps Clair's NormalizedSeverity strings onto this
+// repo's vocabulary. Mirrors scanner.normalizeSeverity's mapping for the
+// Sysdig CLI Scanner and trivy.normalizeSeverity's for Trivy.
+func normalizeSeverity(severity string) string {
+	switch severity {
+	case "Critical":
+		return "Critical"
+	case "High":
+		return "High"
+	case "Medium":
+		return "Medium"
+	case "Low":
+		return "Low"
+	default:
+		return "Negligible"
+	}
+}
@@ -0,0 +1,92 @@
+package clair
+
+import "testing"
+
+func TestParseReport_Empty(t *testing.T) {
+	report, err := ParseReport(nil)
+	if err != nil {
+		t.Errorf("ParseReport() error = %v, want nil", err)
+	}
+	if report != nil {
+		t.Errorf("ParseReport() = %v, want nil", report)
+	}
+}
+
+func TestParseReport_InvalidJSON(t *testing.T) {
+	_, err := ParseReport([]byte("not json"))
+	if err == nil {
+		t.Error("ParseReport() error = nil, want error for invalid JSON")
+	}
+}
+
+func TestParseReport_Vulnerabilities(t *testing.T) {
+	body := []byte(`{
+		"packages": {
+			"1": {"name": "openssl", "version": "1.1.1"},
+			"2": {"name": "busybox", "version": "1.30"}
+		},
+		"vulnerabilities": {
+			"v1": {
+				"id": "v1",
+				"name": "CVE-2023-1234",
+				"description": "a bad bug",
+				"fixed_in_version": "1.1.2",
+				"links": "https://example.com/CVE-2023-1234",
+				"normalized_severity": "Critical"
+			},
+			"v2": {
+				"id": "v2",
+				"name": "CVE-2023-5678",
+				"normalized_severity": "Low"
+			}
+		},
+		"package_vulnerabilities": {
+			"1": ["v1"],
+			"2": ["v2"]
+		}
+	}`)
+
+	report, err := ParseReport(body)
+	if err != nil {
+		t.Fatalf("ParseReport() error = %v, want nil", err)
+	}
+
+	if len(report.Vulnerabilities) != 2 {
+		t.Fatalf("ParseReport() vulnerabilities = %d, want 2", len(report.Vulnerabilities))
+	}
+
+	if report.Summary.Critical != 1 || report.Summary.Low != 1 {
+		t.Errorf("ParseReport() summary = %+v, want Critical=1 Low=1", report.Summary)
+	}
+
+	var critical *bool
+	for _, v := range report.Vulnerabilities {
+		if v.ID == "CVE-2023-1234" {
+			ok := v.Severity == "Critical" && v.PackageName == "openssl" && v.FixedVersion == "1.1.2"
+			critical = &ok
+		}
+	}
+	if critical == nil || !*critical {
+		t.Errorf("ParseReport() vulnerabilities = %+v, want CVE-2023-1234 mapped to openssl/Critical", report.Vulnerabilities)
+	}
+}
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Critical", "Critical"},
+		{"High", "High"},
+		{"Medium", "Medium"},
+		{"Low", "Low"},
+		{"Unknown", "Negligible"},
+		{"", "Negligible"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeSeverity(tt.in); got != tt.want {
+			t.Errorf("normalizeSeverity(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
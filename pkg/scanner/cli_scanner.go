@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 	"time"
@@ -11,21 +12,45 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/events"
+	"github.com/sysdig/registry-webhook-scanner/pkg/imagecache"
 	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
 )
 
 // CLIScanner wraps the Sysdig CLI Scanner
 type CLIScanner struct {
-	config *config.Config
-	logger *logrus.Logger
+	config      *config.Config
+	logger      *logrus.Logger
+	credentials *CredentialProvider
+	eventBus    *events.Bus
+	imageCache  *imagecache.Cache
 }
 
 // NewCLIScanner creates a new CLIScanner instance
 func NewCLIScanner(cfg *config.Config, logger *logrus.Logger) *CLIScanner {
-	return &CLIScanner{
-		config: cfg,
-		logger: logger,
+	s := &CLIScanner{
+		config:      cfg,
+		logger:      logger,
+		credentials: NewCredentialProvider(cfg, logger),
 	}
+
+	if cfg.Scanner.ImageCache != nil && cfg.Scanner.ImageCache.Enabled {
+		cache, err := imagecache.NewCache(cfg.Scanner.ImageCache, logger)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to initialize image cache, CLI Scanner will pull images itself")
+		} else {
+			s.imageCache = cache
+		}
+	}
+
+	return s
+}
+
+// SetEventBus attaches bus, so Scan publishes its lifecycle events
+// (events.TypeStarted/TypeCompleted/TypeFailed/TypeTimeout) and streams
+// the scanner's stderr as events.TypeLog lines, satisfying EventPublisher.
+func (s *CLIScanner) SetEventBus(bus *events.Bus) {
+	s.eventBus = bus
 }
 
 // Type returns the scanner type identifier
@@ -50,22 +75,68 @@ func (s *CLIScanner) Scan(ctx context.Context, req *models.ScanRequest) (*models
 		"scanner_type": "cli",
 	}).Info("Starting CLI Scanner image scan")
 
+	// Every return path below reports result's final status as a
+	// TypeCompleted/TypeFailed/TypeTimeout event, including the early
+	// ones that bail out before a process is even started.
+	if s.eventBus != nil {
+		defer func() {
+			s.publishFinalEvent(req.RequestID, result)
+		}()
+	}
+
+	// When an image cache is configured, pre-pull the image into its
+	// shared content-addressable store and point the scanner at the
+	// resulting local OCI layout instead of letting it pull the image
+	// itself - so back-to-back scans of tags sharing a base image reuse
+	// layers already on disk. A pull failure falls back to letting the
+	// scanner pull the image itself rather than failing the whole scan.
+	var localDir string
+	if s.imageCache != nil {
+		pulled, err := s.imageCache.Pull(ctx, req.ImageRef, req.RegistryName, s.config.Registries)
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"image_ref":  req.ImageRef,
+				"request_id": req.RequestID,
+				"error":      err.Error(),
+			}).Warn("Image cache pull failed, falling back to scanner-managed pull")
+		} else {
+			localDir = pulled.Dir
+			defer pulled.Cleanup()
+		}
+	}
+
 	// Build scanner command
-	cmd, err := s.buildCommand(ctx, req)
+	cmd, cleanupCredentials, err := s.buildCommand(ctx, req, localDir)
 	if err != nil {
 		result.Status = models.ScanStatusFailed
 		result.Error = fmt.Sprintf("failed to build command: %v", err)
 		return result, err
 	}
+	defer cleanupCredentials()
 
 	// Capture stdout and stderr
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+
+	// When an event bus is attached, stderr is also streamed live as
+	// events.TypeLog events (see events.Bus.Writer) in addition to being
+	// captured whole for ScanResult.ErrorOutput below.
+	var stderrLog io.WriteCloser
+	if s.eventBus != nil {
+		stderrLog = s.eventBus.Writer(req.RequestID)
+		cmd.Stderr = io.MultiWriter(&stderr, stderrLog)
+		s.eventBus.Publish(events.Event{Type: events.TypeStarted, RequestID: req.RequestID, Timestamp: time.Now()})
+	} else {
+		cmd.Stderr = &stderr
+	}
 
 	// Execute scanner with timeout
 	err = s.executeWithTimeout(ctx, cmd, req)
 
+	if stderrLog != nil {
+		stderrLog.Close()
+	}
+
 	// Capture output
 	result.Output = stdout.String()
 	result.ErrorOutput = stderr.String()
@@ -101,6 +172,8 @@ func (s *CLIScanner) Scan(ctx context.Context, req *models.ScanRequest) (*models
 				"scanner_type": "cli",
 			}).Info("CLI Scanner completed with vulnerabilities found")
 
+			s.attachReport(result)
+
 			// Record metrics
 			metrics.RecordScannerType("cli", "success")
 			metrics.RecordScanDuration("cli", "success", result.Duration.Seconds())
@@ -138,6 +211,8 @@ func (s *CLIScanner) Scan(ctx context.Context, req *models.ScanRequest) (*models
 		"scanner_type": "cli",
 	}).Info("CLI Scanner completed successfully")
 
+	s.attachReport(result)
+
 	// Record metrics
 	metrics.RecordScannerType("cli", "success")
 	metrics.RecordScanDuration("cli", "success", result.Duration.Seconds())
@@ -146,45 +221,105 @@ func (s *CLIScanner) Scan(ctx context.Context, req *models.ScanRequest) (*models
 	return result, nil
 }
 
-// buildScanArgs constructs the arguments for the Sysdig CLI scanner
-func (s *CLIScanner) buildScanArgs(req *models.ScanRequest) []string {
-	args := []string{
-		req.ImageRef,
-		"--apiurl", "https://secure.sysdig.com",
+// publishFinalEvent publishes the terminal lifecycle event matching
+// result.Status. Called via defer from Scan so it fires on every return
+// path, including ones where a process was never started.
+func (s *CLIScanner) publishFinalEvent(requestID string, result *models.ScanResult) {
+	eventType := events.TypeCompleted
+	switch result.Status {
+	case models.ScanStatusFailed:
+		eventType = events.TypeFailed
+	case models.ScanStatusTimeout:
+		eventType = events.TypeTimeout
 	}
 
-	// Add registry credentials if configured
-	if req.RegistryName != "" {
-		for _, reg := range s.config.Registries {
-			if reg.Name == req.RegistryName {
-				if reg.Scanner.Credentials.Username != "" {
-					args = append(args, "--registry-user", reg.Scanner.Credentials.Username)
-				}
-				if reg.Scanner.Credentials.Password != "" {
-					args = append(args, "--registry-password", reg.Scanner.Credentials.Password)
-				}
-				break
-			}
-		}
+	s.eventBus.Publish(events.Event{
+		Type:      eventType,
+		RequestID: requestID,
+		Message:   result.Error,
+		Timestamp: time.Now(),
+	})
+}
+
+// attachReport parses result.Output as Sysdig CLI Scanner JSON and, if
+// recognized, sets result.Report and logs its vulnerability/policy
+// counts. A parse failure is logged and otherwise ignored: result.Output
+// is still returned to the caller raw, so nothing is lost.
+func (s *CLIScanner) attachReport(result *models.ScanResult) {
+	report, err := ParseReport(result.Output)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"image_ref":  result.ImageRef,
+			"request_id": result.RequestID,
+			"error":      err.Error(),
+		}).Warn("Failed to parse CLI Scanner JSON report")
+		return
+	}
+	if report == nil {
+		return
 	}
 
-	// Add JSON output for easier parsing
-	args = append(args, "--json-scan-result", "/dev/stdout")
+	result.Report = report
 
-	return args
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":       result.ImageRef,
+		"request_id":      result.RequestID,
+		"critical":        report.Summary.Critical,
+		"high":            report.Summary.High,
+		"medium":          report.Summary.Medium,
+		"low":             report.Summary.Low,
+		"total":           report.Summary.Total(),
+		"failed_policies": result.FailedPolicies(),
+	}).Info("CLI Scanner report parsed")
 }
 
-// buildCommand constructs the Sysdig CLI scanner command
-func (s *CLIScanner) buildCommand(ctx context.Context, req *models.ScanRequest) (*exec.Cmd, error) {
-	args := s.buildScanArgs(req)
+// buildScanArgs constructs the arguments for the Sysdig CLI scanner. When
+// localDir is set (an imagecache.Cache pull succeeded), the scanner reads
+// the already-pulled OCI layout from disk instead of pulling req.ImageRef
+// itself, so no registry credentials need to be passed through.
+func (s *CLIScanner) buildScanArgs(req *models.ScanRequest, localDir string) []string {
+	if localDir != "" {
+		return []string{
+			"--storage-type", "oci-dir", localDir,
+			"--apiurl", "https://secure.sysdig.com",
+			"--json-scan-result", "/dev/stdout",
+		}
+	}
 
-	// Create command
-	cmd := exec.CommandContext(ctx, s.config.Scanner.CLIPath, args...)
+	return []string{
+		req.ImageRef,
+		"--apiurl", "https://secure.sysdig.com",
+		"--json-scan-result", "/dev/stdout",
+	}
+}
+
+// buildCommand constructs the Sysdig CLI scanner command. localDir is
+// forwarded to buildScanArgs; see its doc comment. When localDir is
+// empty, the scanner pulls req.ImageRef itself, so any registry
+// credentials are resolved here and materialized into a temporary
+// DOCKER_CONFIG directory (see CredentialProvider.MaterializeDockerConfig)
+// rather than passed as argv flags or put in the process's own
+// environment; the caller must run the returned cleanup once cmd has
+// exited.
+func (s *CLIScanner) buildCommand(ctx context.Context, req *models.ScanRequest, localDir string) (*exec.Cmd, func(), error) {
+	args := s.buildScanArgs(req, localDir)
 
-	// Set environment variables for authentication
+	cmd := exec.CommandContext(ctx, s.config.Scanner.CLIPath, args...)
 	cmd.Env = append(cmd.Env, fmt.Sprintf("SYSDIG_API_TOKEN=%s", s.config.Scanner.SysdigToken))
 
-	return cmd, nil
+	cleanup := func() {}
+	if localDir == "" {
+		dockerConfigDir, dirCleanup, err := s.credentials.MaterializeDockerConfig(req)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to materialize registry credentials: %w", err)
+		}
+		if dockerConfigDir != "" {
+			cmd.Env = append(cmd.Env, "DOCKER_CONFIG="+dockerConfigDir)
+			cleanup = dirCleanup
+		}
+	}
+
+	return cmd, cleanup, nil
 }
 
 // executeWithTimeout executes the command with a timeout
@@ -273,3 +408,12 @@ func (s *CLIScanner) FormatImageRef(req *models.ScanRequest) string {
 
 	return req.ImageRef
 }
+
+// registryHostname strips the scheme from a configured registry URL,
+// since Docker/OCI config.json keys its "auths"/"credHelpers" entries by
+// bare hostname.
+func registryHostname(registryURL string) string {
+	host := strings.TrimPrefix(registryURL, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return host
+}
@@ -2,6 +2,9 @@ package scanner
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -120,17 +123,13 @@ func TestCLIScanner_buildScanArgs(t *testing.T) {
 			wantContain: []string{
 				"registry.example.com/myimage:v1.0.0",
 				"--apiurl",
-				"--registry-user",
-				"testuser",
-				"--registry-password",
-				"testpass",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			args := scanner.buildScanArgs(tt.req)
+			args := scanner.buildScanArgs(tt.req, "")
 
 			for _, want := range tt.wantContain {
 				found := false
@@ -148,6 +147,127 @@ func TestCLIScanner_buildScanArgs(t *testing.T) {
 	}
 }
 
+func TestCLIScanner_buildScanArgs_localDir(t *testing.T) {
+	cfg := &config.Config{
+		Scanner: config.ScannerConfig{Type: config.ScannerTypeCLI, SysdigToken: "test-token-12345"},
+	}
+	scanner := NewCLIScanner(cfg, logrus.New())
+
+	req := &models.ScanRequest{ImageRef: "registry.example.com/myimage:v1.0.0", RequestID: "req-123"}
+
+	args := scanner.buildScanArgs(req, "/var/cache/scanner-webhook/images/layouts/layout-abc123")
+
+	wantContain := []string{"--storage-type", "oci-dir", "/var/cache/scanner-webhook/images/layouts/layout-abc123"}
+	for _, want := range wantContain {
+		found := false
+		for _, arg := range args {
+			if arg == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("buildScanArgs() missing expected arg %q, got args: %v", want, args)
+		}
+	}
+
+	for _, arg := range args {
+		if arg == req.ImageRef {
+			t.Errorf("buildScanArgs() with localDir should not reference ImageRef directly, got args: %v", args)
+		}
+	}
+}
+
+// Test task 9.5: registry credentials reach the CLI scanner via a
+// materialized DOCKER_CONFIG directory, not argv or its own environment.
+func TestCLIScanner_buildCommand_MaterializesDockerConfig(t *testing.T) {
+	cfg := &config.Config{
+		Scanner: config.ScannerConfig{Type: config.ScannerTypeCLI, SysdigToken: "test-token-12345"},
+		Registries: []config.RegistryConfig{
+			{
+				Name: "test-registry",
+				Scanner: config.ScannerOverride{
+					Credentials: config.RegistryCredentials{Username: "testuser", Password: "testpass"},
+				},
+			},
+		},
+	}
+	scanner := NewCLIScanner(cfg, logrus.New())
+
+	req := &models.ScanRequest{
+		ImageRef:     "registry.example.com/myimage:v1.0.0",
+		RequestID:    "req-123",
+		RegistryName: "test-registry",
+	}
+
+	cmd, cleanup, err := scanner.buildCommand(context.Background(), req, "")
+	if err != nil {
+		t.Fatalf("buildCommand() error = %v, want nil", err)
+	}
+	defer cleanup()
+
+	var dockerConfigDir string
+	for _, arg := range []string{"--registry-user", "--registry-password", "--registry-token"} {
+		for _, a := range cmd.Args {
+			if a == arg {
+				t.Errorf("buildCommand() args contain %q, want credentials kept out of argv", arg)
+			}
+		}
+	}
+	for _, env := range cmd.Env {
+		if strings.HasPrefix(env, "DOCKER_CONFIG=") {
+			dockerConfigDir = strings.TrimPrefix(env, "DOCKER_CONFIG=")
+		}
+		if strings.HasPrefix(env, "REGISTRY_USERNAME=") || strings.HasPrefix(env, "REGISTRY_PASSWORD=") {
+			t.Errorf("buildCommand() env contains %q, want credentials kept out of the process environment", env)
+		}
+	}
+	if dockerConfigDir == "" {
+		t.Fatal("buildCommand() did not set DOCKER_CONFIG")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dockerConfigDir, "config.json"))
+	if err != nil {
+		t.Fatalf("failed to read materialized config.json: %v", err)
+	}
+	if !strings.Contains(string(data), "registry.example.com") {
+		t.Errorf("config.json = %s, want an entry for registry.example.com", data)
+	}
+
+	cleanup()
+	if _, err := os.Stat(dockerConfigDir); !os.IsNotExist(err) {
+		t.Errorf("cleanup() left %s behind", dockerConfigDir)
+	}
+}
+
+func TestCLIScanner_buildCommand_NoCredentials_NoDockerConfig(t *testing.T) {
+	cfg := &config.Config{
+		Scanner: config.ScannerConfig{Type: config.ScannerTypeCLI, SysdigToken: "test-token-12345"},
+		Registries: []config.RegistryConfig{
+			{Name: "public-registry"},
+		},
+	}
+	scanner := NewCLIScanner(cfg, logrus.New())
+
+	req := &models.ScanRequest{
+		ImageRef:     "registry.example.com/myimage:v1.0.0",
+		RequestID:    "req-123",
+		RegistryName: "public-registry",
+	}
+
+	cmd, cleanup, err := scanner.buildCommand(context.Background(), req, "")
+	if err != nil {
+		t.Fatalf("buildCommand() error = %v, want nil", err)
+	}
+	defer cleanup()
+
+	for _, env := range cmd.Env {
+		if strings.HasPrefix(env, "DOCKER_CONFIG=") {
+			t.Errorf("buildCommand() env = %v, want no DOCKER_CONFIG when no credentials are configured", cmd.Env)
+		}
+	}
+}
+
 func TestCLIScanner_getTimeout(t *testing.T) {
 	tests := []struct {
 		name    string
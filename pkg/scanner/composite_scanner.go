@@ -0,0 +1,356 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/logging"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/clair"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/trivy"
+)
+
+// CompositeScanner fans a scan out across a configurable set of member
+// backends concurrently, waits for all of them, and reconciles their
+// results into a single models.ScanResult. It's meant for migrating
+// between backends: operators can shadow-scan with both "cli" and
+// "registry", compare findings, and cut over once satisfied.
+type CompositeScanner struct {
+	config   *config.Config
+	logger   *logrus.Logger
+	backends []namedBackend
+	policy   config.ReconciliationPolicy
+	failFast bool
+}
+
+// namedBackend pairs a constructed ScannerBackend with the configured
+// type name it was built from, since ScannerBackend.Type() can't be
+// assumed to round-trip through config.ScannerType exactly.
+type namedBackend struct {
+	scannerType config.ScannerType
+	backend     ScannerBackend
+}
+
+// NewCompositeScanner creates a CompositeScanner from cfg.Scanner.Composite.
+func NewCompositeScanner(cfg *config.Config, logger *logrus.Logger) (*CompositeScanner, error) {
+	if cfg.Scanner.Composite == nil {
+		return nil, fmt.Errorf("scanner.composite configuration is missing")
+	}
+
+	composite := cfg.Scanner.Composite
+	if len(composite.Backends) == 0 {
+		return nil, fmt.Errorf("scanner.composite.backends must list at least one backend")
+	}
+
+	policy := composite.Policy
+	if policy == "" {
+		policy = config.ReconciliationWorstSeverity
+	}
+
+	backends := make([]namedBackend, 0, len(composite.Backends))
+	for _, backendType := range composite.Backends {
+		backend, err := newMemberBackend(backendType, cfg, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create composite member backend %q: %w", backendType, err)
+		}
+		backends = append(backends, namedBackend{scannerType: backendType, backend: backend})
+	}
+
+	return &CompositeScanner{
+		config:   cfg,
+		logger:   logger,
+		backends: backends,
+		policy:   policy,
+		failFast: composite.FailOnPartialError,
+	}, nil
+}
+
+// newMemberBackend constructs a single backend directly from its type,
+// bypassing determineScannerType's per-registry override lookup since
+// composite mode explicitly lists the backends it wants to run.
+func newMemberBackend(backendType config.ScannerType, cfg *config.Config, logger *logrus.Logger) (ScannerBackend, error) {
+	switch backendType {
+	case config.ScannerTypeCLI:
+		return NewCLIScanner(cfg, logger), nil
+	case config.ScannerTypeRegistry:
+		return NewRegistryScanner(cfg, logger), nil
+	case config.ScannerTypeTrivy:
+		return trivy.NewScanner(cfg, logger), nil
+	case config.ScannerTypeClair:
+		return clair.NewScanner(cfg, logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported composite member backend type: %s", backendType)
+	}
+}
+
+// Type returns the scanner type identifier
+func (s *CompositeScanner) Type() string {
+	return string(config.ScannerTypeComposite)
+}
+
+// ValidateConfig validates every member backend's configuration.
+func (s *CompositeScanner) ValidateConfig() error {
+	for _, nb := range s.backends {
+		if err := nb.backend.ValidateConfig(); err != nil {
+			return fmt.Errorf("composite member %q validation failed: %w", nb.scannerType, err)
+		}
+	}
+	return nil
+}
+
+// memberResult is one backend's outcome from a fanned-out scan.
+type memberResult struct {
+	scannerType config.ScannerType
+	result      *models.ScanResult
+	summary     *ScanSummary
+	err         error
+}
+
+// Scan runs every member backend concurrently against req, each bounded
+// by its own configured timeout, and reconciles their results per the
+// configured policy.
+func (s *CompositeScanner) Scan(ctx context.Context, req *models.ScanRequest) (*models.ScanResult, error) {
+	startTime := time.Now()
+
+	outcomes := s.scanMembers(ctx, req)
+
+	for _, o := range outcomes {
+		status := "success"
+		if o.err != nil {
+			status = "failed"
+		}
+		metrics.RecordScan(string(o.scannerType), req.RegistryName, status)
+	}
+
+	succeeded := make([]memberResult, 0, len(outcomes))
+	var firstErr error
+	for _, o := range outcomes {
+		if o.err != nil {
+			if firstErr == nil {
+				firstErr = o.err
+			}
+			continue
+		}
+		succeeded = append(succeeded, o)
+	}
+
+	if len(succeeded) == 0 {
+		duration := time.Since(startTime)
+		metrics.RecordScannerType("composite", "failed")
+		metrics.RecordScanDuration("composite", "failed", duration.Seconds())
+		return &models.ScanResult{
+			ImageRef:    req.ImageRef,
+			RequestID:   req.RequestID,
+			Status:      models.ScanStatusFailed,
+			Error:       fmt.Sprintf("all composite member backends failed: %v", firstErr),
+			StartedAt:   startTime,
+			CompletedAt: time.Now(),
+			Duration:    duration,
+		}, fmt.Errorf("all composite member backends failed: %w", firstErr)
+	}
+
+	if s.failFast && firstErr != nil {
+		duration := time.Since(startTime)
+		metrics.RecordScannerType("composite", "failed")
+		metrics.RecordScanDuration("composite", "failed", duration.Seconds())
+		return &models.ScanResult{
+			ImageRef:    req.ImageRef,
+			RequestID:   req.RequestID,
+			Status:      models.ScanStatusFailed,
+			Error:       fmt.Sprintf("composite member backend failed: %v", firstErr),
+			StartedAt:   startTime,
+			CompletedAt: time.Now(),
+			Duration:    duration,
+		}, fmt.Errorf("composite member backend failed: %w", firstErr)
+	}
+
+	result := s.reconcile(succeeded)
+	result.ImageRef = req.ImageRef
+	result.RequestID = req.RequestID
+	result.Status = models.ScanStatusSuccess
+	result.StartedAt = startTime
+	result.CompletedAt = time.Now()
+	result.Duration = result.CompletedAt.Sub(startTime)
+
+	metrics.RecordScannerType("composite", "success")
+	metrics.RecordScanDuration("composite", "success", result.Duration.Seconds())
+
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":  req.ImageRef,
+		"request_id": req.RequestID,
+		"policy":     s.policy,
+		"backends":   len(s.backends),
+		"succeeded":  len(succeeded),
+	}).Info("Composite scan completed")
+
+	return result, nil
+}
+
+// scanMembers runs every member backend concurrently, each bounded by its
+// own configured timeout, and returns one outcome per backend.
+func (s *CompositeScanner) scanMembers(ctx context.Context, req *models.ScanRequest) []memberResult {
+	outcomes := make([]memberResult, len(s.backends))
+
+	var wg sync.WaitGroup
+	for i, nb := range s.backends {
+		wg.Add(1)
+		go func(i int, nb namedBackend) {
+			defer wg.Done()
+
+			timeout, err := scanTimeout(s.config, req)
+			if err != nil {
+				outcomes[i] = memberResult{scannerType: nb.scannerType, err: fmt.Errorf("invalid timeout: %w", err)}
+				return
+			}
+
+			memberCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result, err := nb.backend.Scan(memberCtx, req)
+			if err != nil {
+				outcomes[i] = memberResult{scannerType: nb.scannerType, result: result, err: err}
+				return
+			}
+
+			rp := NewResultProcessor(0, logging.WrapLogrus(s.logger))
+			summary, _ := rp.parseScanOutput(result.Output)
+
+			outcomes[i] = memberResult{scannerType: nb.scannerType, result: result, summary: summary}
+		}(i, nb)
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+// reconcile merges succeeded member results into one models.ScanResult
+// per the configured policy. PrimaryWithFallback and WorstSeverity return
+// the chosen member's own result; Union and Intersection return a new
+// result with only Output set. Callers then overwrite the request-scoped
+// fields (ImageRef, RequestID, Status, timestamps).
+func (s *CompositeScanner) reconcile(succeeded []memberResult) *models.ScanResult {
+	switch s.policy {
+	case config.ReconciliationPrimaryWithFallback:
+		return reconcilePrimaryWithFallback(s.backends, succeeded)
+	case config.ReconciliationIntersection:
+		return reconcileBySeverity(succeeded, minInt)
+	case config.ReconciliationWorstSeverity:
+		return reconcileWorstSeverity(succeeded)
+	case config.ReconciliationUnion:
+		fallthrough
+	default:
+		return reconcileBySeverity(succeeded, maxInt)
+	}
+}
+
+// reconcilePrimaryWithFallback returns the first configured backend's
+// successful result, falling back to the next backend in declared order.
+func reconcilePrimaryWithFallback(backends []namedBackend, succeeded []memberResult) *models.ScanResult {
+	for _, nb := range backends {
+		for _, o := range succeeded {
+			if o.scannerType == nb.scannerType {
+				return o.result
+			}
+		}
+	}
+	// Unreachable when succeeded is non-empty, since every entry in
+	// succeeded came from a configured backend.
+	return succeeded[0].result
+}
+
+// reconcileWorstSeverity returns the result from whichever backend
+// observed the highest-severity finding, breaking ties by total count.
+func reconcileWorstSeverity(succeeded []memberResult) *models.ScanResult {
+	worst := succeeded[0]
+	for _, o := range succeeded[1:] {
+		if severityWorse(o.summary, worst.summary) {
+			worst = o
+		}
+	}
+	return worst.result
+}
+
+// severityWorse reports whether a is a worse (more severe) finding set
+// than b, comparing critical/high/medium/low counts in that order.
+func severityWorse(a, b *ScanSummary) bool {
+	if a == nil {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	if a.Critical != b.Critical {
+		return a.Critical > b.Critical
+	}
+	if a.High != b.High {
+		return a.High > b.High
+	}
+	if a.Medium != b.Medium {
+		return a.Medium > b.Medium
+	}
+	return a.Low > b.Low
+}
+
+// reconcileBySeverity combines every severity count across succeeded
+// backends using combine (min for intersection, max for union), and
+// synthesizes a models.ScanResult with that combined summary as its
+// Output, in the same JSON shape ResultProcessor.parseScanOutput expects.
+func reconcileBySeverity(succeeded []memberResult, combine func(a, b int) int) *models.ScanResult {
+	combined := ScanSummary{}
+	first := true
+
+	for _, o := range succeeded {
+		if o.summary == nil {
+			continue
+		}
+		if first {
+			combined = *o.summary
+			first = false
+			continue
+		}
+		combined.Critical = combine(combined.Critical, o.summary.Critical)
+		combined.High = combine(combined.High, o.summary.High)
+		combined.Medium = combine(combined.Medium, o.summary.Medium)
+		combined.Low = combine(combined.Low, o.summary.Low)
+	}
+
+	output, _ := json.Marshal(combined)
+
+	return &models.ScanResult{
+		Output: string(output),
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// scanTimeout returns the timeout duration for req, duplicating the
+// registry-override-then-default lookup CLIScanner.getTimeout and
+// RegistryScanner.getTimeout each perform for their own backend, since
+// composite mode needs a timeout before it knows which concrete backend
+// is running.
+func scanTimeout(cfg *config.Config, req *models.ScanRequest) (time.Duration, error) {
+	for _, reg := range cfg.Registries {
+		if reg.Name == req.RegistryName && reg.Scanner.Timeout != "" {
+			return time.ParseDuration(reg.Scanner.Timeout)
+		}
+	}
+	return time.ParseDuration(cfg.Scanner.DefaultTimeout)
+}
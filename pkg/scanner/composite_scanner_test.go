@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func TestReconcileBySeverity(t *testing.T) {
+	succeeded := []memberResult{
+		{scannerType: config.ScannerTypeCLI, result: &models.ScanResult{}, summary: &ScanSummary{Critical: 1, High: 3, Medium: 0, Low: 5}},
+		{scannerType: config.ScannerTypeRegistry, result: &models.ScanResult{}, summary: &ScanSummary{Critical: 2, High: 1, Medium: 4, Low: 0}},
+	}
+
+	union := reconcileBySeverity(succeeded, maxInt)
+	if union.Output != `{"critical":2,"high":3,"medium":4,"low":5}` {
+		t.Errorf("union Output = %s, want max of each severity", union.Output)
+	}
+
+	intersection := reconcileBySeverity(succeeded, minInt)
+	if intersection.Output != `{"critical":1,"high":1,"medium":0,"low":0}` {
+		t.Errorf("intersection Output = %s, want min of each severity", intersection.Output)
+	}
+}
+
+func TestReconcileWorstSeverity(t *testing.T) {
+	cliResult := &models.ScanResult{Output: "cli"}
+	registryResult := &models.ScanResult{Output: "registry"}
+
+	succeeded := []memberResult{
+		{scannerType: config.ScannerTypeCLI, result: cliResult, summary: &ScanSummary{High: 2}},
+		{scannerType: config.ScannerTypeRegistry, result: registryResult, summary: &ScanSummary{Critical: 1}},
+	}
+
+	got := reconcileWorstSeverity(succeeded)
+	if got != registryResult {
+		t.Errorf("reconcileWorstSeverity() picked %v, want the registry backend's result (has a Critical finding)", got)
+	}
+}
+
+func TestReconcilePrimaryWithFallback(t *testing.T) {
+	cliResult := &models.ScanResult{Output: "cli"}
+	registryResult := &models.ScanResult{Output: "registry"}
+
+	backends := []namedBackend{
+		{scannerType: config.ScannerTypeCLI},
+		{scannerType: config.ScannerTypeRegistry},
+	}
+
+	t.Run("primary succeeded", func(t *testing.T) {
+		succeeded := []memberResult{
+			{scannerType: config.ScannerTypeCLI, result: cliResult},
+			{scannerType: config.ScannerTypeRegistry, result: registryResult},
+		}
+		if got := reconcilePrimaryWithFallback(backends, succeeded); got != cliResult {
+			t.Errorf("reconcilePrimaryWithFallback() = %v, want primary (cli) result", got)
+		}
+	})
+
+	t.Run("primary failed, falls back", func(t *testing.T) {
+		succeeded := []memberResult{
+			{scannerType: config.ScannerTypeRegistry, result: registryResult},
+		}
+		if got := reconcilePrimaryWithFallback(backends, succeeded); got != registryResult {
+			t.Errorf("reconcilePrimaryWithFallback() = %v, want fallback (registry) result", got)
+		}
+	})
+}
+
+func TestNewCompositeScanner(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *config.Config
+		wantErr bool
+	}{
+		{
+			name:    "missing composite config",
+			config:  &config.Config{},
+			wantErr: true,
+		},
+		{
+			name: "no backends configured",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{Composite: &config.CompositeConfig{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported member backend",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					Composite: &config.CompositeConfig{Backends: []config.ScannerType{config.ScannerTypeComposite}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid cli + registry backends",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					CLIPath: "/bin/sh",
+					Composite: &config.CompositeConfig{
+						Backends: []config.ScannerType{config.ScannerTypeCLI, config.ScannerTypeRegistry},
+					},
+				},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCompositeScanner(tt.config, logrus.New())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCompositeScanner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
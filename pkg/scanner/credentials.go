@@ -1,24 +1,121 @@
 package scanner
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/registryauth"
 )
 
+// CredentialSource resolves registry pull credentials from one source:
+// inline YAML config, a Docker/Podman-style auth.json, an external
+// credential helper binary, or Kubernetes imagePullSecrets (see
+// kubernetesCredentialSource). CredentialProvider tries its configured
+// sources for a registry in order, stopping at the first that returns
+// credentials.
+type CredentialSource interface {
+	// Resolve returns credentials for host, or nil if this source has
+	// nothing configured for it. A non-nil error means the source itself
+	// failed (a malformed auth file, a helper binary that errored)
+	// rather than "no credentials here" - callers decide whether that's
+	// fatal or should fall back to anonymous access.
+	Resolve(host string) (*RegistryCredentials, error)
+}
+
+// staticCredentialSource resolves the inline username/password
+// configured directly in RegistryConfig.Scanner.Credentials.
+type staticCredentialSource struct {
+	creds config.RegistryCredentials
+}
+
+func (s staticCredentialSource) Resolve(string) (*RegistryCredentials, error) {
+	if s.creds.Username == "" {
+		return nil, nil
+	}
+	return &RegistryCredentials{Username: s.creds.Username, Password: s.creds.Password}, nil
+}
+
+// authFileCredentialSource resolves credentials from a Docker/Podman
+// auth.json (or config.json) via registryauth.Resolver's static "auths",
+// "credHelpers", and "credsStore" handling.
+type authFileCredentialSource struct {
+	resolver *registryauth.Resolver
+	path     string
+}
+
+func (s authFileCredentialSource) Resolve(host string) (*RegistryCredentials, error) {
+	creds, err := s.resolver.Resolve(host, s.path)
+	if err != nil {
+		return nil, fmt.Errorf("auth file: %w", err)
+	}
+	if creds.Username == "" && creds.IdentityToken == "" {
+		return nil, nil
+	}
+	return &RegistryCredentials{
+		Username:      creds.Username,
+		Password:      creds.Password,
+		IdentityToken: creds.IdentityToken,
+	}, nil
+}
+
+// helperCredentialSource invokes an external credential helper binary
+// (docker-credential-<helper>) directly, for registries whose credentials
+// come from a helper with no surrounding config.json.
+type helperCredentialSource struct {
+	helper string
+}
+
+func (s helperCredentialSource) Resolve(host string) (*RegistryCredentials, error) {
+	username, password, identityToken, err := registryauth.InvokeHelper(s.helper, host)
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %s: %w", s.helper, err)
+	}
+	return &RegistryCredentials{Username: username, Password: password, IdentityToken: identityToken}, nil
+}
+
 // CredentialProvider manages credentials for scanner and registry access
 type CredentialProvider struct {
 	config *config.Config
+	logger *logrus.Logger
+
+	authFileResolver *registryauth.Resolver
+
+	// k8sClient and k8sNamespace are set when this process is running
+	// in-cluster (see newInClusterSecretClient), letting sources() add a
+	// kubernetesCredentialSource for registries that configure
+	// ImagePullSecrets. Both stay nil outside a cluster, where that
+	// source is simply never tried.
+	k8sClient    *kubernetesSecretClient
+	k8sNamespace string
 }
 
 // NewCredentialProvider creates a new credential provider
-func NewCredentialProvider(cfg *config.Config) *CredentialProvider {
-	return &CredentialProvider{
-		config: cfg,
+func NewCredentialProvider(cfg *config.Config, logger *logrus.Logger) *CredentialProvider {
+	cp := &CredentialProvider{
+		config:           cfg,
+		logger:           logger,
+		authFileResolver: registryauth.NewResolver(0),
+	}
+
+	if client, err := newInClusterSecretClient(); err == nil {
+		namespace, err := inClusterNamespace()
+		if err != nil {
+			logger.WithError(err).Warn("Running in-cluster but failed to determine namespace, imagePullSecrets credential source is disabled")
+		} else {
+			cp.k8sClient = client
+			cp.k8sNamespace = namespace
+		}
 	}
+
+	return cp
 }
 
 // InjectSysdigToken adds Sysdig API token to the command environment
@@ -33,26 +130,73 @@ func (cp *CredentialProvider) InjectSysdigToken(cmd *exec.Cmd) error {
 	return nil
 }
 
-// GetRegistryCredentials returns registry credentials for the given scan request
+// GetRegistryCredentials returns registry credentials for the given scan
+// request, trying each of the registry's configured CredentialSources in
+// order (static config, auth file, credential helper, imagePullSecrets;
+// see sources) and returning the
+// first that resolves credentials. Returns (nil, nil) if none of them
+// have credentials configured for this registry - that's expected for
+// public images, not an error.
 func (cp *CredentialProvider) GetRegistryCredentials(req *models.ScanRequest) (*RegistryCredentials, error) {
-	// Find registry configuration
 	for _, reg := range cp.config.Registries {
-		if reg.Name == req.RegistryName {
-			if reg.Scanner.Credentials.Username != "" && reg.Scanner.Credentials.Password != "" {
-				return &RegistryCredentials{
-					Username: reg.Scanner.Credentials.Username,
-					Password: reg.Scanner.Credentials.Password,
-					Registry: req.Registry,
-				}, nil
+		if reg.Name != req.RegistryName {
+			continue
+		}
+
+		host := registryHostname(reg.URL)
+		for _, source := range cp.sources(reg) {
+			creds, err := source.Resolve(host)
+			if err != nil {
+				if reg.Scanner.AuthSoftFail {
+					cp.logger.WithError(err).WithField("registry", reg.Name).
+						Warn("Credential source failed, continuing without credentials (auth_soft_fail)")
+					continue
+				}
+				return nil, err
+			}
+			if creds != nil {
+				creds.Registry = req.Registry
+				return creds, nil
 			}
-			// No credentials configured for this registry
-			return nil, nil
 		}
+
+		// No credentials needed for public images.
+		return nil, nil
 	}
 
 	return nil, fmt.Errorf("registry not found: %s", req.RegistryName)
 }
 
+// sources returns reg's credential sources in resolution order: inline
+// static credentials, then an auth.json-style file (reg's own
+// DockerConfigPath, falling back to the scanner-wide default), then an
+// external credential helper if reg names one directly, then reg's
+// ImagePullSecrets if it names any and this process runs in-cluster.
+func (cp *CredentialProvider) sources(reg config.RegistryConfig) []CredentialSource {
+	authFilePath := reg.Scanner.DockerConfigPath
+	if authFilePath == "" {
+		authFilePath = cp.config.Scanner.AuthFile
+	}
+
+	sources := []CredentialSource{
+		staticCredentialSource{creds: reg.Scanner.Credentials},
+		authFileCredentialSource{resolver: cp.authFileResolver, path: authFilePath},
+	}
+	if reg.Scanner.Helper != "" {
+		sources = append(sources, helperCredentialSource{helper: reg.Scanner.Helper})
+	}
+	if len(reg.Scanner.ImagePullSecrets) > 0 && cp.k8sClient != nil {
+		sources = append(sources, kubernetesCredentialSource{
+			client:      cp.k8sClient,
+			namespace:   cp.k8sNamespace,
+			secretNames: reg.Scanner.ImagePullSecrets,
+			dockerAuth:  cp.authFileResolver,
+		})
+	}
+
+	return sources
+}
+
 // InjectRegistryCredentials adds registry credentials to the command
 func (cp *CredentialProvider) InjectRegistryCredentials(cmd *exec.Cmd, req *models.ScanRequest) error {
 	creds, err := cp.GetRegistryCredentials(req)
@@ -60,8 +204,13 @@ func (cp *CredentialProvider) InjectRegistryCredentials(cmd *exec.Cmd, req *mode
 		return err
 	}
 
-	// No credentials needed for public images
-	if creds == nil {
+	// No credentials needed for public images.
+	if creds == nil || creds.IsEmpty() {
+		return nil
+	}
+
+	if creds.IdentityToken != "" {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("REGISTRY_IDENTITY_TOKEN=%s", creds.IdentityToken))
 		return nil
 	}
 
@@ -75,6 +224,65 @@ func (cp *CredentialProvider) InjectRegistryCredentials(cmd *exec.Cmd, req *mode
 	return nil
 }
 
+// MaterializeDockerConfig resolves req's registry credentials (the same
+// GetRegistryCredentials chain InjectRegistryCredentials uses) and writes
+// them into a scoped temporary Docker config.json directory, suitable
+// for pointing a subprocess's DOCKER_CONFIG at so it authenticates its
+// own pull without the credentials ever touching argv or the process's
+// persistent environment. Returns ("", a no-op cleanup, nil) when the
+// registry has no credentials configured, in which case the subprocess
+// is left to pull anonymously. The caller must run the returned cleanup
+// once the subprocess has exited.
+func (cp *CredentialProvider) MaterializeDockerConfig(req *models.ScanRequest) (dir string, cleanup func(), err error) {
+	noop := func() {}
+
+	creds, err := cp.GetRegistryCredentials(req)
+	if err != nil {
+		return "", noop, err
+	}
+	if creds == nil || creds.IsEmpty() {
+		return "", noop, nil
+	}
+
+	dir, err = os.MkdirTemp("", "registry-webhook-dockercfg-*")
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to create temporary docker config directory: %w", err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(dir); err != nil {
+			cp.logger.WithError(err).WithField("dir", dir).Warn("Failed to remove temporary docker config directory")
+		}
+	}
+
+	host, _, _, parseErr := parseImageRef(req.ImageRef)
+	if parseErr != nil {
+		host = registryHostname(req.Registry)
+	}
+
+	entry := map[string]string{}
+	if creds.IdentityToken != "" {
+		entry["identitytoken"] = creds.IdentityToken
+	} else {
+		entry["auth"] = base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+	}
+
+	dockerConfig := map[string]interface{}{
+		"auths": map[string]interface{}{host: entry},
+	}
+	data, err := json.Marshal(dockerConfig)
+	if err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("failed to marshal docker config: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), data, 0600); err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("failed to write docker config: %w", err)
+	}
+
+	return dir, cleanup, nil
+}
+
 // ValidateCredentials checks if all required credentials are configured
 func (cp *CredentialProvider) ValidateCredentials() error {
 	// Validate Sysdig token
@@ -120,9 +328,13 @@ type RegistryCredentials struct {
 	Username string
 	Password string
 	Registry string
+	// IdentityToken is a short-lived OAuth2 bearer token returned by some
+	// credential sources (e.g. ECR's credential helper) in place of a
+	// long-lived password.
+	IdentityToken string
 }
 
 // IsEmpty returns true if credentials are not set
 func (rc *RegistryCredentials) IsEmpty() bool {
-	return rc.Username == "" && rc.Password == ""
+	return rc.Username == "" && rc.Password == "" && rc.IdentityToken == ""
 }
@@ -0,0 +1,301 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func writeTestAuthFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "auth.json")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("failed to write test auth file: %v", err)
+	}
+	return path
+}
+
+func TestCredentialProvider_StaticBeatsAuthFile(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("filealice:filesecret"))
+	authFile := writeTestAuthFile(t, `{"auths":{"registry.example.com":{"auth":"`+auth+`"}}}`)
+
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{
+				Name: "test",
+				URL:  "https://registry.example.com",
+				Scanner: config.ScannerOverride{
+					Credentials:      config.RegistryCredentials{Username: "staticuser", Password: "staticpass"},
+					DockerConfigPath: authFile,
+				},
+			},
+		},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	creds, err := cp.GetRegistryCredentials(&models.ScanRequest{RegistryName: "test", Registry: "registry.example.com"})
+	if err != nil {
+		t.Fatalf("GetRegistryCredentials() error = %v", err)
+	}
+	if creds.Username != "staticuser" || creds.Password != "staticpass" {
+		t.Errorf("GetRegistryCredentials() = %+v, want static credentials", creds)
+	}
+}
+
+func TestCredentialProvider_FallsBackToAuthFile(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("filealice:filesecret"))
+	authFile := writeTestAuthFile(t, `{"auths":{"registry.example.com":{"auth":"`+auth+`"}}}`)
+
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{
+				Name: "test",
+				URL:  "https://registry.example.com",
+				Scanner: config.ScannerOverride{
+					DockerConfigPath: authFile,
+				},
+			},
+		},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	creds, err := cp.GetRegistryCredentials(&models.ScanRequest{RegistryName: "test", Registry: "registry.example.com"})
+	if err != nil {
+		t.Fatalf("GetRegistryCredentials() error = %v", err)
+	}
+	if creds.Username != "filealice" || creds.Password != "filesecret" {
+		t.Errorf("GetRegistryCredentials() = %+v, want auth-file credentials", creds)
+	}
+}
+
+func TestCredentialProvider_UsesGlobalAuthFileDefault(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("globaluser:globalpass"))
+	authFile := writeTestAuthFile(t, `{"auths":{"registry.example.com":{"auth":"`+auth+`"}}}`)
+
+	cfg := &config.Config{
+		Scanner: config.ScannerConfig{AuthFile: authFile},
+		Registries: []config.RegistryConfig{
+			{Name: "test", URL: "https://registry.example.com"},
+		},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	creds, err := cp.GetRegistryCredentials(&models.ScanRequest{RegistryName: "test", Registry: "registry.example.com"})
+	if err != nil {
+		t.Fatalf("GetRegistryCredentials() error = %v", err)
+	}
+	if creds.Username != "globaluser" {
+		t.Errorf("GetRegistryCredentials() = %+v, want global auth file Username=globaluser", creds)
+	}
+}
+
+func TestCredentialProvider_NoCredentialsConfigured(t *testing.T) {
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{Name: "test", URL: "https://registry.example.com"},
+		},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	creds, err := cp.GetRegistryCredentials(&models.ScanRequest{RegistryName: "test", Registry: "registry.example.com"})
+	if err != nil {
+		t.Fatalf("GetRegistryCredentials() error = %v", err)
+	}
+	if creds != nil {
+		t.Errorf("GetRegistryCredentials() = %+v, want nil for public image", creds)
+	}
+}
+
+func TestCredentialProvider_RegistryNotFound(t *testing.T) {
+	cfg := &config.Config{Registries: []config.RegistryConfig{}}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	_, err := cp.GetRegistryCredentials(&models.ScanRequest{RegistryName: "missing"})
+	if err == nil {
+		t.Fatal("GetRegistryCredentials() expected error for unknown registry, got nil")
+	}
+}
+
+func TestCredentialProvider_AuthSoftFail(t *testing.T) {
+	malformedAuthFile := writeTestAuthFile(t, `not valid json`)
+
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{
+				Name: "test",
+				URL:  "https://registry.example.com",
+				Scanner: config.ScannerOverride{
+					DockerConfigPath: malformedAuthFile,
+					AuthSoftFail:     true,
+				},
+			},
+		},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	creds, err := cp.GetRegistryCredentials(&models.ScanRequest{RegistryName: "test", Registry: "registry.example.com"})
+	if err != nil {
+		t.Fatalf("GetRegistryCredentials() with auth_soft_fail error = %v, want nil", err)
+	}
+	if creds != nil {
+		t.Errorf("GetRegistryCredentials() = %+v, want nil after soft-failed source", creds)
+	}
+}
+
+func TestCredentialProvider_AuthHardFailByDefault(t *testing.T) {
+	malformedAuthFile := writeTestAuthFile(t, `not valid json`)
+
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{
+				Name: "test",
+				URL:  "https://registry.example.com",
+				Scanner: config.ScannerOverride{
+					DockerConfigPath: malformedAuthFile,
+				},
+			},
+		},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	_, err := cp.GetRegistryCredentials(&models.ScanRequest{RegistryName: "test", Registry: "registry.example.com"})
+	if err == nil {
+		t.Fatal("GetRegistryCredentials() expected error for malformed auth file without auth_soft_fail, got nil")
+	}
+}
+
+func TestCredentialProvider_InjectRegistryCredentials_IdentityToken(t *testing.T) {
+	auth := `{"auths":{"registry.example.com":{"identitytoken":"ecr-token"}}}`
+	authFile := writeTestAuthFile(t, auth)
+
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{
+				Name: "test",
+				URL:  "https://registry.example.com",
+				Scanner: config.ScannerOverride{
+					DockerConfigPath: authFile,
+				},
+			},
+		},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	cmd := &exec.Cmd{}
+	if err := cp.InjectRegistryCredentials(cmd, &models.ScanRequest{RegistryName: "test", Registry: "registry.example.com"}); err != nil {
+		t.Fatalf("InjectRegistryCredentials() error = %v", err)
+	}
+
+	found := false
+	for _, e := range cmd.Env {
+		if e == "REGISTRY_IDENTITY_TOKEN=ecr-token" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("InjectRegistryCredentials() env = %v, want REGISTRY_IDENTITY_TOKEN=ecr-token", cmd.Env)
+	}
+}
+
+func TestCredentialProvider_MaterializeDockerConfig_WritesCredentials(t *testing.T) {
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{
+				Name: "test-registry",
+				Scanner: config.ScannerOverride{
+					Credentials: config.RegistryCredentials{Username: "alice", Password: "s3cret"},
+				},
+			},
+		},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	req := &models.ScanRequest{
+		ImageRef:     "registry.example.com/team/app:v1",
+		RegistryName: "test-registry",
+	}
+
+	dir, cleanup, err := cp.MaterializeDockerConfig(req)
+	if err != nil {
+		t.Fatalf("MaterializeDockerConfig() error = %v, want nil", err)
+	}
+	defer cleanup()
+
+	if dir == "" {
+		t.Fatal("MaterializeDockerConfig() dir = \"\", want a temp directory")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatalf("failed to read materialized config.json: %v", err)
+	}
+
+	var written struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("failed to parse materialized config.json: %v", err)
+	}
+
+	entry, ok := written.Auths["registry.example.com"]
+	if !ok {
+		t.Fatalf("config.json = %s, want an auths entry for registry.example.com", data)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil || string(decoded) != "alice:s3cret" {
+		t.Errorf("auths[registry.example.com].auth decodes to %q, want alice:s3cret", decoded)
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("cleanup() left %s behind", dir)
+	}
+}
+
+func TestCredentialProvider_MaterializeDockerConfig_NoCredentials(t *testing.T) {
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{{Name: "public-registry"}},
+	}
+	cp := NewCredentialProvider(cfg, logrus.New())
+
+	req := &models.ScanRequest{ImageRef: "registry.example.com/team/app:v1", RegistryName: "public-registry"}
+
+	dir, cleanup, err := cp.MaterializeDockerConfig(req)
+	defer cleanup()
+
+	if err != nil {
+		t.Fatalf("MaterializeDockerConfig() error = %v, want nil", err)
+	}
+	if dir != "" {
+		t.Errorf("MaterializeDockerConfig() dir = %q, want \"\" when no credentials are configured", dir)
+	}
+}
+
+func TestRegistryCredentials_IsEmpty(t *testing.T) {
+	tests := []struct {
+		name string
+		rc   RegistryCredentials
+		want bool
+	}{
+		{name: "all empty", rc: RegistryCredentials{}, want: true},
+		{name: "has username", rc: RegistryCredentials{Username: "u"}, want: false},
+		{name: "has identity token", rc: RegistryCredentials{IdentityToken: "t"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rc.IsEmpty(); got != tt.want {
+				t.Errorf("IsEmpty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,47 @@
 package scanner
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/circuitbreaker"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/retry"
+)
 
 // Registry Scanner specific error types
 
+// ErrorCode identifies the kind of error in a Registry v2 error envelope
+// (see WriteRegistryError), reusing the distribution spec's own codes
+// (UNAUTHORIZED, DENIED, MANIFEST_UNKNOWN) where one applies, and adding
+// a few of this package's own (SCAN_TIMEOUT, CONFIG_INVALID, UNAVAILABLE)
+// for conditions the spec has no code for.
+type ErrorCode string
+
+const (
+	ErrorCodeUnauthorized    ErrorCode = "UNAUTHORIZED"
+	ErrorCodeDenied          ErrorCode = "DENIED"
+	ErrorCodeManifestUnknown ErrorCode = "MANIFEST_UNKNOWN"
+	ErrorCodeScanTimeout     ErrorCode = "SCAN_TIMEOUT"
+	ErrorCodeConfigInvalid   ErrorCode = "CONFIG_INVALID"
+	ErrorCodeUnavailable     ErrorCode = "UNAVAILABLE"
+)
+
 // APIError represents an error from the Registry Scanner API
 type APIError struct {
 	StatusCode int
 	Message    string
 	Retriable  bool
+	// ErrorCode classifies the failure for WriteRegistryError, derived
+	// from StatusCode by NewAPIError.
+	ErrorCode ErrorCode
+	// RetryAfter is the delay the server asked for via a Retry-After
+	// header on a 429/503 response, populated by
+	// NewAPIErrorWithRetryAfter. Zero means the server didn't send one
+	// (or this error didn't come from a response with a Retry-After
+	// header), leaving retry.Do to fall back to its own backoff.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -25,35 +58,67 @@ type ScanTimeoutError struct {
 	ScanID       string
 	PollAttempts int
 	Duration     string
+	// ErrorCode classifies the failure for WriteRegistryError; always
+	// ErrorCodeScanTimeout.
+	ErrorCode ErrorCode
 }
 
 func (e *ScanTimeoutError) Error() string {
 	return fmt.Sprintf("scan timeout after %d poll attempts (scan_id: %s)", e.PollAttempts, e.ScanID)
 }
 
+// NewScanTimeoutError creates a new scan timeout error.
+func NewScanTimeoutError(scanID string, pollAttempts int, duration string) *ScanTimeoutError {
+	return &ScanTimeoutError{
+		ScanID:       scanID,
+		PollAttempts: pollAttempts,
+		Duration:     duration,
+		ErrorCode:    ErrorCodeScanTimeout,
+	}
+}
+
 // AuthenticationError represents an authentication failure
 type AuthenticationError struct {
 	Message string
+	// ErrorCode classifies the failure for WriteRegistryError; always
+	// ErrorCodeUnauthorized.
+	ErrorCode ErrorCode
 }
 
 func (e *AuthenticationError) Error() string {
 	return fmt.Sprintf("authentication failed: %s", e.Message)
 }
 
+// NewAuthenticationError creates a new authentication error.
+func NewAuthenticationError(message string) *AuthenticationError {
+	return &AuthenticationError{Message: message, ErrorCode: ErrorCodeUnauthorized}
+}
+
 // ConfigurationError represents a configuration validation error
 type ConfigurationError struct {
 	Field   string
 	Message string
+	// ErrorCode classifies the failure for WriteRegistryError; always
+	// ErrorCodeConfigInvalid.
+	ErrorCode ErrorCode
 }
 
 func (e *ConfigurationError) Error() string {
 	return fmt.Sprintf("configuration error for %s: %s", e.Field, e.Message)
 }
 
+// NewConfigurationError creates a new configuration error.
+func NewConfigurationError(field, message string) *ConfigurationError {
+	return &ConfigurationError{Field: field, Message: message, ErrorCode: ErrorCodeConfigInvalid}
+}
+
 // NetworkError represents a network connectivity error
 type NetworkError struct {
 	Operation string
 	Err       error
+	// ErrorCode classifies the failure for WriteRegistryError; always
+	// ErrorCodeUnavailable.
+	ErrorCode ErrorCode
 }
 
 func (e *NetworkError) Error() string {
@@ -64,18 +129,63 @@ func (e *NetworkError) Unwrap() error {
 	return e.Err
 }
 
+// NewNetworkError creates a new network error.
+func NewNetworkError(operation string, err error) *NetworkError {
+	return &NetworkError{Operation: operation, Err: err, ErrorCode: ErrorCodeUnavailable}
+}
+
+// apiErrorCodeForStatus maps an HTTP status code from the Registry
+// Scanner API onto the ErrorCode WriteRegistryError should render it as,
+// following the distribution spec's codes where status codes line up
+// with their usual registry meaning.
+func apiErrorCodeForStatus(statusCode int) ErrorCode {
+	switch statusCode {
+	case 401:
+		return ErrorCodeUnauthorized
+	case 403:
+		return ErrorCodeDenied
+	case 404:
+		return ErrorCodeManifestUnknown
+	default:
+		return ErrorCodeUnavailable
+	}
+}
+
 // NewAPIError creates a new API error with retriability determination
 func NewAPIError(statusCode int, message string) *APIError {
+	return NewAPIErrorWithRetryAfter(statusCode, message, 0)
+}
+
+// NewAPIErrorWithRetryAfter is NewAPIError plus a Retry-After delay
+// parsed from the response (see retry.ParseRetryAfter), so a 429/503
+// honors the server's requested backoff instead of only this package's
+// own computed one.
+func NewAPIErrorWithRetryAfter(statusCode int, message string, retryAfter time.Duration) *APIError {
 	retriable := isRetriableStatusCode(statusCode)
 	return &APIError{
 		StatusCode: statusCode,
 		Message:    message,
 		Retriable:  retriable,
+		ErrorCode:  apiErrorCodeForStatus(statusCode),
+		RetryAfter: retryAfter,
 	}
 }
 
 // IsRetriableError checks if an error should be retried
 func IsRetriableError(err error) bool {
+	// A tripped circuit breaker means the dependency is already known to
+	// be down: retrying immediately would just queue another doomed
+	// attempt, so this is deliberately not retriable.
+	if _, ok := err.(*circuitbreaker.CircuitOpenError); ok {
+		return false
+	}
+
+	// retry.Do already retried as much as its policy allows; retrying
+	// again here would just repeat that exhausted budget.
+	if _, ok := err.(*retry.RetryBudgetExceededError); ok {
+		return false
+	}
+
 	if apiErr, ok := err.(*APIError); ok {
 		return apiErr.IsRetriable()
 	}
@@ -103,3 +213,62 @@ func IsRetriableError(err error) bool {
 	// Default to not retriable
 	return false
 }
+
+// registryErrorEnvelope is the Registry v2 error response body
+// (https://distribution.github.io/distribution/spec/api/#errors):
+// {"errors":[{"code":"...","message":"...","detail":...}]}.
+type registryErrorEnvelope struct {
+	Errors []registryErrorDetail `json:"errors"`
+}
+
+type registryErrorDetail struct {
+	Code    ErrorCode   `json:"code"`
+	Message string      `json:"message"`
+	Detail  interface{} `json:"detail,omitempty"`
+}
+
+// WriteRegistryError writes err to w as a Registry v2 error envelope,
+// picking an HTTP status and error code from err's concrete type
+// (APIError, AuthenticationError, ConfigurationError, NetworkError, or
+// ScanTimeoutError from this package). An err of any other type is
+// rendered with a generic "UNKNOWN" code and 500 status, so callers can
+// pass whatever a scan attempt returned without type-switching
+// themselves first.
+func WriteRegistryError(w http.ResponseWriter, err error) {
+	status, detail := registryErrorEnvelopeFor(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(registryErrorEnvelope{Errors: []registryErrorDetail{detail}})
+}
+
+// registryErrorEnvelopeFor maps err onto the HTTP status and error
+// envelope detail WriteRegistryError should emit for it.
+func registryErrorEnvelopeFor(err error) (status int, detail registryErrorDetail) {
+	switch e := err.(type) {
+	case *APIError:
+		status = e.StatusCode
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		return status, registryErrorDetail{Code: e.ErrorCode, Message: e.Message}
+	case *AuthenticationError:
+		return http.StatusUnauthorized, registryErrorDetail{Code: e.ErrorCode, Message: e.Message}
+	case *ConfigurationError:
+		return http.StatusBadRequest, registryErrorDetail{Code: e.ErrorCode, Message: e.Message, Detail: map[string]string{"field": e.Field}}
+	case *NetworkError:
+		return http.StatusBadGateway, registryErrorDetail{Code: e.ErrorCode, Message: e.Error()}
+	case *ScanTimeoutError:
+		return http.StatusGatewayTimeout, registryErrorDetail{
+			Code:    e.ErrorCode,
+			Message: e.Error(),
+			Detail:  map[string]interface{}{"scan_id": e.ScanID, "poll_attempts": e.PollAttempts},
+		}
+	default:
+		message := "internal error"
+		if err != nil {
+			message = err.Error()
+		}
+		return http.StatusInternalServerError, registryErrorDetail{Code: "UNKNOWN", Message: message}
+	}
+}
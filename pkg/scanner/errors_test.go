@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/retry"
+)
+
+func TestWriteRegistryError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   ErrorCode
+	}{
+		{
+			name:       "API error carries its status code and code",
+			err:        NewAPIError(http.StatusNotFound, "manifest not found"),
+			wantStatus: http.StatusNotFound,
+			wantCode:   ErrorCodeManifestUnknown,
+		},
+		{
+			name:       "API error with zero status defaults to bad gateway",
+			err:        &APIError{Message: "boom", ErrorCode: ErrorCodeUnavailable},
+			wantStatus: http.StatusBadGateway,
+			wantCode:   ErrorCodeUnavailable,
+		},
+		{
+			name:       "authentication error maps to 401",
+			err:        NewAuthenticationError("bad token"),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   ErrorCodeUnauthorized,
+		},
+		{
+			name:       "configuration error maps to 400",
+			err:        NewConfigurationError("scanner.url", "must not be empty"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ErrorCodeConfigInvalid,
+		},
+		{
+			name:       "network error maps to 502",
+			err:        NewNetworkError("dial", errors.New("connection refused")),
+			wantStatus: http.StatusBadGateway,
+			wantCode:   ErrorCodeUnavailable,
+		},
+		{
+			name:       "scan timeout error maps to 504",
+			err:        NewScanTimeoutError("scan-1", 5, "10m"),
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   ErrorCodeScanTimeout,
+		},
+		{
+			name:       "unrecognized error falls back to 500 and UNKNOWN",
+			err:        errors.New("something else went wrong"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "UNKNOWN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteRegistryError(rec, tt.err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			var envelope registryErrorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if len(envelope.Errors) != 1 {
+				t.Fatalf("errors = %d, want 1", len(envelope.Errors))
+			}
+			if got := envelope.Errors[0].Code; got != tt.wantCode {
+				t.Errorf("code = %v, want %v", got, tt.wantCode)
+			}
+			if envelope.Errors[0].Message == "" {
+				t.Error("message is empty, want non-empty")
+			}
+		})
+	}
+}
+
+func TestNewAPIErrorWithRetryAfter(t *testing.T) {
+	err := NewAPIErrorWithRetryAfter(http.StatusTooManyRequests, "rate limited", 30*time.Second)
+
+	if err.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", err.RetryAfter)
+	}
+	if !err.IsRetriable() {
+		t.Error("IsRetriable() = false, want true for a 429")
+	}
+}
+
+func TestNewAPIError_LeavesRetryAfterZero(t *testing.T) {
+	if err := NewAPIError(http.StatusNotFound, "not found"); err.RetryAfter != 0 {
+		t.Errorf("RetryAfter = %v, want 0", err.RetryAfter)
+	}
+}
+
+func TestIsRetriableError_RetryBudgetExceeded(t *testing.T) {
+	err := &retry.RetryBudgetExceededError{Attempts: 3, Err: errors.New("still failing")}
+
+	if IsRetriableError(err) {
+		t.Error("IsRetriableError(RetryBudgetExceededError) = true, want false")
+	}
+}
@@ -0,0 +1,15 @@
+package scanner
+
+import "github.com/sysdig/registry-webhook-scanner/pkg/events"
+
+// EventPublisher is implemented by scanner backends that can publish
+// their scan's lifecycle events and log lines to an events.Bus as they
+// run: CLIScanner publishes its stderr as log lines, and RegistryScanner
+// publishes its initiate/pulling/analyzing/reporting stage transitions.
+// A backend that doesn't implement it just scans without publishing.
+type EventPublisher interface {
+	// SetEventBus attaches bus. Scan keys every event it publishes by
+	// the ScanRequest.RequestID it was called with, so one backend
+	// instance can be reused across scans despite only taking bus once.
+	SetEventBus(bus *events.Bus)
+}
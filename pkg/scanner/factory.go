@@ -5,6 +5,8 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/clair"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/trivy"
 )
 
 // NewScannerBackend creates the appropriate scanner backend based on configuration
@@ -27,6 +29,19 @@ func NewScannerBackend(cfg *config.Config, registryName string, logger *logrus.L
 	case config.ScannerTypeRegistry:
 		backend = NewRegistryScanner(cfg, logger)
 
+	case config.ScannerTypeTrivy:
+		backend = trivy.NewScanner(cfg, logger)
+
+	case config.ScannerTypeClair:
+		backend = clair.NewScanner(cfg, logger)
+
+	case config.ScannerTypeComposite:
+		composite, err := NewCompositeScanner(cfg, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create composite scanner: %w", err)
+		}
+		backend = composite
+
 	default:
 		return nil, fmt.Errorf("unsupported scanner type: %s", scannerType)
 	}
@@ -44,6 +44,18 @@ func TestNewScannerBackend(t *testing.T) {
 			wantType:     "registry",
 			wantErr:      false,
 		},
+		{
+			name: "create Trivy scanner from global default",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					Type:  config.ScannerTypeTrivy,
+					Trivy: &config.TrivyScannerConfig{BinaryPath: "/bin/sh"}, // Use existing binary
+				},
+			},
+			registryName: "test-registry",
+			wantType:     "trivy",
+			wantErr:      false,
+		},
 		{
 			name: "create CLI scanner with empty type (backward compatibility)",
 			config: &config.Config{
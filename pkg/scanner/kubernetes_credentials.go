@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/registryauth"
+)
+
+// In-cluster service account mount paths, the same ones every Pod gets
+// automatically and the convention every raw-REST Kubernetes client
+// relies on in place of a kubeconfig.
+const (
+	inClusterTokenPath     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	inClusterCAPath        = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// kubernetesSecretClient fetches dockerconfigjson data out of Kubernetes
+// Secrets via the in-cluster API server, authenticating with the Pod's
+// own service account token. This repo has no client-go dependency, so
+// this speaks the same raw REST pkg/reconciler's listers already speak
+// against registry APIs, pointed at the Kubernetes API server instead.
+type kubernetesSecretClient struct {
+	httpClient *http.Client
+	apiServer  string
+	token      string
+}
+
+// newInClusterSecretClient builds a kubernetesSecretClient from the
+// standard in-cluster service account mount, returning an error if this
+// process isn't running in a Pod with one mounted.
+func newInClusterSecretClient() (*kubernetesSecretClient, error) {
+	host := os.Getenv("KUBERNETES_SERVICE_HOST")
+	port := os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in-cluster: KUBERNETES_SERVICE_HOST/KUBERNETES_SERVICE_PORT not set")
+	}
+
+	token, err := os.ReadFile(inClusterTokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+
+	caCert, err := os.ReadFile(inClusterCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse service account CA bundle")
+	}
+
+	return &kubernetesSecretClient{
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+		apiServer: fmt.Sprintf("https://%s", net.JoinHostPort(host, port)),
+		token:     strings.TrimSpace(string(token)),
+	}, nil
+}
+
+// inClusterNamespace reads the namespace this Pod's service account
+// belongs to, the namespace imagePullSecrets are always looked up in.
+func inClusterNamespace() (string, error) {
+	data, err := os.ReadFile(inClusterNamespacePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account namespace: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// dockerConfigJSON fetches the Secret named name in namespace and
+// returns its ".dockerconfigjson" (or legacy ".dockercfg") data entry,
+// decoded from base64. Returns (nil, nil) if the secret exists but has
+// neither key, or doesn't exist at all - both are "nothing configured"
+// to this source's caller, not an error.
+func (c *kubernetesSecretClient) dockerConfigJSON(namespace, name string) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/namespaces/%s/secrets/%s", c.apiServer, url.PathEscape(namespace), url.PathEscape(name))
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build secret request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Kubernetes API server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("kubernetes API returned status %d for secret %s/%s: %s", resp.StatusCode, namespace, name, body)
+	}
+
+	var secret struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("failed to decode secret %s/%s: %w", namespace, name, err)
+	}
+
+	for _, key := range []string{".dockerconfigjson", ".dockercfg"} {
+		encoded, ok := secret.Data[key]
+		if !ok {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s in secret %s/%s: %w", key, namespace, name, err)
+		}
+		return decoded, nil
+	}
+
+	return nil, nil
+}
+
+// kubernetesCredentialSource resolves registry credentials from the
+// Kubernetes imagePullSecrets named in ScannerOverride.ImagePullSecrets,
+// tried in order, stopping at the first secret that resolves credentials
+// for host.
+type kubernetesCredentialSource struct {
+	client      *kubernetesSecretClient
+	namespace   string
+	secretNames []string
+	dockerAuth  *registryauth.Resolver
+}
+
+func (s kubernetesCredentialSource) Resolve(host string) (*RegistryCredentials, error) {
+	for _, name := range s.secretNames {
+		data, err := s.client.dockerConfigJSON(s.namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("imagePullSecret %s: %w", name, err)
+		}
+		if data == nil {
+			continue
+		}
+
+		creds, err := s.dockerAuth.ResolveBytes(host, data)
+		if err != nil {
+			return nil, fmt.Errorf("imagePullSecret %s: %w", name, err)
+		}
+		if creds.Username != "" || creds.IdentityToken != "" {
+			return &RegistryCredentials{
+				Username:      creds.Username,
+				Password:      creds.Password,
+				IdentityToken: creds.IdentityToken,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
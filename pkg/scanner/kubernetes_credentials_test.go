@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/registryauth"
+)
+
+func dockerConfigJSONSecretServer(t *testing.T, secrets map[string]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		name := r.URL.Path[len("/api/v1/namespaces/test-ns/secrets/"):]
+		dockerConfigJSON, ok := secrets[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{
+				".dockerconfigjson": base64.StdEncoding.EncodeToString([]byte(dockerConfigJSON)),
+			},
+		})
+	}))
+}
+
+func TestKubernetesSecretClient_DockerConfigJSON(t *testing.T) {
+	server := dockerConfigJSONSecretServer(t, map[string]string{
+		"pull-secret": `{"auths":{"registry.example.com":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("alice:s3cret")) + `"}}}`,
+	})
+	defer server.Close()
+
+	client := &kubernetesSecretClient{httpClient: server.Client(), apiServer: server.URL, token: "test-token"}
+
+	data, err := client.dockerConfigJSON("test-ns", "pull-secret")
+	if err != nil {
+		t.Fatalf("dockerConfigJSON() error = %v, want nil", err)
+	}
+	if data == nil {
+		t.Fatal("dockerConfigJSON() = nil, want decoded config bytes")
+	}
+}
+
+func TestKubernetesSecretClient_DockerConfigJSON_NotFound(t *testing.T) {
+	server := dockerConfigJSONSecretServer(t, map[string]string{})
+	defer server.Close()
+
+	client := &kubernetesSecretClient{httpClient: server.Client(), apiServer: server.URL, token: "test-token"}
+
+	data, err := client.dockerConfigJSON("test-ns", "missing-secret")
+	if err != nil {
+		t.Fatalf("dockerConfigJSON() error = %v, want nil for a missing secret", err)
+	}
+	if data != nil {
+		t.Errorf("dockerConfigJSON() = %v, want nil for a missing secret", data)
+	}
+}
+
+func TestKubernetesCredentialSource_Resolve(t *testing.T) {
+	server := dockerConfigJSONSecretServer(t, map[string]string{
+		"pull-secret": `{"auths":{"registry.example.com":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("alice:s3cret")) + `"}}}`,
+	})
+	defer server.Close()
+
+	source := kubernetesCredentialSource{
+		client:      &kubernetesSecretClient{httpClient: server.Client(), apiServer: server.URL, token: "test-token"},
+		namespace:   "test-ns",
+		secretNames: []string{"pull-secret"},
+		dockerAuth:  registryauth.NewResolver(0),
+	}
+
+	creds, err := source.Resolve("registry.example.com")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v, want nil", err)
+	}
+	if creds == nil || creds.Username != "alice" || creds.Password != "s3cret" {
+		t.Errorf("Resolve() = %+v, want Username=alice Password=s3cret", creds)
+	}
+}
+
+func TestKubernetesCredentialSource_Resolve_NoMatchingSecretReturnsNil(t *testing.T) {
+	server := dockerConfigJSONSecretServer(t, map[string]string{
+		"pull-secret": `{"auths":{"other-registry.example.com":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("alice:s3cret")) + `"}}}`,
+	})
+	defer server.Close()
+
+	source := kubernetesCredentialSource{
+		client:      &kubernetesSecretClient{httpClient: server.Client(), apiServer: server.URL, token: "test-token"},
+		namespace:   "test-ns",
+		secretNames: []string{"pull-secret"},
+		dockerAuth:  registryauth.NewResolver(0),
+	}
+
+	creds, err := source.Resolve("registry.example.com")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v, want nil", err)
+	}
+	if creds != nil {
+		t.Errorf("Resolve() = %+v, want nil when no configured secret has a matching host", creds)
+	}
+}
@@ -7,19 +7,87 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/events"
 	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+	dockerconfig "github.com/sysdig/registry-webhook-scanner/pkg/registryauth"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/circuitbreaker"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/registryauth"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/retry"
+)
+
+// defaultRequestsPerSecond and defaultBurst bound how fast this process
+// calls the Sysdig API when RegistryScannerConfig doesn't configure them,
+// chosen to stay well under typical per-tenant API rate limits.
+const (
+	defaultRequestsPerSecond = 5
+	defaultBurst             = 10
 )
 
+// indexMediaTypes are the manifest media types that point at a list of
+// per-platform manifests rather than a single scannable image.
+var indexMediaTypes = map[string]bool{
+	"application/vnd.docker.distribution.manifest.list.v2+json": true,
+	"application/vnd.oci.image.index.v1+json":                   true,
+}
+
 // RegistryScanner implements the ScannerBackend interface using Sysdig Registry Scanner API
 type RegistryScanner struct {
 	config     *config.Config
 	logger     *logrus.Logger
 	httpClient *http.Client
+	limiter    *rate.Limiter
+
+	inFlightMu sync.Mutex
+	inFlight   map[string]*inFlightScan
+
+	// registryClientsMu guards registryClients, a per-(host,repository)
+	// cache of authenticated registry HTTP clients so the bearer tokens
+	// registryauth.TokenHandler fetches are reused across scans instead
+	// of refetched on every pre-flight check.
+	registryClientsMu sync.Mutex
+	registryClients   map[string]*http.Client
+
+	// dockerAuth resolves registry credentials from a Docker/OCI
+	// config.json (static "auths", "credHelpers", "credsStore") for
+	// registries that don't have a static username/password configured
+	// in RegistryConfig.Scanner.Credentials.
+	dockerAuth *dockerconfig.Resolver
+
+	// breakers guards initiateScan and getScanResult with a circuit
+	// breaker per endpoint, so a Sysdig outage fails fast instead of
+	// retrying every scan request into a dependency that's already down.
+	breakers *circuitbreaker.Group
+
+	// eventBus, when set via SetEventBus, receives scanOnce's stage
+	// transitions as events.Bus "stage:<name>" events, satisfying
+	// EventPublisher the same way CLIScanner does.
+	eventBus *events.Bus
+}
+
+// Circuit breaker endpoint keys, one per retry.Do call site in this file.
+const (
+	breakerEndpointInitiate = "initiate"
+	breakerEndpointPoll     = "poll"
+)
+
+// inFlightScan tracks a scan currently in progress for a given image ref,
+// so concurrent callers scanning the same image (e.g. two webhook
+// admissions from the same ReplicaSet rollout) coalesce onto one Sysdig
+// scan instead of starting a redundant one.
+type inFlightScan struct {
+	done   chan struct{}
+	result *models.ScanResult
+	err    error
 }
 
 // NewRegistryScanner creates a new RegistryScanner instance
@@ -35,15 +103,104 @@ func NewRegistryScanner(cfg *config.Config, logger *logrus.Logger) *RegistryScan
 		logger.Warn("TLS verification disabled for Registry Scanner - this is insecure!")
 	}
 
+	requestsPerSecond := float64(defaultRequestsPerSecond)
+	burst := defaultBurst
+	var breakerCfg *config.CircuitBreakerConfig
+	if rs := cfg.Scanner.RegistryScanner; rs != nil {
+		if rs.RequestsPerSecond > 0 {
+			requestsPerSecond = rs.RequestsPerSecond
+		}
+		if rs.Burst > 0 {
+			burst = rs.Burst
+		}
+		breakerCfg = rs.CircuitBreaker
+	}
+
 	return &RegistryScanner{
-		config:     cfg,
-		logger:     logger,
-		httpClient: httpClient,
+		config:          cfg,
+		logger:          logger,
+		httpClient:      httpClient,
+		limiter:         rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
+		inFlight:        make(map[string]*inFlightScan),
+		registryClients: make(map[string]*http.Client),
+		dockerAuth:      dockerconfig.NewResolver(0),
+		breakers:        newBreakerGroup(breakerCfg),
+	}
+}
+
+// SetEventBus attaches bus, so scanOnce publishes its initiate/pulling/
+// analyzing/reporting stage transitions (as "stage:<name>" events, the
+// form events.Event.Type's doc comment reserves for this) plus a
+// terminal events.TypeCompleted/TypeFailed once a single-image scan
+// finishes or fails past its config check, satisfying EventPublisher.
+// A missing-config failure and a manifest list's own outcome publish no
+// terminal event; each per-platform child scan of a manifest list
+// publishes its own stage and terminal events under the parent's
+// RequestID.
+func (s *RegistryScanner) SetEventBus(bus *events.Bus) {
+	s.eventBus = bus
+}
+
+// publishStage publishes a "stage:<name>" event for req if an event bus
+// is attached; a no-op otherwise.
+func (s *RegistryScanner) publishStage(requestID, name string) {
+	if s.eventBus == nil {
+		return
+	}
+	s.eventBus.Publish(events.Event{Type: "stage:" + name, RequestID: requestID, Timestamp: time.Now()})
+}
+
+// publishTerminal publishes result's final events.TypeCompleted/
+// TypeFailed event for requestID, mirroring CLIScanner.publishFinalEvent.
+func (s *RegistryScanner) publishTerminal(requestID string, result *models.ScanResult) {
+	if s.eventBus == nil {
+		return
+	}
+
+	eventType := events.TypeCompleted
+	if result.Status == models.ScanStatusFailed {
+		eventType = events.TypeFailed
 	}
+
+	s.eventBus.Publish(events.Event{
+		Type:      eventType,
+		RequestID: requestID,
+		Message:   result.Error,
+		Timestamp: time.Now(),
+	})
 }
 
-// Scan initiates a scan via Registry Scanner API and polls for results
+// Scan initiates a scan via Registry Scanner API and polls for results. If
+// another goroutine is already scanning the same image reference, this
+// call coalesces onto that in-flight scan and returns its result instead
+// of starting a second Sysdig scan.
 func (s *RegistryScanner) Scan(ctx context.Context, req *models.ScanRequest) (*models.ScanResult, error) {
+	key := req.ImageRef
+
+	s.inFlightMu.Lock()
+	if existing, ok := s.inFlight[key]; ok {
+		s.inFlightMu.Unlock()
+		s.logger.WithField("image_ref", key).Debug("Coalescing onto in-flight Registry Scanner scan")
+		<-existing.done
+		return existing.result, existing.err
+	}
+
+	call := &inFlightScan{done: make(chan struct{})}
+	s.inFlight[key] = call
+	s.inFlightMu.Unlock()
+
+	call.result, call.err = s.scanOnce(ctx, req)
+
+	s.inFlightMu.Lock()
+	delete(s.inFlight, key)
+	s.inFlightMu.Unlock()
+	close(call.done)
+
+	return call.result, call.err
+}
+
+// scanOnce performs the actual initiate-then-poll scan flow, uncoalesced.
+func (s *RegistryScanner) scanOnce(ctx context.Context, req *models.ScanRequest) (*models.ScanResult, error) {
 	startTime := time.Now()
 
 	result := &models.ScanResult{
@@ -67,6 +224,40 @@ func (s *RegistryScanner) Scan(ctx context.Context, req *models.ScanRequest) (*m
 		"request_id":   req.RequestID,
 		"scanner_type": "registry",
 	}).Info("Starting Registry Scanner API scan")
+	s.publishStage(req.RequestID, "initiate")
+
+	// Step 0: Pre-flight check that the image exists and resolve its
+	// digest, using per-registry credentials that may cover private
+	// registries Sysdig's own token doesn't have access to.
+	digest, mediaType, err := s.resolveImageDigest(ctx, req.ImageRef)
+	if err != nil {
+		result.Status = models.ScanStatusFailed
+		result.Error = fmt.Sprintf("image pre-flight check failed: %v", err)
+		result.CompletedAt = time.Now()
+		result.Duration = result.CompletedAt.Sub(startTime)
+
+		metrics.RecordScannerType("registry", "failed")
+		metrics.RecordScanDuration("registry", "failed", result.Duration.Seconds())
+		metrics.RecordScan("registry", req.RegistryName, "failed")
+		s.publishTerminal(req.RequestID, result)
+
+		return result, fmt.Errorf("image pre-flight check failed: %w", err)
+	}
+	s.publishStage(req.RequestID, "pulling")
+
+	// A manifest list / OCI image index doesn't point at a single scannable
+	// image: fan out into one scan per platform it lists (subject to the
+	// registry's ScanPlatforms filter) and roll the results back up,
+	// instead of asking Sysdig to scan the index itself.
+	if indexMediaTypes[mediaType] {
+		return s.scanManifestList(ctx, req, startTime, digest)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":  req.ImageRef,
+		"request_id": req.RequestID,
+		"digest":     digest,
+	}).Debug("Resolved image digest")
 
 	// Step 1: Initiate scan
 	scanID, err := s.initiateScan(ctx, req)
@@ -80,6 +271,7 @@ func (s *RegistryScanner) Scan(ctx context.Context, req *models.ScanRequest) (*m
 		metrics.RecordScannerType("registry", "failed")
 		metrics.RecordScanDuration("registry", "failed", result.Duration.Seconds())
 		metrics.RecordScan("registry", req.RegistryName, "failed")
+		s.publishTerminal(req.RequestID, result)
 
 		return result, err
 	}
@@ -89,6 +281,7 @@ func (s *RegistryScanner) Scan(ctx context.Context, req *models.ScanRequest) (*m
 		"request_id": req.RequestID,
 		"scan_id":    scanID,
 	}).Info("Scan initiated successfully")
+	s.publishStage(req.RequestID, "analyzing")
 
 	// Step 2: Poll for scan completion
 	scanResult, err := s.pollScanStatus(ctx, scanID, req)
@@ -102,9 +295,11 @@ func (s *RegistryScanner) Scan(ctx context.Context, req *models.ScanRequest) (*m
 		metrics.RecordScannerType("registry", "failed")
 		metrics.RecordScanDuration("registry", "failed", result.Duration.Seconds())
 		metrics.RecordScan("registry", req.RegistryName, "failed")
+		s.publishTerminal(req.RequestID, result)
 
 		return result, err
 	}
+	s.publishStage(req.RequestID, "reporting")
 
 	// Step 3: Parse and return results
 	scanResult.ImageRef = req.ImageRef
@@ -113,6 +308,7 @@ func (s *RegistryScanner) Scan(ctx context.Context, req *models.ScanRequest) (*m
 	scanResult.CompletedAt = time.Now()
 	scanResult.Duration = scanResult.CompletedAt.Sub(startTime)
 	scanResult.Status = models.ScanStatusSuccess // Map "completed" to our status type
+	s.publishTerminal(req.RequestID, scanResult)
 
 	s.logger.WithFields(logrus.Fields{
 		"image_ref":  req.ImageRef,
@@ -144,10 +340,27 @@ func (s *RegistryScanner) ValidateConfig() error {
 		return fmt.Errorf("registry scanner API URL is required")
 	}
 
-	if s.config.Scanner.RegistryScanner.ProjectID == "" {
+	rs := s.config.Scanner.RegistryScanner
+	if rs.ProjectID == "" && len(rs.ProjectRoutes) == 0 {
 		return fmt.Errorf("registry scanner project ID is required")
 	}
 
+	for i, route := range rs.ProjectRoutes {
+		if route.ProjectID == "" {
+			return fmt.Errorf("registry scanner project route %d is missing a project ID", i)
+		}
+		if route.Registry != "" {
+			if _, err := path.Match(route.Registry, ""); err != nil {
+				return fmt.Errorf("registry scanner project route %d has an invalid registry pattern: %w", i, err)
+			}
+		}
+		if route.Repository != "" {
+			if _, err := path.Match(route.Repository, ""); err != nil {
+				return fmt.Errorf("registry scanner project route %d has an invalid repository pattern: %w", i, err)
+			}
+		}
+	}
+
 	if s.config.Scanner.SysdigToken == "" {
 		return fmt.Errorf("sysdig API token is required")
 	}
@@ -155,7 +368,50 @@ func (s *RegistryScanner) ValidateConfig() error {
 	return nil
 }
 
-// initiateScan sends a POST request to initiate a scan and returns the scan ID
+// resolveProjectID determines which Sysdig project imageRef should be
+// scanned into: the first ProjectRoutes rule whose Registry/Repository
+// globs match wins, evaluated in order, falling back to
+// RegistryScannerConfig.ProjectID when no rule matches (or the image ref
+// can't be parsed).
+func (s *RegistryScanner) resolveProjectID(imageRef string) (string, error) {
+	rs := s.config.Scanner.RegistryScanner
+	if len(rs.ProjectRoutes) == 0 {
+		return rs.ProjectID, nil
+	}
+
+	host, repository, _, err := parseImageRef(imageRef)
+	if err != nil {
+		if rs.ProjectID != "" {
+			return rs.ProjectID, nil
+		}
+		return "", fmt.Errorf("failed to parse image reference for project routing: %w", err)
+	}
+
+	for _, route := range rs.ProjectRoutes {
+		if route.Registry != "" {
+			if matched, _ := path.Match(route.Registry, host); !matched {
+				continue
+			}
+		}
+		if route.Repository != "" {
+			if matched, _ := path.Match(route.Repository, repository); !matched {
+				continue
+			}
+		}
+		return route.ProjectID, nil
+	}
+
+	if rs.ProjectID != "" {
+		return rs.ProjectID, nil
+	}
+
+	return "", fmt.Errorf("no project route matched image %q and no default project is configured", imageRef)
+}
+
+// initiateScan sends a POST request to initiate a scan and returns the scan
+// ID. Transient failures (5xx, 429, connection errors) are retried with
+// exponential backoff via retry.Do; 4xx responses other than 408/429 fail
+// immediately.
 func (s *RegistryScanner) initiateScan(ctx context.Context, req *models.ScanRequest) (string, error) {
 	apiURL := s.config.Scanner.RegistryScanner.APIURL
 	endpoint := fmt.Sprintf("%s/api/scanning/v1/registry/scan", apiURL)
@@ -171,66 +427,101 @@ func (s *RegistryScanner) initiateScan(ctx context.Context, req *models.ScanRequ
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(payloadBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
+	var scanID string
+	err = retry.Do(ctx, s.retryPolicy(), withBreaker(s.breakers.Breaker(breakerEndpointInitiate), func(ctx context.Context) (retry.Result, error) {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return retry.Result{}, fmt.Errorf("rate limiter: %w", err)
+		}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.Scanner.SysdigToken))
+		// Create HTTP request
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(payloadBytes))
+		if err != nil {
+			return retry.Result{}, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	// Log API request (sanitized)
-	s.logger.WithFields(logrus.Fields{
-		"endpoint":     endpoint,
-		"method":       "POST",
-		"scanner_type": "registry",
-	}).Debug("Sending Registry Scanner API request")
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.Scanner.SysdigToken))
 
-	startTime := time.Now()
+		// Log API request (sanitized)
+		s.logger.WithFields(logrus.Fields{
+			"endpoint":     endpoint,
+			"method":       "POST",
+			"scanner_type": "registry",
+		}).Debug("Sending Registry Scanner API request")
 
-	// Send request
-	resp, err := s.httpClient.Do(httpReq)
-	duration := time.Since(startTime)
+		startTime := time.Now()
 
-	if err != nil {
+		// Send request
+		resp, err := s.httpClient.Do(httpReq)
+		duration := time.Since(startTime)
+
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"endpoint":     endpoint,
+				"duration_ms":  duration.Milliseconds(),
+				"error":        err.Error(),
+				"scanner_type": "registry",
+			}).Error("Registry Scanner API request failed")
+			return retry.Result{Retriable: true}, fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		// Log API response
 		s.logger.WithFields(logrus.Fields{
 			"endpoint":     endpoint,
+			"status_code":  resp.StatusCode,
 			"duration_ms":  duration.Milliseconds(),
-			"error":        err.Error(),
 			"scanner_type": "registry",
-		}).Error("Registry Scanner API request failed")
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+		}).Debug("Received Registry Scanner API response")
 
-	// Log API response
-	s.logger.WithFields(logrus.Fields{
-		"endpoint":     endpoint,
-		"status_code":  resp.StatusCode,
-		"duration_ms":  duration.Milliseconds(),
-		"scanner_type": "registry",
-	}).Debug("Received Registry Scanner API response")
+		// Check response status
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+			body, _ := io.ReadAll(resp.Body)
+			retryAfter := retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			apiErr := NewAPIErrorWithRetryAfter(resp.StatusCode, string(body), retryAfter)
 
-	// Check response status
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
+			if retry.IsRetriableStatusCode(resp.StatusCode) {
+				return retry.Result{Retriable: true, RetryAfter: retryAfter}, apiErr
+			}
+			return retry.Result{}, apiErr
+		}
 
-	// Parse response to get scan ID
-	var scanResp struct {
-		ScanID string `json:"scan_id"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&scanResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		// Parse response to get scan ID
+		var scanResp struct {
+			ScanID string `json:"scan_id"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&scanResp); err != nil {
+			return retry.Result{}, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		if scanResp.ScanID == "" {
+			return retry.Result{}, fmt.Errorf("scan ID not found in response")
+		}
+
+		scanID = scanResp.ScanID
+		return retry.Result{}, nil
+	}))
+
+	if err != nil {
+		return "", err
 	}
+	return scanID, nil
+}
 
-	if scanResp.ScanID == "" {
-		return "", fmt.Errorf("scan ID not found in response")
+// retryPolicy builds the retry.Policy used for API calls from
+// RegistryScannerConfig.Backoff, honoring the legacy top-level
+// MaxAttempts field if Backoff itself leaves attempts unset.
+func (s *RegistryScanner) retryPolicy() retry.Policy {
+	if s.config.Scanner.RegistryScanner == nil {
+		return retry.DefaultPolicy
 	}
 
-	return scanResp.ScanID, nil
+	rs := s.config.Scanner.RegistryScanner
+	policy := backoffPolicyFromRegistryScannerConfig(rs.Backoff)
+	if (rs.Backoff == nil || rs.Backoff.MaxAttempts == 0) && rs.MaxAttempts > 0 {
+		policy.MaxAttempts = rs.MaxAttempts
+	}
+	return policy
 }
 
 // pollScanStatus polls the Registry Scanner API until the scan completes or times out
@@ -302,35 +593,57 @@ func (s *RegistryScanner) pollScanStatus(ctx context.Context, scanID string, req
 	}
 }
 
-// getScanResult retrieves the current scan result from the API
+// getScanResult retrieves the current scan result from the API. Transient
+// HTTP failures are retried here via retry.Do; a successfully retrieved
+// "running"/"pending" status is not an error and is left for
+// pollScanStatus's polling loop to act on.
 func (s *RegistryScanner) getScanResult(ctx context.Context, scanID string) (*models.ScanResult, error) {
 	apiURL := s.config.Scanner.RegistryScanner.APIURL
 	endpoint := fmt.Sprintf("%s/api/scanning/v1/registry/scan/%s", apiURL, scanID)
 
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	var result *models.ScanResult
+	err := retry.Do(ctx, s.retryPolicy(), withBreaker(s.breakers.Breaker(breakerEndpointPoll), func(ctx context.Context) (retry.Result, error) {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return retry.Result{}, fmt.Errorf("rate limiter: %w", err)
+		}
 
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.Scanner.SysdigToken))
+		httpReq, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return retry.Result{}, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	resp, err := s.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.Scanner.SysdigToken))
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
+		resp, err := s.httpClient.Do(httpReq)
+		if err != nil {
+			return retry.Result{Retriable: true}, fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			retryAfter := retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			apiErr := NewAPIErrorWithRetryAfter(resp.StatusCode, string(body), retryAfter)
+
+			if retry.IsRetriableStatusCode(resp.StatusCode) {
+				return retry.Result{Retriable: true, RetryAfter: retryAfter}, apiErr
+			}
+			return retry.Result{}, apiErr
+		}
+
+		// Parse response
+		parsed, err := s.parseScanResponse(resp.Body)
+		if err != nil {
+			return retry.Result{}, fmt.Errorf("failed to parse scan response: %w", err)
+		}
+
+		result = parsed
+		return retry.Result{}, nil
+	}))
 
-	// Parse response
-	result, err := s.parseScanResponse(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse scan response: %w", err)
+		return nil, err
 	}
-
 	return result, nil
 }
 
@@ -340,20 +653,45 @@ func (s *RegistryScanner) buildScanRequest(req *models.ScanRequest) (map[string]
 		"image": req.ImageRef,
 	}
 
-	// Add project ID
-	if s.config.Scanner.RegistryScanner.ProjectID != "" {
-		payload["project_id"] = s.config.Scanner.RegistryScanner.ProjectID
+	// Add project ID, resolved via ProjectRoutes if configured
+	projectID, err := s.resolveProjectID(req.ImageRef)
+	if err != nil {
+		return nil, err
+	}
+	if projectID != "" {
+		payload["project_id"] = projectID
 	}
 
-	// Add registry credentials if available
+	// Add registry credentials if available: a static username/password
+	// wins if set, otherwise fall back to Docker/OCI credential
+	// resolution (config.json "auths", "credHelpers", "credsStore").
 	for _, reg := range s.config.Registries {
-		if reg.Name == req.RegistryName && reg.Scanner.Credentials.Username != "" {
+		if reg.Name != req.RegistryName {
+			continue
+		}
+
+		if reg.Scanner.Credentials.Username != "" {
 			payload["registry_credentials"] = map[string]string{
 				"username": reg.Scanner.Credentials.Username,
 				"password": reg.Scanner.Credentials.Password,
 			}
 			break
 		}
+
+		creds, err := s.dockerAuth.Resolve(registryHostname(reg.URL), reg.Scanner.DockerConfigPath)
+		if err != nil {
+			s.logger.WithError(err).WithField("registry", reg.Name).Warn("Failed to resolve Docker credentials")
+			break
+		}
+		if creds.IdentityToken != "" {
+			payload["registry_credentials"] = map[string]string{"identity_token": creds.IdentityToken}
+		} else if creds.Username != "" {
+			payload["registry_credentials"] = map[string]string{
+				"username": creds.Username,
+				"password": creds.Password,
+			}
+		}
+		break
 	}
 
 	return payload, nil
@@ -398,3 +736,327 @@ func (s *RegistryScanner) getTimeout(req *models.ScanRequest) (time.Duration, er
 	// Use default timeout
 	return time.ParseDuration(s.config.Scanner.DefaultTimeout)
 }
+
+// resolveImageDigest HEADs the image manifest through a per-registry
+// authenticated client, confirming the image exists and resolving its
+// digest before Sysdig is asked to scan it. This catches private
+// registries Sysdig's own credentials can't reach before burning a Sysdig
+// API call on a scan that would just fail the same way.
+func (s *RegistryScanner) resolveImageDigest(ctx context.Context, imageRef string) (digest, mediaType string, err error) {
+	host, repository, reference, err := parseImageRef(imageRef)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse image reference: %w", err)
+	}
+
+	client := s.registryClient(host, repository)
+
+	endpoint := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, reference)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build manifest request: %w", err)
+	}
+	httpReq.Header.Set("Accept", strings.Join([]string{
+		"application/vnd.docker.distribution.manifest.v2+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.index.v1+json",
+	}, ", "))
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to reach registry %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("registry %s returned status %d for %s", host, resp.StatusCode, imageRef)
+	}
+
+	digest = resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", "", fmt.Errorf("registry %s did not return a content digest for %s", host, imageRef)
+	}
+
+	return digest, resp.Header.Get("Content-Type"), nil
+}
+
+// platformManifest is one entry of a manifest list / OCI image index: a
+// single platform's manifest digest.
+type platformManifest struct {
+	Digest   string `json:"digest"`
+	Platform struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform"`
+}
+
+// fetchManifestList GETs the manifest list / image index at digest and
+// returns its per-platform entries.
+func (s *RegistryScanner) fetchManifestList(ctx context.Context, host, repository, digest string) ([]platformManifest, error) {
+	client := s.registryClient(host, repository)
+
+	endpoint := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, digest)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build manifest list request: %w", err)
+	}
+	httpReq.Header.Set("Accept", strings.Join([]string{
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.index.v1+json",
+	}, ", "))
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry %s returned status %d for manifest list %s", host, resp.StatusCode, digest)
+	}
+
+	var index struct {
+		Manifests []platformManifest `json:"manifests"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest list: %w", err)
+	}
+
+	return index.Manifests, nil
+}
+
+// scanPlatformsFor returns the ScanPlatforms filter configured for req's
+// registry, or nil if none is configured (meaning "all").
+func (s *RegistryScanner) scanPlatformsFor(req *models.ScanRequest) []string {
+	for _, reg := range s.config.Registries {
+		if reg.Name == req.RegistryName {
+			return reg.Scanner.ScanPlatforms
+		}
+	}
+	return nil
+}
+
+// matchesPlatforms reports whether platform (in "os/arch" form) passes
+// filter. A nil/empty filter, or a filter containing the literal "all",
+// matches every platform.
+func matchesPlatforms(platform string, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, allowed := range filter {
+		if allowed == "all" || allowed == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// scanManifestList fans a manifest-list/image-index scan out into one
+// scan per platform entry it lists (filtered by the registry's
+// ScanPlatforms), each as its own ScanRequest pinned to that platform's
+// manifest digest so deduplication treats them independently, and rolls
+// the per-platform results back up into a single aggregate ScanResult.
+func (s *RegistryScanner) scanManifestList(ctx context.Context, req *models.ScanRequest, startTime time.Time, indexDigest string) (*models.ScanResult, error) {
+	host, repository, _, err := parseImageRef(req.ImageRef)
+	if err != nil {
+		return s.failResult(req, startTime, fmt.Errorf("failed to parse image reference: %w", err))
+	}
+
+	entries, err := s.fetchManifestList(ctx, host, repository, indexDigest)
+	if err != nil {
+		return s.failResult(req, startTime, fmt.Errorf("failed to fetch manifest list: %w", err))
+	}
+
+	filter := s.scanPlatformsFor(req)
+
+	var children []*models.ScanRequest
+	for _, entry := range entries {
+		if entry.Platform.OS == "unknown" || entry.Platform.Architecture == "unknown" {
+			// Attestation/signature manifests (cosign, buildkit provenance)
+			// ride along in the index under "unknown/unknown"; they aren't
+			// scannable images.
+			continue
+		}
+		platform := fmt.Sprintf("%s/%s", entry.Platform.OS, entry.Platform.Architecture)
+		if !matchesPlatforms(platform, filter) {
+			s.logger.WithFields(logrus.Fields{
+				"image_ref":  req.ImageRef,
+				"request_id": req.RequestID,
+				"platform":   platform,
+			}).Debug("Skipping platform excluded by scan_platforms")
+			continue
+		}
+
+		child := *req
+		child.Digest = entry.Digest
+		child.Tag = ""
+		child.ImageRef = fmt.Sprintf("%s/%s@%s", host, repository, entry.Digest)
+		children = append(children, &child)
+	}
+
+	if len(children) == 0 {
+		return s.failResult(req, startTime, fmt.Errorf("manifest list %s has no platforms matching scan_platforms", indexDigest))
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":  req.ImageRef,
+		"request_id": req.RequestID,
+		"platforms":  len(children),
+	}).Info("Fanning out manifest list scan across platforms")
+
+	results := make([]*models.ScanResult, len(children))
+	errs := make([]error, len(children))
+	var wg sync.WaitGroup
+	for i, child := range children {
+		wg.Add(1)
+		go func(i int, child *models.ScanRequest) {
+			defer wg.Done()
+			results[i], errs[i] = s.Scan(ctx, child)
+		}(i, child)
+	}
+	wg.Wait()
+
+	return aggregateManifestListResults(req, startTime, results, errs)
+}
+
+// failResult builds a failed ScanResult for req, for the pre-scan error
+// paths scanManifestList shares with scanOnce's own.
+func (s *RegistryScanner) failResult(req *models.ScanRequest, startTime time.Time, err error) (*models.ScanResult, error) {
+	result := &models.ScanResult{
+		ImageRef:    req.ImageRef,
+		RequestID:   req.RequestID,
+		Status:      models.ScanStatusFailed,
+		Error:       err.Error(),
+		StartedAt:   startTime,
+		CompletedAt: time.Now(),
+	}
+	result.Duration = result.CompletedAt.Sub(startTime)
+
+	metrics.RecordScannerType("registry", "failed")
+	metrics.RecordScanDuration("registry", "failed", result.Duration.Seconds())
+	metrics.RecordScan("registry", req.RegistryName, "failed")
+
+	return result, err
+}
+
+// aggregateManifestListResults rolls the per-platform scan results of a
+// manifest-list fan-out back up into one ScanResult for the parent
+// request: failed if any platform failed, succeeded only if every
+// platform did.
+func aggregateManifestListResults(req *models.ScanRequest, startTime time.Time, results []*models.ScanResult, errs []error) (*models.ScanResult, error) {
+	completedAt := time.Now()
+	result := &models.ScanResult{
+		ImageRef:    req.ImageRef,
+		RequestID:   req.RequestID,
+		Status:      models.ScanStatusSuccess,
+		StartedAt:   startTime,
+		CompletedAt: completedAt,
+		Duration:    completedAt.Sub(startTime),
+	}
+
+	var failures []string
+	for i, err := range errs {
+		if err == nil && (results[i] == nil || results[i].Status == models.ScanStatusSuccess) {
+			continue
+		}
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		} else if results[i] != nil {
+			msg = results[i].Error
+		}
+		failures = append(failures, fmt.Sprintf("%s: %s", results[i].ImageRef, msg))
+	}
+
+	if len(failures) > 0 {
+		result.Status = models.ScanStatusFailed
+		result.Error = fmt.Sprintf("%d of %d platform scans failed: %s", len(failures), len(results), strings.Join(failures, "; "))
+
+		metrics.RecordScannerType("registry", "failed")
+		metrics.RecordScanDuration("registry", "failed", result.Duration.Seconds())
+		metrics.RecordScan("registry", req.RegistryName, "failed")
+
+		return result, fmt.Errorf("%s", result.Error)
+	}
+
+	metrics.RecordScannerType("registry", "success")
+	metrics.RecordScanDuration("registry", "success", result.Duration.Seconds())
+	metrics.RecordScan("registry", req.RegistryName, "success")
+
+	return result, nil
+}
+
+// registryClient returns an http.Client that transparently authenticates
+// against host, scoped to pull access on repository, reusing any cached
+// bearer token across calls for the same (host, repository) pair.
+func (s *RegistryScanner) registryClient(host, repository string) *http.Client {
+	key := host + "|" + repository
+
+	s.registryClientsMu.Lock()
+	defer s.registryClientsMu.Unlock()
+
+	if client, ok := s.registryClients[key]; ok {
+		return client
+	}
+
+	creds := s.registryCredentials(host)
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+
+	transport := registryauth.NewTransport(
+		http.DefaultTransport,
+		registryauth.NewChallengeManager(),
+		registryauth.NewTokenHandler(http.DefaultTransport, creds, scope),
+		registryauth.NewBasicHandler(creds),
+	)
+
+	client := &http.Client{Transport: transport, Timeout: 15 * time.Second}
+	s.registryClients[key] = client
+	return client
+}
+
+// registryCredentials looks up the configured pre-flight-check credentials
+// for a registry host from RegistryScannerConfig.Registries.
+func (s *RegistryScanner) registryCredentials(host string) registryauth.CredentialStore {
+	if rs := s.config.Scanner.RegistryScanner; rs != nil {
+		if auth, ok := rs.Registries[host]; ok {
+			return &registryauth.StaticCredentialStore{
+				Username:      auth.Username,
+				Password:      auth.Password,
+				IdentityToken: auth.IdentityToken,
+			}
+		}
+	}
+	return &registryauth.StaticCredentialStore{}
+}
+
+// parseImageRef splits an image reference like
+// "registry.example.com/team/app:v1.0.0" or
+// "registry.example.com/team/app@sha256:..." into its registry host,
+// repository path, and tag/digest reference.
+func parseImageRef(imageRef string) (host, repository, reference string, err error) {
+	namePart := imageRef
+	reference = "latest"
+
+	if at := strings.LastIndex(imageRef, "@"); at != -1 {
+		namePart = imageRef[:at]
+		reference = imageRef[at+1:]
+	} else if colon := strings.LastIndex(imageRef, ":"); colon != -1 && !strings.Contains(imageRef[colon:], "/") {
+		// Guard against the ":" in "host:port/repo" being mistaken for a
+		// tag separator: a real tag never contains a "/".
+		namePart = imageRef[:colon]
+		reference = imageRef[colon+1:]
+	}
+
+	slash := strings.Index(namePart, "/")
+	if slash == -1 {
+		return "", "", "", fmt.Errorf("image ref %q has no registry host", imageRef)
+	}
+
+	host = namePart[:slash]
+	repository = namePart[slash+1:]
+	if host == "" || repository == "" {
+		return "", "", "", fmt.Errorf("invalid image ref: %q", imageRef)
+	}
+
+	return host, repository, reference, nil
+}
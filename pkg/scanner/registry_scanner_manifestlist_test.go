@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func TestMatchesPlatforms(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		filter   []string
+		want     bool
+	}{
+		{name: "no filter matches everything", platform: "linux/amd64", filter: nil, want: true},
+		{name: "exact match", platform: "linux/arm64", filter: []string{"linux/amd64", "linux/arm64"}, want: true},
+		{name: "no match", platform: "linux/386", filter: []string{"linux/amd64", "linux/arm64"}, want: false},
+		{name: "all keyword matches everything", platform: "windows/amd64", filter: []string{"all"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesPlatforms(tt.platform, tt.filter); got != tt.want {
+				t.Errorf("matchesPlatforms(%q, %v) = %v, want %v", tt.platform, tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryScanner_ScanPlatformsFor(t *testing.T) {
+	cfg := &config.Config{
+		Registries: []config.RegistryConfig{
+			{
+				Name:    "my-registry",
+				Scanner: config.ScannerOverride{ScanPlatforms: []string{"linux/amd64"}},
+			},
+		},
+	}
+	scanner := NewRegistryScanner(cfg, logrus.New())
+
+	got := scanner.scanPlatformsFor(&models.ScanRequest{RegistryName: "my-registry"})
+	if len(got) != 1 || got[0] != "linux/amd64" {
+		t.Errorf("scanPlatformsFor() = %v, want [linux/amd64]", got)
+	}
+
+	if got := scanner.scanPlatformsFor(&models.ScanRequest{RegistryName: "unconfigured"}); got != nil {
+		t.Errorf("scanPlatformsFor() for unconfigured registry = %v, want nil", got)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/events"
 )
 
 func TestRegistryScanner_Type(t *testing.T) {
@@ -122,6 +123,178 @@ func TestRegistryScanner_ValidateConfig(t *testing.T) {
 	}
 }
 
+func TestRegistryScanner_ValidateConfig_ProjectRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *config.Config
+		wantErr bool
+	}{
+		{
+			name: "routes with default is valid",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					SysdigToken: "test-token",
+					RegistryScanner: &config.RegistryScannerConfig{
+						APIURL:    "https://secure.sysdig.com",
+						ProjectID: "fallback-project",
+						ProjectRoutes: []config.ProjectRoute{
+							{Registry: "registry.internal.acme.com", Repository: "team-a/*", ProjectID: "team-a-prod"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "routes without default or project ID is valid as long as routes exist",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					SysdigToken: "test-token",
+					RegistryScanner: &config.RegistryScannerConfig{
+						APIURL: "https://secure.sysdig.com",
+						ProjectRoutes: []config.ProjectRoute{
+							{Repository: "team-a/*", ProjectID: "team-a-prod"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "route missing project ID",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					SysdigToken: "test-token",
+					RegistryScanner: &config.RegistryScannerConfig{
+						APIURL:    "https://secure.sysdig.com",
+						ProjectID: "fallback-project",
+						ProjectRoutes: []config.ProjectRoute{
+							{Repository: "team-a/*"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route has invalid repository glob",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					SysdigToken: "test-token",
+					RegistryScanner: &config.RegistryScannerConfig{
+						APIURL:    "https://secure.sysdig.com",
+						ProjectID: "fallback-project",
+						ProjectRoutes: []config.ProjectRoute{
+							{Repository: "team-a[", ProjectID: "team-a-prod"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no project ID and no routes",
+			config: &config.Config{
+				Scanner: config.ScannerConfig{
+					SysdigToken: "test-token",
+					RegistryScanner: &config.RegistryScannerConfig{
+						APIURL: "https://secure.sysdig.com",
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewRegistryScanner(tt.config, logrus.New())
+			err := scanner.ValidateConfig()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryScanner_ResolveProjectID(t *testing.T) {
+	tests := []struct {
+		name      string
+		routes    []config.ProjectRoute
+		projectID string
+		imageRef  string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "no routes uses default",
+			projectID: "default-project",
+			imageRef:  "registry.internal.acme.com/team-a/app:v1",
+			want:      "default-project",
+		},
+		{
+			name: "matches registry and repository",
+			routes: []config.ProjectRoute{
+				{Registry: "registry.internal.acme.com", Repository: "team-a/*", ProjectID: "team-a-prod"},
+			},
+			projectID: "default-project",
+			imageRef:  "registry.internal.acme.com/team-a/app:v1",
+			want:      "team-a-prod",
+		},
+		{
+			name: "first matching overlapping rule wins",
+			routes: []config.ProjectRoute{
+				{Repository: "team-a/*", ProjectID: "team-a-generic"},
+				{Registry: "registry.internal.acme.com", Repository: "team-a/*", ProjectID: "team-a-prod"},
+			},
+			projectID: "default-project",
+			imageRef:  "registry.internal.acme.com/team-a/app:v1",
+			want:      "team-a-generic",
+		},
+		{
+			name: "unmatched image falls back to default",
+			routes: []config.ProjectRoute{
+				{Registry: "registry.internal.acme.com", Repository: "team-a/*", ProjectID: "team-a-prod"},
+			},
+			projectID: "default-project",
+			imageRef:  "other-registry.example.com/team-b/app:v1",
+			want:      "default-project",
+		},
+		{
+			name: "unmatched image with no default errors",
+			routes: []config.ProjectRoute{
+				{Registry: "registry.internal.acme.com", Repository: "team-a/*", ProjectID: "team-a-prod"},
+			},
+			imageRef: "other-registry.example.com/team-b/app:v1",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Scanner: config.ScannerConfig{
+					RegistryScanner: &config.RegistryScannerConfig{
+						ProjectID:     tt.projectID,
+						ProjectRoutes: tt.routes,
+					},
+				},
+			}
+			scanner := NewRegistryScanner(cfg, logrus.New())
+
+			got, err := scanner.resolveProjectID(tt.imageRef)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveProjectID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("resolveProjectID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // Test task 8.4: Successful scan initiation
 func TestRegistryScanner_InitiateScan_Success(t *testing.T) {
 	// Create mock server
@@ -576,3 +749,77 @@ func TestNewRegistryScanner_TLSWarning(t *testing.T) {
 		t.Fatal("NewRegistryScanner() returned nil even with VerifyTLS=false")
 	}
 }
+
+// Test task 9.4: stage/terminal events are published once SetEventBus is called
+func TestRegistryScanner_PublishStage_WithoutBus(t *testing.T) {
+	cfg := &config.Config{Scanner: config.ScannerConfig{Type: config.ScannerTypeRegistry}}
+	scanner := NewRegistryScanner(cfg, logrus.New())
+
+	// No bus attached: must not panic.
+	scanner.publishStage("req-1", "initiate")
+	scanner.publishTerminal("req-1", &models.ScanResult{Status: models.ScanStatusSuccess})
+}
+
+func TestRegistryScanner_PublishStage_PublishesToBus(t *testing.T) {
+	cfg := &config.Config{Scanner: config.ScannerConfig{Type: config.ScannerTypeRegistry}}
+	scanner := NewRegistryScanner(cfg, logrus.New())
+
+	bus := events.NewBus(16)
+	defer bus.Close()
+	scanner.SetEventBus(bus)
+
+	ch, unsubscribe := bus.Subscribe("req-1")
+	defer unsubscribe()
+
+	scanner.publishStage("req-1", "initiate")
+	scanner.publishStage("req-1", "pulling")
+
+	for _, want := range []string{"stage:initiate", "stage:pulling"} {
+		select {
+		case event := <-ch:
+			if event.Type != want {
+				t.Errorf("event.Type = %v, want %v", event.Type, want)
+			}
+			if event.RequestID != "req-1" {
+				t.Errorf("event.RequestID = %v, want req-1", event.RequestID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %v event", want)
+		}
+	}
+}
+
+func TestRegistryScanner_PublishTerminal_SuccessAndFailure(t *testing.T) {
+	cfg := &config.Config{Scanner: config.ScannerConfig{Type: config.ScannerTypeRegistry}}
+	scanner := NewRegistryScanner(cfg, logrus.New())
+
+	bus := events.NewBus(16)
+	defer bus.Close()
+	scanner.SetEventBus(bus)
+
+	ch, unsubscribe := bus.Subscribe("req-1")
+	defer unsubscribe()
+
+	scanner.publishTerminal("req-1", &models.ScanResult{Status: models.ScanStatusSuccess})
+	select {
+	case event := <-ch:
+		if event.Type != events.TypeCompleted {
+			t.Errorf("event.Type = %v, want %v", event.Type, events.TypeCompleted)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for completed event")
+	}
+
+	scanner.publishTerminal("req-1", &models.ScanResult{Status: models.ScanStatusFailed, Error: "boom"})
+	select {
+	case event := <-ch:
+		if event.Type != events.TypeFailed {
+			t.Errorf("event.Type = %v, want %v", event.Type, events.TypeFailed)
+		}
+		if event.Message != "boom" {
+			t.Errorf("event.Message = %v, want boom", event.Message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for failed event")
+	}
+}
@@ -0,0 +1,33 @@
+package registryauth
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// BasicHandler implements the Basic authentication scheme, for registries
+// that don't support the bearer-token dance.
+type BasicHandler struct {
+	creds CredentialStore
+}
+
+// NewBasicHandler creates a BasicHandler backed by creds.
+func NewBasicHandler(creds CredentialStore) *BasicHandler {
+	return &BasicHandler{creds: creds}
+}
+
+// Scheme implements AuthenticationHandler.
+func (b *BasicHandler) Scheme() string {
+	return "basic"
+}
+
+// AuthorizeRequest implements AuthenticationHandler.
+func (b *BasicHandler) AuthorizeRequest(req *http.Request, params map[string]string) error {
+	username, password := b.creds.Basic(req.URL)
+	if username == "" {
+		return fmt.Errorf("registryauth: no credentials configured for basic auth against %s", req.URL.Host)
+	}
+
+	req.SetBasicAuth(username, password)
+	return nil
+}
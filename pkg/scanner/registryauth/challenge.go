@@ -0,0 +1,128 @@
+package registryauth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Challenge is one parsed WWW-Authenticate challenge, e.g.
+// `Bearer realm="https://auth.example.com/token",service="registry.example.com"`.
+type Challenge struct {
+	Scheme     string
+	Parameters map[string]string
+}
+
+// ChallengeManager records, per registry host, the auth challenges
+// returned by a 401 response so request authorization doesn't need to
+// re-ping the registry on every call.
+type ChallengeManager interface {
+	// AddResponse parses resp's WWW-Authenticate headers (if resp is a
+	// 401) and records them against resp.Request's host.
+	AddResponse(resp *http.Response) error
+	// GetChallenges returns the challenges last recorded for host.
+	GetChallenges(host string) ([]Challenge, error)
+}
+
+type challengeManager struct {
+	mu         sync.Mutex
+	challenges map[string][]Challenge
+}
+
+// NewChallengeManager creates an in-memory ChallengeManager.
+func NewChallengeManager() ChallengeManager {
+	return &challengeManager{challenges: make(map[string][]Challenge)}
+}
+
+func (m *challengeManager) AddResponse(resp *http.Response) error {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return nil
+	}
+
+	challenges := parseAuthHeader(resp.Header)
+	if len(challenges) == 0 {
+		return fmt.Errorf("registryauth: 401 response carried no WWW-Authenticate challenge")
+	}
+
+	if resp.Request == nil || resp.Request.URL == nil {
+		return fmt.Errorf("registryauth: cannot determine host for challenge response")
+	}
+
+	m.mu.Lock()
+	m.challenges[resp.Request.URL.Host] = challenges
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *challengeManager) GetChallenges(host string) ([]Challenge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.challenges[host], nil
+}
+
+// parseAuthHeader parses every WWW-Authenticate header value on resp into
+// Challenges.
+func parseAuthHeader(header http.Header) []Challenge {
+	var challenges []Challenge
+
+	for _, h := range header.Values("WWW-Authenticate") {
+		scheme, params := parseChallenge(h)
+		if scheme == "" {
+			continue
+		}
+		challenges = append(challenges, Challenge{Scheme: scheme, Parameters: params})
+	}
+
+	return challenges
+}
+
+// parseChallenge parses a single challenge of the form
+// `Scheme key1="value1",key2="value2"`.
+func parseChallenge(header string) (string, map[string]string) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	scheme := strings.ToLower(parts[0])
+
+	params := make(map[string]string)
+	if len(parts) == 2 {
+		for _, pair := range splitChallengeParams(parts[1]) {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			key := strings.TrimSpace(kv[0])
+			value := strings.Trim(strings.TrimSpace(kv[1]), `"`)
+			params[key] = value
+		}
+	}
+
+	return scheme, params
+}
+
+// splitChallengeParams splits a comma-separated challenge parameter list
+// while respecting commas embedded inside quoted values (e.g. a scope
+// containing multiple repositories).
+func splitChallengeParams(s string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case r == ',' && !inQuotes:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+
+	return parts
+}
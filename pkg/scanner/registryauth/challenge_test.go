@@ -0,0 +1,100 @@
+package registryauth
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseChallenge(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		wantScheme string
+		wantParams map[string]string
+	}{
+		{
+			name:       "quoted params",
+			header:     `Bearer realm="https://auth.example.com/token",service="registry.example.com"`,
+			wantScheme: "bearer",
+			wantParams: map[string]string{"realm": "https://auth.example.com/token", "service": "registry.example.com"},
+		},
+		{
+			name:       "unquoted params",
+			header:     `Bearer realm=https://auth.example.com/token,service=registry.example.com`,
+			wantScheme: "bearer",
+			wantParams: map[string]string{"realm": "https://auth.example.com/token", "service": "registry.example.com"},
+		},
+		{
+			name:       "comma-separated scope",
+			header:     `Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:foo:pull,repository:bar:push"`,
+			wantScheme: "bearer",
+			wantParams: map[string]string{"realm": "https://auth.example.com/token", "service": "registry.example.com", "scope": "repository:foo:pull,repository:bar:push"},
+		},
+		{
+			name:       "basic scheme with realm only",
+			header:     `Basic realm="registry"`,
+			wantScheme: "basic",
+			wantParams: map[string]string{"realm": "registry"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme, params := parseChallenge(tt.header)
+			if scheme != tt.wantScheme {
+				t.Errorf("parseChallenge() scheme = %q, want %q", scheme, tt.wantScheme)
+			}
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("parseChallenge() params = %v, want %v", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestParseAuthHeader_MultipleChallenges(t *testing.T) {
+	header := make(http.Header)
+	header.Add("WWW-Authenticate", `Bearer realm="https://auth.example.com/token",service="registry.example.com"`)
+	header.Add("WWW-Authenticate", `Basic realm="registry.example.com"`)
+
+	challenges := parseAuthHeader(header)
+	if len(challenges) != 2 {
+		t.Fatalf("parseAuthHeader() returned %d challenges, want 2", len(challenges))
+	}
+	if challenges[0].Scheme != "bearer" || challenges[1].Scheme != "basic" {
+		t.Errorf("parseAuthHeader() schemes = [%s, %s], want [bearer, basic]", challenges[0].Scheme, challenges[1].Scheme)
+	}
+}
+
+func TestChallengeManager_AddResponse(t *testing.T) {
+	m := NewChallengeManager()
+
+	header := http.Header{"WWW-Authenticate": []string{`Bearer realm="https://auth.example.com/token",service="registry.example.com"`}}
+	req, _ := http.NewRequest(http.MethodGet, "https://registry.example.com/v2/", nil)
+
+	if err := m.AddResponse(&http.Response{StatusCode: http.StatusUnauthorized, Header: header, Request: req}); err != nil {
+		t.Fatalf("AddResponse() returned unexpected error: %v", err)
+	}
+
+	challenges, err := m.GetChallenges("registry.example.com")
+	if err != nil {
+		t.Fatalf("GetChallenges() returned unexpected error: %v", err)
+	}
+	if len(challenges) != 1 || challenges[0].Scheme != "bearer" {
+		t.Errorf("GetChallenges() = %+v, want one bearer challenge", challenges)
+	}
+}
+
+func TestChallengeManager_AddResponse_NonUnauthorized(t *testing.T) {
+	m := NewChallengeManager()
+	req, _ := http.NewRequest(http.MethodGet, "https://registry.example.com/v2/", nil)
+
+	if err := m.AddResponse(&http.Response{StatusCode: http.StatusOK, Request: req}); err != nil {
+		t.Fatalf("AddResponse() returned unexpected error for 200 response: %v", err)
+	}
+
+	challenges, _ := m.GetChallenges("registry.example.com")
+	if challenges != nil {
+		t.Errorf("GetChallenges() = %+v, want nil for a host that never challenged", challenges)
+	}
+}
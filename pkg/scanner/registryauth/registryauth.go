@@ -0,0 +1,61 @@
+// Package registryauth implements the Docker Registry HTTP API v2
+// authentication dance (modeled on docker/distribution's
+// registry/client/auth package) so the scanner can perform pre-flight
+// manifest checks against private registries that Sysdig's own
+// credentials don't cover: a ChallengeManager parses the WWW-Authenticate
+// challenge from a registry's 401, AuthenticationHandlers (Bearer, Basic)
+// satisfy it, and a Transport composes them into a drop-in
+// http.RoundTripper.
+//
+// This is the only implementation of that dance in this repo -
+// RegistryScanner.resolveImageDigest (in-scan digest resolution) and
+// pkg/registry/auth.Checker (the webhook's pre-enqueue pullability gate)
+// both build their http.Client around this package's Transport rather
+// than parsing WWW-Authenticate themselves. A prior request
+// (bourkey/sysdig-registry-webhook#chunk7-5) added a third,
+// internal/registryclient, that reimplemented the same challenge/token
+// logic from scratch instead of reusing this package; it went unused and
+// was deleted. If a new call site needs this dance, build it on
+// NewTransport/NewChallengeManager/NewTokenHandler/NewBasicHandler rather
+// than writing a fourth copy.
+package registryauth
+
+import "net/url"
+
+// CredentialStore supplies the username/password or OAuth2 identity/refresh
+// token used to satisfy a registry's auth challenge.
+type CredentialStore interface {
+	// Basic returns the username and password to use for the given
+	// realm, or empty strings if none are configured.
+	Basic(realm *url.URL) (username, password string)
+	// RefreshToken returns a previously-issued OAuth2 refresh token for
+	// realm/service, or "" if none is available.
+	RefreshToken(realm *url.URL, service string) string
+	// SetRefreshToken records a refresh token returned by the auth
+	// server for later use.
+	SetRefreshToken(realm *url.URL, service, token string)
+}
+
+// StaticCredentialStore is a CredentialStore backed by a single fixed set
+// of credentials, as configured per-hostname in
+// RegistryScannerConfig.Registries.
+type StaticCredentialStore struct {
+	Username      string
+	Password      string
+	IdentityToken string
+}
+
+// Basic implements CredentialStore.
+func (s *StaticCredentialStore) Basic(*url.URL) (string, string) {
+	return s.Username, s.Password
+}
+
+// RefreshToken implements CredentialStore.
+func (s *StaticCredentialStore) RefreshToken(*url.URL, string) string {
+	return s.IdentityToken
+}
+
+// SetRefreshToken implements CredentialStore. Static credentials are
+// configured up front, so rotated tokens handed back by the auth server
+// aren't persisted anywhere.
+func (s *StaticCredentialStore) SetRefreshToken(*url.URL, string, string) {}
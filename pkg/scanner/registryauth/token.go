@@ -0,0 +1,160 @@
+package registryauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// TokenHandler implements the Bearer authentication scheme: it fetches a
+// short-lived token from the realm advertised by the registry's challenge,
+// scoped to the repository/actions being accessed, and caches it until
+// shortly before it expires.
+type TokenHandler struct {
+	transport http.RoundTripper
+	creds     CredentialStore
+	scope     string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewTokenHandler creates a TokenHandler that fetches tokens over
+// transport using creds, defaulting to scope when a challenge doesn't
+// specify its own.
+func NewTokenHandler(transport http.RoundTripper, creds CredentialStore, scope string) *TokenHandler {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &TokenHandler{transport: transport, creds: creds, scope: scope}
+}
+
+// Scheme implements AuthenticationHandler.
+func (t *TokenHandler) Scheme() string {
+	return "bearer"
+}
+
+// AuthorizeRequest implements AuthenticationHandler, attaching a bearer
+// token fetched (or reused from cache) according to params.
+func (t *TokenHandler) AuthorizeRequest(req *http.Request, params map[string]string) error {
+	token, err := t.cachedToken(req.Context(), params)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// cachedToken returns the cached token if it hasn't expired, otherwise
+// fetches and caches a new one.
+func (t *TokenHandler) cachedToken(ctx context.Context, params map[string]string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Now().Before(t.expiresAt) {
+		return t.token, nil
+	}
+
+	token, expiresIn, err := t.fetchToken(ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	t.token = token
+	// Refresh a bit early so a request in flight doesn't race the token's
+	// real expiry.
+	t.expiresAt = time.Now().Add(time.Duration(expiresIn) * time.Second / 2)
+
+	return t.token, nil
+}
+
+// fetchToken performs the GET against the realm URL advertised in the
+// challenge, attaching service/scope query parameters and either basic
+// credentials or a refresh token, per the OAuth2 token endpoint used by
+// registries implementing the distribution spec.
+func (t *TokenHandler) fetchToken(ctx context.Context, params map[string]string) (string, int, error) {
+	realm, ok := params["realm"]
+	if !ok {
+		return "", 0, fmt.Errorf("registryauth: bearer challenge missing realm")
+	}
+
+	realmURL, err := url.Parse(realm)
+	if err != nil {
+		return "", 0, fmt.Errorf("registryauth: invalid realm %q: %w", realm, err)
+	}
+
+	query := realmURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+
+	scope := params["scope"]
+	if scope == "" {
+		scope = t.scope
+	}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+
+	username, password := t.creds.Basic(realmURL)
+	if refreshToken := t.creds.RefreshToken(realmURL, params["service"]); refreshToken != "" {
+		query.Set("grant_type", "refresh_token")
+		query.Set("refresh_token", refreshToken)
+	}
+	realmURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realmURL.String(), nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("registryauth: failed to build token request: %w", err)
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := (&http.Client{Transport: t.transport}).Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("registryauth: token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", 0, fmt.Errorf("registryauth: token endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		Token        string `json:"token"`
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", 0, fmt.Errorf("registryauth: failed to decode token response: %w", err)
+	}
+
+	if tokenResp.RefreshToken != "" {
+		t.creds.SetRefreshToken(realmURL, params["service"], tokenResp.RefreshToken)
+	}
+
+	token := tokenResp.Token
+	if token == "" {
+		token = tokenResp.AccessToken
+	}
+	if token == "" {
+		return "", 0, fmt.Errorf("registryauth: token endpoint response had no token")
+	}
+
+	expiresIn := tokenResp.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = 60
+	}
+
+	return token, expiresIn, nil
+}
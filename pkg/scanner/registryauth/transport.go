@@ -0,0 +1,95 @@
+package registryauth
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// AuthenticationHandler authorizes a single outgoing request according to
+// one WWW-Authenticate challenge scheme.
+type AuthenticationHandler interface {
+	// Scheme is the challenge scheme this handler satisfies, e.g.
+	// "bearer" or "basic".
+	Scheme() string
+	// AuthorizeRequest attaches credentials to req based on the
+	// challenge's parameters (realm, service, scope, ...).
+	AuthorizeRequest(req *http.Request, params map[string]string) error
+}
+
+// Transport is an http.RoundTripper that transparently performs a
+// registry's authentication dance: requests are first sent unauthorized
+// (or authorized per a previously-seen challenge); on a 401 the
+// WWW-Authenticate header is parsed into manager, the matching handler
+// attaches credentials, and the request is retried once.
+type Transport struct {
+	base     http.RoundTripper
+	manager  ChallengeManager
+	handlers map[string]AuthenticationHandler
+}
+
+// NewTransport composes handlers (keyed by Scheme()) behind base, using
+// manager to remember which auth scheme each registry host challenged
+// with.
+func NewTransport(base http.RoundTripper, manager ChallengeManager, handlers ...AuthenticationHandler) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	byScheme := make(map[string]AuthenticationHandler, len(handlers))
+	for _, h := range handlers {
+		byScheme[h.Scheme()] = h
+	}
+
+	return &Transport{base: base, manager: manager, handlers: byScheme}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authReq := req.Clone(req.Context())
+	if err := t.authorize(authReq); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(authReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	// The registry challenged (or re-challenged, e.g. because a cached
+	// token expired). Record the challenge and retry exactly once.
+	if addErr := t.manager.AddResponse(resp); addErr != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	retryReq := req.Clone(req.Context())
+	if err := t.authorize(retryReq); err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(retryReq)
+}
+
+// authorize looks up the challenges recorded for req's host and delegates
+// to the first handler with a matching scheme. If no challenge has been
+// recorded yet (e.g. this is the very first request), req is sent as-is.
+func (t *Transport) authorize(req *http.Request) error {
+	challenges, err := t.manager.GetChallenges(req.URL.Host)
+	if err != nil {
+		return fmt.Errorf("registryauth: failed to look up challenges for %s: %w", req.URL.Host, err)
+	}
+
+	for _, c := range challenges {
+		handler, ok := t.handlers[c.Scheme]
+		if !ok {
+			continue
+		}
+		return handler.AuthorizeRequest(req, c.Parameters)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// rawReport is the subset of the Sysdig CLI Scanner's JSON scan output
+// (produced via --json-scan-result) this package understands.
+// scanneradapter.rawScanOutput parses the same vulnerability fields for
+// Harbor's report format; keep the two in sync if the CLI's schema
+// changes.
+type rawReport struct {
+	Vulnerabilities   []rawVulnerability   `json:"vulnerabilities"`
+	PolicyEvaluations []rawPolicyEvalution `json:"policyEvaluations"`
+	Layers            []rawLayer           `json:"layers"`
+}
+
+type rawVulnerability struct {
+	ID             string  `json:"vulnerabilityId"`
+	Severity       string  `json:"severity"`
+	PackageName    string  `json:"packageName"`
+	PackageVersion string  `json:"packageVersion"`
+	FixedVersion   string  `json:"fixedVersion"`
+	Description    string  `json:"description"`
+	CVSSScore      float64 `json:"cvssScore"`
+	CVSSVector     string  `json:"cvssVector"`
+	Link           string  `json:"link"`
+	LayerDigest    string  `json:"layerDigest"`
+}
+
+type rawPolicyEvalution struct {
+	Name             string `json:"name"`
+	EvaluationResult string `json:"evaluationResult"`
+}
+
+type rawLayer struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+	Index  int    `json:"index"`
+}
+
+// ParseReport unmarshals output as Sysdig CLI Scanner JSON, returning a
+// models.ScanReport with per-finding detail. An empty output isn't an
+// error here: it means the scan produced no structured report (e.g. it
+// failed before the scanner ran), so callers get a nil report rather
+// than a parse failure. Non-empty output that isn't valid JSON returns
+// an error.
+func ParseReport(output string) (*models.ScanReport, error) {
+	if output == "" {
+		return nil, nil
+	}
+
+	var raw rawReport
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse scan report: %w", err)
+	}
+
+	report := &models.ScanReport{}
+
+	for _, v := range raw.Vulnerabilities {
+		if v.ID == "" || v.PackageName == "" {
+			continue
+		}
+
+		severity := normalizeSeverity(v.Severity)
+		report.Vulnerabilities = append(report.Vulnerabilities, models.Vulnerability{
+			ID:             v.ID,
+			Severity:       severity,
+			PackageName:    v.PackageName,
+			PackageVersion: v.PackageVersion,
+			FixedVersion:   v.FixedVersion,
+			Description:    v.Description,
+			CVSSScore:      v.CVSSScore,
+			CVSSVector:     v.CVSSVector,
+			Link:           v.Link,
+			LayerDigest:    v.LayerDigest,
+		})
+
+		switch severity {
+		case "Critical":
+			report.Summary.Critical++
+		case "High":
+			report.Summary.High++
+		case "Medium":
+			report.Summary.Medium++
+		case "Low":
+			report.Summary.Low++
+		default:
+			report.Summary.Negligible++
+		}
+	}
+
+	for _, p := range raw.PolicyEvaluations {
+		if p.Name == "" {
+			continue
+		}
+		report.PolicyEvaluations = append(report.PolicyEvaluations, models.PolicyEvaluation{
+			Name:   p.Name,
+			Passed: p.EvaluationResult == "passed",
+		})
+	}
+
+	for _, l := range raw.Layers {
+		if l.Digest == "" {
+			continue
+		}
+		report.Layers = append(report.Layers, models.ImageLayer{
+			Digest: l.Digest,
+			Size:   l.Size,
+			Index:  l.Index,
+		})
+	}
+
+	return report, nil
+}
+
+// normalizeSeverity maps the CLI's severity strings onto this package's
+// vocabulary, defaulting to "Negligible" for anything unrecognized.
+// Mirrors scanneradapter.normalizeSeverity's mapping (that one defaults
+// to "Unknown" instead, since Harbor's vocabulary has that bucket).
+func normalizeSeverity(severity string) string {
+	switch severity {
+	case "Critical", "critical":
+		return "Critical"
+	case "High", "high":
+		return "High"
+	case "Medium", "medium":
+		return "Medium"
+	case "Low", "low":
+		return "Low"
+	default:
+		return "Negligible"
+	}
+}
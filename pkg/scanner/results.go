@@ -1,45 +1,166 @@
 package scanner
 
 import (
+	"container/list"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/logging"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+	"golang.org/x/sync/singleflight"
 )
 
+// DefaultCacheMaxEntries bounds a ResultProcessor cache created without
+// an explicit size (cacheMaxEntries <= 0).
+const DefaultCacheMaxEntries = 10000
+
 // ResultProcessor handles scan result processing and caching
 type ResultProcessor struct {
-	logger      *logrus.Logger
-	cache       map[string]*CachedResult
-	cacheTTL    time.Duration
-	mu          sync.RWMutex
-	metrics     *ResultMetrics
+	logger   logging.Logger
+	cacheTTL time.Duration
+	maxSize  int
+
+	mu       sync.Mutex
+	order    *list.List
+	elements map[string]*list.Element
+
+	scanGroup singleflight.Group
+
+	metrics *ResultMetrics
+
+	janitorStop chan struct{}
+	janitorDone chan struct{}
+}
+
+// cacheEntry is the value stored in ResultProcessor's eviction list.
+type cacheEntry struct {
+	key       string
+	result    *models.ScanResult
+	cachedAt  time.Time
+	expiresAt time.Time
+}
+
+// NewResultProcessor creates a new result processor whose cache holds at
+// most DefaultCacheMaxEntries results, each valid for cacheTTL. A
+// cacheTTL of zero disables caching and its janitor: every insert is
+// immediately expired, matching the old unbounded-map processor's
+// behavior when callers didn't care about reuse (e.g.
+// CompositeScanner.scanMembers, which only uses ProcessResult's
+// parseScanOutput step).
+//
+// A background janitor goroutine sweeps expired entries for the life of
+// the processor; call Close once it's no longer needed to stop it.
+func NewResultProcessor(cacheTTL time.Duration, logger logging.Logger) *ResultProcessor {
+	return NewResultProcessorWithCacheSize(cacheTTL, DefaultCacheMaxEntries, logger)
+}
+
+// NewResultProcessorWithCacheSize is NewResultProcessor with an explicit
+// cache bound, wired from config.ScannerConfig.CacheMaxEntries.
+func NewResultProcessorWithCacheSize(cacheTTL time.Duration, cacheMaxEntries int, logger logging.Logger) *ResultProcessor {
+	if cacheMaxEntries <= 0 {
+		cacheMaxEntries = DefaultCacheMaxEntries
+	}
+
+	rp := &ResultProcessor{
+		logger:      logger,
+		cacheTTL:    cacheTTL,
+		maxSize:     cacheMaxEntries,
+		order:       list.New(),
+		elements:    make(map[string]*list.Element),
+		metrics:     NewResultMetrics(),
+		janitorStop: make(chan struct{}),
+		janitorDone: make(chan struct{}),
+	}
+
+	go rp.runJanitor()
+
+	return rp
+}
+
+// Close stops the background janitor goroutine. Safe to call once;
+// callers that own a long-lived ResultProcessor should wire this into
+// their shutdown path (e.g. a shutdown.Manager cleanup, the same way
+// webhook.Server.Shutdown drains its idle tracker).
+func (rp *ResultProcessor) Close() {
+	select {
+	case <-rp.janitorStop:
+		// already closed
+	default:
+		close(rp.janitorStop)
+	}
+	<-rp.janitorDone
 }
 
-// NewResultProcessor creates a new result processor
-func NewResultProcessor(cacheTTL time.Duration, logger *logrus.Logger) *ResultProcessor {
-	return &ResultProcessor{
-		logger:   logger,
-		cache:    make(map[string]*CachedResult),
-		cacheTTL: cacheTTL,
-		metrics:  NewResultMetrics(),
+// runJanitor periodically sweeps expired cache entries, recording each
+// as a "ttl" eviction. Ticks every cacheTTL/4 so an entry is never
+// visibly stale for longer than a quarter of its own TTL; disabled
+// entirely when cacheTTL is zero since nothing is ever cached.
+func (rp *ResultProcessor) runJanitor() {
+	defer close(rp.janitorDone)
+
+	if rp.cacheTTL <= 0 {
+		return
+	}
+
+	interval := rp.cacheTTL / 4
+	if interval <= 0 {
+		interval = rp.cacheTTL
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rp.sweepExpired()
+		case <-rp.janitorStop:
+			return
+		}
+	}
+}
+
+// sweepExpired removes every cache entry whose TTL has passed, recording
+// a "ttl" eviction for each.
+func (rp *ResultProcessor) sweepExpired() {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	now := time.Now()
+
+	var next *list.Element
+	for el := rp.order.Front(); el != nil; el = next {
+		next = el.Next()
+
+		entry := el.Value.(*cacheEntry)
+		if entry.expiresAt.After(now) {
+			continue
+		}
+
+		rp.order.Remove(el)
+		delete(rp.elements, entry.key)
+		metrics.RecordScanCacheEviction("ttl")
 	}
 }
 
 // ProcessResult processes and logs a scan result
 func (rp *ResultProcessor) ProcessResult(result *models.ScanResult) error {
+	ctx := context.Background()
+
 	// Parse scan output if JSON
 	summary, err := rp.parseScanOutput(result.Output)
 	if err != nil {
-		rp.logger.WithFields(logrus.Fields{
-			"image_ref":  result.ImageRef,
-			"request_id": result.RequestID,
-			"error":      err.Error(),
-		}).Warn("Failed to parse scan output")
+		rp.logger.Warn(ctx, "Failed to parse scan output",
+			"image_ref", result.ImageRef,
+			"request_id", result.RequestID,
+			"error", err.Error(),
+		)
 	}
 
 	// Log result with structured fields
@@ -50,12 +171,68 @@ func (rp *ResultProcessor) ProcessResult(result *models.ScanResult) error {
 
 	// Cache result if successful
 	if result.Status == models.ScanStatusSuccess {
-		rp.cacheResult(result)
+		rp.cacheResult(cacheKeyForResult(result), result)
 	}
 
 	return nil
 }
 
+// Scan coalesces concurrent calls for the same image (preferring
+// req.Digest when the caller has resolved one) into a single invocation
+// of scan, sharing the resulting *models.ScanResult with every coalesced
+// caller. A live cache entry short-circuits scan entirely; otherwise
+// scan's result is processed (logged, counted in metrics, and cached
+// under its ImageRef-derived key via ProcessResult) and additionally
+// cached under this call's own key so a later Scan for the same req hits
+// even when that key is digest-based and differs from ImageRef's.
+func (rp *ResultProcessor) Scan(ctx context.Context, req *models.ScanRequest, scan func(context.Context, *models.ScanRequest) (*models.ScanResult, error)) (*models.ScanResult, error) {
+	key := rp.generateKey(req)
+
+	if cached, ok := rp.getCached(key); ok {
+		metrics.RecordScanCacheHit()
+		return cached, nil
+	}
+	metrics.RecordScanCacheMiss()
+
+	v, err, _ := rp.scanGroup.Do(key, func() (interface{}, error) {
+		result, err := scan(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if procErr := rp.ProcessResult(result); procErr != nil {
+			rp.logger.Warn(ctx, "Failed to process coalesced scan result",
+				"image_ref", req.ImageRef,
+				"request_id", req.RequestID,
+				"error", procErr.Error(),
+			)
+		}
+
+		if result.Status == models.ScanStatusSuccess {
+			rp.cacheResult(key, result)
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*models.ScanResult), nil
+}
+
+// generateKey derives the cache/singleflight key for req, preferring
+// Digest over ImageRef so two tags of the same content coalesce,
+// matching queue.StoreBackedDeduplicator.generateKey.
+func (rp *ResultProcessor) generateKey(req *models.ScanRequest) string {
+	if req.Digest != "" {
+		return fmt.Sprintf("digest:%s", req.Digest)
+	}
+
+	hash := sha256.Sum256([]byte(req.ImageRef))
+	return fmt.Sprintf("ref:%x", hash[:16])
+}
+
 // parseScanOutput attempts to parse JSON scan output
 func (rp *ResultProcessor) parseScanOutput(output string) (*ScanSummary, error) {
 	if output == "" {
@@ -95,38 +272,42 @@ func (rp *ResultProcessor) parseTextOutput(output string) *ScanSummary {
 
 // logResult logs the scan result with structured fields
 func (rp *ResultProcessor) logResult(result *models.ScanResult, summary *ScanSummary) {
-	fields := logrus.Fields{
-		"image_ref":  result.ImageRef,
-		"request_id": result.RequestID,
-		"status":     result.Status,
-		"duration":   result.Duration.Seconds(),
-		"exit_code":  result.ExitCode,
+	ctx := context.Background()
+
+	kv := []any{
+		"image_ref", result.ImageRef,
+		"request_id", result.RequestID,
+		"status", result.Status,
+		"duration", result.Duration.Seconds(),
+		"exit_code", result.ExitCode,
 	}
 
 	if summary != nil {
-		fields["vulnerabilities"] = map[string]int{
-			"critical": summary.Critical,
-			"high":     summary.High,
-			"medium":   summary.Medium,
-			"low":      summary.Low,
-		}
-		fields["total_vulnerabilities"] = summary.Total()
+		kv = append(kv,
+			"vulnerabilities", map[string]int{
+				"critical": summary.Critical,
+				"high":     summary.High,
+				"medium":   summary.Medium,
+				"low":      summary.Low,
+			},
+			"total_vulnerabilities", summary.Total(),
+		)
 	}
 
 	if result.Error != "" {
-		fields["error"] = result.Error
+		kv = append(kv, "error", result.Error)
 	}
 
 	// Log at appropriate level based on status
 	switch result.Status {
 	case models.ScanStatusSuccess:
-		rp.logger.WithFields(fields).Info("Scan result processed")
+		rp.logger.Info(ctx, "Scan result processed", kv...)
 	case models.ScanStatusFailed:
-		rp.logger.WithFields(fields).Error("Scan failed")
+		rp.logger.Error(ctx, "Scan failed", kv...)
 	case models.ScanStatusTimeout:
-		rp.logger.WithFields(fields).Warn("Scan timeout")
+		rp.logger.Warn(ctx, "Scan timeout", kv...)
 	default:
-		rp.logger.WithFields(fields).Info("Scan result")
+		rp.logger.Info(ctx, "Scan result", kv...)
 	}
 }
 
@@ -159,34 +340,78 @@ func (rp *ResultProcessor) updateMetrics(result *models.ScanResult) {
 	rp.metrics.AvgDuration = (rp.metrics.AvgDuration*float64(rp.metrics.TotalScans-1) + duration) / float64(rp.metrics.TotalScans)
 }
 
-// cacheResult stores the scan result in cache
-func (rp *ResultProcessor) cacheResult(result *models.ScanResult) {
+// cacheResult stores result under key in the bounded cache, evicting the
+// oldest entry once the cache is over its configured size.
+func (rp *ResultProcessor) cacheResult(key string, result *models.ScanResult) {
 	rp.mu.Lock()
 	defer rp.mu.Unlock()
 
-	rp.cache[result.ImageRef] = &CachedResult{
-		Result:    result,
-		CachedAt:  time.Now(),
-		ExpiresAt: time.Now().Add(rp.cacheTTL),
+	now := time.Now()
+	entry := &cacheEntry{
+		key:       key,
+		result:    result,
+		cachedAt:  now,
+		expiresAt: now.Add(rp.cacheTTL),
+	}
+
+	if el, ok := rp.elements[key]; ok {
+		rp.order.Remove(el)
+	}
+
+	rp.elements[key] = rp.order.PushFront(entry)
+
+	for rp.order.Len() > rp.maxSize {
+		oldest := rp.order.Back()
+		if oldest == nil {
+			break
+		}
+		rp.order.Remove(oldest)
+		delete(rp.elements, oldest.Value.(*cacheEntry).key)
+		metrics.RecordScanCacheEviction("lru")
 	}
 }
 
-// GetCachedResult retrieves a cached result if available and not expired
-func (rp *ResultProcessor) GetCachedResult(imageRef string) (*models.ScanResult, bool) {
-	rp.mu.RLock()
-	defer rp.mu.RUnlock()
+// cacheKeyForResult derives a ResultProcessor cache key from a
+// models.ScanResult. models.ScanResult carries no Digest field, so
+// unlike generateKey this always hashes ImageRef; a Scan call caches
+// under its req-derived (possibly digest-based) key regardless, since
+// cacheResult is keyed from the same result the Scan call produced.
+func cacheKeyForResult(result *models.ScanResult) string {
+	hash := sha256.Sum256([]byte(result.ImageRef))
+	return fmt.Sprintf("ref:%x", hash[:16])
+}
+
+// getCached retrieves a cached result by its generateKey/cacheKeyForResult
+// key, if present and not expired.
+func (rp *ResultProcessor) getCached(key string) (*models.ScanResult, bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
 
-	cached, ok := rp.cache[imageRef]
+	el, ok := rp.elements[key]
 	if !ok {
 		return nil, false
 	}
 
-	// Check if expired
-	if time.Now().After(cached.ExpiresAt) {
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
 		return nil, false
 	}
 
-	return cached.Result, true
+	return entry.result, true
+}
+
+// GetCachedResult retrieves a cached result if available and not expired
+func (rp *ResultProcessor) GetCachedResult(imageRef string) (*models.ScanResult, bool) {
+	hash := sha256.Sum256([]byte(imageRef))
+	key := fmt.Sprintf("ref:%x", hash[:16])
+
+	result, ok := rp.getCached(key)
+	if ok {
+		metrics.RecordScanCacheHit()
+	} else {
+		metrics.RecordScanCacheMiss()
+	}
+	return result, ok
 }
 
 // GetMetrics returns current scan metrics
@@ -218,13 +443,6 @@ func (ss *ScanSummary) Total() int {
 	return ss.Critical + ss.High + ss.Medium + ss.Low
 }
 
-// CachedResult represents a cached scan result
-type CachedResult struct {
-	Result    *models.ScanResult
-	CachedAt  time.Time
-	ExpiresAt time.Time
-}
-
 // ResultMetrics tracks scan result metrics
 type ResultMetrics struct {
 	TotalScans      int64
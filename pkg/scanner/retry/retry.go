@@ -0,0 +1,179 @@
+// Package retry implements a small exponential-backoff-with-jitter retry
+// helper for the Registry Scanner API client. It exists so the scan
+// polling loop can tell "keep polling, the scan is still running" (a
+// normal, non-error outcome handled by the caller) apart from "retry
+// because the HTTP call itself failed transiently" (handled here).
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Policy configures Do's attempt count and backoff bounds.
+type Policy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Jitter enables randomizing each computed backoff delay. Disabling
+	// it makes delays deterministic (always the decorrelated-jitter
+	// upper bound), which is mainly useful for reproducible tests.
+	Jitter bool
+}
+
+// DefaultPolicy applies decorrelated-jitter exponential backoff: base
+// ~500ms, capped at ~30s, up to 5 attempts.
+var DefaultPolicy = Policy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	Jitter:      true,
+}
+
+// Result describes how an attempt's error should affect the next
+// iteration of Do.
+type Result struct {
+	// Retriable marks the attempt's error as transient; Do will try again
+	// if attempts remain.
+	Retriable bool
+	// RetryAfter overrides the computed backoff delay for the next
+	// attempt, e.g. from a 429/503 Retry-After header. Zero means "use the
+	// policy's exponential backoff instead".
+	RetryAfter time.Duration
+}
+
+// Func performs a single attempt. A nil error means success; any other
+// error is paired with a Result saying whether it's worth retrying.
+type Func func(ctx context.Context) (Result, error)
+
+// RetryBudgetExceededError is the terminal error Do returns once
+// policy.MaxAttempts is exhausted without success, wrapping the last
+// attempt's error. It is deliberately never retriable itself, so a
+// caller checking IsRetriableStatusCode/whatever error type it usually
+// switches on can tell "Do gave up after exhausting its budget" apart
+// from "the error itself wasn't worth retrying in the first place".
+type RetryBudgetExceededError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryBudgetExceededError) Error() string {
+	return fmt.Sprintf("retry: budget of %d attempts exceeded: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryBudgetExceededError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetriable always returns false: a RetryBudgetExceededError means Do
+// already retried as much as policy allows.
+func (e *RetryBudgetExceededError) IsRetriable() bool {
+	return false
+}
+
+// Do calls fn until it succeeds, returns a non-retriable error, the
+// context is done, or MaxAttempts is exhausted.
+func Do(ctx context.Context, policy Policy, fn Func) error {
+	var lastErr error
+	var prevDelay time.Duration
+
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		result, err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		if !result.Retriable {
+			return lastErr
+		}
+		if attempt == policy.MaxAttempts-1 {
+			return &RetryBudgetExceededError{Attempts: policy.MaxAttempts, Err: lastErr}
+		}
+
+		delay := result.RetryAfter
+		if delay <= 0 {
+			delay = decorrelatedJitterBackoff(policy, prevDelay)
+		}
+		prevDelay = delay
+		if deadline, ok := ctx.Deadline(); ok {
+			if remaining := time.Until(deadline); delay > remaining {
+				delay = remaining
+			}
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return &RetryBudgetExceededError{Attempts: policy.MaxAttempts, Err: lastErr}
+}
+
+// decorrelatedJitterBackoff returns the next delay using the
+// "decorrelated jitter" formula (AWS's preferred backoff over full
+// jitter, since it still spreads retries out but without the long tail
+// full jitter can produce): sleep = min(cap, random_between(base,
+// prev*3)). prev is the delay returned by the previous call (zero on the
+// first). With policy.Jitter disabled, the upper bound itself is
+// returned instead of a random point within it.
+func decorrelatedJitterBackoff(policy Policy, prev time.Duration) time.Duration {
+	upper := prev * 3
+	if upper < policy.BaseDelay {
+		upper = policy.BaseDelay
+	}
+	if upper > policy.MaxDelay {
+		upper = policy.MaxDelay
+	}
+	if upper <= policy.BaseDelay {
+		return upper
+	}
+	if !policy.Jitter {
+		return upper
+	}
+	return policy.BaseDelay + time.Duration(rand.Int63n(int64(upper-policy.BaseDelay)+1))
+}
+
+// IsRetriableStatusCode reports whether an HTTP response with this status
+// code should be retried: 5xx, 429 (Too Many Requests), and 408 (Request
+// Timeout). Other 4xx responses are not retried.
+func IsRetriableStatusCode(code int) bool {
+	switch {
+	case code == http.StatusTooManyRequests, code == http.StatusRequestTimeout:
+		return true
+	case code >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header in either the delay-seconds
+// or HTTP-date form, relative to now. Returns zero if header is empty,
+// unparseable, or resolves to a time in the past.
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if when, err := http.ParseTime(header); err == nil {
+		if d := when.Sub(now); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,175 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func testPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    10 * time.Millisecond,
+		Jitter:      false,
+	}
+}
+
+func TestDo_SucceedsWithoutRetry(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), testPolicy(), func(ctx context.Context) (Result, error) {
+		calls++
+		return Result{}, nil
+	})
+
+	if err != nil {
+		t.Fatalf("Do() error = %v, want nil", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDo_NonRetriableErrorReturnsImmediately(t *testing.T) {
+	calls := 0
+	wantErr := errors.New("permanent failure")
+	err := Do(context.Background(), testPolicy(), func(ctx context.Context) (Result, error) {
+		calls++
+		return Result{Retriable: false}, wantErr
+	})
+
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Do() error = %v, want %v", err, wantErr)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 for a non-retriable error", calls)
+	}
+}
+
+func TestDo_RetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), testPolicy(), func(ctx context.Context) (Result, error) {
+		calls++
+		if calls < 3 {
+			return Result{Retriable: true}, errors.New("transient")
+		}
+		return Result{}, nil
+	})
+
+	if err != nil {
+		t.Fatalf("Do() error = %v, want nil", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDo_ExhaustsBudgetAsRetryBudgetExceededError(t *testing.T) {
+	calls := 0
+	wantErr := errors.New("still failing")
+	err := Do(context.Background(), testPolicy(), func(ctx context.Context) (Result, error) {
+		calls++
+		return Result{Retriable: true}, wantErr
+	})
+
+	var budgetErr *RetryBudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("Do() error = %v, want *RetryBudgetExceededError", err)
+	}
+	if budgetErr.IsRetriable() {
+		t.Error("RetryBudgetExceededError.IsRetriable() = true, want false")
+	}
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Do() error does not unwrap to %v", wantErr)
+	}
+	if calls != testPolicy().MaxAttempts {
+		t.Errorf("calls = %d, want %d", calls, testPolicy().MaxAttempts)
+	}
+}
+
+func TestDo_RespectsContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Do(ctx, testPolicy(), func(ctx context.Context) (Result, error) {
+		calls++
+		if calls == 1 {
+			cancel()
+		}
+		return Result{Retriable: true}, errors.New("transient")
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do() error = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (Do should stop at the cancellation, not keep retrying)", calls)
+	}
+}
+
+func TestDo_HonorsResultRetryAfter(t *testing.T) {
+	calls := 0
+	var firstDelay time.Duration
+	start := time.Now()
+
+	_ = Do(context.Background(), testPolicy(), func(ctx context.Context) (Result, error) {
+		calls++
+		if calls == 1 {
+			return Result{Retriable: true, RetryAfter: 5 * time.Millisecond}, errors.New("rate limited")
+		}
+		firstDelay = time.Since(start)
+		return Result{}, nil
+	})
+
+	if firstDelay < 5*time.Millisecond {
+		t.Errorf("retry happened after %v, want at least the requested 5ms Retry-After", firstDelay)
+	}
+}
+
+func TestDecorrelatedJitterBackoff_GrowsAndCaps(t *testing.T) {
+	policy := Policy{BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond, Jitter: false}
+
+	first := decorrelatedJitterBackoff(policy, 0)
+	if first != policy.BaseDelay {
+		t.Errorf("first backoff = %v, want base delay %v", first, policy.BaseDelay)
+	}
+
+	grown := decorrelatedJitterBackoff(policy, 4*time.Millisecond)
+	if grown != 10*time.Millisecond {
+		t.Errorf("backoff = %v, want capped at MaxDelay (10ms)", grown)
+	}
+}
+
+func TestIsRetriableStatusCode(t *testing.T) {
+	tests := map[int]bool{
+		429: true,
+		408: true,
+		500: true,
+		503: true,
+		404: false,
+		200: false,
+	}
+
+	for code, want := range tests {
+		if got := IsRetriableStatusCode(code); got != want {
+			t.Errorf("IsRetriableStatusCode(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestParseRetryAfter_Seconds(t *testing.T) {
+	now := time.Now()
+	if got := ParseRetryAfter("5", now); got != 5*time.Second {
+		t.Errorf("ParseRetryAfter(%q) = %v, want 5s", "5", got)
+	}
+}
+
+func TestParseRetryAfter_EmptyOrInvalid(t *testing.T) {
+	now := time.Now()
+	if got := ParseRetryAfter("", now); got != 0 {
+		t.Errorf("ParseRetryAfter(\"\") = %v, want 0", got)
+	}
+	if got := ParseRetryAfter("not-a-date", now); got != 0 {
+		t.Errorf("ParseRetryAfter(invalid) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+)
+
+// ProgressStreamer is implemented by scanner backends that can report a
+// scan's incremental progress while it runs, so a caller (e.g.
+// scanneradapter.Server) can forward it to Prometheus and to a
+// Server-Sent Events subscriber instead of only learning the outcome when
+// Scan returns. Backends that don't implement it just scan without
+// emitting progress; none of this repository's backends (CLIScanner,
+// RegistryScanner, CompositeScanner, trivy.Scanner, clair.Scanner)
+// implements it, and nothing outside tests calls StreamAPIRequest.
+type ProgressStreamer interface {
+	StreamProgress(ctx context.Context, req *models.ScanRequest) (<-chan ScanProgressEvent, error)
+}
+
+// ScanProgressEvent is one line of newline-delimited JSON progress a
+// streaming scan status endpoint (or a local `sysdig-cli-scanner
+// --json-progress` invocation) emits while a scan is in progress, modeled
+// on Docker/Podman's own image-pull progress events.
+type ScanProgressEvent struct {
+	Stage     string `json:"stage"`
+	Layer     string `json:"layer,omitempty"`
+	BytesRead int64  `json:"bytes_read,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// StreamAPIRequest sends an HTTP request and decodes the response body as
+// newline-delimited ScanProgressEvent JSON, forwarding each event on the
+// returned channel as it arrives. The channel is closed once the stream
+// ends, ctx is cancelled, or retries are exhausted; a final event with a
+// non-empty Error is sent before closing if the stream ended abnormally.
+//
+// A mid-stream disconnect (io.ErrUnexpectedEOF) after at least one event
+// has been read is not treated as a full scan failure: the request is
+// reissued, carrying the last reported Stage as a "resume_from" query
+// parameter, up to c.maxRetries times, so a flaky connection doesn't
+// restart a long-running scan from the beginning.
+func (c *APIClient) StreamAPIRequest(ctx context.Context, method, targetURL string, body io.Reader) (<-chan ScanProgressEvent, error) {
+	events := make(chan ScanProgressEvent)
+
+	go func() {
+		defer close(events)
+
+		var lastStage string
+		for attempt := 0; ; attempt++ {
+			err := c.streamOnce(ctx, method, resumeURL(targetURL, lastStage), body, &lastStage, events)
+			if err == nil {
+				return
+			}
+
+			if !errors.Is(err, io.ErrUnexpectedEOF) || lastStage == "" || attempt >= c.maxRetries {
+				select {
+				case events <- ScanProgressEvent{Stage: lastStage, Error: err.Error()}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			c.logger.WithFields(logrus.Fields{
+				"attempt":    attempt + 1,
+				"last_stage": lastStage,
+			}).Warn("Scan progress stream disconnected, resuming from last stage")
+		}
+	}()
+
+	return events, nil
+}
+
+// streamOnce issues a single streaming request and decodes its body as
+// NDJSON, updating *lastStage as events are forwarded so a caller that
+// retries after a disconnect knows where to resume from.
+func (c *APIClient) streamOnce(ctx context.Context, method, targetURL string, body io.Reader, lastStage *string, events chan<- ScanProgressEvent) error {
+	req, err := http.NewRequestWithContext(ctx, method, targetURL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(HeaderAuthorization, fmt.Sprintf("Bearer %s", c.token))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("stream request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	lineScanner := bufio.NewScanner(resp.Body)
+	lineScanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for lineScanner.Scan() {
+		line := lineScanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var event ScanProgressEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("failed to decode progress event: %w", err)
+		}
+
+		*lastStage = event.Stage
+		metrics.RecordScanProgressStage(event.Stage)
+
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if event.Error != "" {
+			return fmt.Errorf("scan reported error at stage %s: %s", event.Stage, event.Error)
+		}
+	}
+
+	if err := lineScanner.Err(); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("stream read error: %w", err)
+	}
+
+	return nil
+}
+
+// resumeURL appends a "resume_from" query parameter naming lastStage, so a
+// reconnecting stream request picks up after the last stage it already
+// reported instead of restarting the scan. Returns targetURL unchanged
+// when lastStage is empty (first attempt, nothing to resume from).
+func resumeURL(targetURL, lastStage string) string {
+	if lastStage == "" {
+		return targetURL
+	}
+
+	sep := "?"
+	if strings.Contains(targetURL, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%sresume_from=%s", targetURL, sep, url.QueryEscape(lastStage))
+}
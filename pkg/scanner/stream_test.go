@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestAPIClient_StreamAPIRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"stage":"pulling","layer":"layer1","bytes_read":10,"total":100}` + "\n"))
+		w.Write([]byte(`{"stage":"analyzing"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client := NewAPIClient("test-token", true, logrus.New())
+
+	events, err := client.StreamAPIRequest(context.Background(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("StreamAPIRequest() error = %v", err)
+	}
+
+	var got []ScanProgressEvent
+	for event := range events {
+		got = append(got, event)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("StreamAPIRequest() forwarded %d events, want 2: %+v", len(got), got)
+	}
+	if got[0].Stage != "pulling" || got[0].Layer != "layer1" || got[0].BytesRead != 10 || got[0].Total != 100 {
+		t.Errorf("StreamAPIRequest() first event = %+v, want pulling/layer1/10/100", got[0])
+	}
+	if got[1].Stage != "analyzing" {
+		t.Errorf("StreamAPIRequest() second event = %+v, want stage=analyzing", got[1])
+	}
+}
+
+func TestAPIClient_StreamAPIRequest_ScanReportedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"stage":"pulling"}` + "\n"))
+		w.Write([]byte(`{"stage":"failed","error":"layer pull timed out"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client := NewAPIClient("test-token", true, logrus.New())
+	client.maxRetries = 0
+
+	events, err := client.StreamAPIRequest(context.Background(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("StreamAPIRequest() error = %v", err)
+	}
+
+	var got []ScanProgressEvent
+	for event := range events {
+		got = append(got, event)
+	}
+
+	last := got[len(got)-1]
+	if last.Error == "" {
+		t.Errorf("StreamAPIRequest() last event = %+v, want a reported error", last)
+	}
+}
+
+func TestAPIClient_StreamAPIRequest_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"stage":"pulling"}` + "\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewAPIClient("test-token", true, logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := client.StreamAPIRequest(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("StreamAPIRequest() error = %v", err)
+	}
+
+	<-events
+	cancel()
+
+	for range events {
+		// Drain until the goroutine observes ctx.Done() and closes the channel.
+	}
+}
+
+func TestResumeURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		targetURL string
+		lastStage string
+		want      string
+	}{
+		{name: "no last stage", targetURL: "https://example.com/scan/1", lastStage: "", want: "https://example.com/scan/1"},
+		{name: "no existing query", targetURL: "https://example.com/scan/1", lastStage: "pulling", want: "https://example.com/scan/1?resume_from=pulling"},
+		{name: "existing query", targetURL: "https://example.com/scan/1?foo=bar", lastStage: "pulling", want: "https://example.com/scan/1?foo=bar&resume_from=pulling"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resumeURL(tt.targetURL, tt.lastStage); got != tt.want {
+				t.Errorf("resumeURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package trivy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// rawReport is the subset of `trivy image --format json` output this
+// package understands: one entry per scanned target (typically just the
+// image's root filesystem), each carrying its own vulnerability list.
+type rawReport struct {
+	Results []rawResult `json:"Results"`
+}
+
+type rawResult struct {
+	Target          string             `json:"Target"`
+	Vulnerabilities []rawVulnerability `json:"Vulnerabilities"`
+}
+
+type rawVulnerability struct {
+	ID               string     `json:"VulnerabilityID"`
+	PkgName          string     `json:"PkgName"`
+	InstalledVersion string     `json:"InstalledVersion"`
+	FixedVersion     string     `json:"FixedVersion"`
+	Severity         string     `json:"Severity"`
+	Description      string     `json:"Description"`
+	PrimaryURL       string     `json:"PrimaryURL"`
+	CVSS             rawCVSSMap `json:"CVSS"`
+}
+
+// rawCVSSMap is keyed by scoring source (e.g. "nvd", "redhat"); this
+// package just takes whichever entry is present first, since Trivy
+// doesn't guarantee a fixed key set across ecosystems.
+type rawCVSSMap map[string]rawCVSS
+
+type rawCVSS struct {
+	V3Score  float64 `json:"V3Score"`
+	V3Vector string  `json:"V3Vector"`
+}
+
+// ParseReport unmarshals output as `trivy image --format json` output,
+// returning a models.ScanReport with per-finding detail. An empty output
+// isn't an error here: it means the scan produced no structured report
+// (e.g. it failed before Trivy ran), so callers get a nil report rather
+// than a parse failure. Non-empty output that isn't valid JSON returns
+// an error. Mirrors scanner.ParseReport's
+// contract for the Sysdig CLI Scanner's JSON output.
+func ParseReport(output string) (*models.ScanReport, error) {
+	if output == "" {
+		return nil, nil
+	}
+
+	var raw rawReport
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse Trivy report: %w", err)
+	}
+
+	report := &models.ScanReport{}
+
+	for _, result := range raw.Results {
+		for _, v := range result.Vulnerabilities {
+			if v.ID == "" || v.PkgName == "" {
+				continue
+			}
+
+			score, vector := firstCVSS(v.CVSS)
+			severity := normalizeSeverity(v.Severity)
+
+			report.Vulnerabilities = append(report.Vulnerabilities, models.Vulnerability{
+				ID:             v.ID,
+				Severity:       severity,
+				PackageName:    v.PkgName,
+				PackageVersion: v.InstalledVersion,
+				FixedVersion:   v.FixedVersion,
+				Description:    v.Description,
+				CVSSScore:      score,
+				CVSSVector:     vector,
+				Link:           v.PrimaryURL,
+				LayerDigest:    result.Target,
+			})
+
+			switch severity {
+			case "Critical":
+				report.Summary.Critical++
+			case "High":
+				report.Summary.High++
+			case "Medium":
+				report.Summary.Medium++
+			case "Low":
+				report.Summary.Low++
+			default:
+				report.Summary.Negligible++
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// firstCVSS returns the score/vector from an arbitrary entry in cvss,
+// since Go map iteration order is unspecified but Trivy only ever
+// populates one or two scoring sources per finding and this package
+// doesn't distinguish between them.
+func firstCVSS(cvss rawCVSSMap) (float64, string) {
+	for _, c := range cvss {
+		return c.V3Score, c.V3Vector
+	}
+	return 0, ""
+}
+
+// normalizeSeverity maps Trivy's severity strings (upper-case, e.g.
+// "CRITICAL") onto this repo's vocabulary. Mirrors
+// scanner.normalizeSeverity's mapping for the Sysdig CLI Scanner.
+func normalizeSeverity(severity string) string {
+	switch severity {
+	case "CRITICAL":
+		return "Critical"
+	case "HIGH":
+		return "High"
+	case "MEDIUM":
+		return "Medium"
+	case "LOW":
+		return "Low"
+	default:
+		return "Negligible"
+	}
+}
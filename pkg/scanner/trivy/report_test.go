@@ -0,0 +1,69 @@
+package trivy
+
+import "testing"
+
+func TestParseReport_Empty(t *testing.T) {
+	report, err := ParseReport("")
+	if err != nil {
+		t.Errorf("ParseReport() error = %v, want nil", err)
+	}
+	if report != nil {
+		t.Errorf("ParseReport() = %v, want nil", report)
+	}
+}
+
+func TestParseReport_InvalidJSON(t *testing.T) {
+	_, err := ParseReport("not json")
+	if err == nil {
+		t.Error("ParseReport() error = nil, want error for invalid JSON")
+	}
+}
+
+func TestParseReport_Vulnerabilities(t *testing.T) {
+	output := `{
+		"Results": [
+			{
+				"Target": "myimage (alpine 3.18)",
+				"Vulnerabilities": [
+					{
+						"VulnerabilityID": "CVE-2023-1234",
+						"PkgName": "openssl",
+						"InstalledVersion": "1.1.1",
+						"FixedVersion": "1.1.2",
+						"Severity": "CRITICAL",
+						"Description": "a bad bug",
+						"PrimaryURL": "https://example.com/CVE-2023-1234",
+						"CVSS": {"nvd": {"V3Score": 9.8, "V3Vector": "AV:N"}}
+					},
+					{
+						"VulnerabilityID": "CVE-2023-5678",
+						"PkgName": "busybox",
+						"InstalledVersion": "1.30",
+						"Severity": "LOW"
+					}
+				]
+			}
+		]
+	}`
+
+	report, err := ParseReport(output)
+	if err != nil {
+		t.Fatalf("ParseReport() error = %v, want nil", err)
+	}
+
+	if len(report.Vulnerabilities) != 2 {
+		t.Fatalf("ParseReport() vulnerabilities = %d, want 2", len(report.Vulnerabilities))
+	}
+
+	first := report.Vulnerabilities[0]
+	if first.ID != "CVE-2023-1234" || first.Severity != "Critical" || first.PackageName != "openssl" {
+		t.Errorf("ParseReport() first vulnerability = %+v", first)
+	}
+	if first.CVSSScore != 9.8 || first.LayerDigest != "myimage (alpine 3.18)" {
+		t.Errorf("ParseReport() first vulnerability CVSS/target = %+v", first)
+	}
+
+	if report.Summary.Critical != 1 || report.Summary.Low != 1 {
+		t.Errorf("ParseReport() summary = %+v, want Critical=1 Low=1", report.Summary)
+	}
+}
@@ -0,0 +1,313 @@
+// Package trivy implements a ScannerBackend that shells out to the Trivy
+// CLI (aquasecurity/trivy), normalizing its findings into the same
+// models.ScanResult/models.ScanReport shape scanner.ParseReport produces
+// for the Sysdig CLI Scanner. It exists so operators without a Sysdig
+// license can still use this webhook, and so Sysdig users can cross-check
+// findings by running both backends (see config.ScannerTypeComposite).
+package trivy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+	"github.com/sysdig/registry-webhook-scanner/pkg/registryauth"
+)
+
+// DefaultBinaryPath is used when config.TrivyScannerConfig.BinaryPath is
+// unset, relying on "trivy" being resolvable via PATH.
+const DefaultBinaryPath = "trivy"
+
+// Scanner wraps the Trivy CLI.
+type Scanner struct {
+	config       *config.Config
+	logger       *logrus.Logger
+	registryAuth *registryauth.Resolver
+}
+
+// NewScanner creates a new Trivy Scanner instance.
+func NewScanner(cfg *config.Config, logger *logrus.Logger) *Scanner {
+	return &Scanner{
+		config:       cfg,
+		logger:       logger,
+		registryAuth: registryauth.NewResolver(0),
+	}
+}
+
+// Type returns the scanner type identifier
+func (s *Scanner) Type() string {
+	return string(config.ScannerTypeTrivy)
+}
+
+// binaryPath returns the configured trivy executable path, falling back
+// to DefaultBinaryPath.
+func (s *Scanner) binaryPath() string {
+	if s.config.Scanner.Trivy != nil && s.config.Scanner.Trivy.BinaryPath != "" {
+		return s.config.Scanner.Trivy.BinaryPath
+	}
+	return DefaultBinaryPath
+}
+
+// ValidateConfig checks that the Trivy binary is available and executable
+func (s *Scanner) ValidateConfig() error {
+	_, err := exec.LookPath(s.binaryPath())
+	if err != nil {
+		return fmt.Errorf("trivy binary not found at %s: %w", s.binaryPath(), err)
+	}
+	return nil
+}
+
+// Scan executes `trivy image --format json` for the given image
+func (s *Scanner) Scan(ctx context.Context, req *models.ScanRequest) (*models.ScanResult, error) {
+	startTime := time.Now()
+
+	result := &models.ScanResult{
+		ImageRef:  req.ImageRef,
+		RequestID: req.RequestID,
+		Status:    models.ScanStatusRunning,
+		StartedAt: startTime,
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":    req.ImageRef,
+		"request_id":   req.RequestID,
+		"scanner_type": "trivy",
+	}).Info("Starting Trivy image scan")
+
+	cmd, err := s.buildCommand(ctx, req)
+	if err != nil {
+		result.Status = models.ScanStatusFailed
+		result.Error = fmt.Sprintf("failed to build command: %v", err)
+		return result, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = s.executeWithTimeout(ctx, cmd, req)
+
+	result.Output = stdout.String()
+	result.ErrorOutput = stderr.String()
+	result.CompletedAt = time.Now()
+	result.Duration = result.CompletedAt.Sub(startTime)
+
+	if err != nil {
+		result.ExitCode = s.getExitCode(err)
+
+		if ctx.Err() == context.DeadlineExceeded || errors.Is(err, context.DeadlineExceeded) {
+			result.Status = models.ScanStatusTimeout
+			result.Error = "Trivy scan timeout exceeded"
+			s.logger.WithFields(logrus.Fields{
+				"image_ref":    req.ImageRef,
+				"request_id":   req.RequestID,
+				"duration":     result.Duration,
+				"scanner_type": "trivy",
+			}).Warn("Trivy scan timeout")
+			return result, fmt.Errorf("Trivy scan timeout")
+		}
+
+		result.Status = models.ScanStatusFailed
+		result.Error = err.Error()
+		s.logger.WithFields(logrus.Fields{
+			"image_ref":    req.ImageRef,
+			"request_id":   req.RequestID,
+			"error":        err.Error(),
+			"exit_code":    result.ExitCode,
+			"scanner_type": "trivy",
+		}).Error("Trivy scan failed")
+
+		metrics.RecordScannerType("trivy", "failed")
+		metrics.RecordScanDuration("trivy", "failed", result.Duration.Seconds())
+		metrics.RecordScan("trivy", req.RegistryName, "failed")
+
+		return result, err
+	}
+
+	result.Status = models.ScanStatusSuccess
+	result.ExitCode = 0
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":    req.ImageRef,
+		"request_id":   req.RequestID,
+		"duration":     result.Duration,
+		"scanner_type": "trivy",
+	}).Info("Trivy scan completed successfully")
+
+	s.attachReport(result)
+
+	metrics.RecordScannerType("trivy", "success")
+	metrics.RecordScanDuration("trivy", "success", result.Duration.Seconds())
+	metrics.RecordScan("trivy", req.RegistryName, "success")
+
+	return result, nil
+}
+
+// attachReport parses result.Output as Trivy JSON and, if recognized,
+// sets result.Report and logs its vulnerability counts. A parse failure
+// is logged and otherwise ignored: result.Output is still returned to the
+// caller raw, so nothing is lost.
+func (s *Scanner) attachReport(result *models.ScanResult) {
+	report, err := ParseReport(result.Output)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"image_ref":  result.ImageRef,
+			"request_id": result.RequestID,
+			"error":      err.Error(),
+		}).Warn("Failed to parse Trivy JSON report")
+		return
+	}
+	if report == nil {
+		return
+	}
+
+	result.Report = report
+
+	s.logger.WithFields(logrus.Fields{
+		"image_ref":  result.ImageRef,
+		"request_id": result.RequestID,
+		"critical":   report.Summary.Critical,
+		"high":       report.Summary.High,
+		"medium":     report.Summary.Medium,
+		"low":        report.Summary.Low,
+		"total":      report.Summary.Total(),
+	}).Info("Trivy report parsed")
+}
+
+// buildScanArgs constructs the arguments for the Trivy CLI
+func (s *Scanner) buildScanArgs(req *models.ScanRequest) []string {
+	return []string{
+		"image",
+		"--format", "json",
+		"--quiet",
+		req.ImageRef,
+	}
+}
+
+// buildCommand constructs the Trivy command, injecting registry
+// credentials as the TRIVY_USERNAME/TRIVY_PASSWORD (or
+// TRIVY_REGISTRY_TOKEN for a resolved identity token) environment
+// variables Trivy reads for private images.
+func (s *Scanner) buildCommand(ctx context.Context, req *models.ScanRequest) (*exec.Cmd, error) {
+	args := s.buildScanArgs(req)
+
+	cmd := exec.CommandContext(ctx, s.binaryPath(), args...)
+	cmd.Env = append(cmd.Env, s.registryEnv(req)...)
+
+	return cmd, nil
+}
+
+// registryEnv resolves req's registry credentials (a static
+// username/password wins if set, otherwise falling back to Docker/OCI
+// credential resolution of reg's DockerConfigPath through
+// registryauth.Resolver) and returns them as Trivy's expected environment
+// variables. Unlike CLIScanner's CredentialProvider it does not consult
+// Scanner.AuthFile, a per-registry Helper or ImagePullSecrets. Returns nil
+// for a public image with nothing configured.
+func (s *Scanner) registryEnv(req *models.ScanRequest) []string {
+	if req.RegistryName == "" {
+		return nil
+	}
+
+	for _, reg := range s.config.Registries {
+		if reg.Name != req.RegistryName {
+			continue
+		}
+
+		if reg.Scanner.Credentials.Username != "" {
+			return []string{
+				fmt.Sprintf("TRIVY_USERNAME=%s", reg.Scanner.Credentials.Username),
+				fmt.Sprintf("TRIVY_PASSWORD=%s", reg.Scanner.Credentials.Password),
+			}
+		}
+
+		creds, err := s.registryAuth.Resolve(registryHostname(reg.URL), reg.Scanner.DockerConfigPath)
+		if err != nil {
+			s.logger.WithError(err).WithField("registry", reg.Name).Warn("Failed to resolve Docker credentials")
+			return nil
+		}
+		if creds.IdentityToken != "" {
+			return []string{fmt.Sprintf("TRIVY_REGISTRY_TOKEN=%s", creds.IdentityToken)}
+		}
+		if creds.Username != "" {
+			return []string{
+				fmt.Sprintf("TRIVY_USERNAME=%s", creds.Username),
+				fmt.Sprintf("TRIVY_PASSWORD=%s", creds.Password),
+			}
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// executeWithTimeout executes the command with a timeout
+func (s *Scanner) executeWithTimeout(ctx context.Context, cmd *exec.Cmd, req *models.ScanRequest) error {
+	timeout, err := s.getTimeout(req)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start scanner: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timeoutCtx.Done():
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+		return timeoutCtx.Err()
+	}
+}
+
+// getTimeout returns the timeout duration for a scan request, duplicating
+// cli_scanner.CLIScanner.getTimeout's registry-override-then-default
+// lookup since the two backends share a config shape but not a package.
+func (s *Scanner) getTimeout(req *models.ScanRequest) (time.Duration, error) {
+	for _, reg := range s.config.Registries {
+		if reg.Name == req.RegistryName && reg.Scanner.Timeout != "" {
+			return time.ParseDuration(reg.Scanner.Timeout)
+		}
+	}
+	return time.ParseDuration(s.config.Scanner.DefaultTimeout)
+}
+
+// getExitCode extracts the exit code from an exec.ExitError
+func (s *Scanner) getExitCode(err error) int {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// registryHostname strips the scheme from a configured registry URL,
+// since Docker/OCI config.json keys its "auths"/"credHelpers" entries by
+// bare hostname. Duplicates cli_scanner.registryHostname for the same
+// reason getTimeout does.
+func registryHostname(registryURL string) string {
+	host := strings.TrimPrefix(registryURL, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return host
+}
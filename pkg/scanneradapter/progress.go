@@ -0,0 +1,67 @@
+package scanneradapter
+
+import (
+	"sync"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner"
+)
+
+// progressBroker fans out scanner.ScanProgressEvent for each in-flight
+// scan to any subscribers (handleScanProgress's SSE connections), keyed
+// by scan_request_id. A scan with no subscribers simply drops its events
+// instead of blocking.
+type progressBroker struct {
+	mu   sync.Mutex
+	subs map[string][]chan scanner.ScanProgressEvent
+}
+
+// newProgressBroker creates an empty progressBroker.
+func newProgressBroker() *progressBroker {
+	return &progressBroker{subs: make(map[string][]chan scanner.ScanProgressEvent)}
+}
+
+// subscribe registers a subscriber channel for id, returning an
+// unsubscribe function the caller must call exactly once when done
+// listening.
+func (b *progressBroker) subscribe(id string) (<-chan scanner.ScanProgressEvent, func()) {
+	ch := make(chan scanner.ScanProgressEvent, 16)
+
+	b.mu.Lock()
+	b.subs[id] = append(b.subs[id], ch)
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		subs := b.subs[id]
+		for i, s := range subs {
+			if s == ch {
+				b.subs[id] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(b.subs[id]) == 0 {
+			delete(b.subs, id)
+		}
+		close(ch)
+	}
+
+	return ch, unsubscribe
+}
+
+// publish forwards event to every subscriber currently listening for id.
+// A subscriber whose buffer is full has the event dropped rather than
+// blocking the scan that's producing it.
+func (b *progressBroker) publish(id string, event scanner.ScanProgressEvent) {
+	b.mu.Lock()
+	subs := append([]chan scanner.ScanProgressEvent(nil), b.subs[id]...)
+	b.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
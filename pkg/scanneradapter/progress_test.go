@@ -0,0 +1,46 @@
+package scanneradapter
+
+import (
+	"testing"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner"
+)
+
+func TestProgressBroker_PublishToSubscriber(t *testing.T) {
+	b := newProgressBroker()
+
+	events, unsubscribe := b.subscribe("scan-1")
+	defer unsubscribe()
+
+	b.publish("scan-1", scanner.ScanProgressEvent{Stage: "pulling"})
+
+	select {
+	case event := <-events:
+		if event.Stage != "pulling" {
+			t.Errorf("publish() delivered %+v, want stage=pulling", event)
+		}
+	default:
+		t.Error("publish() did not deliver to subscriber")
+	}
+}
+
+func TestProgressBroker_PublishWithNoSubscribers(t *testing.T) {
+	b := newProgressBroker()
+
+	// Should not panic or block when nobody is listening.
+	b.publish("scan-1", scanner.ScanProgressEvent{Stage: "pulling"})
+}
+
+func TestProgressBroker_Unsubscribe(t *testing.T) {
+	b := newProgressBroker()
+
+	events, unsubscribe := b.subscribe("scan-1")
+	unsubscribe()
+
+	if _, ok := <-events; ok {
+		t.Error("unsubscribe() expected channel to be closed")
+	}
+
+	// publish() after unsubscribe should be a no-op, not a panic.
+	b.publish("scan-1", scanner.ScanProgressEvent{Stage: "pulling"})
+}
@@ -0,0 +1,101 @@
+package scanneradapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// rawScanOutput is the subset of the CLI/Registry scanner's JSON scan
+// output this adapter understands for per-finding detail, parsing the
+// same vulnerability fields as scanner.rawReport (see scanner.ParseReport)
+// for Harbor's report format. It is intentionally tolerant: any
+// vulnerability missing an ID or package name is skipped rather than
+// failing the whole translation.
+type rawScanOutput struct {
+	Vulnerabilities []rawVulnerability `json:"vulnerabilities"`
+}
+
+type rawVulnerability struct {
+	ID             string  `json:"vulnerabilityId"`
+	Severity       string  `json:"severity"`
+	PackageName    string  `json:"packageName"`
+	PackageVersion string  `json:"packageVersion"`
+	FixedVersion   string  `json:"fixedVersion"`
+	Description    string  `json:"description"`
+	CVSSScore      float64 `json:"cvssScore"`
+	CVSSVector     string  `json:"cvssVector"`
+	Link           string  `json:"link"`
+}
+
+// buildReport translates a scanner.ScannerBackend result into a Harbor
+// VulnerabilityReport. If the scanner's output doesn't contain
+// per-finding detail in a shape this adapter recognizes, it falls back to
+// a report with no individual findings rather than guessing.
+func buildReport(scannerInfo ScannerInfo, result *models.ScanResult) (*VulnerabilityReport, error) {
+	report := &VulnerabilityReport{
+		GeneratedAt: result.CompletedAt,
+		Scanner:     scannerInfo,
+		Severity:    "Unknown",
+	}
+
+	var raw rawScanOutput
+	if err := json.Unmarshal([]byte(result.Output), &raw); err != nil {
+		// Not (or not fully) the JSON shape we understand; return a
+		// severity-less report rather than erroring the whole scan.
+		return report, nil
+	}
+
+	for _, v := range raw.Vulnerabilities {
+		if v.ID == "" || v.PackageName == "" {
+			continue
+		}
+
+		vuln := Vulnerability{
+			ID:          v.ID,
+			Package:     v.PackageName,
+			Version:     v.PackageVersion,
+			FixVersion:  v.FixedVersion,
+			Severity:    normalizeSeverity(v.Severity),
+			Description: v.Description,
+		}
+		if v.Link != "" {
+			vuln.Links = []string{v.Link}
+		}
+		if v.CVSSScore > 0 || v.CVSSVector != "" {
+			vuln.CVSS = &CVSS{ScoreV3: v.CVSSScore, VectorV3: v.CVSSVector}
+		}
+
+		report.Vulnerabilities = append(report.Vulnerabilities, vuln)
+		if severityRank[vuln.Severity] > severityRank[report.Severity] {
+			report.Severity = vuln.Severity
+		}
+	}
+
+	return report, nil
+}
+
+// normalizeSeverity maps scanner severity strings onto Harbor's severity
+// vocabulary, defaulting to "Unknown" for anything unrecognized.
+func normalizeSeverity(severity string) string {
+	switch severity {
+	case "Critical", "critical":
+		return "Critical"
+	case "High", "high":
+		return "High"
+	case "Medium", "medium":
+		return "Medium"
+	case "Low", "low":
+		return "Low"
+	case "Negligible", "negligible":
+		return "Negligible"
+	default:
+		return "Unknown"
+	}
+}
+
+// errScanFailed wraps a failed ScanResult's error for the report endpoint.
+func errScanFailed(result *models.ScanResult) error {
+	return fmt.Errorf("scan failed: %s", result.Error)
+}
@@ -0,0 +1,100 @@
+package scanneradapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+func TestBuildReport(t *testing.T) {
+	scannerInfo := ScannerInfo{Name: "sysdig-registry-webhook", Vendor: "sysdig", Version: "1.0.0"}
+
+	tests := []struct {
+		name             string
+		result           *models.ScanResult
+		wantSeverity     string
+		wantVulnCount    int
+	}{
+		{
+			name: "well-formed JSON output with mixed severities",
+			result: &models.ScanResult{
+				Output: `{"vulnerabilities":[
+					{"vulnerabilityId":"CVE-2024-0001","severity":"High","packageName":"openssl","packageVersion":"1.1.1","fixedVersion":"1.1.2","cvssScore":7.5,"cvssVector":"AV:N/AC:L"},
+					{"vulnerabilityId":"CVE-2024-0002","severity":"Critical","packageName":"libxml2","packageVersion":"2.9.10","fixedVersion":"2.9.11"}
+				]}`,
+				CompletedAt: time.Now(),
+			},
+			wantSeverity:  "Critical",
+			wantVulnCount: 2,
+		},
+		{
+			name: "vulnerability missing id or package is skipped",
+			result: &models.ScanResult{
+				Output: `{"vulnerabilities":[
+					{"vulnerabilityId":"","severity":"High","packageName":"openssl"},
+					{"vulnerabilityId":"CVE-2024-0003","severity":"Low","packageName":""}
+				]}`,
+				CompletedAt: time.Now(),
+			},
+			wantSeverity:  "Unknown",
+			wantVulnCount: 0,
+		},
+		{
+			name: "non-JSON output falls back to an empty report",
+			result: &models.ScanResult{
+				Output:      "Scan complete: 2 critical, 1 high vulnerabilities found",
+				CompletedAt: time.Now(),
+			},
+			wantSeverity:  "Unknown",
+			wantVulnCount: 0,
+		},
+		{
+			name: "empty output falls back to an empty report",
+			result: &models.ScanResult{
+				Output:      "",
+				CompletedAt: time.Now(),
+			},
+			wantSeverity:  "Unknown",
+			wantVulnCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := buildReport(scannerInfo, tt.result)
+			if err != nil {
+				t.Fatalf("buildReport() returned unexpected error: %v", err)
+			}
+
+			if report.Severity != tt.wantSeverity {
+				t.Errorf("Severity = %q, want %q", report.Severity, tt.wantSeverity)
+			}
+			if len(report.Vulnerabilities) != tt.wantVulnCount {
+				t.Errorf("len(Vulnerabilities) = %d, want %d", len(report.Vulnerabilities), tt.wantVulnCount)
+			}
+			if report.Scanner != scannerInfo {
+				t.Errorf("Scanner = %+v, want %+v", report.Scanner, scannerInfo)
+			}
+		})
+	}
+}
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := map[string]string{
+		"Critical":  "Critical",
+		"critical":  "Critical",
+		"High":      "High",
+		"Medium":    "Medium",
+		"low":       "Low",
+		"Negligible": "Negligible",
+		"":          "Unknown",
+		"garbage":   "Unknown",
+	}
+
+	for input, want := range tests {
+		if got := normalizeSeverity(input); got != want {
+			t.Errorf("normalizeSeverity(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
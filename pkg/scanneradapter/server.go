@@ -0,0 +1,378 @@
+package scanneradapter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/auth"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/queue"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner"
+)
+
+// resultTTL bounds how long a completed scan's report is kept around for
+// Harbor to collect before this adapter evicts it.
+const resultTTL = 1 * time.Hour
+
+// Server exposes the configured scanner backend as a Harbor Pluggable
+// Scanner HTTP API. It owns its own scan queue and worker pool,
+// independent of the webhook path's, since scans submitted here are
+// driven by Harbor's adapter protocol rather than registry webhooks and
+// need their outcome tracked for later retrieval via GET .../report.
+type Server struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	router     *mux.Router
+	httpServer *http.Server
+
+	queue      *queue.ScanQueue
+	workerPool *queue.WorkerPool
+	results    *resultStore
+	progress   *progressBroker
+
+	scannerInfo ScannerInfo
+}
+
+// NewServer creates a Harbor Pluggable Scanner adapter listening on
+// cfg.ScannerAdapter.Port.
+// Requests must carry cfg.ScannerAdapter.Token as a "Bearer <token>"
+// Authorization header, same convention as shutdown.AdminServer.
+func NewServer(cfg *config.Config, logger *logrus.Logger) *Server {
+	queueSize := cfg.ScannerAdapter.QueueSize
+	if queueSize == 0 {
+		queueSize = 100
+	}
+	workers := cfg.ScannerAdapter.Workers
+	if workers == 0 {
+		workers = 3
+	}
+
+	registryLimits := make(map[string]int, len(cfg.Registries))
+	for _, reg := range cfg.Registries {
+		if reg.MaxConcurrent > 0 {
+			registryLimits[reg.Name] = reg.MaxConcurrent
+		}
+	}
+
+	s := &Server{
+		config:   cfg,
+		logger:   logger,
+		router:   mux.NewRouter(),
+		queue:    queue.NewScanQueue(queueSize, registryLimits, logger),
+		results:  newResultStore(resultTTL),
+		progress: newProgressBroker(),
+		scannerInfo: ScannerInfo{
+			Name:    "sysdig-registry-webhook",
+			Vendor:  "sysdig",
+			Version: "1.0.0",
+		},
+	}
+
+	s.workerPool = queue.NewWorkerPool(s.queue, workers, s.handleScan, logger)
+
+	s.router.HandleFunc("/api/v1/metadata", s.handleMetadata).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/scan", s.handleSubmitScan).Methods(http.MethodPost)
+	s.router.HandleFunc("/api/v1/scan/{id}/report", s.handleGetReport).Methods(http.MethodGet)
+	s.router.HandleFunc("/api/v1/scan/{id}/progress", s.handleScanProgress).Methods(http.MethodGet)
+
+	s.httpServer = &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.ScannerAdapter.Port),
+		Handler: s.authMiddleware(s.router),
+	}
+
+	return s
+}
+
+// Start starts the worker pool and the adapter HTTP server, blocking
+// until the server stops.
+func (s *Server) Start() error {
+	s.workerPool.Start()
+
+	s.logger.WithField("addr", s.httpServer.Addr).Info("Starting Harbor scanner adapter API")
+
+	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("scanner adapter server error: %w", err)
+	}
+	return nil
+}
+
+// Shutdown stops the adapter HTTP server and its worker pool.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("scanner adapter server shutdown error: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	timeout := 30 * time.Second
+	if ok {
+		timeout = time.Until(deadline)
+	}
+	if err := s.workerPool.Stop(timeout); err != nil {
+		return fmt.Errorf("scanner adapter worker pool shutdown error: %w", err)
+	}
+
+	s.queue.Close()
+	return nil
+}
+
+// authMiddleware wires auth.VerifyBearerToken in front of every adapter
+// route, same as Harbor's own scanner registration: the token configured
+// when registering this webhook as a scanner is sent back as the
+// "Authorization" header on every request.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := auth.VerifyBearerToken(r, s.config.ScannerAdapter.Token); err != nil {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleMetadata serves GET /api/v1/metadata.
+func (s *Server) handleMetadata(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Metadata{
+		Scanner: s.scannerInfo,
+		Capabilities: []Capability{
+			{
+				ConsumesMimeTypes: []string{MimeTypeOCIManifest, MimeTypeDockerManifest},
+				ProducesMimeTypes: []string{MimeTypeHarborReport, MimeTypeGenericReport},
+			},
+		},
+		Properties: map[string]string{
+			"harbor.scanner-adapter/scanner-type": "os-package-vulnerability",
+		},
+	})
+}
+
+// handleSubmitScan serves POST /api/v1/scan.
+func (s *Server) handleSubmitScan(w http.ResponseWriter, r *http.Request) {
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid scan request: %v", err)})
+		return
+	}
+
+	if req.Artifact.Repository == "" || req.Artifact.Digest == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "artifact.repository and artifact.digest are required"})
+		return
+	}
+
+	scanReq := s.buildScanRequest(&req)
+
+	id := generateScanID()
+	scanReq.RequestID = id
+	s.results.evictExpired()
+	s.results.putPending(id)
+
+	if err := s.queue.Enqueue(r.Context(), scanReq, s.registryPriority(scanReq.RegistryName)); err != nil {
+		s.results.fail(id, err)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": fmt.Sprintf("failed to enqueue scan: %v", err)})
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"request_id": id,
+		"image_ref":  scanReq.ImageRef,
+	}).Info("Scan accepted via Harbor scanner adapter")
+
+	writeJSON(w, http.StatusAccepted, ScanResponse{ID: id})
+}
+
+// buildScanRequest maps a Harbor ScanRequest onto an internal
+// models.ScanRequest. Per-registry credentials aren't resolved here: the
+// scanner backend (CLIScanner.buildCommand, RegistryScanner.buildScanRequest)
+// already looks them up from config.Config.Registries by RegistryName, the
+// same as it does for webhook-driven scans.
+func (s *Server) buildScanRequest(req *ScanRequest) *models.ScanRequest {
+	imageRef := fmt.Sprintf("%s@%s", req.Artifact.Repository, req.Artifact.Digest)
+
+	registryName := s.resolveRegistryName(req.Registry.URL)
+
+	return &models.ScanRequest{
+		ImageRef:     imageRef,
+		RegistryName: registryName,
+		Registry:     req.Registry.URL,
+		Repository:   req.Artifact.Repository,
+		Tag:          req.Artifact.Tag,
+		Digest:       req.Artifact.Digest,
+		ReceivedAt:   time.Now(),
+		QueuedAt:     time.Now(),
+	}
+}
+
+// resolveRegistryName finds the configured registry whose URL matches
+// registryURL, so the right scanner override and credentials are used.
+func (s *Server) resolveRegistryName(registryURL string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(registryURL, "https://"), "http://")
+
+	for _, reg := range s.config.Registries {
+		regHost := strings.TrimPrefix(strings.TrimPrefix(reg.URL, "https://"), "http://")
+		if regHost == host {
+			return reg.Name
+		}
+	}
+
+	return ""
+}
+
+// registryPriority looks up registryName's configured
+// RegistryConfig.Priority and parses it into a queue.Priority, so a scan
+// for a high-priority registry isn't stuck behind a backlog from a
+// lower-priority one sharing this server's worker pool.
+func (s *Server) registryPriority(registryName string) queue.Priority {
+	for _, reg := range s.config.Registries {
+		if reg.Name == registryName {
+			return queue.ParsePriority(reg.Priority)
+		}
+	}
+	return queue.PriorityNormal
+}
+
+// handleGetReport serves GET /api/v1/scan/{id}/report.
+func (s *Server) handleGetReport(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	state, ok := s.results.get(id)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown scan_request_id"})
+		return
+	}
+
+	switch state.status {
+	case models.ScanStatusSuccess:
+		writeJSON(w, http.StatusOK, state.report)
+	case models.ScanStatusFailed, models.ScanStatusTimeout:
+		scanner.WriteRegistryError(w, state.err)
+	default:
+		// Scan is still pending or running; Harbor polls until this
+		// stops returning 302.
+		w.Header().Set("Location", r.URL.Path)
+		w.WriteHeader(http.StatusFound)
+	}
+}
+
+// handleScanProgress serves GET /api/v1/scan/{id}/progress as
+// Server-Sent Events, so a UI can subscribe to a scan's incremental
+// progress (scanner.ScanProgressEvent) instead of polling handleGetReport.
+// Only scans run by a backend implementing scanner.ProgressStreamer
+// produce any events; for others the connection simply stays open with no
+// data lines until the client disconnects.
+func (s *Server) handleScanProgress(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "streaming not supported"})
+		return
+	}
+
+	events, unsubscribe := s.progress.subscribe(id)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// streamScanProgress forwards a scan's incremental progress events to the
+// progress broker for handleScanProgress's subscribers, if any. It
+// returns once the backend's progress channel closes (the scan finished)
+// or ctx is cancelled.
+func (s *Server) streamScanProgress(ctx context.Context, streamer scanner.ProgressStreamer, req *models.ScanRequest) {
+	events, err := streamer.StreamProgress(ctx, req)
+	if err != nil {
+		s.logger.WithError(err).WithField("request_id", req.RequestID).Warn("Failed to start scan progress stream")
+		return
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			s.progress.publish(req.RequestID, event)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleScan is the queue.ScanHandler that actually runs scans submitted
+// through the adapter, storing their translated report for later
+// retrieval via handleGetReport.
+func (s *Server) handleScan(ctx context.Context, req *models.ScanRequest) error {
+	backend, err := scanner.NewScannerBackend(s.config, req.RegistryName, s.logger)
+	if err != nil {
+		s.results.fail(req.RequestID, err)
+		return fmt.Errorf("scanner backend creation failed: %w", err)
+	}
+
+	if streamer, ok := backend.(scanner.ProgressStreamer); ok {
+		go s.streamScanProgress(ctx, streamer, req)
+	}
+
+	result, err := backend.Scan(ctx, req)
+	if err != nil {
+		s.results.fail(req.RequestID, err)
+		return fmt.Errorf("scan execution failed: %w", err)
+	}
+
+	if result.Status != models.ScanStatusSuccess {
+		s.results.fail(req.RequestID, errScanFailed(result))
+		return errScanFailed(result)
+	}
+
+	report, err := buildReport(s.scannerInfo, result)
+	if err != nil {
+		s.results.fail(req.RequestID, err)
+		return fmt.Errorf("failed to translate scan result: %w", err)
+	}
+
+	s.results.complete(req.RequestID, report)
+	return nil
+}
+
+// generateScanID generates a unique scan_request_id, same convention as
+// webhook/parsers.generateRequestID.
+func generateScanID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,99 @@
+package scanneradapter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// scanState tracks the lifecycle of a scan submitted through the adapter,
+// from acceptance through to its translated Harbor report.
+type scanState struct {
+	status    models.ScanStatus
+	report    *VulnerabilityReport
+	err       error
+	createdAt time.Time
+}
+
+// resultStore holds in-flight and completed scans keyed by the
+// scan_request_id the adapter handed back from POST /api/v1/scan, so a
+// later GET /api/v1/scan/{id}/report can look up its outcome. Entries are
+// evicted after ttl to bound memory for reports Harbor never collects.
+type resultStore struct {
+	mu      sync.RWMutex
+	entries map[string]*scanState
+	ttl     time.Duration
+}
+
+// newResultStore creates a result store that evicts entries older than ttl.
+func newResultStore(ttl time.Duration) *resultStore {
+	return &resultStore{
+		entries: make(map[string]*scanState),
+		ttl:     ttl,
+	}
+}
+
+// putPending records a newly-accepted scan as pending.
+func (s *resultStore) putPending(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[id] = &scanState{
+		status:    models.ScanStatusPending,
+		createdAt: time.Now(),
+	}
+}
+
+// complete records the translated report for a finished scan.
+func (s *resultStore) complete(id string, report *VulnerabilityReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[id]
+	if !ok {
+		entry = &scanState{createdAt: time.Now()}
+		s.entries[id] = entry
+	}
+	entry.status = models.ScanStatusSuccess
+	entry.report = report
+}
+
+// fail records a scan as failed with the given error, kept as-is (rather
+// than just its message) so handleGetReport can render it through
+// scanner.WriteRegistryError when it's one of the scanner subsystem's
+// typed errors.
+func (s *resultStore) fail(id string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[id]
+	if !ok {
+		entry = &scanState{createdAt: time.Now()}
+		s.entries[id] = entry
+	}
+	entry.status = models.ScanStatusFailed
+	entry.err = err
+}
+
+// get returns the current state of a scan, or false if the ID is unknown
+// (either never submitted, or evicted).
+func (s *resultStore) get(id string) (scanState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[id]
+	if !ok {
+		return scanState{}, false
+	}
+	return *entry, true
+}
+
+// evictExpired removes entries older than ttl. Server.handleSubmitScan
+// runs it before recording each newly accepted scan.
+func (s *resultStore) evictExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, entry := range s.entries {
+		if now.Sub(entry.createdAt) > s.ttl {
+			delete(s.entries, id)
+		}
+	}
+}
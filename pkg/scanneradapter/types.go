@@ -0,0 +1,108 @@
+// Package scanneradapter exposes the configured scanner backend as a
+// Harbor-compatible "Pluggable Scanner" (https://github.com/goharbor/pluggable-scanner-spec),
+// so Harbor (or any other consumer speaking that contract) can register
+// this webhook as a vulnerability scanner rather than only receiving
+// webhooks from it.
+package scanneradapter
+
+import "time"
+
+const (
+	// MimeTypeOCIManifest and MimeTypeDockerManifest are the artifact
+	// manifest types this adapter accepts for scanning.
+	MimeTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	MimeTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// MimeTypeHarborReport and MimeTypeGenericReport are the report
+	// formats this adapter can produce.
+	MimeTypeHarborReport  = "application/vnd.security.vulnerability.report; version=1.1"
+	MimeTypeGenericReport = "application/vnd.scanner.adapter.vuln.report.harbor+json; version=1.0"
+)
+
+// Metadata describes this adapter's capabilities, returned from
+// GET /api/v1/metadata.
+type Metadata struct {
+	Scanner      ScannerInfo       `json:"scanner"`
+	Capabilities []Capability      `json:"capabilities"`
+	Properties   map[string]string `json:"properties,omitempty"`
+}
+
+// ScannerInfo identifies the underlying scanner backend to Harbor.
+type ScannerInfo struct {
+	Name    string `json:"name"`
+	Vendor  string `json:"vendor"`
+	Version string `json:"version"`
+}
+
+// Capability advertises one consumable artifact format and the report
+// formats the adapter can produce for it.
+type Capability struct {
+	ConsumesMimeTypes []string `json:"consumes_mime_types"`
+	ProducesMimeTypes []string `json:"produces_mime_types"`
+}
+
+// ScanRequest is the body of POST /api/v1/scan.
+type ScanRequest struct {
+	Registry RegistryInfo `json:"registry"`
+	Artifact Artifact     `json:"artifact"`
+}
+
+// RegistryInfo identifies the registry Harbor wants the artifact pulled
+// from, along with a short-lived credential scoped to that pull.
+type RegistryInfo struct {
+	URL           string `json:"url"`
+	Authorization string `json:"authorization,omitempty"`
+}
+
+// Artifact identifies the image Harbor wants scanned.
+type Artifact struct {
+	Repository string `json:"repository"`
+	Digest     string `json:"digest"`
+	Tag        string `json:"tag,omitempty"`
+	MimeType   string `json:"mime_type"`
+}
+
+// ScanResponse is returned from POST /api/v1/scan.
+type ScanResponse struct {
+	ID string `json:"id"`
+}
+
+// VulnerabilityReport is the Harbor native vulnerability report format
+// (application/vnd.security.vulnerability.report; version=1.1) returned
+// from GET /api/v1/scan/{id}/report.
+type VulnerabilityReport struct {
+	GeneratedAt     time.Time       `json:"generated_at"`
+	Scanner         ScannerInfo     `json:"scanner"`
+	Severity        string          `json:"severity"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Vulnerability is a single finding within a VulnerabilityReport.
+type Vulnerability struct {
+	ID          string   `json:"id"`
+	Package     string   `json:"package"`
+	Version     string   `json:"version"`
+	FixVersion  string   `json:"fix_version,omitempty"`
+	Severity    string   `json:"severity"`
+	Description string   `json:"description,omitempty"`
+	Links       []string `json:"links,omitempty"`
+	CVSS        *CVSS    `json:"preferred_cvss,omitempty"`
+}
+
+// CVSS carries the CVSS score/vector for a vulnerability, when the
+// underlying scanner reported one.
+type CVSS struct {
+	ScoreV3  float64 `json:"score_v3,omitempty"`
+	VectorV3 string  `json:"vector_v3,omitempty"`
+}
+
+// severityRank orders Harbor's severity vocabulary from least to most
+// severe, used to compute a report's overall Severity from its findings.
+var severityRank = map[string]int{
+	"Unknown":  0,
+	"Negligible": 1,
+	"Low":      2,
+	"Medium":   3,
+	"High":     4,
+	"Critical": 5,
+}
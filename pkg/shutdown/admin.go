@@ -0,0 +1,145 @@
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AdminServer exposes a small token-gated HTTP API that lets operators
+// introspect and steer the shutdown subsystem at runtime: checking handler
+// progress, triggering a shutdown early, or draining the webhook server
+// ahead of a deploy without killing the process. It listens separately from
+// the webhook server so it stays reachable even once the webhook listener
+// has stopped accepting connections.
+type AdminServer struct {
+	manager    *Manager
+	token      string
+	httpServer *http.Server
+	logger     *logrus.Logger
+}
+
+// NewAdminServer creates an admin API server bound to addr. Requests must
+// carry the configured token as a "Bearer <token>" Authorization header.
+func NewAdminServer(addr, token string, manager *Manager, logger *logrus.Logger) *AdminServer {
+	as := &AdminServer{
+		manager: manager,
+		token:   token,
+		logger:  logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/shutdown/status", as.handleStatus)
+	mux.HandleFunc("/admin/shutdown/trigger", as.handleTrigger)
+	mux.HandleFunc("/admin/handlers", as.handleHandlers)
+	mux.HandleFunc("/admin/drain", as.handleDrain)
+
+	as.httpServer = &http.Server{
+		Addr:    addr,
+		Handler: as.authMiddleware(mux),
+	}
+
+	return as
+}
+
+// Start starts the admin HTTP server and blocks until it stops.
+func (as *AdminServer) Start() error {
+	as.logger.WithField("addr", as.httpServer.Addr).Info("Starting admin API server")
+
+	if err := as.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("admin server error: %w", err)
+	}
+	return nil
+}
+
+// Shutdown stops the admin HTTP server.
+func (as *AdminServer) Shutdown(ctx context.Context) error {
+	if err := as.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("admin server shutdown error: %w", err)
+	}
+	return nil
+}
+
+// authMiddleware rejects requests that don't carry the configured bearer
+// token.
+func (as *AdminServer) authMiddleware(next http.Handler) http.Handler {
+	expected := fmt.Sprintf("Bearer %s", as.token)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if as.token == "" || r.Header.Get("Authorization") != expected {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// shutdownStatusResponse is the payload for GET /admin/shutdown/status.
+type shutdownStatusResponse struct {
+	IsShuttingDown bool           `json:"isShuttingDown"`
+	Phase          int            `json:"phase"`
+	Handlers       []HandlerStats `json:"handlers"`
+}
+
+func (as *AdminServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, shutdownStatusResponse{
+		IsShuttingDown: as.manager.IsShuttingDown(),
+		Phase:          as.manager.CurrentPhase(),
+		Handlers:       as.manager.HandlerStats(),
+	})
+}
+
+func (as *AdminServer) handleHandlers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string][]HandlerStats{"handlers": as.manager.HandlerStats()})
+}
+
+// triggerRequest is the optional body for POST /admin/shutdown/trigger.
+type triggerRequest struct {
+	Reason         string `json:"reason"`
+	TimeoutSeconds int    `json:"timeoutSeconds"`
+}
+
+func (as *AdminServer) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req triggerRequest
+	if r.Body != nil {
+		// The body is optional; a missing or empty one just means "use the
+		// configured timeout and no reason".
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+
+	if req.TimeoutSeconds > 0 {
+		as.manager.SetSoftTimeout(time.Duration(req.TimeoutSeconds) * time.Second)
+	}
+
+	as.logger.WithField("reason", req.Reason).Warn("Shutdown triggered via admin API")
+	as.manager.TriggerShutdown()
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "shutdown triggered"})
+}
+
+func (as *AdminServer) handleDrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	as.logger.Warn("Drain requested via admin API")
+	as.manager.Drain()
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "draining"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
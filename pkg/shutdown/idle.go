@@ -0,0 +1,160 @@
+package shutdown
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+)
+
+// defaultIdleGracePeriod debounces the idle check against Keep-Alive churn:
+// a connection count that briefly drops to zero between requests shouldn't
+// be mistaken for true idleness.
+const defaultIdleGracePeriod = 100 * time.Millisecond
+
+// idlePollInterval controls how often WaitIdle re-checks tracker state
+// while waiting for connections to drain.
+const idlePollInterval = 10 * time.Millisecond
+
+// IdleTracker counts active HTTP connections (via the http.Server's
+// ConnState hook) and in-flight webhook requests (via Middleware), and
+// reports when the server has gone idle so shutdown can complete without
+// waiting out its full timeout.
+type IdleTracker struct {
+	mu             sync.Mutex
+	activeConns    int
+	activeRequests int
+	activeScans    int
+	gracePeriod    time.Duration
+	idleSince      time.Time
+}
+
+// NewIdleTracker creates an IdleTracker with the given debounce grace
+// period. A non-positive gracePeriod falls back to defaultIdleGracePeriod.
+func NewIdleTracker(gracePeriod time.Duration) *IdleTracker {
+	if gracePeriod <= 0 {
+		gracePeriod = defaultIdleGracePeriod
+	}
+	return &IdleTracker{
+		gracePeriod: gracePeriod,
+		idleSince:   time.Now(),
+	}
+}
+
+// ConnState is installed as the http.Server's ConnState hook to track
+// connection lifecycle transitions.
+func (t *IdleTracker) ConnState(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch state {
+	case http.StateNew:
+		t.activeConns++
+	case http.StateClosed, http.StateHijacked:
+		if t.activeConns > 0 {
+			t.activeConns--
+		}
+	}
+
+	t.publishLocked()
+}
+
+// Middleware wraps an http.Handler, tracking the handler's execution as an
+// in-flight webhook request for the duration of the call.
+func (t *IdleTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.mu.Lock()
+		t.activeRequests++
+		t.publishLocked()
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			t.activeRequests--
+			t.publishLocked()
+			t.mu.Unlock()
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RegisterScan marks a scan dispatched from a webhook handler as
+// in-flight. Unlike Middleware, the matching Done() call is not tied to
+// the HTTP handler returning, since handlers may hand a scan off to run
+// after the response has already been written; this lets WaitIdle keep
+// waiting until that scan actually finishes.
+func (t *IdleTracker) RegisterScan() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.activeScans++
+	t.publishLocked()
+}
+
+// Done marks a scan registered with RegisterScan as finished.
+func (t *IdleTracker) Done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.activeScans > 0 {
+		t.activeScans--
+	}
+	t.publishLocked()
+}
+
+// publishLocked updates idleSince and the metrics gauges. Callers must hold
+// t.mu.
+func (t *IdleTracker) publishLocked() {
+	if t.activeConns == 0 && t.activeRequests == 0 && t.activeScans == 0 {
+		t.idleSince = time.Now()
+	}
+
+	metrics.SetActiveConnections(t.activeConns)
+	metrics.SetActiveScans(t.activeScans)
+	metrics.SetIdleSince(t.idleSince)
+}
+
+// isIdle reports whether there are currently no active connections,
+// in-flight requests, or in-flight scans.
+func (t *IdleTracker) isIdle() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.activeConns == 0 && t.activeRequests == 0 && t.activeScans == 0
+}
+
+// WaitIdle blocks until the tracker has reported zero active connections,
+// zero in-flight requests and zero in-flight scans continuously for the
+// configured grace period, or until ctx is done. Callers should close the
+// listener before calling WaitIdle so no new connections can arrive.
+func (t *IdleTracker) WaitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(idlePollInterval)
+	defer ticker.Stop()
+
+	for {
+		if t.isIdle() {
+			select {
+			case <-time.After(t.gracePeriod):
+				// publishLocked moves idleSince forward on every change
+				// that leaves the counts at zero, so a connection or
+				// scan that came and went during the wait restarts it.
+				t.mu.Lock()
+				quiet := t.activeConns == 0 && t.activeRequests == 0 && t.activeScans == 0 &&
+					time.Since(t.idleSince) >= t.gracePeriod
+				t.mu.Unlock()
+				if quiet {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -3,8 +3,10 @@ package shutdown
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
+	"sort"
 	"sync"
 	"syscall"
 	"time"
@@ -12,45 +14,149 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// defaultHammerTimeout bounds the hard "hammer" phase that runs if the soft
+// phase fails to drain in-flight work within SoftTimeout.
+const defaultHammerTimeout = 10 * time.Second
+
+// Phases for the soft shutdown sequence, in the order components should
+// shut down: stop accepting new connections first, drain what's already
+// in-flight, then unwind further down the stack. Components that don't fit
+// one of these may define their own phase numbers between them.
+const (
+	PhaseListeners        = 0
+	PhaseHTTPDrain        = 10
+	PhaseWorkerPool       = 20
+	PhaseQueuePersistence = 30
+	PhaseExternalClients  = 40
+	PhaseLoggerFlush      = 50
+)
+
 // Manager handles graceful shutdown coordination
 type Manager struct {
-	logger       *logrus.Logger
-	shutdownChan chan os.Signal
-	handlers     []ShutdownHandler
-	timeout      time.Duration
-	mu           sync.Mutex
+	logger         *logrus.Logger
+	shutdownChan   chan os.Signal
+	handlers       []phasedHandler
+	hammerHandlers []namedHammerHandler
+	timeout        time.Duration
+	softTimeout    time.Duration
+	hammerTimeout  time.Duration
+	mu             sync.Mutex
 	isShuttingDown bool
+	currentPhase   int
+	listeners      map[string]net.Listener
+	handlerStats   map[string]*HandlerStats
+	drainFunc      func()
+	draining       bool
+}
+
+// HandlerStats records the outcome of a registered shutdown handler's most
+// recent run, so the admin API can surface handler progress both during an
+// in-progress shutdown and after a completed one.
+type HandlerStats struct {
+	Name       string `json:"name"`
+	Phase      int    `json:"phase"`
+	State      string `json:"state"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
 }
 
-// ShutdownHandler is a function that performs cleanup during shutdown
+// Handler states surfaced via HandlerStats.State.
+const (
+	handlerStatePending = "pending"
+	handlerStateRunning = "running"
+	handlerStateSuccess = "success"
+	handlerStateFailed  = "failed"
+)
+
+// phasedHandler pairs a wrapped ShutdownHandler with the phase it belongs
+// to, so Shutdown() can group and order them.
+type phasedHandler struct {
+	name    string
+	phase   int
+	handler ShutdownHandler
+}
+
+// ShutdownHandler is a function that performs cleanup during the soft
+// shutdown phase. It receives a cooperative context and is expected to
+// drain in-flight work (e.g. finish scans already in progress) before
+// SoftTimeout elapses.
 type ShutdownHandler func(ctx context.Context) error
 
-// NewManager creates a new shutdown manager
+// HammerHandler is a function that forcibly aborts remaining work during
+// the hard "hammer" phase, once the soft phase has timed out. Unlike
+// ShutdownHandler, it must not wait for work to finish naturally: it should
+// kill idle HTTP connections, cancel outstanding Sysdig API calls, and drop
+// queued items.
+type HammerHandler func(ctx context.Context) error
+
+type namedHammerHandler struct {
+	name    string
+	handler HammerHandler
+}
+
+// NewManager creates a new shutdown manager. timeout is used as both the
+// soft-phase timeout and the legacy single-timeout value; use
+// SetHammerTimeout to configure the hammer phase (defaults to 10s).
 func NewManager(timeout time.Duration, logger *logrus.Logger) *Manager {
 	return &Manager{
-		logger:       logger,
-		shutdownChan: make(chan os.Signal, 1),
-		handlers:     make([]ShutdownHandler, 0),
-		timeout:      timeout,
+		logger:         logger,
+		shutdownChan:   make(chan os.Signal, 1),
+		handlers:       make([]phasedHandler, 0),
+		timeout:        timeout,
+		softTimeout:    timeout,
+		hammerTimeout:  defaultHammerTimeout,
 		isShuttingDown: false,
 	}
 }
 
-// RegisterHandler adds a shutdown handler to be called during shutdown
+// SetSoftTimeout overrides the cooperative-drain phase timeout.
+func (m *Manager) SetSoftTimeout(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.softTimeout = d
+}
+
+// SetHammerTimeout overrides the forced-abort phase timeout.
+func (m *Manager) SetHammerTimeout(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hammerTimeout = d
+}
+
+// RegisterHandler adds a shutdown handler to be called during shutdown.
+// Equivalent to RegisterHandlerWithPhase(name, PhaseWorkerPool, handler) for
+// backward compatibility with callers that don't care about ordering.
 func (m *Manager) RegisterHandler(name string, handler ShutdownHandler) {
+	m.RegisterHandlerWithPhase(name, PhaseWorkerPool, handler)
+}
+
+// RegisterHandlerWithPhase adds a shutdown handler to the given phase.
+// Handlers registered in the same phase run concurrently; Shutdown() does
+// not start phase N+1 until every handler in phase N has returned or that
+// phase's budget has expired. Lower phase numbers run first.
+func (m *Manager) RegisterHandlerWithPhase(name string, phase int, handler ShutdownHandler) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.handlerStats == nil {
+		m.handlerStats = make(map[string]*HandlerStats)
+	}
+	m.handlerStats[name] = &HandlerStats{Name: name, Phase: phase, State: handlerStatePending}
+
 	wrappedHandler := func(ctx context.Context) error {
-		m.logger.WithField("handler", name).Info("Executing shutdown handler")
+		m.logger.WithFields(logrus.Fields{"handler": name, "phase": phase}).Info("Executing shutdown handler")
 		start := time.Now()
+		m.setHandlerState(name, handlerStateRunning, 0, nil)
 
 		err := handler(ctx)
 
 		duration := time.Since(start)
+		m.setHandlerState(name, handlerOutcomeState(err), duration.Milliseconds(), err)
+
 		if err != nil {
 			m.logger.WithFields(logrus.Fields{
 				"handler":  name,
+				"phase":    phase,
 				"duration": duration.Seconds(),
 				"error":    err.Error(),
 			}).Error("Shutdown handler failed")
@@ -59,12 +165,103 @@ func (m *Manager) RegisterHandler(name string, handler ShutdownHandler) {
 
 		m.logger.WithFields(logrus.Fields{
 			"handler":  name,
+			"phase":    phase,
 			"duration": duration.Seconds(),
 		}).Info("Shutdown handler completed")
 		return nil
 	}
 
-	m.handlers = append(m.handlers, wrappedHandler)
+	m.handlers = append(m.handlers, phasedHandler{name: name, phase: phase, handler: wrappedHandler})
+}
+
+// handlerOutcomeState maps a handler's returned error to the HandlerStats
+// state it should record.
+func handlerOutcomeState(err error) string {
+	if err != nil {
+		return handlerStateFailed
+	}
+	return handlerStateSuccess
+}
+
+// setHandlerState updates the recorded stats for a single handler. Callers
+// must not hold m.mu.
+func (m *Manager) setHandlerState(name, state string, durationMs int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.handlerStats[name]
+	if !ok {
+		return
+	}
+	stats.State = state
+	if durationMs > 0 {
+		stats.DurationMs = durationMs
+	}
+	if err != nil {
+		stats.Error = err.Error()
+	}
+}
+
+// HandlerStats returns a snapshot of every registered handler's last-run
+// state, for introspection via the admin API.
+func (m *Manager) HandlerStats() []HandlerStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]HandlerStats, 0, len(m.handlerStats))
+	for _, h := range m.handlers {
+		if stats, ok := m.handlerStats[h.name]; ok {
+			out = append(out, *stats)
+		}
+	}
+	return out
+}
+
+// CurrentPhase returns the phase currently running, or the last phase run
+// if shutdown has completed. Meaningful only once Shutdown() has started.
+func (m *Manager) CurrentPhase() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.currentPhase
+}
+
+// SetDrainFunc sets the function invoked by Drain() to stop accepting new
+// webhooks without shutting the process down, e.g. for pre-deploy drains
+// triggered via the admin API.
+func (m *Manager) SetDrainFunc(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drainFunc = fn
+}
+
+// Drain stops the webhook server from accepting new requests without
+// initiating the rest of the shutdown sequence. It is safe to call even if
+// a full Shutdown() follows later.
+func (m *Manager) Drain() {
+	m.mu.Lock()
+	fn := m.drainFunc
+	m.draining = true
+	m.mu.Unlock()
+
+	if fn != nil {
+		fn()
+	}
+}
+
+// IsDraining reports whether Drain() has been called.
+func (m *Manager) IsDraining() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.draining
+}
+
+// RegisterHammerHandler adds a hammer-phase handler, invoked only if the
+// soft phase fails to complete within SoftTimeout.
+func (m *Manager) RegisterHammerHandler(name string, handler HammerHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hammerHandlers = append(m.hammerHandlers, namedHammerHandler{name: name, handler: handler})
 }
 
 // WaitForShutdown blocks until a shutdown signal is received
@@ -82,7 +279,10 @@ func (m *Manager) WaitForShutdown() {
 	m.Shutdown()
 }
 
-// Shutdown executes all registered shutdown handlers
+// Shutdown runs the two-phase shutdown sequence: a soft phase where
+// registered ShutdownHandlers cooperatively drain in-flight work against
+// SoftTimeout, followed by a hard "hammer" phase where HammerHandlers
+// forcibly abort whatever didn't finish in time.
 func (m *Manager) Shutdown() {
 	m.mu.Lock()
 	if m.isShuttingDown {
@@ -92,32 +292,90 @@ func (m *Manager) Shutdown() {
 	m.isShuttingDown = true
 	m.mu.Unlock()
 
-	m.logger.Info("Starting graceful shutdown")
+	m.logger.Info("Starting graceful shutdown (soft phase)")
 	start := time.Now()
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
-	defer cancel()
+	if m.runSoftPhase(start) {
+		return
+	}
+
+	m.logger.WithField("soft_timeout", m.softTimeout.Seconds()).
+		Warn("Soft shutdown phase timed out, entering hammer phase")
+	m.runHammerPhase()
+}
+
+// runSoftPhase groups registered handlers by phase and runs each phase in
+// ascending order, carving an equal budget out of SoftTimeout for each
+// distinct phase. Handlers within a phase run concurrently; a phase that
+// exceeds its budget is abandoned and the next phase starts regardless.
+// Returns true if the overall SoftTimeout was not exceeded.
+func (m *Manager) runSoftPhase(start time.Time) bool {
+	overallCtx, overallCancel := context.WithTimeout(context.Background(), m.softTimeout)
+	defer overallCancel()
+
+	phases := groupByPhase(m.handlers)
+	if len(phases) == 0 {
+		return true
+	}
+
+	budget := m.softTimeout / time.Duration(len(phases))
+	errorCount := 0
+
+	for _, phase := range phases {
+		deadline := budget
+		if remaining := time.Until(start.Add(m.softTimeout)); remaining < deadline {
+			deadline = remaining
+		}
 
-	// Execute all handlers
+		m.mu.Lock()
+		m.currentPhase = phase[0].phase
+		m.mu.Unlock()
+
+		phaseCtx, phaseCancel := context.WithTimeout(overallCtx, deadline)
+		errs := m.runPhase(phaseCtx, phase)
+		phaseCancel()
+		errorCount += errs
+
+		if overallCtx.Err() != nil {
+			return false
+		}
+	}
+
+	duration := time.Since(start)
+	if errorCount > 0 {
+		m.logger.WithFields(logrus.Fields{
+			"duration": duration.Seconds(),
+			"errors":   errorCount,
+		}).Warn("Soft shutdown phase completed with errors")
+	} else {
+		m.logger.WithFields(logrus.Fields{
+			"duration": duration.Seconds(),
+		}).Info("Soft shutdown phase completed successfully")
+	}
+	return true
+}
+
+// runPhase runs every handler belonging to a single phase concurrently and
+// waits for them to finish or for ctx to expire, whichever comes first.
+// Returns the number of handlers that returned an error.
+func (m *Manager) runPhase(ctx context.Context, phase []phasedHandler) int {
 	var wg sync.WaitGroup
-	errors := make([]error, 0)
-	errorsMu := sync.Mutex{}
+	errorCount := 0
+	var mu sync.Mutex
 
-	for _, handler := range m.handlers {
+	for _, ph := range phase {
 		wg.Add(1)
 		go func(h ShutdownHandler) {
 			defer wg.Done()
 
 			if err := h(ctx); err != nil {
-				errorsMu.Lock()
-				errors = append(errors, err)
-				errorsMu.Unlock()
+				mu.Lock()
+				errorCount++
+				mu.Unlock()
 			}
-		}(handler)
+		}(ph.handler)
 	}
 
-	// Wait for all handlers to complete or timeout
 	done := make(chan struct{})
 	go func() {
 		wg.Wait()
@@ -126,21 +384,81 @@ func (m *Manager) Shutdown() {
 
 	select {
 	case <-done:
-		duration := time.Since(start)
-		if len(errors) > 0 {
-			m.logger.WithFields(logrus.Fields{
-				"duration": duration.Seconds(),
-				"errors":   len(errors),
-			}).Warn("Shutdown completed with errors")
-		} else {
-			m.logger.WithFields(logrus.Fields{
-				"duration": duration.Seconds(),
-			}).Info("Shutdown completed successfully")
-		}
 	case <-ctx.Done():
-		m.logger.WithFields(logrus.Fields{
-			"timeout": m.timeout.Seconds(),
-		}).Error("Shutdown timeout exceeded")
+		m.logger.WithField("phase", phase[0].phase).Warn("Shutdown phase budget exceeded, moving to next phase")
+	}
+
+	return errorCount
+}
+
+// groupByPhase buckets handlers by phase number and returns the buckets
+// ordered by ascending phase.
+func groupByPhase(handlers []phasedHandler) [][]phasedHandler {
+	byPhase := make(map[int][]phasedHandler)
+	for _, h := range handlers {
+		byPhase[h.phase] = append(byPhase[h.phase], h)
+	}
+
+	phaseNumbers := make([]int, 0, len(byPhase))
+	for phase := range byPhase {
+		phaseNumbers = append(phaseNumbers, phase)
+	}
+	sort.Ints(phaseNumbers)
+
+	phases := make([][]phasedHandler, 0, len(phaseNumbers))
+	for _, phase := range phaseNumbers {
+		phases = append(phases, byPhase[phase])
+	}
+	return phases
+}
+
+// runHammerPhase forcibly aborts remaining work via the registered
+// HammerHandlers, bounded by HammerTimeout. Each handler that ran is logged
+// so the post-mortem can identify what needed hammering.
+func (m *Manager) runHammerPhase() {
+	if len(m.hammerHandlers) == 0 {
+		m.logger.Warn("No hammer handlers registered; remaining work will be abandoned as-is")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.hammerTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	hammered := make([]string, 0, len(m.hammerHandlers))
+
+	for _, nh := range m.hammerHandlers {
+		wg.Add(1)
+		go func(nh namedHammerHandler) {
+			defer wg.Done()
+
+			if err := nh.handler(ctx); err != nil {
+				m.logger.WithFields(logrus.Fields{
+					"handler": nh.name,
+					"error":   err.Error(),
+				}).Error("Hammer handler failed")
+			}
+
+			mu.Lock()
+			hammered = append(hammered, nh.name)
+			mu.Unlock()
+		}(nh)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.logger.WithField("hammered_handlers", hammered).
+			Warn("Hammer phase completed: forcibly aborted remaining work")
+	case <-ctx.Done():
+		m.logger.WithField("hammer_timeout", m.hammerTimeout.Seconds()).
+			Error("Hammer phase timeout exceeded; process is exiting with work potentially incomplete")
 	}
 }
 
@@ -151,6 +469,34 @@ func (m *Manager) IsShuttingDown() bool {
 	return m.isShuttingDown
 }
 
+// RegisterListener keeps a reference to a net.Listener (the webhook HTTP
+// server, an admin/health server, etc.) so it can be handed off to a
+// freshly exec'd child process during a graceful restart instead of being
+// closed outright. See the restart package for the SIGHUP handoff logic.
+func (m *Manager) RegisterListener(name string, ln net.Listener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.listeners == nil {
+		m.listeners = make(map[string]net.Listener)
+	}
+	m.listeners[name] = ln
+
+	m.logger.WithField("listener", name).Debug("Listener registered for graceful restart handoff")
+}
+
+// Listeners returns a snapshot of all registered listeners, keyed by name.
+func (m *Manager) Listeners() map[string]net.Listener {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]net.Listener, len(m.listeners))
+	for name, ln := range m.listeners {
+		out[name] = ln
+	}
+	return out
+}
+
 // TriggerShutdown manually triggers a shutdown (for testing or programmatic shutdown)
 func (m *Manager) TriggerShutdown() {
 	m.shutdownChan <- syscall.SIGTERM
@@ -160,8 +506,10 @@ func (m *Manager) TriggerShutdown() {
 type ShutdownCoordinator struct {
 	stopAcceptingRequests func()
 	stopWorkerPool        func(context.Context) error
+	hammerWorkerPool      func(context.Context) error
 	closeQueue            func()
 	cleanupResources      func()
+	idleTracker           *IdleTracker
 	logger                *logrus.Logger
 }
 
@@ -182,6 +530,20 @@ func (sc *ShutdownCoordinator) SetStopWorkerPool(fn func(context.Context) error)
 	sc.stopWorkerPool = fn
 }
 
+// SetHammerWorkerPool sets the function that forcibly aborts the worker
+// pool's in-flight scans during the hammer phase (e.g. canceling
+// outstanding Sysdig API calls rather than waiting for them to finish).
+func (sc *ShutdownCoordinator) SetHammerWorkerPool(fn func(context.Context) error) {
+	sc.hammerWorkerPool = fn
+}
+
+// SetIdleTracker sets the IdleTracker used to short-circuit the wait for
+// in-flight work once active connections and requests have drained to
+// zero, rather than always waiting out the full timeout.
+func (sc *ShutdownCoordinator) SetIdleTracker(tracker *IdleTracker) {
+	sc.idleTracker = tracker
+}
+
 // SetCloseQueue sets the function to close the queue
 func (sc *ShutdownCoordinator) SetCloseQueue(fn func()) {
 	sc.closeQueue = fn
@@ -202,6 +564,18 @@ func (sc *ShutdownCoordinator) ExecuteShutdown(ctx context.Context) error {
 		sc.stopAcceptingRequests()
 	}
 
+	// Step 1.5: wait for active connections and in-flight requests to
+	// drain. If the server is already idle this returns immediately
+	// instead of waiting out the full shutdown timeout.
+	if sc.idleTracker != nil {
+		sc.logger.Info("Waiting for active connections to drain")
+		if err := sc.idleTracker.WaitIdle(ctx); err != nil {
+			sc.logger.WithError(err).Warn("Connections did not drain before deadline, proceeding anyway")
+		} else {
+			sc.logger.Info("Server idle, proceeding immediately")
+		}
+	}
+
 	// Step 2: Wait for worker pool to finish in-flight scans
 	if sc.stopWorkerPool != nil {
 		sc.logger.Info("Waiting for in-flight scans to complete")
@@ -226,13 +600,48 @@ func (sc *ShutdownCoordinator) ExecuteShutdown(ctx context.Context) error {
 	return nil
 }
 
-// GracefulShutdownHandler creates a shutdown handler from a coordinator
+// ExecuteHammerShutdown forcibly aborts whatever the soft shutdown phase
+// could not drain in time: outstanding Sysdig API calls, queued work, and
+// any resources still held open.
+func (sc *ShutdownCoordinator) ExecuteHammerShutdown(ctx context.Context) error {
+	sc.logger.Warn("Executing hammer shutdown phase")
+
+	if sc.hammerWorkerPool != nil {
+		sc.logger.Warn("Forcibly aborting in-flight scans")
+		if err := sc.hammerWorkerPool(ctx); err != nil {
+			sc.logger.WithError(err).Warn("Hammer worker pool abort had errors")
+		}
+	}
+
+	if sc.closeQueue != nil {
+		sc.logger.Warn("Dropping queued items")
+		sc.closeQueue()
+	}
+
+	if sc.cleanupResources != nil {
+		sc.cleanupResources()
+	}
+
+	sc.logger.Warn("Hammer shutdown complete")
+	return nil
+}
+
+// GracefulShutdownHandler creates a soft-phase ShutdownHandler from a
+// coordinator
 func GracefulShutdownHandler(coordinator *ShutdownCoordinator) ShutdownHandler {
 	return func(ctx context.Context) error {
 		return coordinator.ExecuteShutdown(ctx)
 	}
 }
 
+// HammerShutdownHandler creates a hammer-phase HammerHandler from a
+// coordinator
+func HammerShutdownHandler(coordinator *ShutdownCoordinator) HammerHandler {
+	return func(ctx context.Context) error {
+		return coordinator.ExecuteHammerShutdown(ctx)
+	}
+}
+
 // WaitWithContext waits for context cancellation or timeout
 func WaitWithContext(ctx context.Context, logger *logrus.Logger) error {
 	<-ctx.Done()
@@ -0,0 +1,258 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+)
+
+// DefaultRetryConfig is used for a sink whose config.RetryConfig is the
+// zero value.
+var DefaultRetryConfig = config.RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: "1s",
+	MaxBackoff:     "30s",
+}
+
+// sinkEntry pairs one ResultSink with the retry/dead-letter behavior its
+// SinkConfig configured.
+type sinkEntry struct {
+	sink           ResultSink
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	deadLetterPath string
+
+	deadLetterMu sync.Mutex
+}
+
+// Dispatcher fans a scan result out to every configured ResultSink
+// concurrently, retrying each sink independently with exponential
+// backoff and, once its retries are exhausted, appending the result to
+// that sink's dead letter file rather than dropping it.
+type Dispatcher struct {
+	entries []*sinkEntry
+	logger  *logrus.Logger
+}
+
+// NewDispatcher builds sinks from cfgs (as produced by a registry's
+// RegistryConfig.Sinks) and returns a Dispatcher fanning out to all of
+// them.
+func NewDispatcher(ctx context.Context, cfgs []config.SinkConfig, logger *logrus.Logger) (*Dispatcher, error) {
+	entries := make([]*sinkEntry, 0, len(cfgs))
+
+	for _, cfg := range cfgs {
+		s, err := buildSink(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build sink %q: %w", cfg.Type, err)
+		}
+
+		entry, err := newSinkEntry(s, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure sink %q: %w", cfg.Type, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return &Dispatcher{entries: entries, logger: logger}, nil
+}
+
+// Close closes every underlying sink that implements io.Closer (FileSink
+// and KafkaSink hold a handle worth releasing; HTTPSink and ObjectSink
+// don't need one). Intended to be wired into a shutdown.Manager cleanup
+// the same way scanner.ResultProcessor.Close is.
+func (d *Dispatcher) Close() error {
+	var errs []error
+	for _, entry := range d.entries {
+		closer, ok := entry.sink.(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.sink.Name(), err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to close %d sink(s): %v", len(errs), errs)
+}
+
+// buildSink constructs the concrete ResultSink cfg describes.
+func buildSink(ctx context.Context, cfg config.SinkConfig) (ResultSink, error) {
+	switch cfg.Type {
+	case config.SinkTypeFile:
+		return NewFileSink(cfg.File.Path)
+	case config.SinkTypeHTTP:
+		timeout, _ := time.ParseDuration(cfg.HTTP.Timeout)
+		return NewHTTPSink(cfg.HTTP.URL, cfg.HTTP.Secret, timeout), nil
+	case config.SinkTypeObject:
+		if cfg.Object.Provider == "gcs" {
+			return NewGCSObjectSink(ctx, cfg.Object.Bucket, cfg.Object.Prefix)
+		}
+		return NewS3ObjectSink(ctx, cfg.Object.Bucket, cfg.Object.Prefix, cfg.Object.Region)
+	case config.SinkTypeKafka:
+		return NewKafkaSink(cfg.Kafka.Brokers, cfg.Kafka.Topic), nil
+	default:
+		return nil, fmt.Errorf("unsupported sink type: %s", cfg.Type)
+	}
+}
+
+// newSinkEntry wraps s with the retry/dead-letter behavior cfg
+// describes, falling back to DefaultRetryConfig for unset fields.
+func newSinkEntry(s ResultSink, cfg config.SinkConfig) (*sinkEntry, error) {
+	retry := cfg.Retry
+	if retry.MaxAttempts == 0 {
+		retry.MaxAttempts = DefaultRetryConfig.MaxAttempts
+	}
+	if retry.InitialBackoff == "" {
+		retry.InitialBackoff = DefaultRetryConfig.InitialBackoff
+	}
+	if retry.MaxBackoff == "" {
+		retry.MaxBackoff = DefaultRetryConfig.MaxBackoff
+	}
+
+	initialBackoff, err := time.ParseDuration(retry.InitialBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid initial_backoff: %w", err)
+	}
+	maxBackoff, err := time.ParseDuration(retry.MaxBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max_backoff: %w", err)
+	}
+
+	return &sinkEntry{
+		sink:           s,
+		maxAttempts:    retry.MaxAttempts,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		deadLetterPath: cfg.DeadLetterPath,
+	}, nil
+}
+
+// Publish fans result out to every configured sink concurrently and
+// waits for all of them to finish retrying (or dead-lettering) before
+// returning. A sink failing entirely doesn't stop the others. If any
+// sink failed, the returned error counts the failures and lists every
+// failed sink's final error as text (it does not wrap them, so
+// errors.Is and errors.As don't see them).
+func (d *Dispatcher) Publish(ctx context.Context, result *models.ScanResult) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(d.entries))
+
+	for i, entry := range d.entries {
+		wg.Add(1)
+		go func(i int, entry *sinkEntry) {
+			defer wg.Done()
+			errs[i] = d.publishToSink(ctx, entry, result)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d sinks failed: %v", len(failed), len(d.entries), failed)
+}
+
+// publishToSink retries entry.sink.Publish with exponential backoff up
+// to entry.maxAttempts times, dead-lettering result if every attempt
+// fails.
+func (d *Dispatcher) publishToSink(ctx context.Context, entry *sinkEntry, result *models.ScanResult) error {
+	start := time.Now()
+	name := entry.sink.Name()
+
+	backoff := entry.initialBackoff
+	var lastErr error
+
+	for attempt := 1; attempt <= entry.maxAttempts; attempt++ {
+		lastErr = entry.sink.Publish(ctx, result)
+		if lastErr == nil {
+			metrics.RecordSinkPublish(name, "success", time.Since(start).Seconds())
+			return nil
+		}
+
+		if attempt == entry.maxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			lastErr = ctx.Err()
+			attempt = entry.maxAttempts
+		}
+
+		backoff *= 2
+		if backoff > entry.maxBackoff {
+			backoff = entry.maxBackoff
+		}
+	}
+
+	metrics.RecordSinkPublish(name, "failed", time.Since(start).Seconds())
+
+	if entry.deadLetterPath == "" {
+		return fmt.Errorf("sink %s: %w", name, lastErr)
+	}
+
+	if dlErr := entry.writeDeadLetter(result, lastErr); dlErr != nil {
+		d.logger.WithFields(logrus.Fields{
+			"sink":  name,
+			"error": dlErr.Error(),
+		}).Error("Failed to write sink dead letter entry")
+		return fmt.Errorf("sink %s: %w (dead letter also failed: %v)", name, lastErr, dlErr)
+	}
+
+	metrics.RecordSinkPublish(name, "dead_letter", time.Since(start).Seconds())
+	return fmt.Errorf("sink %s: %w (dead-lettered to %s)", name, lastErr, entry.deadLetterPath)
+}
+
+// deadLetterEntry is one line appended to a sink's dead letter file.
+type deadLetterEntry struct {
+	Result   *models.ScanResult `json:"result"`
+	Error    string             `json:"error"`
+	FailedAt time.Time          `json:"failed_at"`
+}
+
+// writeDeadLetter appends result and the error that doomed it to
+// entry.deadLetterPath as a single JSON line.
+func (entry *sinkEntry) writeDeadLetter(result *models.ScanResult, cause error) error {
+	entry.deadLetterMu.Lock()
+	defer entry.deadLetterMu.Unlock()
+
+	f, err := os.OpenFile(entry.deadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file %q: %w", entry.deadLetterPath, err)
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(deadLetterEntry{
+		Result:   result,
+		Error:    cause.Error(),
+		FailedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	_, err = f.Write(line)
+	return err
+}
@@ -0,0 +1,133 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// fakeSink is a ResultSink whose Publish outcome and call count a test
+// controls directly, used in place of a real FileSink/HTTPSink/etc. to
+// exercise Dispatcher's retry and dead-letter behavior in isolation.
+type fakeSink struct {
+	name     string
+	fail     int32 // number of leading calls that fail
+	attempts int32
+}
+
+func (f *fakeSink) Name() string { return f.name }
+
+func (f *fakeSink) Publish(_ context.Context, _ *models.ScanResult) error {
+	n := atomic.AddInt32(&f.attempts, 1)
+	if n <= f.fail {
+		return errors.New("simulated publish failure")
+	}
+	return nil
+}
+
+func discardLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(logrus.PanicLevel)
+	return logger
+}
+
+func TestDispatcher_PublishRetriesThenSucceeds(t *testing.T) {
+	entry := &sinkEntry{
+		sink:           &fakeSink{name: "fake", fail: 2},
+		maxAttempts:    3,
+		initialBackoff: time.Millisecond,
+		maxBackoff:     10 * time.Millisecond,
+	}
+	d := &Dispatcher{entries: []*sinkEntry{entry}, logger: discardLogger()}
+
+	err := d.Publish(context.Background(), &models.ScanResult{ImageRef: "nginx:latest"})
+	if err != nil {
+		t.Fatalf("Publish() error = %v, want nil after retries succeed", err)
+	}
+	if got := entry.sink.(*fakeSink).attempts; got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestDispatcher_PublishDeadLettersOnExhaustedRetries(t *testing.T) {
+	dir := t.TempDir()
+	dlPath := filepath.Join(dir, "dead-letters.jsonl")
+
+	entry := &sinkEntry{
+		sink:           &fakeSink{name: "fake", fail: 99},
+		maxAttempts:    2,
+		initialBackoff: time.Millisecond,
+		maxBackoff:     time.Millisecond,
+		deadLetterPath: dlPath,
+	}
+	d := &Dispatcher{entries: []*sinkEntry{entry}, logger: discardLogger()}
+
+	result := &models.ScanResult{ImageRef: "nginx:latest", RequestID: "req-1"}
+	err := d.Publish(context.Background(), result)
+	if err == nil {
+		t.Fatal("Publish() error = nil, want error for exhausted retries")
+	}
+
+	data, readErr := os.ReadFile(dlPath)
+	if readErr != nil {
+		t.Fatalf("reading dead letter file: %v", readErr)
+	}
+
+	var dl deadLetterEntry
+	if err := json.Unmarshal(data, &dl); err != nil {
+		t.Fatalf("unmarshal dead letter entry: %v", err)
+	}
+	if dl.Result.RequestID != "req-1" {
+		t.Errorf("dead letter RequestID = %q, want %q", dl.Result.RequestID, "req-1")
+	}
+}
+
+func TestDispatcher_PublishOneSinkFailureDoesNotBlockOthers(t *testing.T) {
+	okEntry := &sinkEntry{
+		sink:           &fakeSink{name: "ok", fail: 0},
+		maxAttempts:    1,
+		initialBackoff: time.Millisecond,
+		maxBackoff:     time.Millisecond,
+	}
+	failEntry := &sinkEntry{
+		sink:           &fakeSink{name: "fail", fail: 99},
+		maxAttempts:    1,
+		initialBackoff: time.Millisecond,
+		maxBackoff:     time.Millisecond,
+	}
+	d := &Dispatcher{entries: []*sinkEntry{okEntry, failEntry}, logger: discardLogger()}
+
+	err := d.Publish(context.Background(), &models.ScanResult{ImageRef: "nginx:latest"})
+	if err == nil {
+		t.Fatal("Publish() error = nil, want error reporting the failing sink")
+	}
+	if got := okEntry.sink.(*fakeSink).attempts; got != 1 {
+		t.Errorf("ok sink attempts = %d, want 1 (should still have been published)", got)
+	}
+}
+
+func TestNewSinkEntry_AppliesDefaultRetryConfig(t *testing.T) {
+	entry, err := newSinkEntry(&fakeSink{name: "fake"}, config.SinkConfig{})
+	if err != nil {
+		t.Fatalf("newSinkEntry() error = %v", err)
+	}
+
+	if entry.maxAttempts != DefaultRetryConfig.MaxAttempts {
+		t.Errorf("maxAttempts = %d, want %d", entry.maxAttempts, DefaultRetryConfig.MaxAttempts)
+	}
+	wantInitial, _ := time.ParseDuration(DefaultRetryConfig.InitialBackoff)
+	if entry.initialBackoff != wantInitial {
+		t.Errorf("initialBackoff = %v, want %v", entry.initialBackoff, wantInitial)
+	}
+}
@@ -0,0 +1,63 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// FileSink appends one JSON line per published scan result to a local
+// file, opening it once and reusing the handle for the sink's lifetime.
+type FileSink struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink creates a FileSink appending to path, creating it (and any
+// missing parent directories are the caller's responsibility) if it
+// doesn't already exist.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sink file %q: %w", path, err)
+	}
+
+	return &FileSink{path: path, file: f}, nil
+}
+
+// Name identifies this sink for metrics and logging.
+func (s *FileSink) Name() string {
+	return fmt.Sprintf("file:%s", s.path)
+}
+
+// Publish appends result to the sink file as a single JSON line.
+func (s *FileSink) Publish(_ context.Context, result *models.ScanResult) error {
+	line, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan result: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write to sink file %q: %w", s.path, err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
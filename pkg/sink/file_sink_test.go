@@ -0,0 +1,68 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+func TestFileSink_PublishAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+
+	s, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer s.Close()
+
+	results := []*models.ScanResult{
+		{ImageRef: "nginx:latest", RequestID: "req-1"},
+		{ImageRef: "redis:latest", RequestID: "req-2"},
+	}
+	for _, result := range results {
+		if err := s.Publish(context.Background(), result); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening sink file: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != len(results) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(results))
+	}
+
+	var got models.ScanResult
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("unmarshal first line: %v", err)
+	}
+	if got.RequestID != "req-1" {
+		t.Errorf("first line RequestID = %q, want %q", got.RequestID, "req-1")
+	}
+}
+
+func TestFileSink_Name(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	s, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer s.Close()
+
+	if got := s.Name(); got != "file:"+path {
+		t.Errorf("Name() = %q, want %q", got, "file:"+path)
+	}
+}
@@ -0,0 +1,92 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// SignatureHeader is the header HTTPSink sets to "sha256=<hex>" when
+// Secret is configured, in the same format auth.VerifyHMACSignature
+// expects on the inbound side - so a callback endpoint built on this
+// repo's own webhook auth can verify deliveries from it.
+const SignatureHeader = "X-Scan-Result-Signature"
+
+// DefaultHTTPSinkTimeout bounds an HTTPSink created without an explicit
+// timeout.
+const DefaultHTTPSinkTimeout = 10 * time.Second
+
+// HTTPSink POSTs one JSON body per published scan result to a callback
+// URL, HMAC-signing the body when a secret is configured.
+type HTTPSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewHTTPSink creates an HTTPSink posting to url. A zero timeout falls
+// back to DefaultHTTPSinkTimeout. An empty secret disables signing.
+func NewHTTPSink(url, secret string, timeout time.Duration) *HTTPSink {
+	if timeout <= 0 {
+		timeout = DefaultHTTPSinkTimeout
+	}
+
+	return &HTTPSink{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name identifies this sink for metrics and logging.
+func (s *HTTPSink) Name() string {
+	return fmt.Sprintf("http:%s", s.url)
+}
+
+// Publish POSTs result as JSON to the sink's URL, returning an error if
+// the request couldn't be sent or the endpoint returned a non-2xx
+// status.
+func (s *HTTPSink) Publish(ctx context.Context, result *models.ScanResult) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan result: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build sink request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.secret != "" {
+		req.Header.Set(SignatureHeader, signBody(body, s.secret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sink request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sink endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// signBody returns the "sha256=<hex>" HMAC-SHA256 signature of body
+// under secret.
+func signBody(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
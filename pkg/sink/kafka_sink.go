@@ -0,0 +1,61 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// KafkaSink produces one message per published scan result to a Kafka
+// topic, keyed by the lower-cased ImageRef so a topic partitioned by key keeps a given
+// image's results in order.
+type KafkaSink struct {
+	topic  string
+	writer *kafka.Writer
+}
+
+// NewKafkaSink creates a KafkaSink producing to topic on brokers.
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{
+		topic: topic,
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		},
+	}
+}
+
+// Name identifies this sink for metrics and logging.
+func (s *KafkaSink) Name() string {
+	return fmt.Sprintf("kafka:%s", s.topic)
+}
+
+// Publish produces result as a JSON message keyed by the lower-cased
+// ImageRef.
+func (s *KafkaSink) Publish(ctx context.Context, result *models.ScanResult) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan result: %w", err)
+	}
+
+	err = s.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strings.ToLower(result.ImageRef)),
+		Value: body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to produce to kafka topic %q: %w", s.topic, err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying Kafka writer.
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
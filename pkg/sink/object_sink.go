@@ -0,0 +1,125 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// objectUploader is the subset of an object store client ObjectSink
+// needs, so S3 and GCS can share one Publish implementation.
+type objectUploader interface {
+	Upload(ctx context.Context, key string, body []byte) error
+}
+
+// ObjectSink uploads one JSON object per published scan result to an
+// S3- or GCS-style bucket, keyed by a hash of ImageRef and RequestID
+// (see objectKey) so retried webhooks for the same image don't collide.
+type ObjectSink struct {
+	provider string
+	bucket   string
+	prefix   string
+	uploader objectUploader
+}
+
+// NewS3ObjectSink creates an ObjectSink backed by Amazon S3.
+func NewS3ObjectSink(ctx context.Context, bucket, prefix, region string) (*ObjectSink, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &ObjectSink{
+		provider: "s3",
+		bucket:   bucket,
+		prefix:   prefix,
+		uploader: &s3Uploader{client: s3.NewFromConfig(cfg), bucket: bucket},
+	}, nil
+}
+
+// NewGCSObjectSink creates an ObjectSink backed by Google Cloud Storage.
+func NewGCSObjectSink(ctx context.Context, bucket, prefix string) (*ObjectSink, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+
+	return &ObjectSink{
+		provider: "gcs",
+		bucket:   bucket,
+		prefix:   prefix,
+		uploader: &gcsUploader{client: client, bucket: bucket},
+	}, nil
+}
+
+// Name identifies this sink for metrics and logging.
+func (s *ObjectSink) Name() string {
+	return fmt.Sprintf("%s:%s/%s", s.provider, s.bucket, s.prefix)
+}
+
+// Publish uploads result as a JSON object keyed by objectKey.
+func (s *ObjectSink) Publish(ctx context.Context, result *models.ScanResult) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan result: %w", err)
+	}
+
+	key := s.prefix + objectKey(result)
+	if err := s.uploader.Upload(ctx, key, body); err != nil {
+		return fmt.Errorf("failed to upload scan result to %s: %w", s.Name(), err)
+	}
+
+	return nil
+}
+
+// objectKey derives a stable per-result object key. models.ScanResult
+// has no Digest field (see scanner.cacheKeyForResult for the same
+// constraint), so this hashes ImageRef and RequestID together rather
+// than risk two concurrent scans of the same image overwriting one
+// another's report.
+func objectKey(result *models.ScanResult) string {
+	hash := sha256.Sum256([]byte(result.ImageRef + "@" + result.RequestID))
+	return fmt.Sprintf("%x.json", hash[:16])
+}
+
+// s3Uploader adapts the AWS SDK's S3 client to objectUploader.
+type s3Uploader struct {
+	client *s3.Client
+	bucket string
+}
+
+func (u *s3Uploader) Upload(ctx context.Context, key string, body []byte) error {
+	_, err := u.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+// gcsUploader adapts the GCS client to objectUploader.
+type gcsUploader struct {
+	client *storage.Client
+	bucket string
+}
+
+func (u *gcsUploader) Upload(ctx context.Context, key string, body []byte) error {
+	w := u.client.Bucket(u.bucket).Object(key).NewWriter(ctx)
+	w.ContentType = "application/json"
+
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
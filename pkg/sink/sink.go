@@ -0,0 +1,28 @@
+// Package sink publishes completed scan results to downstream
+// integrations: a local JSON-lines file, an HTTP callback, an S3/GCS
+// object store, or a Kafka topic. Config.Registries[].Sinks lists the
+// ResultSink(s) configured per registry; Dispatcher fans a single scan
+// result out to all of them concurrently so one sink's failure or
+// latency doesn't block the others.
+package sink
+
+import (
+	"context"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// ResultSink publishes one completed scan result to a downstream
+// integration. Implementations should treat Publish as best-effort for a
+// single attempt; Dispatcher is responsible for retrying and for
+// recording a failure to the configured dead letter path once retries
+// are exhausted.
+type ResultSink interface {
+	// Publish sends result to the sink's destination. A non-nil error
+	// is retried by Dispatcher according to its RetryConfig.
+	Publish(ctx context.Context, result *models.ScanResult) error
+
+	// Name identifies this sink instance for metrics and logging, e.g.
+	// "file:/var/log/scan-results.jsonl" or "http:https://example.com/hook".
+	Name() string
+}
@@ -0,0 +1,16 @@
+package verify
+
+import "fmt"
+
+// RejectedError indicates that an image failed its registry's
+// VerificationConfig policy: unsigned, signed by an untrusted identity,
+// missing a required annotation, or missing a required SBOM attestation.
+// Unlike a scanner failure, a RejectedError isn't retriable: the image
+// won't become signed by waiting and trying again.
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("image rejected: %s", e.Reason)
+}
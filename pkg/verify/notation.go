@@ -0,0 +1,418 @@
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner/registryauth"
+)
+
+// notationSignatureArtifactType is the artifactType the OCI Distribution
+// referrers API reports for notation's signature manifests.
+const notationSignatureArtifactType = "application/vnd.cncf.notary.signature"
+
+// notationVerifier checks a manifest digest's notation signature: the
+// signing certificate embedded in the JWS envelope must chain to caPool
+// and its subject must match one of trustedIdentities.
+type notationVerifier struct {
+	caPool            *x509.CertPool
+	trustedIdentities []*regexp.Regexp
+}
+
+// newNotationVerifier parses cfg's trust store and identity patterns
+// once at construction time, rather than on every Verify call.
+func newNotationVerifier(cfg config.NotationConfig) (*notationVerifier, error) {
+	if cfg.TrustStore == "" {
+		return nil, fmt.Errorf("notation.trust_store is required")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(cfg.TrustStore)) {
+		return nil, fmt.Errorf("failed to parse notation.trust_store PEM")
+	}
+
+	if len(cfg.TrustedIdentitySubjects) == 0 {
+		return nil, fmt.Errorf("notation.trusted_identity_subjects must list at least one trusted signer")
+	}
+
+	identities := make([]*regexp.Regexp, 0, len(cfg.TrustedIdentitySubjects))
+	for _, pattern := range cfg.TrustedIdentitySubjects {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notation.trusted_identity_subjects entry %q: %w", pattern, err)
+		}
+		identities = append(identities, re)
+	}
+
+	return &notationVerifier{caPool: pool, trustedIdentities: identities}, nil
+}
+
+// referrersResponse is the OCI Distribution Spec's referrers API list
+// response.
+type referrersResponse struct {
+	Manifests []struct {
+		Digest       string `json:"digest"`
+		ArtifactType string `json:"artifactType"`
+	} `json:"manifests"`
+}
+
+// ociManifest is the subset of a signature manifest this package reads:
+// just enough to find the JWS envelope blob.
+type ociManifest struct {
+	Layers []struct {
+		Digest string `json:"digest"`
+	} `json:"layers"`
+}
+
+// jwsEnvelope is notation's default signing envelope: a flattened JSON
+// JWS (RFC 7515) whose protected header embeds the signing certificate
+// chain as "x5c".
+type jwsEnvelope struct {
+	Payload   string `json:"payload"`
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+type jwsProtectedHeader struct {
+	Alg string   `json:"alg"`
+	X5C []string `json:"x5c"`
+}
+
+// verifyNotation checks req's image against nv's trust policy: it
+// resolves the image's manifest digest, queries the OCI Distribution
+// referrers API for notation signature manifests pointing at it, and
+// accepts the first signature whose certificate chain and cryptographic
+// signature both check out.
+func (v *Verifier) verifyNotation(ctx context.Context, nv *notationVerifier, req *models.ScanRequest) error {
+	host, repository, reference, err := parseNotationImageRef(req.ImageRef)
+	if err != nil {
+		return &RejectedError{Reason: fmt.Sprintf("invalid image reference: %v", err)}
+	}
+
+	client := v.notationRegistryClient(host, repository)
+
+	digest, err := v.resolveManifestDigest(ctx, client, host, repository, reference)
+	if err != nil {
+		return fmt.Errorf("failed to resolve manifest digest: %w", err)
+	}
+
+	referrers, err := v.fetchReferrers(ctx, client, host, repository, digest)
+	if err != nil {
+		return fmt.Errorf("failed to query referrers API: %w", err)
+	}
+
+	for _, referrer := range referrers.Manifests {
+		if referrer.ArtifactType != notationSignatureArtifactType {
+			continue
+		}
+
+		manifest, err := v.fetchOCIManifest(ctx, client, host, repository, referrer.Digest)
+		if err != nil {
+			continue
+		}
+
+		for _, layer := range manifest.Layers {
+			envelope, err := v.fetchBlob(ctx, client, host, repository, layer.Digest)
+			if err != nil {
+				continue
+			}
+			if nv.verifyEnvelope(envelope) {
+				return nil
+			}
+		}
+	}
+
+	return &RejectedError{Reason: "no valid notation signature found"}
+}
+
+// verifyEnvelope reports whether raw is a validly-signed JWS envelope
+// whose signing certificate chains to v.caPool and matches one of
+// v.trustedIdentities.
+func (v *notationVerifier) verifyEnvelope(raw []byte) bool {
+	var env jwsEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return false
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(env.Protected)
+	if err != nil {
+		return false
+	}
+
+	var header jwsProtectedHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil || len(header.X5C) == 0 {
+		return false
+	}
+
+	certs := make([]*x509.Certificate, 0, len(header.X5C))
+	for _, b64cert := range header.X5C {
+		der, err := base64.StdEncoding.DecodeString(b64cert)
+		if err != nil {
+			return false
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return false
+		}
+		certs = append(certs, cert)
+	}
+
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: v.caPool, Intermediates: intermediates}); err != nil {
+		return false
+	}
+
+	identified := false
+	for _, re := range v.trustedIdentities {
+		if re.MatchString(leaf.Subject.String()) {
+			identified = true
+			break
+		}
+	}
+	if !identified {
+		return false
+	}
+
+	return verifyJWSSignature(env.Protected, env.Payload, env.Signature, leaf.PublicKey, header.Alg) == nil
+}
+
+// verifyJWSSignature checks signature over "<protected>.<payload>"
+// against pub, supporting the algorithms notation uses by default:
+// PS256 for RSA keys and ES256 for EC keys.
+func verifyJWSSignature(protected, payload, signature string, pub crypto.PublicKey, alg string) error {
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(protected + "." + payload))
+
+	switch alg {
+	case "PS256":
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("PS256 requires an RSA public key")
+		}
+		return rsa.VerifyPSS(rsaPub, crypto.SHA256, digest[:], sig, nil)
+
+	case "ES256":
+		ecPub, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("ES256 requires an ECDSA public key")
+		}
+		if len(sig) != 64 {
+			return fmt.Errorf("invalid ES256 signature length: %d", len(sig))
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(ecPub, digest[:], r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported jws algorithm %q", alg)
+	}
+}
+
+// notationRegistryClients caches per-(host,repository) authenticated
+// HTTP clients, mirroring registry_scanner.go's registryClient.
+var (
+	notationRegistryClientsMu sync.Mutex
+	notationRegistryClients   = make(map[string]*http.Client)
+)
+
+// notationRegistryClient returns an http.Client that transparently
+// authenticates against host, scoped to pull access on repository.
+// Duplicates registry_scanner.go's registryClient for the same reason
+// clair.Scanner's does.
+func (v *Verifier) notationRegistryClient(host, repository string) *http.Client {
+	key := host + "|" + repository
+
+	notationRegistryClientsMu.Lock()
+	defer notationRegistryClientsMu.Unlock()
+
+	if client, ok := notationRegistryClients[key]; ok {
+		return client
+	}
+
+	creds := v.notationRegistryCredentials(host)
+	scope := fmt.Sprintf("repository:%s:pull", repository)
+
+	transport := registryauth.NewTransport(
+		http.DefaultTransport,
+		registryauth.NewChallengeManager(),
+		registryauth.NewTokenHandler(http.DefaultTransport, creds, scope),
+		registryauth.NewBasicHandler(creds),
+	)
+
+	client := &http.Client{Transport: transport, Timeout: 15 * time.Second}
+	notationRegistryClients[key] = client
+	return client
+}
+
+// notationRegistryCredentials looks up the static username/password
+// configured for host under the owning registry's Scanner.Credentials.
+func (v *Verifier) notationRegistryCredentials(host string) registryauth.CredentialStore {
+	for _, reg := range v.cfg.Registries {
+		if notationRegistryHostname(reg.URL) == host {
+			return &registryauth.StaticCredentialStore{
+				Username: reg.Scanner.Credentials.Username,
+				Password: reg.Scanner.Credentials.Password,
+			}
+		}
+	}
+	return &registryauth.StaticCredentialStore{}
+}
+
+// notationRegistryHostname strips the scheme from a configured registry
+// URL, duplicating cli_scanner.go's registryHostname.
+func notationRegistryHostname(registryURL string) string {
+	host := strings.TrimPrefix(registryURL, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return host
+}
+
+// resolveManifestDigest HEADs imageRef's manifest to resolve its
+// content digest without pulling the manifest body.
+func (v *Verifier) resolveManifestDigest(ctx context.Context, client *http.Client, host, repository, reference string) (string, error) {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, reference)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build manifest request: %w", err)
+	}
+	httpReq.Header.Set("Accept", manifestAcceptHeader)
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach registry %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s returned status %d for %s/%s:%s", host, resp.StatusCode, host, repository, reference)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s did not return a content digest for %s", host, reference)
+	}
+	return digest, nil
+}
+
+// fetchReferrers GETs the OCI Distribution Spec's referrers API for
+// digest.
+func (v *Verifier) fetchReferrers(ctx context.Context, client *http.Client, host, repository, digest string) (*referrersResponse, error) {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/referrers/%s", host, repository, digest)
+	body, err := v.get(ctx, client, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var referrers referrersResponse
+	if err := json.Unmarshal(body, &referrers); err != nil {
+		return nil, fmt.Errorf("failed to decode referrers response: %w", err)
+	}
+	return &referrers, nil
+}
+
+// fetchOCIManifest GETs a signature manifest by digest.
+func (v *Verifier) fetchOCIManifest(ctx context.Context, client *http.Client, host, repository, digest string) (*ociManifest, error) {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, digest)
+	body, err := v.get(ctx, client, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode signature manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// fetchBlob GETs a blob by digest.
+func (v *Verifier) fetchBlob(ctx context.Context, client *http.Client, host, repository, digest string) ([]byte, error) {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/blobs/%s", host, repository, digest)
+	return v.get(ctx, client, endpoint)
+}
+
+// get performs an authenticated GET against endpoint and returns its
+// body.
+func (v *Verifier) get(ctx context.Context, client *http.Client, endpoint string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", endpoint, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// manifestAcceptHeader lists the manifest media types this package
+// accepts: the two single-image types registry_scanner.go's
+// resolveImageDigest also sends, without its manifest list and OCI index
+// types.
+const manifestAcceptHeader = "application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.manifest.v1+json"
+
+// parseNotationImageRef splits an image reference like
+// "registry.example.com/team/app:v1.0.0" or
+// "registry.example.com/team/app@sha256:..." into its registry host,
+// repository path, and tag/digest reference. Duplicates
+// registry_scanner.go's parseImageRef for the same reason
+// notationRegistryHostname does.
+func parseNotationImageRef(imageRef string) (host, repository, reference string, err error) {
+	namePart := imageRef
+	reference = "latest"
+
+	if at := strings.LastIndex(imageRef, "@"); at != -1 {
+		namePart = imageRef[:at]
+		reference = imageRef[at+1:]
+	} else if colon := strings.LastIndex(imageRef, ":"); colon != -1 && !strings.Contains(imageRef[colon:], "/") {
+		namePart = imageRef[:colon]
+		reference = imageRef[colon+1:]
+	}
+
+	slash := strings.Index(namePart, "/")
+	if slash == -1 {
+		return "", "", "", fmt.Errorf("image ref %q has no registry host", imageRef)
+	}
+
+	host = namePart[:slash]
+	repository = namePart[slash+1:]
+	if host == "" || repository == "" {
+		return "", "", "", fmt.Errorf("invalid image ref: %q", imageRef)
+	}
+
+	return host, repository, reference, nil
+}
@@ -0,0 +1,252 @@
+package verify
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func TestNewNotationVerifierRequiresTrustStore(t *testing.T) {
+	_, err := newNotationVerifier(config.NotationConfig{TrustedIdentitySubjects: []string{".*"}})
+	if err == nil {
+		t.Fatal("expected error when trust_store is empty")
+	}
+}
+
+func TestNewNotationVerifierRequiresTrustedIdentities(t *testing.T) {
+	ca := mustGenerateNotationCA(t)
+	_, err := newNotationVerifier(config.NotationConfig{TrustStore: ca.pemBytes})
+	if err == nil {
+		t.Fatal("expected error when trusted_identity_subjects is empty")
+	}
+}
+
+func TestNewNotationVerifierInvalidIdentityRegex(t *testing.T) {
+	ca := mustGenerateNotationCA(t)
+	_, err := newNotationVerifier(config.NotationConfig{
+		TrustStore:              ca.pemBytes,
+		TrustedIdentitySubjects: []string{"["},
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid trusted_identity_subjects regex")
+	}
+}
+
+func TestNotationVerifier_VerifyEnvelope(t *testing.T) {
+	ca := mustGenerateNotationCA(t)
+	leafKey, leafCertDER := ca.mustIssueLeaf(t, "test-signer")
+
+	nv, err := newNotationVerifier(config.NotationConfig{
+		TrustStore:              ca.pemBytes,
+		TrustedIdentitySubjects: []string{"CN=test-signer"},
+	})
+	if err != nil {
+		t.Fatalf("newNotationVerifier() error = %v", err)
+	}
+
+	envelope := mustSignJWSEnvelope(t, leafKey, leafCertDER, []byte(`{"targetArtifact":{"digest":"sha256:abc"}}`))
+
+	if !nv.verifyEnvelope(envelope) {
+		t.Error("verifyEnvelope() = false, want true for a validly signed envelope")
+	}
+}
+
+func TestNotationVerifier_VerifyEnvelope_UntrustedIdentity(t *testing.T) {
+	ca := mustGenerateNotationCA(t)
+	leafKey, leafCertDER := ca.mustIssueLeaf(t, "someone-else")
+
+	nv, err := newNotationVerifier(config.NotationConfig{
+		TrustStore:              ca.pemBytes,
+		TrustedIdentitySubjects: []string{"CN=test-signer"},
+	})
+	if err != nil {
+		t.Fatalf("newNotationVerifier() error = %v", err)
+	}
+
+	envelope := mustSignJWSEnvelope(t, leafKey, leafCertDER, []byte(`{"targetArtifact":{"digest":"sha256:abc"}}`))
+
+	if nv.verifyEnvelope(envelope) {
+		t.Error("verifyEnvelope() = true, want false for an untrusted signer identity")
+	}
+}
+
+func TestNotationVerifier_VerifyEnvelope_UntrustedCA(t *testing.T) {
+	trustedCA := mustGenerateNotationCA(t)
+	otherCA := mustGenerateNotationCA(t)
+	leafKey, leafCertDER := otherCA.mustIssueLeaf(t, "test-signer")
+
+	nv, err := newNotationVerifier(config.NotationConfig{
+		TrustStore:              trustedCA.pemBytes,
+		TrustedIdentitySubjects: []string{"CN=test-signer"},
+	})
+	if err != nil {
+		t.Fatalf("newNotationVerifier() error = %v", err)
+	}
+
+	envelope := mustSignJWSEnvelope(t, leafKey, leafCertDER, []byte(`{"targetArtifact":{"digest":"sha256:abc"}}`))
+
+	if nv.verifyEnvelope(envelope) {
+		t.Error("verifyEnvelope() = true, want false for a certificate chaining to an untrusted CA")
+	}
+}
+
+func TestNotationVerifier_VerifyEnvelope_TamperedPayload(t *testing.T) {
+	ca := mustGenerateNotationCA(t)
+	leafKey, leafCertDER := ca.mustIssueLeaf(t, "test-signer")
+
+	nv, err := newNotationVerifier(config.NotationConfig{
+		TrustStore:              ca.pemBytes,
+		TrustedIdentitySubjects: []string{"CN=test-signer"},
+	})
+	if err != nil {
+		t.Fatalf("newNotationVerifier() error = %v", err)
+	}
+
+	envelope := mustSignJWSEnvelope(t, leafKey, leafCertDER, []byte(`{"targetArtifact":{"digest":"sha256:abc"}}`))
+
+	var parsed jwsEnvelope
+	if err := json.Unmarshal(envelope, &parsed); err != nil {
+		t.Fatalf("failed to unmarshal test envelope: %v", err)
+	}
+	parsed.Payload = base64.RawURLEncoding.EncodeToString([]byte(`{"targetArtifact":{"digest":"sha256:tampered"}}`))
+	tampered, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatalf("failed to remarshal test envelope: %v", err)
+	}
+
+	if nv.verifyEnvelope(tampered) {
+		t.Error("verifyEnvelope() = true, want false for a tampered payload")
+	}
+}
+
+func TestParseNotationImageRef(t *testing.T) {
+	host, repository, reference, err := parseNotationImageRef("registry.example.com/team/app:v1.0.0")
+	if err != nil {
+		t.Fatalf("parseNotationImageRef() error = %v", err)
+	}
+	if host != "registry.example.com" || repository != "team/app" || reference != "v1.0.0" {
+		t.Errorf("parseNotationImageRef() = (%v, %v, %v)", host, repository, reference)
+	}
+
+	if _, _, _, err := parseNotationImageRef("app:v1.0.0"); err == nil {
+		t.Error("parseNotationImageRef() expected error for missing registry host")
+	}
+}
+
+// notationTestCA is a self-signed CA generated for tests, able to issue
+// leaf certificates signed by its own key.
+type notationTestCA struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	pemBytes string
+}
+
+// mustGenerateNotationCA creates a self-signed CA certificate for tests.
+func mustGenerateNotationCA(t *testing.T) *notationTestCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return &notationTestCA{cert: cert, key: key, pemBytes: string(pemBytes)}
+}
+
+// mustIssueLeaf creates a leaf certificate signed by ca with the given
+// common name, returning the leaf's signing key and DER bytes.
+func (ca *notationTestCA) mustIssueLeaf(t *testing.T, commonName string) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate leaf key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Example"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &leafKey.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to create leaf certificate: %v", err)
+	}
+
+	return leafKey, der
+}
+
+// mustSignJWSEnvelope builds a flattened JWS envelope (ES256) over
+// payload, embedding leafCertDER as the sole "x5c" certificate.
+func mustSignJWSEnvelope(t *testing.T, key *ecdsa.PrivateKey, leafCertDER []byte, payload []byte) []byte {
+	t.Helper()
+
+	header := jwsProtectedHeader{
+		Alg: "ES256",
+		X5C: []string{base64.StdEncoding.EncodeToString(leafCertDER)},
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("failed to marshal header: %v", err)
+	}
+
+	protected := base64.RawURLEncoding.EncodeToString(headerJSON)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(protected + "." + encodedPayload))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign test envelope: %v", err)
+	}
+
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	env := jwsEnvelope{
+		Payload:   encodedPayload,
+		Protected: protected,
+		Signature: base64.RawURLEncoding.EncodeToString(sig),
+	}
+
+	envJSON, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("failed to marshal test envelope: %v", err)
+	}
+	return envJSON
+}
@@ -0,0 +1,256 @@
+// Package verify checks that an image referenced by a scan request
+// carries a valid signature (and, optionally, SBOM attestation) before
+// the scan pipeline spends resources on it. It wraps sigstore/cosign's
+// verification libraries, resolving each registry's trust configuration
+// (static public keys, keyless Fulcio/Rekor identities, required
+// annotations) from config.VerificationConfig. A registry configuring
+// VerificationConfig.Notation instead is routed through notation.go's
+// Notary v2 flow. Registries that don't configure verification are
+// passed through unchecked.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	rekorclient "github.com/sigstore/rekor/pkg/client"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sirupsen/logrus"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+)
+
+// Verifier checks a scan request's image against its registry's
+// configured signature/attestation policy before a scan runs.
+// Registries with no config.VerificationConfig (or Enabled: false) are
+// passed through unchecked, preserving prior behavior.
+type Verifier struct {
+	cfg      *config.Config
+	logger   *logrus.Logger
+	policies map[string]*registryPolicy
+}
+
+// registryPolicy is one registry's VerificationConfig, pre-parsed into
+// the forms cosign's (or notation's) verification calls need.
+type registryPolicy struct {
+	cfg         config.VerificationConfig
+	keyVerifier signature.Verifier
+	identities  []compiledIdentity
+	fulcioRoot  []byte
+	// notation is set instead of keyVerifier/identities when cfg.Notation
+	// is configured, routing Verify through the notation flow rather than
+	// cosign's.
+	notation *notationVerifier
+}
+
+// compiledIdentity is one KeylessIdentity with its regexes compiled.
+type compiledIdentity struct {
+	subject *regexp.Regexp
+	issuer  *regexp.Regexp
+}
+
+// NewVerifier builds a Verifier from every registry in cfg.Registries
+// that configures a Verification block with Enabled set.
+func NewVerifier(cfg *config.Config, logger *logrus.Logger) (*Verifier, error) {
+	policies := make(map[string]*registryPolicy)
+
+	for _, reg := range cfg.Registries {
+		if reg.Verification == nil || !reg.Verification.Enabled {
+			continue
+		}
+
+		policy, err := newRegistryPolicy(*reg.Verification)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: invalid verification config: %w", reg.Name, err)
+		}
+		policies[reg.Name] = policy
+	}
+
+	return &Verifier{cfg: cfg, logger: logger, policies: policies}, nil
+}
+
+// newRegistryPolicy parses cfg's PEM keys and identity regexes once at
+// construction time, rather than on every Verify call.
+func newRegistryPolicy(cfg config.VerificationConfig) (*registryPolicy, error) {
+	policy := &registryPolicy{cfg: cfg}
+
+	if cfg.Notation != nil {
+		nv, err := newNotationVerifier(*cfg.Notation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notation config: %w", err)
+		}
+		policy.notation = nv
+		return policy, nil
+	}
+
+	if len(cfg.Keys) > 0 {
+		v, err := signature.LoadPublicKeyVerifier([]byte(cfg.Keys[0]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load public key: %w", err)
+		}
+		policy.keyVerifier = v
+	}
+
+	for _, id := range cfg.KeylessIdentities {
+		subject, err := regexp.Compile(id.SubjectRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyless identity subject_regex %q: %w", id.SubjectRegex, err)
+		}
+		issuer, err := regexp.Compile(id.IssuerRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyless identity issuer_regex %q: %w", id.IssuerRegex, err)
+		}
+		policy.identities = append(policy.identities, compiledIdentity{subject: subject, issuer: issuer})
+	}
+
+	if cfg.FulcioRoot != "" {
+		policy.fulcioRoot = []byte(cfg.FulcioRoot)
+	}
+
+	if policy.keyVerifier == nil && len(policy.identities) == 0 {
+		return nil, fmt.Errorf("verification is enabled but no keys or keyless_identities are configured")
+	}
+
+	return policy, nil
+}
+
+// Verify checks req's image against its registry's configured policy.
+// Registries with no policy return nil immediately (verification
+// skipped). An unsigned or invalidly-signed image returns a
+// *RejectedError describing why; the caller should turn that into a
+// models.ScanStatusRejected result rather than retrying the scan, since
+// retrying won't change whether the image is signed.
+func (v *Verifier) Verify(ctx context.Context, req *models.ScanRequest) error {
+	policy, ok := v.policies[req.RegistryName]
+	if !ok {
+		return nil
+	}
+
+	log := v.logger.WithFields(logrus.Fields{
+		"image_ref":  req.ImageRef,
+		"request_id": req.RequestID,
+		"registry":   req.RegistryName,
+	})
+
+	if policy.notation != nil {
+		if err := v.verifyNotation(ctx, policy.notation, req); err != nil {
+			var rejected *RejectedError
+			if errors.As(err, &rejected) {
+				log.WithField("reason", rejected.Reason).Warn("Notation signature verification failed")
+				metrics.RecordVerification(req.RegistryName, "rejected")
+			} else {
+				metrics.RecordVerification(req.RegistryName, "error")
+			}
+			return err
+		}
+
+		log.Info("Image signature verified")
+		metrics.RecordVerification(req.RegistryName, "verified")
+		return nil
+	}
+
+	ref, err := ociremote.ParseReference(req.ImageRef)
+	if err != nil {
+		metrics.RecordVerification(req.RegistryName, "rejected")
+		return &RejectedError{Reason: fmt.Sprintf("invalid image reference: %v", err)}
+	}
+
+	opts, err := checkOpts(ctx, policy)
+	if err != nil {
+		metrics.RecordVerification(req.RegistryName, "error")
+		return fmt.Errorf("failed to build verification options: %w", err)
+	}
+
+	signatures, _, err := cosign.VerifyImageSignatures(ctx, ref, opts)
+	if err != nil || len(signatures) == 0 {
+		log.WithError(err).Warn("Image signature verification failed")
+		metrics.RecordVerification(req.RegistryName, "rejected")
+		return &RejectedError{Reason: fmt.Sprintf("signature verification failed: %v", err)}
+	}
+
+	if err := checkAnnotations(signatures, policy.cfg.RequiredAnnotations); err != nil {
+		metrics.RecordVerification(req.RegistryName, "rejected")
+		return &RejectedError{Reason: err.Error()}
+	}
+
+	if policy.cfg.RequireAttestations {
+		attestations, _, err := cosign.VerifyImageAttestations(ctx, ref, opts)
+		if err != nil || len(attestations) == 0 {
+			log.WithError(err).Warn("SBOM attestation verification failed")
+			metrics.RecordVerification(req.RegistryName, "rejected")
+			return &RejectedError{Reason: fmt.Sprintf("SBOM attestation verification failed: %v", err)}
+		}
+	}
+
+	log.Info("Image signature verified")
+	metrics.RecordVerification(req.RegistryName, "verified")
+	return nil
+}
+
+// checkOpts builds the cosign.CheckOpts that express policy's key or
+// keyless trust configuration.
+func checkOpts(ctx context.Context, policy *registryPolicy) (*cosign.CheckOpts, error) {
+	opts := &cosign.CheckOpts{}
+
+	if policy.cfg.RekorURL != "" {
+		opts.RekorClient = rekorclient.NewHTTPClientWithConfig(nil, rekorclient.DefaultTransportConfig().WithHost(policy.cfg.RekorURL))
+	}
+
+	if policy.keyVerifier != nil {
+		opts.SigVerifier = policy.keyVerifier
+		return opts, nil
+	}
+
+	for _, id := range policy.identities {
+		opts.Identities = append(opts.Identities, cosign.Identity{
+			Subject: id.subject.String(),
+			Issuer:  id.issuer.String(),
+		})
+	}
+
+	if len(policy.fulcioRoot) > 0 {
+		roots, err := cryptoutils.LoadCertificatesFromPEM(bytes.NewReader(policy.fulcioRoot))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fulcio_root: %w", err)
+		}
+		pool := x509.NewCertPool()
+		for _, cert := range roots {
+			pool.AddCert(cert)
+		}
+		opts.RootCerts = pool
+	}
+
+	return opts, nil
+}
+
+// checkAnnotations confirms every required annotation is present on at
+// least one of sigs with a matching value.
+func checkAnnotations(sigs []oci.Signature, required map[string]string) error {
+	for key, want := range required {
+		found := false
+		for _, sig := range sigs {
+			got, ok, err := sig.Annotations()
+			if err != nil {
+				continue
+			}
+			if v, present := got[key]; ok && present && v == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("signature missing required annotation %q=%q", key, want)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package verify
+
+import (
+	"testing"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func TestNewRegistryPolicyRequiresKeysOrIdentities(t *testing.T) {
+	_, err := newRegistryPolicy(config.VerificationConfig{Enabled: true})
+	if err == nil {
+		t.Fatal("expected error when neither keys nor keyless_identities are configured")
+	}
+}
+
+func TestNewRegistryPolicyInvalidIdentityRegex(t *testing.T) {
+	_, err := newRegistryPolicy(config.VerificationConfig{
+		Enabled: true,
+		KeylessIdentities: []config.KeylessIdentity{
+			{SubjectRegex: "[", IssuerRegex: "https://accounts.example.com"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid subject_regex")
+	}
+}
+
+func TestCheckAnnotations(t *testing.T) {
+	sig, err := static.NewSignature([]byte("payload"), "sig",
+		static.WithAnnotations(map[string]string{"ci-pipeline": "build-123"}))
+	if err != nil {
+		t.Fatalf("failed to build test signature: %v", err)
+	}
+	sigs := []oci.Signature{sig}
+
+	if err := checkAnnotations(sigs, map[string]string{"ci-pipeline": "build-123"}); err != nil {
+		t.Errorf("expected required annotation to be satisfied, got %v", err)
+	}
+
+	if err := checkAnnotations(sigs, map[string]string{"ci-pipeline": "other"}); err == nil {
+		t.Error("expected error for mismatched annotation value")
+	}
+
+	if err := checkAnnotations(sigs, map[string]string{"missing": "value"}); err == nil {
+		t.Error("expected error for missing required annotation")
+	}
+}
@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+)
+
+// Adapter pairs one configured registry's webhook parser with a Detect
+// check that recognizes its webhook deliveries by shape, so Dispatcher
+// can identify which registry an inbound request came from before
+// authenticating or parsing it, instead of trying every configured
+// registry's auth credentials in turn.
+type Adapter interface {
+	// Detect reports whether r/body look like a webhook delivery this
+	// adapter knows how to parse, inspecting headers and (for registry
+	// types that don't send a distinguishing one) well-known top-level
+	// JSON fields. body is the already-drained request body; Detect
+	// must not consume r.Body itself.
+	Detect(r *http.Request, body []byte) bool
+
+	// Parse validates and extracts scan requests from r/body via the
+	// wrapped models.WebhookParser, restoring r.Body around each call so
+	// Validate's signature check and Parse's field extraction both see
+	// the full body.
+	Parse(r *http.Request, body []byte) ([]*models.ScanRequest, error)
+
+	// RegistryName returns the configured registry (config.RegistryConfig.Name)
+	// this adapter parses webhooks for, used to look up that registry's
+	// auth/scanner/verification configuration.
+	RegistryName() string
+}
+
+// registryAdapter implements Adapter by wrapping one registry's
+// models.WebhookParser (as built by parsers.NewParserRegistry, so it
+// carries that registry's URL/secret/auth-header configuration) with a
+// Detect function keyed off the registry's configured Type.
+type registryAdapter struct {
+	registryName string
+	registryType string
+	parser       models.WebhookParser
+}
+
+// RegistryName implements Adapter.
+func (a *registryAdapter) RegistryName() string {
+	return a.registryName
+}
+
+// Detect implements Adapter.
+func (a *registryAdapter) Detect(r *http.Request, body []byte) bool {
+	detect, ok := detectorsByType[a.registryType]
+	if !ok {
+		return false
+	}
+	return detect(r, body)
+}
+
+// Parse implements Adapter.
+func (a *registryAdapter) Parse(r *http.Request, body []byte) ([]*models.ScanRequest, error) {
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := a.parser.Validate(r); err != nil {
+		return nil, err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return a.parser.Parse(r)
+}
+
+// buildAdapters constructs one registryAdapter per registry in
+// parserRegistry, in the same order as cfg.Registries so Dispatcher's
+// Detect loop is deterministic.
+func buildAdapters(cfg []registryAndParser) []Adapter {
+	adapters := make([]Adapter, 0, len(cfg))
+	for _, rp := range cfg {
+		adapters = append(adapters, &registryAdapter{
+			registryName: rp.name,
+			registryType: rp.registryType,
+			parser:       rp.parser,
+		})
+	}
+	return adapters
+}
+
+// registryAndParser is the input buildAdapters needs per registry:
+// Dispatcher resolves this from config.Config plus parsers.ParserRegistry.
+type registryAndParser struct {
+	name         string
+	registryType string
+	parser       models.WebhookParser
+}
+
+// detectorsByType maps a registry type to the function recognizing its
+// webhook deliveries. Types with a distinguishing header (GitLab, GHCR,
+// Docker Distribution) check it directly; types that send plain
+// "application/json" with no such header (Harbor, Quay, DockerHub, ACR)
+// fall back to sniffing well-known top-level JSON fields, since their
+// payload shapes don't otherwise overlap. ECR arrives as an SNS envelope
+// wrapping an EventBridge event, identified by its own nested
+// "detail-type" field; GCR arrives as a Pub/Sub push envelope, identified
+// by its "message"/"subscription" fields.
+var detectorsByType = map[string]func(r *http.Request, body []byte) bool{
+	"harbor":       detectHarbor,
+	"gitlab":       detectGitLab,
+	"ghcr":         detectGHCR,
+	"distribution": detectDistribution,
+	"ecr":          detectECR,
+	"quay":         detectQuay,
+	"dockerhub":    detectDockerHub,
+	"acr":          detectACR,
+	"gcr":          detectGCR,
+}
+
+// distributionEventsContentType duplicates
+// parsers.distributionEventsContentType: see that package's doc comment
+// for why scanner backend packages (and this one) keep small constants
+// like this duplicated rather than exporting them across a package
+// boundary just for this one check.
+const distributionEventsContentType = "application/vnd.docker.distribution.events.v1+json"
+
+func detectHarbor(r *http.Request, body []byte) bool {
+	if r.Header.Get("X-Harbor-Event") != "" {
+		return true
+	}
+
+	var probe struct {
+		Type      string `json:"type"`
+		EventData struct {
+			Resources []struct {
+				ResourceURL string `json:"resource_url"`
+			} `json:"resources"`
+		} `json:"event_data"`
+	}
+	if json.Unmarshal(body, &probe) != nil {
+		return false
+	}
+	return (probe.Type == "PUSH_ARTIFACT" || probe.Type == "pushImage") && len(probe.EventData.Resources) > 0
+}
+
+func detectGitLab(r *http.Request, body []byte) bool {
+	return r.Header.Get("X-Gitlab-Event") != ""
+}
+
+func detectGHCR(r *http.Request, body []byte) bool {
+	return r.Header.Get("X-GitHub-Event") == "package"
+}
+
+func detectDistribution(r *http.Request, body []byte) bool {
+	return r.Header.Get("Content-Type") == distributionEventsContentType
+}
+
+func detectECR(r *http.Request, body []byte) bool {
+	var envelope struct {
+		Type    string `json:"Type"`
+		Message string `json:"Message"`
+	}
+	if json.Unmarshal(body, &envelope) != nil || envelope.Type != "Notification" {
+		return false
+	}
+
+	var event struct {
+		DetailType string `json:"detail-type"`
+	}
+	return json.Unmarshal([]byte(envelope.Message), &event) == nil && event.DetailType == "ECR Image Action"
+}
+
+func detectQuay(r *http.Request, body []byte) bool {
+	var probe struct {
+		DockerURL   string   `json:"docker_url"`
+		UpdatedTags []string `json:"updated_tags"`
+	}
+	return json.Unmarshal(body, &probe) == nil && probe.DockerURL != "" && len(probe.UpdatedTags) > 0
+}
+
+func detectDockerHub(r *http.Request, body []byte) bool {
+	var probe struct {
+		CallbackURL string `json:"callback_url"`
+		PushData    struct {
+			Tag string `json:"tag"`
+		} `json:"push_data"`
+	}
+	return json.Unmarshal(body, &probe) == nil && probe.CallbackURL != "" && probe.PushData.Tag != ""
+}
+
+// detectACR sniffs ACRWebhookEvent's shape: a top-level "target" object
+// with a repository and media type, plus a "request" object with the
+// originating host. Neither field name overlaps with Harbor's,
+// Quay's, or DockerHub's probes above.
+func detectACR(r *http.Request, body []byte) bool {
+	var probe struct {
+		Target struct {
+			Repository string `json:"repository"`
+			MediaType  string `json:"mediaType"`
+		} `json:"target"`
+		Request struct {
+			Host string `json:"host"`
+		} `json:"request"`
+	}
+	return json.Unmarshal(body, &probe) == nil &&
+		probe.Target.Repository != "" && probe.Target.MediaType != "" && probe.Request.Host != ""
+}
+
+// detectGCR sniffs PubSubPushEnvelope's shape: a "message" object
+// carrying the base64-encoded notification data, plus the push
+// subscription's resource name.
+func detectGCR(r *http.Request, body []byte) bool {
+	var probe struct {
+		Message struct {
+			Data string `json:"data"`
+		} `json:"message"`
+		Subscription string `json:"subscription"`
+	}
+	return json.Unmarshal(body, &probe) == nil && probe.Message.Data != "" && probe.Subscription != ""
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/webhook/parsers"
+)
+
+// TestDetectorsByType_CoversAllRegisteredParserTypes guards against the
+// class of bug that left ACR/GCR webhooks unreachable: a registry type
+// registered with parsers.Default() but missing from detectorsByType
+// means registryAdapter.Detect silently returns false for every request
+// of that type, so Dispatcher.Dispatch can never route to it.
+func TestDetectorsByType_CoversAllRegisteredParserTypes(t *testing.T) {
+	for _, registryType := range parsers.Default().Types() {
+		if _, ok := detectorsByType[registryType]; !ok {
+			t.Errorf("parsers.Default() registers %q but detectorsByType has no detector for it; its webhooks can never reach Dispatcher.Dispatch", registryType)
+		}
+	}
+}
+
+func newDetectRequest(body string) (*http.Request, []byte) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req, []byte(body)
+}
+
+func TestDetectACR(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{
+			name: "valid ACR push event",
+			body: `{
+				"action": "push",
+				"target": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "repository": "myapp", "tag": "latest"},
+				"request": {"id": "req-1", "host": "myregistry.azurecr.io", "method": "PUT"}
+			}`,
+			want: true,
+		},
+		{
+			name: "missing request host",
+			body: `{
+				"action": "push",
+				"target": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "repository": "myapp", "tag": "latest"}
+			}`,
+			want: false,
+		},
+		{
+			name: "Quay payload",
+			body: `{"docker_url": "quay.io/myapp", "updated_tags": ["latest"]}`,
+			want: false,
+		},
+		{
+			name: "invalid JSON",
+			body: `{not json`,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, body := newDetectRequest(tt.body)
+			if got := detectACR(r, body); got != tt.want {
+				t.Errorf("detectACR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectGCR(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{
+			name: "valid Pub/Sub push envelope",
+			body: `{"message": {"data": "eyJhY3Rpb24iOiJJTlNFUlQifQ==", "messageId": "msg-1"}, "subscription": "projects/p/subscriptions/s"}`,
+			want: true,
+		},
+		{
+			name: "missing subscription",
+			body: `{"message": {"data": "eyJhY3Rpb24iOiJJTlNFUlQifQ==", "messageId": "msg-1"}}`,
+			want: false,
+		},
+		{
+			name: "DockerHub payload",
+			body: `{"callback_url": "https://x", "push_data": {"tag": "latest"}}`,
+			want: false,
+		},
+		{
+			name: "invalid JSON",
+			body: `{not json`,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, body := newDetectRequest(tt.body)
+			if got := detectGCR(r, body); got != tt.want {
+				t.Errorf("detectGCR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
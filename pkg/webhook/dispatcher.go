@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/auth"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/webhook/parsers"
+)
+
+// Dispatcher identifies which configured registry an inbound webhook
+// request came from, authenticates the request against only that
+// registry's credentials, and then parses it with that registry's
+// adapter - replacing the O(N) "try every registry in turn" loop
+// RequestAuthenticator.Middleware used before a request's registry was
+// known.
+type Dispatcher struct {
+	adapters      []Adapter
+	authenticator *auth.RequestAuthenticator
+}
+
+// NewDispatcher builds a Dispatcher from every registry in cfg.Registries
+// whose Type has a registered parser, reusing parsers.NewParserRegistry
+// so each adapter's parser carries that registry's URL/webhook secret/
+// auth header configuration exactly as ParserRegistry already resolves
+// it. authenticator is used to verify the request once Dispatch has
+// identified which registry it's for.
+func NewDispatcher(cfg *config.Config, authenticator *auth.RequestAuthenticator) *Dispatcher {
+	parserRegistry := parsers.NewParserRegistry(cfg)
+
+	registryAndParsers := make([]registryAndParser, 0, len(cfg.Registries))
+	for _, reg := range cfg.Registries {
+		parser, err := parserRegistry.GetParser(reg.Name)
+		if err != nil {
+			// Unknown/unconfigured registry type; skip rather than fail
+			// Dispatcher construction, matching ParserRegistry's own
+			// "skip unknown registry types" behavior.
+			continue
+		}
+		registryAndParsers = append(registryAndParsers, registryAndParser{
+			name:         reg.Name,
+			registryType: reg.Type,
+			parser:       parser,
+		})
+	}
+
+	return &Dispatcher{
+		adapters:      buildAdapters(registryAndParsers),
+		authenticator: authenticator,
+	}
+}
+
+// AuthenticationError wraps an auth.RequestAuthenticator failure from
+// Dispatch, distinguishing it from a detection or payload-parsing
+// failure so handleWebhook can respond 401 rather than 400.
+type AuthenticationError struct {
+	Registry string
+	Err      error
+}
+
+func (e *AuthenticationError) Error() string {
+	return fmt.Sprintf("registry %s: authentication failed: %v", e.Registry, e.Err)
+}
+
+func (e *AuthenticationError) Unwrap() error {
+	return e.Err
+}
+
+// Dispatch reads r's body, finds the first configured Adapter whose
+// Detect recognizes it, authenticates r against that adapter's registry,
+// and returns the parsed scan requests along with the registry name they
+// belong to.
+func (d *Dispatcher) Dispatch(r *http.Request) (registryName string, requests []*models.ScanRequest, err error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	adapter := d.detect(r, body)
+	if adapter == nil {
+		return "", nil, fmt.Errorf("no configured registry recognized this webhook delivery")
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := d.authenticator.AuthenticateRegistry(r, adapter.RegistryName()); err != nil {
+		return "", nil, &AuthenticationError{Registry: adapter.RegistryName(), Err: err}
+	}
+
+	requests, err = adapter.Parse(r, body)
+	if err != nil {
+		return "", nil, fmt.Errorf("registry %s: %w", adapter.RegistryName(), err)
+	}
+
+	return adapter.RegistryName(), requests, nil
+}
+
+// detect returns the first adapter whose Detect recognizes body, or nil
+// if none do.
+func (d *Dispatcher) detect(r *http.Request, body []byte) Adapter {
+	for _, adapter := range d.adapters {
+		if adapter.Detect(r, body) {
+			return adapter
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/metrics"
+)
+
+// requestIDHeader is the header incoming requests may set to propagate a
+// caller-assigned correlation ID, and that the server echoes back on the
+// response.
+const requestIDHeader = "X-Request-ID"
+
+// maxIncomingRequestIDLen bounds how much of a caller-supplied
+// X-Request-ID we'll trust verbatim; longer values are discarded in
+// favor of a generated one rather than risk log injection or unbounded
+// header growth.
+const maxIncomingRequestIDLen = 128
+
+// validRequestID matches the conservative charset accepted for a
+// caller-supplied X-Request-ID: ASCII letters, digits, and -._.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// requestIDMiddleware assigns every request a correlation ID, preferring
+// an incoming X-Request-ID header when it looks reasonable and otherwise
+// generating a UUIDv4. The ID is stashed on the request context under
+// models.WithRequestID so parsers.generateRequestID, loggingMiddleware,
+// and everything downstream (ResultProcessor.logResult, scanner backend
+// logs) report the same request_id, and it's echoed back on the response
+// for the caller to correlate against.
+func (s *Server) requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !isValidIncomingRequestID(requestID) {
+			requestID = generateUUIDv4()
+		}
+
+		w.Header().Set(requestIDHeader, requestID)
+		r = r.WithContext(models.WithRequestID(r.Context(), requestID))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isValidIncomingRequestID reports whether a caller-supplied
+// X-Request-ID is short and plain enough to echo back and log verbatim.
+func isValidIncomingRequestID(id string) bool {
+	return id != "" && len(id) <= maxIncomingRequestIDLen && validRequestID.MatchString(id)
+}
+
+// generateUUIDv4 returns a random RFC 4122 version 4 UUID.
+func generateUUIDv4() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand.Read on this platform failing is unrecoverable; a
+		// request ID that isn't actually random is still better than
+		// panicking the handler over it.
+		return hexRequestIDFallback()
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// hexRequestIDFallback mints a request ID without crypto/rand, for the
+// extraordinarily unlikely case that it errors.
+func hexRequestIDFallback() string {
+	return fmt.Sprintf("fallback-%d", runtime.NumGoroutine())
+}
+
+// panicRecoveryMiddleware recovers a panic from any handler further down
+// the chain, logs it with a full goroutine stack at Warn level, counts it
+// in webhook_panics_total, and responds with a JSON 500 instead of
+// letting net/http close the connection with no body.
+func (s *Server) panicRecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				stack := make([]byte, 64*1024)
+				stack = stack[:runtime.Stack(stack, false)]
+
+				logger := s.logger
+				if requestID, ok := models.RequestIDFromContext(r.Context()); ok {
+					logger = logger.WithRequestID(requestID)
+				}
+
+				logger.Warn(r.Context(), "Recovered from panic in webhook handler",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"panic", fmt.Sprintf("%v", rec),
+					"stack", string(stack),
+				)
+
+				metrics.RecordWebhookPanic(r.URL.Path)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "internal server error"})
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,143 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// ACRParser parses Azure Container Registry webhook events. ACR models
+// its webhook payload on the Docker Distribution notification format
+// (a single "target"/"request" event rather than a DistributionEvent
+// array), so it gets its own parser rather than reusing
+// DistributionParser.
+type ACRParser struct {
+	registryURL string
+	verifier    SignatureVerifier
+}
+
+// NewACRParser creates a new ACR parser. ACR doesn't sign its webhook
+// deliveries, so the only way to authenticate the sender is the same
+// approach Azure's own docs recommend: configure the webhook's "Service
+// URI" with HTTP basic auth credentials, which Azure then sends back
+// verbatim in the Authorization header. If webhookSecret is non-empty,
+// Validate requires an Authorization header matching it.
+func NewACRParser(registryURL, webhookSecret string) *ACRParser {
+	p := &ACRParser{registryURL: registryURL}
+	if webhookSecret != "" {
+		p.verifier = &SharedSecretHeaderVerifier{Header: "Authorization", Secret: webhookSecret}
+	}
+	return p
+}
+
+func init() {
+	Register("acr", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewACRParser(cfg.URL, cfg.WebhookSecret), nil
+	})
+}
+
+// RegistryType returns the registry type this parser handles
+func (p *ACRParser) RegistryType() string {
+	return "acr"
+}
+
+// Validate checks if the webhook payload is valid
+func (p *ACRParser) Validate(r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return fmt.Errorf("invalid HTTP method: %s", r.Method)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		return fmt.Errorf("invalid content type: %s", contentType)
+	}
+
+	if p.verifier != nil {
+		body, err := readAndRestoreBody(r)
+		if err != nil {
+			return err
+		}
+		if err := p.verifier.Verify(r, body); err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Parse extracts a scan request from an ACR webhook event, emitting one
+// for "push" actions and ignoring "delete" and "quarantine".
+func (p *ACRParser) Parse(r *http.Request) ([]*models.ScanRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	var event ACRWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if event.Action != "push" {
+		return nil, fmt.Errorf("unsupported action: %s", event.Action)
+	}
+
+	if event.Target.Repository == "" {
+		return nil, fmt.Errorf("missing target repository")
+	}
+
+	registryHost := p.registryURL
+	if registryHost == "" {
+		registryHost = event.Request.Host
+	}
+	registryHost = strings.TrimPrefix(registryHost, "https://")
+	registryHost = strings.TrimPrefix(registryHost, "http://")
+
+	var imageRef string
+	if event.Target.Tag != "" {
+		imageRef = fmt.Sprintf("%s/%s:%s", registryHost, event.Target.Repository, event.Target.Tag)
+	} else {
+		imageRef = fmt.Sprintf("%s/%s@%s", registryHost, event.Target.Repository, event.Target.Digest)
+	}
+
+	scanRequest := &models.ScanRequest{
+		ImageRef:     imageRef,
+		RegistryName: "acr",
+		Registry:     registryHost,
+		Repository:   event.Target.Repository,
+		Tag:          event.Target.Tag,
+		Digest:       event.Target.Digest,
+		ReceivedAt:   time.Now(),
+		RequestID:    generateRequestID(r),
+	}
+
+	return []*models.ScanRequest{scanRequest}, nil
+}
+
+// ACRWebhookEvent represents the Azure Container Registry webhook event
+// payload, sent for repository push/delete/quarantine actions.
+type ACRWebhookEvent struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"` // push, delete, quarantine
+	Target    struct {
+		MediaType  string `json:"mediaType"`
+		Size       int64  `json:"size"`
+		Digest     string `json:"digest"`
+		Length     int64  `json:"length"`
+		Repository string `json:"repository"`
+		Tag        string `json:"tag"`
+	} `json:"target"`
+	Request struct {
+		ID        string `json:"id"`
+		Host      string `json:"host"`
+		Method    string `json:"method"`
+		Useragent string `json:"useragent"`
+	} `json:"request"`
+}
@@ -0,0 +1,183 @@
+package parsers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestACRParser_Parse(t *testing.T) {
+	parser := NewACRParser("myregistry.azurecr.io", "")
+
+	tests := []struct {
+		name        string
+		payload     string
+		wantCount   int
+		wantRepo    string
+		wantTag     string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "valid push event",
+			payload: `{
+				"id": "evt-1",
+				"timestamp": "2024-01-01T00:00:00Z",
+				"action": "push",
+				"target": {
+					"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+					"digest": "sha256:abc123",
+					"repository": "myapp",
+					"tag": "latest"
+				},
+				"request": {"id": "req-1", "host": "myregistry.azurecr.io", "method": "PUT"}
+			}`,
+			wantCount: 1,
+			wantRepo:  "myapp",
+			wantTag:   "latest",
+			wantErr:   false,
+		},
+		{
+			name: "ignores delete action",
+			payload: `{
+				"action": "delete",
+				"target": {"repository": "myapp", "tag": "latest"}
+			}`,
+			wantErr:     true,
+			errContains: "unsupported action",
+		},
+		{
+			name: "ignores quarantine action",
+			payload: `{
+				"action": "quarantine",
+				"target": {"repository": "myapp", "tag": "latest"}
+			}`,
+			wantErr:     true,
+			errContains: "unsupported action",
+		},
+		{
+			name: "missing repository",
+			payload: `{
+				"action": "push",
+				"target": {"tag": "latest"}
+			}`,
+			wantErr:     true,
+			errContains: "missing target repository",
+		},
+		{
+			name:        "invalid JSON",
+			payload:     `{invalid json}`,
+			wantErr:     true,
+			errContains: "failed to parse JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			requests, err := parser.Parse(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Parse() error = %v, want error containing %v", err, tt.errContains)
+				}
+				return
+			}
+
+			if len(requests) != tt.wantCount {
+				t.Errorf("Parse() returned %d requests, want %d", len(requests), tt.wantCount)
+				return
+			}
+
+			if tt.wantCount > 0 {
+				req := requests[0]
+				if req.Repository != tt.wantRepo {
+					t.Errorf("Parse() repository = %v, want %v", req.Repository, tt.wantRepo)
+				}
+				if req.Tag != tt.wantTag {
+					t.Errorf("Parse() tag = %v, want %v", req.Tag, tt.wantTag)
+				}
+				if req.Registry != "myregistry.azurecr.io" {
+					t.Errorf("Parse() registry = %v, want myregistry.azurecr.io", req.Registry)
+				}
+			}
+		})
+	}
+}
+
+func TestACRParser_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		authHeader  string
+		secret      string
+		wantErr     bool
+	}{
+		{
+			name:        "valid POST with JSON content type",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			wantErr:     false,
+		},
+		{
+			name:        "invalid method GET",
+			method:      http.MethodGet,
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid content type",
+			method:      http.MethodPost,
+			contentType: "application/xml",
+			wantErr:     true,
+		},
+		{
+			name:        "matching Authorization header",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			authHeader:  "Basic c2VjcmV0",
+			secret:      "Basic c2VjcmV0",
+			wantErr:     false,
+		},
+		{
+			name:        "missing Authorization header when secret configured",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			secret:      "Basic c2VjcmV0",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewACRParser("", tt.secret)
+			req := httptest.NewRequest(tt.method, "/webhook", bytes.NewBufferString("{}"))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			err := parser.Validate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestACRParser_RegistryType(t *testing.T) {
+	parser := NewACRParser("", "")
+	if got := parser.RegistryType(); got != "acr" {
+		t.Errorf("RegistryType() = %v, want acr", got)
+	}
+}
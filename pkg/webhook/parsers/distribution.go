@@ -0,0 +1,176 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// distributionEventsContentType is the content type self-hosted
+// registries implementing the distribution spec (CNCF Distribution,
+// Harbor's underlying registry, GHCR-compatible servers) send their
+// notifications as.
+const distributionEventsContentType = "application/vnd.docker.distribution.events.v1+json"
+
+// manifestMediaTypes are the target.mediaType values DistributionParser
+// treats as a manifest push worth scanning, as opposed to the individual
+// layer/blob pushes that make up an image and arrive as their own events.
+var manifestMediaTypes = map[string]bool{
+	"application/vnd.docker.distribution.manifest.v1+json":      true,
+	"application/vnd.docker.distribution.manifest.v1+prettyjws": true,
+	"application/vnd.docker.distribution.manifest.v2+json":      true,
+	"application/vnd.docker.distribution.manifest.list.v2+json": true,
+	"application/vnd.oci.image.manifest.v1+json":                true,
+	"application/vnd.oci.image.index.v1+json":                   true,
+}
+
+// DistributionParser parses the native Docker Distribution notification
+// webhook payload emitted by self-hosted registries implementing the
+// distribution spec directly, as opposed to a higher-level product like
+// Docker Hub or Harbor's own webhook format.
+type DistributionParser struct {
+	registryURL string
+}
+
+// NewDistributionParser creates a new Distribution notification parser.
+func NewDistributionParser(registryURL string) *DistributionParser {
+	return &DistributionParser{
+		registryURL: registryURL,
+	}
+}
+
+func init() {
+	Register("distribution", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewDistributionParser(cfg.URL), nil
+	})
+}
+
+// RegistryType returns the registry type this parser handles
+func (p *DistributionParser) RegistryType() string {
+	return "distribution"
+}
+
+// Validate checks if the webhook payload is valid
+func (p *DistributionParser) Validate(r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return fmt.Errorf("invalid HTTP method: %s", r.Method)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != distributionEventsContentType {
+		return fmt.Errorf("invalid content type: %s", contentType)
+	}
+
+	return nil
+}
+
+// Parse extracts scan requests from a Docker Distribution notification
+// payload, emitting one ScanRequest per "push" event whose target is a
+// manifest (ignoring layer/blob pushes and pull/delete/mount actions),
+// and deduplicating events that share the same digest within the
+// payload.
+func (p *DistributionParser) Parse(r *http.Request) ([]*models.ScanRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	var payload DistributionNotification
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	fallbackHost := strings.TrimPrefix(strings.TrimPrefix(p.registryURL, "https://"), "http://")
+
+	seenDigests := make(map[string]bool)
+	var scanRequests []*models.ScanRequest
+
+	for _, event := range payload.Events {
+		if event.Action != "push" {
+			continue
+		}
+		if !manifestMediaTypes[event.Target.MediaType] {
+			continue
+		}
+		if event.Target.Digest != "" {
+			if seenDigests[event.Target.Digest] {
+				continue
+			}
+			seenDigests[event.Target.Digest] = true
+		}
+
+		if event.Target.Repository == "" {
+			return nil, fmt.Errorf("missing target repository in push event")
+		}
+
+		host := event.Request.Host
+		if host == "" {
+			host = fallbackHost
+		}
+		if host == "" {
+			host = "distribution.local"
+		}
+
+		var imageRef string
+		if event.Target.Tag != "" {
+			imageRef = fmt.Sprintf("%s/%s:%s", host, event.Target.Repository, event.Target.Tag)
+		} else {
+			imageRef = fmt.Sprintf("%s/%s@%s", host, event.Target.Repository, event.Target.Digest)
+		}
+
+		scanRequests = append(scanRequests, &models.ScanRequest{
+			ImageRef:     imageRef,
+			RegistryName: "distribution",
+			Registry:     host,
+			Repository:   event.Target.Repository,
+			Tag:          event.Target.Tag,
+			Digest:       event.Target.Digest,
+			ReceivedAt:   time.Now(),
+			RequestID:    generateRequestID(r),
+		})
+	}
+
+	if len(scanRequests) == 0 {
+		return nil, fmt.Errorf("no push events with manifest media type found")
+	}
+
+	return scanRequests, nil
+}
+
+// DistributionNotification represents the
+// "application/vnd.docker.distribution.events.v1+json" notification
+// envelope.
+type DistributionNotification struct {
+	Events []DistributionEvent `json:"events"`
+}
+
+// DistributionEvent is a single event in a DistributionNotification.
+type DistributionEvent struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"` // push, pull, delete, mount
+	Target    struct {
+		MediaType  string `json:"mediaType"`
+		Digest     string `json:"digest"`
+		Repository string `json:"repository"`
+		URL        string `json:"url"`
+		Tag        string `json:"tag"`
+		Size       int64  `json:"size"`
+	} `json:"target"`
+	Request struct {
+		ID        string `json:"id"`
+		Addr      string `json:"addr"`
+		Host      string `json:"host"`
+		Method    string `json:"method"`
+		UserAgent string `json:"useragent"`
+	} `json:"request"`
+	Actor struct {
+		Name string `json:"name"`
+	} `json:"actor"`
+}
@@ -0,0 +1,250 @@
+package parsers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDistributionParser_Parse(t *testing.T) {
+	parser := NewDistributionParser("")
+
+	tests := []struct {
+		name        string
+		payload     string
+		wantCount   int
+		wantRepo    string
+		wantTag     string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "push event with manifest",
+			payload: `{
+				"events": [
+					{
+						"id": "event-1",
+						"action": "push",
+						"target": {
+							"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+							"digest": "sha256:abc123",
+							"repository": "myapp",
+							"tag": "v1.0.0"
+						},
+						"request": {"host": "registry.example.com"}
+					}
+				]
+			}`,
+			wantCount: 1,
+			wantRepo:  "myapp",
+			wantTag:   "v1.0.0",
+			wantErr:   false,
+		},
+		{
+			name: "manifest list push",
+			payload: `{
+				"events": [
+					{
+						"action": "push",
+						"target": {
+							"mediaType": "application/vnd.docker.distribution.manifest.list.v2+json",
+							"digest": "sha256:list123",
+							"repository": "myapp",
+							"tag": "v1.0.0"
+						},
+						"request": {"host": "registry.example.com"}
+					}
+				]
+			}`,
+			wantCount: 1,
+			wantRepo:  "myapp",
+			wantTag:   "v1.0.0",
+			wantErr:   false,
+		},
+		{
+			name: "ignores layer blob push",
+			payload: `{
+				"events": [
+					{
+						"action": "push",
+						"target": {
+							"mediaType": "application/octet-stream",
+							"digest": "sha256:layer1",
+							"repository": "myapp",
+							"tag": "v1.0.0"
+						},
+						"request": {"host": "registry.example.com"}
+					}
+				]
+			}`,
+			wantErr:     true,
+			errContains: "no push events",
+		},
+		{
+			name: "ignores pull action",
+			payload: `{
+				"events": [
+					{
+						"action": "pull",
+						"target": {
+							"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+							"digest": "sha256:abc123",
+							"repository": "myapp",
+							"tag": "v1.0.0"
+						},
+						"request": {"host": "registry.example.com"}
+					}
+				]
+			}`,
+			wantErr:     true,
+			errContains: "no push events",
+		},
+		{
+			name: "dedupes events sharing a digest",
+			payload: `{
+				"events": [
+					{
+						"action": "push",
+						"target": {
+							"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+							"digest": "sha256:abc123",
+							"repository": "myapp",
+							"tag": "v1.0.0"
+						},
+						"request": {"host": "registry.example.com"}
+					},
+					{
+						"action": "push",
+						"target": {
+							"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+							"digest": "sha256:abc123",
+							"repository": "myapp",
+							"tag": "latest"
+						},
+						"request": {"host": "registry.example.com"}
+					}
+				]
+			}`,
+			wantCount: 1,
+			wantRepo:  "myapp",
+			wantTag:   "v1.0.0",
+			wantErr:   false,
+		},
+		{
+			name: "no tag falls back to digest reference",
+			payload: `{
+				"events": [
+					{
+						"action": "push",
+						"target": {
+							"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+							"digest": "sha256:abc123",
+							"repository": "myapp"
+						},
+						"request": {"host": "registry.example.com"}
+					}
+				]
+			}`,
+			wantCount: 1,
+			wantRepo:  "myapp",
+			wantTag:   "",
+			wantErr:   false,
+		},
+		{
+			name:        "invalid JSON",
+			payload:     `{invalid json}`,
+			wantErr:     true,
+			errContains: "failed to parse JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", distributionEventsContentType)
+
+			requests, err := parser.Parse(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Parse() error = %v, want error containing %v", err, tt.errContains)
+				}
+				return
+			}
+
+			if len(requests) != tt.wantCount {
+				t.Errorf("Parse() returned %d requests, want %d", len(requests), tt.wantCount)
+				return
+			}
+
+			if tt.wantCount > 0 {
+				req := requests[0]
+				if req.Repository != tt.wantRepo {
+					t.Errorf("Parse() repository = %v, want %v", req.Repository, tt.wantRepo)
+				}
+				if req.Tag != tt.wantTag {
+					t.Errorf("Parse() tag = %v, want %v", req.Tag, tt.wantTag)
+				}
+				if req.Registry != "registry.example.com" {
+					t.Errorf("Parse() registry = %v, want registry.example.com", req.Registry)
+				}
+			}
+		})
+	}
+}
+
+func TestDistributionParser_Validate(t *testing.T) {
+	parser := NewDistributionParser("")
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		wantErr     bool
+	}{
+		{
+			name:        "valid POST with distribution events content type",
+			method:      http.MethodPost,
+			contentType: distributionEventsContentType,
+			wantErr:     false,
+		},
+		{
+			name:        "invalid method GET",
+			method:      http.MethodGet,
+			contentType: distributionEventsContentType,
+			wantErr:     true,
+		},
+		{
+			name:        "invalid content type",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/webhook", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			err := parser.Validate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDistributionParser_RegistryType(t *testing.T) {
+	parser := NewDistributionParser("")
+	if got := parser.RegistryType(); got != "distribution" {
+		t.Errorf("RegistryType() = %v, want distribution", got)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
 )
 
 // DockerHubParser parses Docker Hub webhook payloads
@@ -18,6 +20,12 @@ func NewDockerHubParser() *DockerHubParser {
 	return &DockerHubParser{}
 }
 
+func init() {
+	Register("dockerhub", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewDockerHubParser(), nil
+	})
+}
+
 // RegistryType returns the registry type this parser handles
 func (p *DockerHubParser) RegistryType() string {
 	return "dockerhub"
@@ -59,14 +67,19 @@ func (p *DockerHubParser) Parse(r *http.Request) ([]*models.ScanRequest, error)
 	}
 
 	// Create scan request
+	ref := Reference{Domain: "docker.io", Path: payload.Repository.RepoName, Tag: payload.PushData.Tag}
+	if !strings.Contains(ref.Path, "/") {
+		ref.Path = "library/" + ref.Path
+	}
+
 	scanRequest := &models.ScanRequest{
-		ImageRef:     fmt.Sprintf("%s:%s", payload.Repository.RepoName, payload.PushData.Tag),
+		ImageRef:     ref.Familiar(),
 		RegistryName: "dockerhub",
 		Registry:     "docker.io",
 		Repository:   payload.Repository.RepoName,
 		Tag:          payload.PushData.Tag,
 		ReceivedAt:   time.Now(),
-		RequestID:    generateRequestID(),
+		RequestID:    generateRequestID(r),
 	}
 
 	return []*models.ScanRequest{scanRequest}, nil
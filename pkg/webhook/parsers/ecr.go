@@ -0,0 +1,158 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// ECRParser parses ECR "Image Action" events delivered via an SNS topic
+// subscribed to an EventBridge rule, the standard way to get ECR push
+// notifications to an HTTP endpoint.
+//
+// SNS notifications are themselves signed (Signature/SigningCertURL),
+// but verifying that signature means fetching and caching AWS's signing
+// certificate over the network, which nothing in this package does
+// today; ECRParser doesn't accept a webhook secret for that reason; SNS
+// authenticity should instead be enforced at the network layer (e.g. an
+// IP allowlist or a reverse-proxy shared secret in front of this
+// endpoint) until that's built out.
+type ECRParser struct {
+	registryURL string
+}
+
+// NewECRParser creates a new ECR parser.
+func NewECRParser(registryURL string) *ECRParser {
+	return &ECRParser{registryURL: registryURL}
+}
+
+func init() {
+	Register("ecr", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewECRParser(cfg.URL), nil
+	})
+}
+
+// RegistryType returns the registry type this parser handles
+func (p *ECRParser) RegistryType() string {
+	return "ecr"
+}
+
+// Validate checks if the webhook payload is valid
+func (p *ECRParser) Validate(r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return fmt.Errorf("invalid HTTP method: %s", r.Method)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "text/plain") && !strings.HasPrefix(contentType, "application/json") {
+		return fmt.Errorf("invalid content type: %s", contentType)
+	}
+
+	return nil
+}
+
+// Parse extracts scan requests from an SNS-delivered ECR "Image Action"
+// event, emitting a ScanRequest for PUSH actions that succeeded.
+func (p *ECRParser) Parse(r *http.Request) ([]*models.ScanRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	var envelope SNSEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if envelope.Type != "Notification" {
+		return nil, fmt.Errorf("unsupported SNS message type: %s", envelope.Type)
+	}
+
+	var event ECREventBridgeEvent
+	if err := json.Unmarshal([]byte(envelope.Message), &event); err != nil {
+		return nil, fmt.Errorf("failed to parse embedded EventBridge event: %w", err)
+	}
+
+	if event.DetailType != "ECR Image Action" {
+		return nil, fmt.Errorf("unsupported detail-type: %s", event.DetailType)
+	}
+
+	if event.Detail.ActionType != "PUSH" {
+		return nil, fmt.Errorf("unsupported action-type: %s", event.Detail.ActionType)
+	}
+
+	if event.Detail.Result != "SUCCESS" {
+		return nil, fmt.Errorf("ignoring non-successful push: %s", event.Detail.Result)
+	}
+
+	if event.Detail.RepositoryName == "" {
+		return nil, fmt.Errorf("missing repository name")
+	}
+
+	registryHost := p.registryURL
+	if registryHost == "" {
+		registryHost = fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com", event.Account, event.Region)
+	}
+	registryHost = strings.TrimPrefix(registryHost, "https://")
+	registryHost = strings.TrimPrefix(registryHost, "http://")
+
+	var imageRef string
+	if event.Detail.ImageTag != "" {
+		imageRef = fmt.Sprintf("%s/%s:%s", registryHost, event.Detail.RepositoryName, event.Detail.ImageTag)
+	} else {
+		imageRef = fmt.Sprintf("%s/%s@%s", registryHost, event.Detail.RepositoryName, event.Detail.ImageDigest)
+	}
+
+	scanRequest := &models.ScanRequest{
+		ImageRef:     imageRef,
+		RegistryName: "ecr",
+		Registry:     registryHost,
+		Repository:   event.Detail.RepositoryName,
+		Tag:          event.Detail.ImageTag,
+		Digest:       event.Detail.ImageDigest,
+		ReceivedAt:   time.Now(),
+		RequestID:    generateRequestID(r),
+	}
+
+	return []*models.ScanRequest{scanRequest}, nil
+}
+
+// SNSEnvelope represents the envelope SNS wraps every delivered message
+// in. Message holds the raw JSON text of the underlying event, not a
+// nested object, so it's unmarshaled separately.
+type SNSEnvelope struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	TopicArn         string `json:"TopicArn"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+}
+
+// ECREventBridgeEvent represents the EventBridge event ECR emits for a
+// repository image action, JSON-encoded inside an SNSEnvelope's Message.
+type ECREventBridgeEvent struct {
+	Version    string   `json:"version"`
+	ID         string   `json:"id"`
+	DetailType string   `json:"detail-type"`
+	Source     string   `json:"source"`
+	Account    string   `json:"account"`
+	Time       string   `json:"time"`
+	Region     string   `json:"region"`
+	Resources  []string `json:"resources"`
+	Detail     struct {
+		ActionType     string `json:"action-type"`
+		Result         string `json:"result"`
+		RepositoryName string `json:"repository-name"`
+		ImageDigest    string `json:"image-digest"`
+		ImageTag       string `json:"image-tag"`
+	} `json:"detail"`
+}
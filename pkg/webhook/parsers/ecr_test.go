@@ -0,0 +1,169 @@
+package parsers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestECRParser_Parse(t *testing.T) {
+	parser := NewECRParser("123456789012.dkr.ecr.us-east-1.amazonaws.com")
+
+	tests := []struct {
+		name        string
+		payload     string
+		wantCount   int
+		wantRepo    string
+		wantTag     string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "valid ECR image push notification",
+			payload: `{
+				"Type": "Notification",
+				"MessageId": "msg-1",
+				"Message": "{\"version\":\"0\",\"id\":\"evt-1\",\"detail-type\":\"ECR Image Action\",\"source\":\"aws.ecr\",\"account\":\"123456789012\",\"time\":\"2024-01-01T00:00:00Z\",\"region\":\"us-east-1\",\"resources\":[],\"detail\":{\"action-type\":\"PUSH\",\"result\":\"SUCCESS\",\"repository-name\":\"myapp\",\"image-digest\":\"sha256:abc123\",\"image-tag\":\"latest\"}}"
+			}`,
+			wantCount: 1,
+			wantRepo:  "myapp",
+			wantTag:   "latest",
+			wantErr:   false,
+		},
+		{
+			name: "ignores non-PUSH action",
+			payload: `{
+				"Type": "Notification",
+				"Message": "{\"detail-type\":\"ECR Image Action\",\"detail\":{\"action-type\":\"DELETE\",\"result\":\"SUCCESS\",\"repository-name\":\"myapp\"}}"
+			}`,
+			wantErr:     true,
+			errContains: "unsupported action-type",
+		},
+		{
+			name: "ignores failed push",
+			payload: `{
+				"Type": "Notification",
+				"Message": "{\"detail-type\":\"ECR Image Action\",\"detail\":{\"action-type\":\"PUSH\",\"result\":\"FAILURE\",\"repository-name\":\"myapp\"}}"
+			}`,
+			wantErr:     true,
+			errContains: "non-successful push",
+		},
+		{
+			name: "unsupported detail-type",
+			payload: `{
+				"Type": "Notification",
+				"Message": "{\"detail-type\":\"ECR Image Scan\",\"detail\":{\"action-type\":\"PUSH\"}}"
+			}`,
+			wantErr:     true,
+			errContains: "unsupported detail-type",
+		},
+		{
+			name:        "unsupported SNS message type",
+			payload:     `{"Type": "SubscriptionConfirmation"}`,
+			wantErr:     true,
+			errContains: "unsupported SNS message type",
+		},
+		{
+			name:        "invalid JSON",
+			payload:     `{invalid json}`,
+			wantErr:     true,
+			errContains: "failed to parse JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			requests, err := parser.Parse(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Parse() error = %v, want error containing %v", err, tt.errContains)
+				}
+				return
+			}
+
+			if len(requests) != tt.wantCount {
+				t.Errorf("Parse() returned %d requests, want %d", len(requests), tt.wantCount)
+				return
+			}
+
+			if tt.wantCount > 0 {
+				req := requests[0]
+				if req.Repository != tt.wantRepo {
+					t.Errorf("Parse() repository = %v, want %v", req.Repository, tt.wantRepo)
+				}
+				if req.Tag != tt.wantTag {
+					t.Errorf("Parse() tag = %v, want %v", req.Tag, tt.wantTag)
+				}
+				if req.Registry != "123456789012.dkr.ecr.us-east-1.amazonaws.com" {
+					t.Errorf("Parse() registry = %v, want 123456789012.dkr.ecr.us-east-1.amazonaws.com", req.Registry)
+				}
+			}
+		})
+	}
+}
+
+func TestECRParser_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		wantErr     bool
+	}{
+		{
+			name:        "valid POST with SNS text/plain content type",
+			method:      http.MethodPost,
+			contentType: "text/plain; charset=UTF-8",
+			wantErr:     false,
+		},
+		{
+			name:        "valid POST with JSON content type",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			wantErr:     false,
+		},
+		{
+			name:        "invalid method GET",
+			method:      http.MethodGet,
+			contentType: "text/plain",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid content type",
+			method:      http.MethodPost,
+			contentType: "application/xml",
+			wantErr:     true,
+		},
+	}
+
+	parser := NewECRParser("")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/webhook", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			err := parser.Validate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestECRParser_RegistryType(t *testing.T) {
+	parser := NewECRParser("")
+	if got := parser.RegistryType(); got != "ecr" {
+		t.Errorf("RegistryType() = %v, want ecr", got)
+	}
+}
@@ -0,0 +1,91 @@
+package parsers
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// ParserFactory constructs a models.WebhookParser for a registry type
+// from that registry's config.RegistryConfig. Most parsers only look at
+// cfg.URL and cfg.WebhookSecret, ignoring fields (e.g. the auth header
+// Harbor parses, or credentials ACR/GCR validate) that don't apply to
+// them.
+type ParserFactory func(cfg config.RegistryConfig) (models.WebhookParser, error)
+
+// Registry maps registry-type strings ("dockerhub", "gitlab", "harbor",
+// ...) to the ParserFactory that builds a parser for them, populated via
+// Register so a new registry integration can add a parser without editing
+// the webhook handler. A handler looks up the registry type from an
+// inbound "?registry=" query param or path segment and passes it to New.
+// Built-in parsers register themselves in their own init(), the way
+// database/sql drivers do.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]ParserFactory
+}
+
+// defaultRegistry is the process-wide Registry built-in parsers register
+// themselves into via the package-level Register.
+var defaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]ParserFactory)}
+}
+
+// Register adds factory under registryType to the default Registry.
+// Intended to be called from a parser's init(), so built-in parsers are
+// available without explicit wiring. Panics on a duplicate registryType,
+// since that indicates two parsers registering for the same type, not a
+// runtime condition a caller can recover from.
+func Register(registryType string, factory ParserFactory) {
+	defaultRegistry.Register(registryType, factory)
+}
+
+// Register adds factory under registryType to r.
+func (r *Registry) Register(registryType string, factory ParserFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[registryType]; exists {
+		panic(fmt.Sprintf("parsers: factory already registered for registry type %q", registryType))
+	}
+	r.factories[registryType] = factory
+}
+
+// New builds the parser registered for cfg.Type, passing it cfg.
+func (r *Registry) New(cfg config.RegistryConfig) (models.WebhookParser, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[cfg.Type]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no parser registered for registry type: %s", cfg.Type)
+	}
+
+	return factory(cfg)
+}
+
+// Types returns the registered registry-type strings in sorted order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.factories))
+	for t := range r.factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// Default returns the process-wide Registry that built-in parsers
+// register themselves into.
+func Default() *Registry {
+	return defaultRegistry
+}
@@ -0,0 +1,57 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+func TestRegistry_NewAndTypes(t *testing.T) {
+	r := NewRegistry()
+	r.Register("fake", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewHarborParser(cfg.URL, cfg.WebhookSecret), nil
+	})
+
+	parser, err := r.New(config.RegistryConfig{Type: "fake", URL: "harbor.example.com"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if parser.RegistryType() != "harbor" {
+		t.Errorf("New() built parser with RegistryType() = %v, want harbor", parser.RegistryType())
+	}
+
+	if got := r.Types(); len(got) != 1 || got[0] != "fake" {
+		t.Errorf("Types() = %v, want [fake]", got)
+	}
+}
+
+func TestRegistry_New_Unregistered(t *testing.T) {
+	r := NewRegistry()
+
+	if _, err := r.New(config.RegistryConfig{Type: "nonexistent"}); err == nil {
+		t.Error("New() expected error for unregistered registry type, got nil")
+	}
+}
+
+func TestRegistry_Register_DuplicatePanics(t *testing.T) {
+	r := NewRegistry()
+	r.Register("fake", func(config.RegistryConfig) (models.WebhookParser, error) { return nil, nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() expected panic on duplicate registryType, got none")
+		}
+	}()
+	r.Register("fake", func(config.RegistryConfig) (models.WebhookParser, error) { return nil, nil })
+}
+
+func TestDefaultRegistry_HasBuiltinParsers(t *testing.T) {
+	want := []string{"dockerhub", "gitlab", "harbor", "distribution", "quay", "ghcr", "ecr", "acr", "gcr"}
+
+	for _, registryType := range want {
+		if _, err := Default().New(config.RegistryConfig{Type: registryType, URL: "registry.example.com"}); err != nil {
+			t.Errorf("Default().New(%q) error = %v, want built-in parser", registryType, err)
+		}
+	}
+}
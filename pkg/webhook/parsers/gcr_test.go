@@ -0,0 +1,161 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func pubSubEnvelope(notification string) string {
+	data := base64.StdEncoding.EncodeToString([]byte(notification))
+	return fmt.Sprintf(`{"message": {"data": %q, "messageId": "msg-1"}, "subscription": "projects/p/subscriptions/s"}`, data)
+}
+
+func TestGCRParser_Parse(t *testing.T) {
+	parser := NewGCRParser("")
+
+	tests := []struct {
+		name        string
+		notify      string
+		wantCount   int
+		wantRepo    string
+		wantTag     string
+		wantReg     string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:      "valid INSERT with tag",
+			notify:    `{"action":"INSERT","digest":"gcr.io/myproj/myapp@sha256:abc123","tag":"gcr.io/myproj/myapp:latest"}`,
+			wantCount: 1,
+			wantRepo:  "myproj/myapp",
+			wantTag:   "latest",
+			wantReg:   "gcr.io",
+			wantErr:   false,
+		},
+		{
+			name:   "ignores DELETE action",
+			notify: `{"action":"DELETE","digest":"gcr.io/myproj/myapp@sha256:abc123"}`,
+
+			wantErr:     true,
+			errContains: "unsupported action",
+		},
+		{
+			name:        "missing tag and digest",
+			notify:      `{"action":"INSERT"}`,
+			wantErr:     true,
+			errContains: "missing tag and digest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(pubSubEnvelope(tt.notify)))
+			req.Header.Set("Content-Type", "application/json")
+
+			requests, err := parser.Parse(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Parse() error = %v, want error containing %v", err, tt.errContains)
+				}
+				return
+			}
+
+			if len(requests) != tt.wantCount {
+				t.Errorf("Parse() returned %d requests, want %d", len(requests), tt.wantCount)
+				return
+			}
+
+			if tt.wantCount > 0 {
+				req := requests[0]
+				if req.Repository != tt.wantRepo {
+					t.Errorf("Parse() repository = %v, want %v", req.Repository, tt.wantRepo)
+				}
+				if req.Tag != tt.wantTag {
+					t.Errorf("Parse() tag = %v, want %v", req.Tag, tt.wantTag)
+				}
+				if req.Registry != tt.wantReg {
+					t.Errorf("Parse() registry = %v, want %v", req.Registry, tt.wantReg)
+				}
+			}
+		})
+	}
+}
+
+func TestGCRParser_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		authHeader  string
+		secret      string
+		wantErr     bool
+	}{
+		{
+			name:        "valid POST with JSON content type",
+			method:      http.MethodPost,
+			contentType: "application/json; charset=UTF-8",
+			wantErr:     false,
+		},
+		{
+			name:        "invalid method GET",
+			method:      http.MethodGet,
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid content type",
+			method:      http.MethodPost,
+			contentType: "application/xml",
+			wantErr:     true,
+		},
+		{
+			name:        "matching bearer token",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			authHeader:  "Bearer mytoken",
+			secret:      "mytoken",
+			wantErr:     false,
+		},
+		{
+			name:        "missing Authorization header when secret configured",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			secret:      "mytoken",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewGCRParser(tt.secret)
+			req := httptest.NewRequest(tt.method, "/webhook", bytes.NewBufferString("{}"))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			err := parser.Validate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGCRParser_RegistryType(t *testing.T) {
+	parser := NewGCRParser("")
+	if got := parser.RegistryType(); got != "gcr" {
+		t.Errorf("RegistryType() = %v, want gcr", got)
+	}
+}
@@ -0,0 +1,152 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// ghcrSignatureHeader is the header GitHub signs "package" webhook
+// deliveries in, the same X-Hub-Signature-256 convention used by every
+// GitHub App/webhook event.
+const ghcrSignatureHeader = "X-Hub-Signature-256"
+
+// ghcrSignaturePrefix precedes the hex digest in ghcrSignatureHeader.
+const ghcrSignaturePrefix = "sha256="
+
+// GHCRParser parses GitHub Container Registry "package" webhook events.
+type GHCRParser struct {
+	registryURL string
+	verifier    SignatureVerifier
+}
+
+// NewGHCRParser creates a new GHCR parser. If webhookSecret is
+// non-empty, Validate requires an X-Hub-Signature-256 header matching an
+// HMAC-SHA256 of the request body keyed by webhookSecret.
+func NewGHCRParser(registryURL, webhookSecret string) *GHCRParser {
+	p := &GHCRParser{registryURL: registryURL}
+	if webhookSecret != "" {
+		p.verifier = &HMACSHA256Verifier{Header: ghcrSignatureHeader, Secret: webhookSecret, Prefix: ghcrSignaturePrefix}
+	}
+	return p
+}
+
+func init() {
+	Register("ghcr", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewGHCRParser(cfg.URL, cfg.WebhookSecret), nil
+	})
+}
+
+// RegistryType returns the registry type this parser handles
+func (p *GHCRParser) RegistryType() string {
+	return "ghcr"
+}
+
+// Validate checks if the webhook payload is valid
+func (p *GHCRParser) Validate(r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return fmt.Errorf("invalid HTTP method: %s", r.Method)
+	}
+
+	if r.Header.Get("X-GitHub-Event") != "package" {
+		return fmt.Errorf("unsupported event type: %s", r.Header.Get("X-GitHub-Event"))
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		return fmt.Errorf("invalid content type: %s", contentType)
+	}
+
+	if p.verifier != nil {
+		body, err := readAndRestoreBody(r)
+		if err != nil {
+			return err
+		}
+		if err := p.verifier.Verify(r, body); err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Parse extracts scan requests from a GHCR "package" webhook event.
+func (p *GHCRParser) Parse(r *http.Request) ([]*models.ScanRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	var payload GHCRWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if payload.Action != "published" && payload.Action != "updated" {
+		return nil, fmt.Errorf("unsupported package action: %s", payload.Action)
+	}
+
+	if payload.Package.PackageType != "container" {
+		return nil, fmt.Errorf("unsupported package type: %s", payload.Package.PackageType)
+	}
+
+	repository := payload.Repository.FullName
+	if repository == "" {
+		return nil, fmt.Errorf("missing repository")
+	}
+
+	tag := payload.Package.PackageVersion.ContainerMetadata.Tag.Name
+	if tag == "" {
+		return nil, fmt.Errorf("missing tag")
+	}
+
+	registryHost := p.registryURL
+	if registryHost == "" {
+		registryHost = "ghcr.io"
+	}
+	registryHost = strings.TrimPrefix(registryHost, "https://")
+	registryHost = strings.TrimPrefix(registryHost, "http://")
+
+	imageRef := fmt.Sprintf("%s/%s:%s", registryHost, strings.ToLower(repository), tag)
+
+	scanRequest := &models.ScanRequest{
+		ImageRef:     imageRef,
+		RegistryName: "ghcr",
+		Registry:     registryHost,
+		Repository:   strings.ToLower(repository),
+		Tag:          tag,
+		Digest:       payload.Package.PackageVersion.ContainerMetadata.Tag.Digest,
+		ReceivedAt:   time.Now(),
+		RequestID:    generateRequestID(r),
+	}
+
+	return []*models.ScanRequest{scanRequest}, nil
+}
+
+// GHCRWebhook represents the GitHub "package" webhook event payload for
+// a container package push to GHCR.
+type GHCRWebhook struct {
+	Action  string `json:"action"`
+	Package struct {
+		Name           string `json:"name"`
+		PackageType    string `json:"package_type"`
+		PackageVersion struct {
+			Version           string `json:"version"`
+			ContainerMetadata struct {
+				Tag struct {
+					Name   string `json:"name"`
+					Digest string `json:"digest"`
+				} `json:"tag"`
+			} `json:"container_metadata"`
+		} `json:"package_version"`
+	} `json:"package"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
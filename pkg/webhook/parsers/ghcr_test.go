@@ -0,0 +1,225 @@
+package parsers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGHCRParser_Parse(t *testing.T) {
+	parser := NewGHCRParser("ghcr.io", "")
+
+	tests := []struct {
+		name        string
+		payload     string
+		wantCount   int
+		wantRepo    string
+		wantTag     string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "valid package published event",
+			payload: `{
+				"action": "published",
+				"package": {
+					"name": "myapp",
+					"package_type": "container",
+					"package_version": {
+						"version": "sha256:abc123",
+						"container_metadata": {
+							"tag": {"name": "latest", "digest": "sha256:abc123"}
+						}
+					}
+				},
+				"repository": {"full_name": "MyOrg/myapp"}
+			}`,
+			wantCount: 1,
+			wantRepo:  "myorg/myapp",
+			wantTag:   "latest",
+			wantErr:   false,
+		},
+		{
+			name: "unsupported action",
+			payload: `{
+				"action": "deleted",
+				"package": {"package_type": "container"},
+				"repository": {"full_name": "myorg/myapp"}
+			}`,
+			wantErr:     true,
+			errContains: "unsupported package action",
+		},
+		{
+			name: "unsupported package type",
+			payload: `{
+				"action": "published",
+				"package": {"package_type": "npm"},
+				"repository": {"full_name": "myorg/myapp"}
+			}`,
+			wantErr:     true,
+			errContains: "unsupported package type",
+		},
+		{
+			name: "missing tag",
+			payload: `{
+				"action": "published",
+				"package": {"package_type": "container", "package_version": {"container_metadata": {"tag": {"name": ""}}}},
+				"repository": {"full_name": "myorg/myapp"}
+			}`,
+			wantErr:     true,
+			errContains: "missing tag",
+		},
+		{
+			name:        "invalid JSON",
+			payload:     `{invalid json}`,
+			wantErr:     true,
+			errContains: "failed to parse JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			requests, err := parser.Parse(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Parse() error = %v, want error containing %v", err, tt.errContains)
+				}
+				return
+			}
+
+			if len(requests) != tt.wantCount {
+				t.Errorf("Parse() returned %d requests, want %d", len(requests), tt.wantCount)
+				return
+			}
+
+			if tt.wantCount > 0 {
+				req := requests[0]
+				if req.Repository != tt.wantRepo {
+					t.Errorf("Parse() repository = %v, want %v", req.Repository, tt.wantRepo)
+				}
+				if req.Tag != tt.wantTag {
+					t.Errorf("Parse() tag = %v, want %v", req.Tag, tt.wantTag)
+				}
+				if req.Registry != "ghcr.io" {
+					t.Errorf("Parse() registry = %v, want ghcr.io", req.Registry)
+				}
+			}
+		})
+	}
+}
+
+func TestGHCRParser_Validate(t *testing.T) {
+	const payload = `{"action":"published","package":{"package_type":"container"},"repository":{"full_name":"myorg/myapp"}}`
+
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+		return ghcrSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name        string
+		secret      string
+		method      string
+		contentType string
+		event       string
+		signature   string
+		wantErr     bool
+	}{
+		{
+			name:        "valid POST with JSON, no secret configured",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			event:       "package",
+			wantErr:     false,
+		},
+		{
+			name:        "invalid method GET",
+			method:      http.MethodGet,
+			contentType: "application/json",
+			event:       "package",
+			wantErr:     true,
+		},
+		{
+			name:        "wrong event type",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			event:       "ping",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid content type",
+			method:      http.MethodPost,
+			contentType: "text/plain",
+			event:       "package",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, signature missing",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			event:       "package",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, signature mismatch",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			event:       "package",
+			signature:   ghcrSignaturePrefix + "deadbeef",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, signature matches",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			event:       "package",
+			signature:   sign("shh"),
+			wantErr:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewGHCRParser("ghcr.io", tt.secret)
+
+			req := httptest.NewRequest(tt.method, "/webhook", bytes.NewBufferString(payload))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.event != "" {
+				req.Header.Set("X-GitHub-Event", tt.event)
+			}
+			if tt.signature != "" {
+				req.Header.Set(ghcrSignatureHeader, tt.signature)
+			}
+
+			err := parser.Validate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGHCRParser_RegistryType(t *testing.T) {
+	parser := NewGHCRParser("ghcr.io", "")
+	if got := parser.RegistryType(); got != "ghcr" {
+		t.Errorf("RegistryType() = %v, want ghcr", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
 )
 
 // GitLabParser parses GitLab Container Registry webhook payloads
@@ -23,6 +24,12 @@ func NewGitLabParser(registryURL string) *GitLabParser {
 	}
 }
 
+func init() {
+	Register("gitlab", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewGitLabParser(cfg.URL), nil
+	})
+}
+
 // RegistryType returns the registry type this parser handles
 func (p *GitLabParser) RegistryType() string {
 	return "gitlab"
@@ -93,7 +100,7 @@ func (p *GitLabParser) Parse(r *http.Request) ([]*models.ScanRequest, error) {
 			Repository:   payload.Project.PathWithNamespace,
 			Tag:          tag,
 			ReceivedAt:   time.Now(),
-			RequestID:    generateRequestID(),
+			RequestID:    generateRequestID(r),
 		}
 
 		scanRequests = append(scanRequests, scanRequest)
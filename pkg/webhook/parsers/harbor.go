@@ -9,18 +9,51 @@ import (
 	"time"
 
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
 )
 
+// harborSignatureHeader is the header Harbor sends an HMAC-SHA256 of the
+// raw payload in, when the webhook endpoint's "Auth Header" is configured
+// with a secret.
+const harborSignatureHeader = "X-Harbor-Signature"
+
 // HarborParser parses Harbor webhook payloads
 type HarborParser struct {
-	registryURL string
+	registryURL        string
+	verifier           SignatureVerifier
+	authHeaderVerifier SignatureVerifier
+}
+
+// NewHarborParser creates a new Harbor parser. If webhookSecret is
+// non-empty, Validate requires an X-Harbor-Signature header matching an
+// HMAC-SHA256 of the request body keyed by webhookSecret. Use
+// WithAuthHeader to additionally (or instead) require Harbor's "Auth
+// Header" value verbatim, since Harbor's webhook policy UI lets an
+// operator configure either scheme.
+func NewHarborParser(registryURL, webhookSecret string) *HarborParser {
+	p := &HarborParser{registryURL: registryURL}
+	if webhookSecret != "" {
+		p.verifier = &HMACSHA256Verifier{Header: harborSignatureHeader, Secret: webhookSecret}
+	}
+	return p
 }
 
-// NewHarborParser creates a new Harbor parser
-func NewHarborParser(registryURL string) *HarborParser {
-	return &HarborParser{
-		registryURL: registryURL,
+// WithAuthHeader configures p to additionally require that the inbound
+// request's Authorization header match authHeader verbatim (constant-time
+// compared), matching Harbor's "Auth Header" webhook policy setting. A
+// no-op if authHeader is empty. Returns p for chaining at construction
+// time.
+func (p *HarborParser) WithAuthHeader(authHeader string) *HarborParser {
+	if authHeader != "" {
+		p.authHeaderVerifier = &SharedSecretHeaderVerifier{Header: "Authorization", Secret: authHeader}
 	}
+	return p
+}
+
+func init() {
+	Register("harbor", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewHarborParser(cfg.URL, cfg.WebhookSecret).WithAuthHeader(cfg.WebhookAuthHeader), nil
+	})
 }
 
 // RegistryType returns the registry type this parser handles
@@ -39,6 +72,22 @@ func (p *HarborParser) Validate(r *http.Request) error {
 		return fmt.Errorf("invalid content type: %s", contentType)
 	}
 
+	if p.authHeaderVerifier != nil {
+		if err := p.authHeaderVerifier.Verify(r, nil); err != nil {
+			return fmt.Errorf("auth header verification failed: %w", err)
+		}
+	}
+
+	if p.verifier != nil {
+		body, err := readAndRestoreBody(r)
+		if err != nil {
+			return err
+		}
+		if err := p.verifier.Verify(r, body); err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -93,7 +142,7 @@ func (p *HarborParser) Parse(r *http.Request) ([]*models.ScanRequest, error) {
 			Tag:          tag.Tag,
 			Digest:       tag.Digest,
 			ReceivedAt:   time.Now(),
-			RequestID:    generateRequestID(),
+			RequestID:    generateRequestID(r),
 		}
 
 		scanRequests = append(scanRequests, scanRequest)
@@ -0,0 +1,246 @@
+package parsers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHarborParser_Parse(t *testing.T) {
+	parser := NewHarborParser("harbor.example.com", "")
+
+	tests := []struct {
+		name        string
+		payload     string
+		wantCount   int
+		wantRepo    string
+		wantTag     string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "valid PUSH_ARTIFACT event",
+			payload: `{
+				"type": "PUSH_ARTIFACT",
+				"event_data": {
+					"resources": [{"digest": "sha256:abc123", "tag": "v1.0.0"}],
+					"repository": {"name": "myproject/myapp"}
+				}
+			}`,
+			wantCount: 1,
+			wantRepo:  "myproject/myapp",
+			wantTag:   "v1.0.0",
+			wantErr:   false,
+		},
+		{
+			name: "unsupported event type",
+			payload: `{
+				"type": "DELETE_ARTIFACT",
+				"event_data": {
+					"resources": [{"digest": "sha256:abc123", "tag": "v1.0.0"}],
+					"repository": {"name": "myproject/myapp"}
+				}
+			}`,
+			wantErr:     true,
+			errContains: "unsupported event type",
+		},
+		{
+			name: "missing repository name",
+			payload: `{
+				"type": "PUSH_ARTIFACT",
+				"event_data": {
+					"resources": [{"digest": "sha256:abc123", "tag": "v1.0.0"}],
+					"repository": {"name": ""}
+				}
+			}`,
+			wantErr:     true,
+			errContains: "missing repository name",
+		},
+		{
+			name:        "invalid JSON",
+			payload:     `{invalid json}`,
+			wantErr:     true,
+			errContains: "failed to parse JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			requests, err := parser.Parse(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Parse() error = %v, want error containing %v", err, tt.errContains)
+				}
+				return
+			}
+
+			if len(requests) != tt.wantCount {
+				t.Errorf("Parse() returned %d requests, want %d", len(requests), tt.wantCount)
+				return
+			}
+
+			if tt.wantCount > 0 {
+				req := requests[0]
+				if req.Repository != tt.wantRepo {
+					t.Errorf("Parse() repository = %v, want %v", req.Repository, tt.wantRepo)
+				}
+				if req.Tag != tt.wantTag {
+					t.Errorf("Parse() tag = %v, want %v", req.Tag, tt.wantTag)
+				}
+				if req.Registry != "harbor.example.com" {
+					t.Errorf("Parse() registry = %v, want harbor.example.com", req.Registry)
+				}
+			}
+		})
+	}
+}
+
+func TestHarborParser_Validate_Signature(t *testing.T) {
+	const payload = `{"type":"PUSH_ARTIFACT","event_data":{"resources":[{"tag":"v1.0.0"}],"repository":{"name":"myproject/myapp"}}}`
+
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name        string
+		secret      string
+		method      string
+		contentType string
+		signature   string
+		wantErr     bool
+	}{
+		{
+			name:        "valid POST with JSON, no secret configured",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			wantErr:     false,
+		},
+		{
+			name:        "invalid method GET",
+			method:      http.MethodGet,
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid content type",
+			method:      http.MethodPost,
+			contentType: "text/plain",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, signature missing",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, signature mismatch",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			signature:   "deadbeef",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, signature matches",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			signature:   sign("shh"),
+			wantErr:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewHarborParser("harbor.example.com", tt.secret)
+
+			req := httptest.NewRequest(tt.method, "/webhook", bytes.NewBufferString(payload))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.signature != "" {
+				req.Header.Set(harborSignatureHeader, tt.signature)
+			}
+
+			err := parser.Validate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHarborParser_Validate_AuthHeader(t *testing.T) {
+	const payload = `{"type":"PUSH_ARTIFACT","event_data":{"resources":[{"tag":"v1.0.0"}],"repository":{"name":"myproject/myapp"}}}`
+
+	tests := []struct {
+		name       string
+		authHeader string
+		sent       string
+		wantErr    bool
+	}{
+		{
+			name:    "no auth header configured",
+			wantErr: false,
+		},
+		{
+			name:       "auth header configured, header missing",
+			authHeader: "s3cret-header",
+			wantErr:    true,
+		},
+		{
+			name:       "auth header configured, header mismatch",
+			authHeader: "s3cret-header",
+			sent:       "wrong",
+			wantErr:    true,
+		},
+		{
+			name:       "auth header configured, header matches",
+			authHeader: "s3cret-header",
+			sent:       "s3cret-header",
+			wantErr:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewHarborParser("harbor.example.com", "").WithAuthHeader(tt.authHeader)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(payload))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.sent != "" {
+				req.Header.Set("Authorization", tt.sent)
+			}
+
+			err := parser.Validate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHarborParser_RegistryType(t *testing.T) {
+	parser := NewHarborParser("harbor.example.com", "")
+	if got := parser.RegistryType(); got != "harbor" {
+		t.Errorf("RegistryType() = %v, want harbor", got)
+	}
+}
@@ -1,92 +1,64 @@
 package parsers
 
-import (
-	"fmt"
-	"strings"
-)
+import "strings"
 
-// NormalizeImageReference converts image references to a standard format
-// Format: [registry/]repository:tag[@digest]
+// NormalizeImageReference converts image reference components to a
+// standard format: "[registry/]repository:tag[@digest]", in the
+// same shortened form Reference.Familiar produces. An empty tag
+// defaults to "latest"; a digest with no "<algo>:" prefix is assumed to
+// be a sha256 hex digest.
 func NormalizeImageReference(registry, repository, tag, digest string) string {
-	var ref string
-
-	// Build base reference
-	if registry != "" && registry != "docker.io" {
-		ref = fmt.Sprintf("%s/%s", registry, repository)
-	} else {
-		ref = repository
+	domain, path := registry, repository
+	if domain == "" || domain == "docker.io" {
+		domain = "docker.io"
+		if !strings.Contains(path, "/") {
+			path = "library/" + path
+		}
 	}
 
-	// Add tag
-	if tag != "" {
-		ref = fmt.Sprintf("%s:%s", ref, tag)
-	} else {
-		ref = fmt.Sprintf("%s:latest", ref)
+	if tag == "" {
+		tag = "latest"
 	}
 
-	// Add digest if available
-	if digest != "" {
-		if !strings.HasPrefix(digest, "sha256:") {
-			digest = "sha256:" + digest
-		}
-		ref = fmt.Sprintf("%s@%s", ref, digest)
+	if digest != "" && !strings.Contains(digest, ":") {
+		digest = "sha256:" + digest
 	}
 
-	return ref
+	ref := Reference{Domain: domain, Path: path, Tag: tag, Digest: digest}
+	return ref.Familiar()
 }
 
-// ParseImageReference parses an image reference string into components
+// ParseImageReference parses an image reference string into its
+// registry, repository, tag and digest components, using the canonical
+// Reference grammar (see ParseReference). The repository and registry
+// returned are in Reference.Familiar's shortened form, e.g. "nginx"
+// rather than "library/nginx", matching what NormalizeImageReference
+// produces. An unparseable imageRef returns all-empty components rather
+// than an error, for callers that already validate separately via
+// ValidateImageReference.
 func ParseImageReference(imageRef string) (registry, repository, tag, digest string) {
-	// Handle digest
-	if strings.Contains(imageRef, "@") {
-		parts := strings.SplitN(imageRef, "@", 2)
-		imageRef = parts[0]
-		digest = parts[1]
+	ref, err := ParseReference(imageRef)
+	if err != nil {
+		return "", "", "", ""
 	}
 
-	// Handle tag
-	if strings.Contains(imageRef, ":") {
-		parts := strings.SplitN(imageRef, ":", 2)
-		imageRef = parts[0]
-		tag = parts[1]
-	} else {
-		tag = "latest"
+	registry = ref.Domain
+	repository = ref.Path
+	if registry == "docker.io" {
+		repository = strings.TrimPrefix(repository, "library/")
 	}
 
-	// Handle registry and repository
-	parts := strings.Split(imageRef, "/")
-	if len(parts) > 1 && (strings.Contains(parts[0], ".") || strings.Contains(parts[0], ":")) {
-		// First part looks like a registry (has . or :)
-		registry = parts[0]
-		repository = strings.Join(parts[1:], "/")
-	} else {
-		// No explicit registry, assume Docker Hub
-		registry = "docker.io"
-		repository = imageRef
+	tag = ref.Tag
+	if tag == "" && ref.Digest == "" {
+		tag = "latest"
 	}
 
-	return
+	return registry, repository, tag, ref.Digest
 }
 
-// ValidateImageReference checks if an image reference is valid
+// ValidateImageReference checks that imageRef parses as a well-formed
+// Reference.
 func ValidateImageReference(imageRef string) error {
-	if imageRef == "" {
-		return fmt.Errorf("image reference is empty")
-	}
-
-	registry, repository, tag, _ := ParseImageReference(imageRef)
-
-	if repository == "" {
-		return fmt.Errorf("repository is empty")
-	}
-
-	if tag == "" {
-		return fmt.Errorf("tag is empty")
-	}
-
-	if registry == "" {
-		return fmt.Errorf("registry is empty")
-	}
-
-	return nil
+	_, err := ParseReference(imageRef)
+	return err
 }
@@ -0,0 +1,134 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// quaySecretHeader is the header an operator configures Quay's webhook
+// notification to send, since Quay's "Webhook POST" notifications aren't
+// signed: the only way to authenticate the sender is a custom header
+// Quay is told to attach to every request, compared against the
+// registry's configured webhook secret.
+const quaySecretHeader = "X-Quay-Webhook-Secret"
+
+// QuayParser parses Quay.io "Repository Push" webhook notifications.
+type QuayParser struct {
+	registryURL string
+	verifier    SignatureVerifier
+}
+
+// NewQuayParser creates a new Quay parser. If webhookSecret is non-empty,
+// Validate requires an X-Quay-Webhook-Secret header matching it.
+func NewQuayParser(registryURL, webhookSecret string) *QuayParser {
+	p := &QuayParser{registryURL: registryURL}
+	if webhookSecret != "" {
+		p.verifier = &SharedSecretHeaderVerifier{Header: quaySecretHeader, Secret: webhookSecret}
+	}
+	return p
+}
+
+func init() {
+	Register("quay", func(cfg config.RegistryConfig) (models.WebhookParser, error) {
+		return NewQuayParser(cfg.URL, cfg.WebhookSecret), nil
+	})
+}
+
+// RegistryType returns the registry type this parser handles
+func (p *QuayParser) RegistryType() string {
+	return "quay"
+}
+
+// Validate checks if the webhook payload is valid
+func (p *QuayParser) Validate(r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return fmt.Errorf("invalid HTTP method: %s", r.Method)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/json" {
+		return fmt.Errorf("invalid content type: %s", contentType)
+	}
+
+	if p.verifier != nil {
+		body, err := readAndRestoreBody(r)
+		if err != nil {
+			return err
+		}
+		if err := p.verifier.Verify(r, body); err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Parse extracts scan requests from a Quay "Repository Push" webhook
+// notification.
+func (p *QuayParser) Parse(r *http.Request) ([]*models.ScanRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	var payload QuayWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if payload.Repository == "" {
+		return nil, fmt.Errorf("missing repository")
+	}
+
+	registryHost := p.registryURL
+	if registryHost == "" {
+		registryHost = "quay.io"
+	}
+	registryHost = strings.TrimPrefix(registryHost, "https://")
+	registryHost = strings.TrimPrefix(registryHost, "http://")
+
+	var scanRequests []*models.ScanRequest
+
+	// A single push can update more than one tag (e.g. "latest" and a
+	// version tag pushed together).
+	for _, tag := range payload.UpdatedTags {
+		if tag == "" {
+			continue
+		}
+
+		imageRef := fmt.Sprintf("%s/%s:%s", registryHost, payload.Repository, tag)
+
+		scanRequests = append(scanRequests, &models.ScanRequest{
+			ImageRef:     imageRef,
+			RegistryName: "quay",
+			Registry:     registryHost,
+			Repository:   payload.Repository,
+			Tag:          tag,
+			ReceivedAt:   time.Now(),
+			RequestID:    generateRequestID(r),
+		})
+	}
+
+	if len(scanRequests) == 0 {
+		return nil, fmt.Errorf("no updated tags found in webhook")
+	}
+
+	return scanRequests, nil
+}
+
+// QuayWebhook represents the Quay.io "Repository Push" webhook payload.
+type QuayWebhook struct {
+	Name        string   `json:"name"`
+	Repository  string   `json:"repository"`
+	Namespace   string   `json:"namespace"`
+	DockerURL   string   `json:"docker_url"`
+	Homepage    string   `json:"homepage"`
+	UpdatedTags []string `json:"updated_tags"`
+}
@@ -0,0 +1,189 @@
+package parsers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQuayParser_Parse(t *testing.T) {
+	parser := NewQuayParser("quay.io", "")
+
+	tests := []struct {
+		name        string
+		payload     string
+		wantCount   int
+		wantRepo    string
+		wantTag     string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "valid quay repo_push webhook",
+			payload: `{
+				"name": "myapp",
+				"repository": "myorg/myapp",
+				"namespace": "myorg",
+				"docker_url": "quay.io/myorg/myapp",
+				"homepage": "https://quay.io/repository/myorg/myapp",
+				"updated_tags": ["latest"]
+			}`,
+			wantCount: 1,
+			wantRepo:  "myorg/myapp",
+			wantTag:   "latest",
+			wantErr:   false,
+		},
+		{
+			name: "multiple updated tags",
+			payload: `{
+				"repository": "myorg/myapp",
+				"updated_tags": ["v1.0.0", "latest"]
+			}`,
+			wantCount: 2,
+			wantRepo:  "myorg/myapp",
+			wantTag:   "v1.0.0",
+			wantErr:   false,
+		},
+		{
+			name: "missing repository",
+			payload: `{
+				"updated_tags": ["latest"]
+			}`,
+			wantErr:     true,
+			errContains: "missing repository",
+		},
+		{
+			name: "no updated tags",
+			payload: `{
+				"repository": "myorg/myapp",
+				"updated_tags": []
+			}`,
+			wantErr:     true,
+			errContains: "no updated tags",
+		},
+		{
+			name:        "invalid JSON",
+			payload:     `{invalid json}`,
+			wantErr:     true,
+			errContains: "failed to parse JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			requests, err := parser.Parse(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				if tt.errContains != "" && !contains(err.Error(), tt.errContains) {
+					t.Errorf("Parse() error = %v, want error containing %v", err, tt.errContains)
+				}
+				return
+			}
+
+			if len(requests) != tt.wantCount {
+				t.Errorf("Parse() returned %d requests, want %d", len(requests), tt.wantCount)
+				return
+			}
+
+			if tt.wantCount > 0 {
+				req := requests[0]
+				if req.Repository != tt.wantRepo {
+					t.Errorf("Parse() repository = %v, want %v", req.Repository, tt.wantRepo)
+				}
+				if req.Tag != tt.wantTag {
+					t.Errorf("Parse() tag = %v, want %v", req.Tag, tt.wantTag)
+				}
+				if req.Registry != "quay.io" {
+					t.Errorf("Parse() registry = %v, want quay.io", req.Registry)
+				}
+			}
+		})
+	}
+}
+
+func TestQuayParser_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		secret      string
+		method      string
+		contentType string
+		header      string
+		wantErr     bool
+	}{
+		{
+			name:        "valid POST with JSON, no secret configured",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			wantErr:     false,
+		},
+		{
+			name:        "invalid method GET",
+			method:      http.MethodGet,
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid content type",
+			method:      http.MethodPost,
+			contentType: "text/plain",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, header missing",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, header mismatch",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			header:      "nope",
+			wantErr:     true,
+		},
+		{
+			name:        "secret configured, header matches",
+			secret:      "shh",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			header:      "shh",
+			wantErr:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewQuayParser("quay.io", tt.secret)
+
+			req := httptest.NewRequest(tt.method, "/webhook", bytes.NewBufferString(`{"repository":"myorg/myapp","updated_tags":["latest"]}`))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.header != "" {
+				req.Header.Set(quaySecretHeader, tt.header)
+			}
+
+			err := parser.Validate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuayParser_RegistryType(t *testing.T) {
+	parser := NewQuayParser("quay.io", "")
+	if got := parser.RegistryType(); got != "quay" {
+		t.Errorf("RegistryType() = %v, want quay", got)
+	}
+}
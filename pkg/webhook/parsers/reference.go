@@ -0,0 +1,180 @@
+package parsers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pathComponentRegexp matches one "/"-separated path component of a
+// reference name, per the docker/distribution grammar:
+// [a-z0-9]+ optionally separated by a single ".", "_", "__", or a run of
+// "-". Path components are always lowercase; that's what lets
+// looksLikeDomain tell a hostname from a path component below.
+var pathComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+
+// digestRegexp matches an "<algorithm>:<hex>" digest, e.g.
+// "sha256:abcdef0123...". The hex part must be long enough to rule out
+// obvious typos; the algorithm itself isn't restricted to a fixed set
+// since OCI allows registering new ones.
+var digestRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+
+// Reference is a parsed, canonical container image reference, split the
+// way github.com/docker/distribution/reference's grammar does:
+//
+//	reference := name [ ":" tag ] [ "@" digest ]
+//	name      := [ domain "/" ] path-component ( "/" path-component )*
+//
+// Domain is recognized only when name has more than one "/"-separated
+// segment and the first one looks like a hostname (see looksLikeDomain);
+// otherwise the whole name is Path and Domain defaults to "docker.io"
+// with "library/" prepended to Path, matching how the Docker CLI
+// resolves an unqualified name like "nginx".
+type Reference struct {
+	Domain string
+	Path   string
+	Tag    string
+	Digest string
+}
+
+// ParseReference parses s into a Reference, or returns an error
+// describing which part of the grammar it violates. Either Tag or
+// Digest (or both) may be empty; a reference with neither is valid
+// (callers that need a tag default should apply one themselves, since
+// "no tag" and "tag latest" aren't the same thing for a digest-pinned
+// reference).
+func ParseReference(s string) (Reference, error) {
+	if s == "" {
+		return Reference{}, fmt.Errorf("image reference is empty")
+	}
+
+	remainder := s
+	var digest string
+	if i := strings.IndexByte(remainder, '@'); i != -1 {
+		digest = remainder[i+1:]
+		remainder = remainder[:i]
+
+		if !digestRegexp.MatchString(digest) {
+			return Reference{}, fmt.Errorf("invalid digest %q", digest)
+		}
+	}
+
+	if remainder == "" {
+		return Reference{}, fmt.Errorf("image reference %q has no repository name", s)
+	}
+
+	segments := strings.Split(remainder, "/")
+
+	domain := ""
+	pathSegments := segments
+	if len(segments) > 1 && looksLikeDomain(segments[0]) {
+		domain = segments[0]
+		pathSegments = segments[1:]
+	}
+
+	// The tag, if any, is only ever attached to the last path segment:
+	// a ":" earlier in the name (i.e. in the domain segment) is a port
+	// number, not a tag separator.
+	tag := ""
+	last := len(pathSegments) - 1
+	if i := strings.IndexByte(pathSegments[last], ':'); i != -1 {
+		tag = pathSegments[last][i+1:]
+		pathSegments[last] = pathSegments[last][:i]
+	}
+
+	path := strings.Join(pathSegments, "/")
+	if err := validatePath(path); err != nil {
+		return Reference{}, fmt.Errorf("invalid repository name %q: %w", remainder, err)
+	}
+
+	if domain == "" {
+		domain = "docker.io"
+		if !strings.Contains(path, "/") {
+			path = "library/" + path
+		}
+	}
+
+	return Reference{Domain: domain, Path: path, Tag: tag, Digest: digest}, nil
+}
+
+// looksLikeDomain reports whether seg, the first "/"-separated segment
+// of a reference name, is a hostname rather than the start of the
+// repository path. A path component is always lowercase with no "." or
+// ":", so any of those appearing (or the literal "localhost") mark seg
+// as a domain instead.
+func looksLikeDomain(seg string) bool {
+	if seg == "localhost" {
+		return true
+	}
+	if strings.ContainsAny(seg, ".:") {
+		return true
+	}
+	for _, r := range seg {
+		if r >= 'A' && r <= 'Z' {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePath checks that every "/"-separated component of path
+// matches pathComponentRegexp.
+func validatePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("repository path is empty")
+	}
+	for _, component := range strings.Split(path, "/") {
+		if !pathComponentRegexp.MatchString(component) {
+			return fmt.Errorf("invalid path component %q", component)
+		}
+	}
+	return nil
+}
+
+// String returns ref's fully-qualified canonical form:
+// "domain/path[:tag][@digest]".
+func (r Reference) String() string {
+	var b strings.Builder
+	b.WriteString(r.Domain)
+	b.WriteByte('/')
+	b.WriteString(r.Path)
+	if r.Tag != "" {
+		b.WriteByte(':')
+		b.WriteString(r.Tag)
+	}
+	if r.Digest != "" {
+		b.WriteByte('@')
+		b.WriteString(r.Digest)
+	}
+	return b.String()
+}
+
+// Familiar returns ref's shortened, human-typed form, the way it would
+// be written on a `docker pull` command line: the "docker.io" domain
+// and "library/" path prefix a Docker Hub reference normally carries are
+// both dropped, so "docker.io/library/nginx:latest" becomes
+// "nginx:latest" and "docker.io/myorg/myapp:v1" becomes "myorg/myapp:v1".
+// References on any other domain are unaffected.
+func (r Reference) Familiar() string {
+	domain, path := r.Domain, r.Path
+	if domain == "docker.io" {
+		domain = ""
+		path = strings.TrimPrefix(path, "library/")
+	}
+
+	var b strings.Builder
+	if domain != "" {
+		b.WriteString(domain)
+		b.WriteByte('/')
+	}
+	b.WriteString(path)
+	if r.Tag != "" {
+		b.WriteByte(':')
+		b.WriteString(r.Tag)
+	}
+	if r.Digest != "" {
+		b.WriteByte('@')
+		b.WriteString(r.Digest)
+	}
+	return b.String()
+}
@@ -0,0 +1,177 @@
+package parsers
+
+import "testing"
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		want    Reference
+		wantErr bool
+	}{
+		{
+			name: "unqualified docker hub name",
+			ref:  "nginx",
+			want: Reference{Domain: "docker.io", Path: "library/nginx"},
+		},
+		{
+			name: "docker hub namespaced name",
+			ref:  "myorg/myapp:v1",
+			want: Reference{Domain: "docker.io", Path: "myorg/myapp", Tag: "v1"},
+		},
+		{
+			name: "digest only, no tag",
+			ref:  "nginx@sha256:" + sha256Hex,
+			want: Reference{Domain: "docker.io", Path: "library/nginx", Digest: "sha256:" + sha256Hex},
+		},
+		{
+			name: "tag and digest both present",
+			ref:  "harbor.example.com/app:v1.0.0@sha256:" + sha256Hex,
+			want: Reference{Domain: "harbor.example.com", Path: "app", Tag: "v1.0.0", Digest: "sha256:" + sha256Hex},
+		},
+		{
+			name: "hostname distinguished by dot",
+			ref:  "gcr.io/project/app:latest",
+			want: Reference{Domain: "gcr.io", Path: "project/app", Tag: "latest"},
+		},
+		{
+			name: "hostname distinguished by port",
+			ref:  "localhost:5000/myapp:v1",
+			want: Reference{Domain: "localhost:5000", Path: "myapp", Tag: "v1"},
+		},
+		{
+			name: "hostname distinguished by literal localhost",
+			ref:  "localhost/myapp",
+			want: Reference{Domain: "localhost", Path: "myapp"},
+		},
+		{
+			name: "uppercase first segment is a hostname, not a path component",
+			ref:  "MyRegistry.internal/team/app:v2",
+			want: Reference{Domain: "MyRegistry.internal", Path: "team/app", Tag: "v2"},
+		},
+		{
+			name: "first segment with no dot, port, or uppercase is a path component",
+			ref:  "myorg/myapp",
+			want: Reference{Domain: "docker.io", Path: "myorg/myapp"},
+		},
+		{
+			name:    "empty reference",
+			ref:     "",
+			wantErr: true,
+		},
+		{
+			name:    "invalid digest algorithm hex",
+			ref:     "nginx@sha256:nothex",
+			wantErr: true,
+		},
+		{
+			name:    "invalid path component",
+			ref:     "nginx//app",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReference(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseReference(%q) = %+v, want %+v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferenceFamiliar(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  Reference
+		want string
+	}{
+		{
+			name: "docker hub library image",
+			ref:  Reference{Domain: "docker.io", Path: "library/nginx", Tag: "latest"},
+			want: "nginx:latest",
+		},
+		{
+			name: "docker hub namespaced image",
+			ref:  Reference{Domain: "docker.io", Path: "myorg/myapp", Tag: "v1"},
+			want: "myorg/myapp:v1",
+		},
+		{
+			name: "non-docker-hub domain is kept",
+			ref:  Reference{Domain: "harbor.example.com", Path: "project/app", Tag: "v1.0.0"},
+			want: "harbor.example.com/project/app:v1.0.0",
+		},
+		{
+			name: "digest with no tag",
+			ref:  Reference{Domain: "docker.io", Path: "library/nginx", Digest: "sha256:" + sha256Hex},
+			want: "nginx@sha256:" + sha256Hex,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ref.Familiar(); got != tt.want {
+				t.Errorf("Familiar() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// sha256Hex is a syntactically valid (if not actually meaningful) 64
+// character hex digest, used by test cases that need a well-formed
+// digest suffix.
+const sha256Hex = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b85"
+
+// FuzzParseReference exercises ParseReference against the ambiguous
+// forms that tripped up the old string-split parser: digest-only
+// references, hostnames distinguished from a path component only by a
+// ".", ":port", or "localhost", uppercase hostnames, and references
+// carrying both a tag and a digest.
+func FuzzParseReference(f *testing.F) {
+	seeds := []string{
+		"nginx",
+		"nginx:latest",
+		"nginx@sha256:" + sha256Hex,
+		"myorg/myapp:v1@sha256:" + sha256Hex,
+		"docker.io/library/nginx:latest",
+		"localhost:5000/myapp:v1",
+		"localhost/myapp",
+		"gcr.io/project/subproject/app:latest",
+		"MyRegistry.internal/team/app:v2",
+		"harbor.example.com:443/project/app@sha256:" + sha256Hex,
+		"",
+		"/",
+		"@",
+		":",
+		"a@b:c",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, ref string) {
+		parsed, err := ParseReference(ref)
+		if err != nil {
+			return
+		}
+
+		// A reference that parsed successfully must round-trip: its
+		// canonical String() form must parse back to the same
+		// Reference, since String() is just ref's own fields joined
+		// the way ParseReference expects to split them.
+		reparsed, err := ParseReference(parsed.String())
+		if err != nil {
+			t.Fatalf("ParseReference(%q) succeeded but its String() form %q failed to reparse: %v", ref, parsed.String(), err)
+		}
+		if reparsed != parsed {
+			t.Fatalf("ParseReference(%q).String() = %q reparsed as %+v, want %+v", ref, parsed.String(), reparsed, parsed)
+		}
+	})
+}
@@ -12,24 +12,19 @@ type ParserRegistry struct {
 	parsers map[string]models.WebhookParser
 }
 
-// NewParserRegistry creates a new parser registry
+// NewParserRegistry creates a new parser registry, building one parser
+// per configured registry via the factories registered with Register
+// (built-in parsers register themselves in their own init()). A registry
+// whose Type has no registered factory is skipped, preserving the
+// pre-factory behavior of silently ignoring unknown registry types.
 func NewParserRegistry(cfg *config.Config) *ParserRegistry {
 	registry := &ParserRegistry{
 		parsers: make(map[string]models.WebhookParser),
 	}
 
-	// Register parsers based on configured registries
 	for _, regConfig := range cfg.Registries {
-		var parser models.WebhookParser
-
-		switch regConfig.Type {
-		case "dockerhub":
-			parser = NewDockerHubParser()
-		case "harbor":
-			parser = NewHarborParser(regConfig.URL)
-		case "gitlab":
-			parser = NewGitLabParser(regConfig.URL)
-		default:
+		parser, err := Default().New(regConfig)
+		if err != nil {
 			// Skip unknown registry types
 			continue
 		}
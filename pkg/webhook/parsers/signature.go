@@ -0,0 +1,71 @@
+package parsers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SignatureVerifier checks a webhook request's registry-specific
+// signature (or shared secret) against the raw request body, returning
+// an error if it doesn't match. A nil SignatureVerifier skips
+// verification entirely - the default for a registry configured without
+// a webhook secret, matching how DockerHubParser and the original
+// HarborParser behaved before signature checking existed.
+type SignatureVerifier interface {
+	Verify(r *http.Request, body []byte) error
+}
+
+// HMACSHA256Verifier checks that Header holds an HMAC-SHA256 of body
+// keyed by Secret, hex-encoded and optionally prefixed (GitHub's
+// X-Hub-Signature-256 sends "sha256=<hex>"; Harbor's X-Harbor-Signature
+// sends just "<hex>").
+type HMACSHA256Verifier struct {
+	Header string
+	Secret string
+	Prefix string
+}
+
+// Verify implements SignatureVerifier.
+func (v *HMACSHA256Verifier) Verify(r *http.Request, body []byte) error {
+	got := r.Header.Get(v.Header)
+	if got == "" {
+		return fmt.Errorf("missing %s header", v.Header)
+	}
+	got = strings.TrimPrefix(got, v.Prefix)
+
+	mac := hmac.New(sha256.New, []byte(v.Secret))
+	mac.Write(body)
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return fmt.Errorf("%s signature mismatch", v.Header)
+	}
+	return nil
+}
+
+// SharedSecretHeaderVerifier checks that Header holds Secret verbatim.
+// Used for registries whose webhook notifications aren't signed at all
+// (e.g. Quay), where the only tamper protection is a secret value the
+// operator configures the registry to send with every request.
+type SharedSecretHeaderVerifier struct {
+	Header string
+	Secret string
+}
+
+// Verify implements SignatureVerifier.
+func (v *SharedSecretHeaderVerifier) Verify(r *http.Request, body []byte) error {
+	got := r.Header.Get(v.Header)
+	if got == "" {
+		return fmt.Errorf("missing %s header", v.Header)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(got), []byte(v.Secret)) != 1 {
+		return fmt.Errorf("%s does not match configured webhook secret", v.Header)
+	}
+	return nil
+}
@@ -1,13 +1,40 @@
 package parsers
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
 )
 
-// generateRequestID generates a unique request ID for tracing
-func generateRequestID() string {
-	bytes := make([]byte, 16)
-	rand.Read(bytes)
-	return hex.EncodeToString(bytes)
+// generateRequestID returns the correlation ID the webhook server's
+// request-ID middleware stashed on r's context, if any, so a ScanRequest
+// shares its ID with every HTTP log line already written for this
+// request. Falls back to minting a fresh one for requests that bypassed
+// that middleware (e.g. direct unit-test calls).
+func generateRequestID(r *http.Request) string {
+	if requestID, ok := models.RequestIDFromContext(r.Context()); ok {
+		return requestID
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// readAndRestoreBody reads r's entire body and replaces r.Body with a
+// fresh reader over the same bytes, so a parser's Validate can inspect
+// (and signature-check) the body without consuming it for the Parse
+// call that follows.
+func readAndRestoreBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
 }
@@ -2,32 +2,59 @@ package webhook
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/auth"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/events"
+	"github.com/sysdig/registry-webhook-scanner/pkg/logging"
+	"github.com/sysdig/registry-webhook-scanner/pkg/queue"
+	pullability "github.com/sysdig/registry-webhook-scanner/pkg/registry/auth"
+	"github.com/sysdig/registry-webhook-scanner/pkg/scanner"
+	"github.com/sysdig/registry-webhook-scanner/pkg/shutdown"
 )
 
+// sseHeartbeatInterval is how often handleScanEvents writes a
+// comment-only SSE line to keep the connection alive through proxies
+// that otherwise time out an idle response.
+const sseHeartbeatInterval = 15 * time.Second
+
 // Server represents the HTTP webhook server
 type Server struct {
-	config     *config.Config
-	router     *mux.Router
-	httpServer *http.Server
-	logger     *logrus.Logger
-	ready      bool
+	cfg             atomic.Pointer[config.Config]
+	dispatcher      atomic.Pointer[Dispatcher]
+	router          *mux.Router
+	httpServer      *http.Server
+	listener        net.Listener
+	idleTracker     *shutdown.IdleTracker
+	logger          logging.Logger
+	resultProcessor *scanner.ResultProcessor
+	eventBus        *events.Bus
+	queueBackend    queue.Backend
+	pullChecker     *pullability.Checker
+	ready           bool
 }
 
 // NewServer creates a new webhook server instance
-func NewServer(cfg *config.Config, logger *logrus.Logger) *Server {
+func NewServer(cfg *config.Config, logger logging.Logger) *Server {
 	s := &Server{
-		config: cfg,
-		router: mux.NewRouter(),
-		logger: logger,
-		ready:  false,
+		router:      mux.NewRouter(),
+		idleTracker: shutdown.NewIdleTracker(0),
+		logger:      logger,
+		ready:       false,
 	}
+	s.cfg.Store(cfg)
+	s.rebuildDispatcher(cfg)
 
 	// Setup routes
 	s.setupRoutes()
@@ -37,24 +64,113 @@ func NewServer(cfg *config.Config, logger *logrus.Logger) *Server {
 	writeTimeout, _ := cfg.ParseDuration(cfg.Server.WriteTimeout)
 
 	s.httpServer = &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler:      s.router,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
+		Addr:           fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:        s.router,
+		ReadTimeout:    readTimeout,
+		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: 1 << 20, // 1MB
+		ConnState:      s.idleTracker.ConnState,
 	}
 
 	return s
 }
 
+// IdleTracker returns the server's connection/request idle tracker, used to
+// short-circuit the drain wait during graceful shutdown.
+func (s *Server) IdleTracker() *shutdown.IdleTracker {
+	return s.idleTracker
+}
+
+// SetResultProcessor attaches the scanner.ResultProcessor whose cache
+// backs this server's scan coalescing, so Shutdown can stop its janitor
+// goroutine cleanly instead of leaking it past process exit.
+func (s *Server) SetResultProcessor(rp *scanner.ResultProcessor) {
+	s.resultProcessor = rp
+}
+
+// SetEventBus attaches the events.Bus scanner backends publish scan
+// lifecycle events and log lines to, so handleScanEvents can serve them
+// over SSE. Leaving it unset (the default) makes that endpoint respond
+// 501, since there's nothing to subscribe to.
+func (s *Server) SetEventBus(bus *events.Bus) {
+	s.eventBus = bus
+}
+
+// SetQueueBackend attaches the queue.Backend handleWebhook enqueues
+// parsed scan requests to. Leaving it unset (the default) makes
+// handleWebhook accept and parse webhooks but drop the resulting scan
+// requests, since there's nowhere to put them.
+func (s *Server) SetQueueBackend(backend queue.Backend) {
+	s.queueBackend = backend
+}
+
+// SetPullabilityChecker attaches the pullability.Checker handleWebhook
+// uses to confirm an image is actually pullable from its registry before
+// enqueueing it, so a webhook firing for a push that's been deleted,
+// re-tagged, or made private by the time the scanner would pull it fails
+// fast here instead of after it's taken a worker slot. Leaving it unset
+// (the default) enqueues requests unchecked, same as before this existed.
+func (s *Server) SetPullabilityChecker(checker *pullability.Checker) {
+	s.pullChecker = checker
+}
+
+// rebuildDispatcher constructs a fresh Dispatcher from cfg's registries
+// and stores it, so handleWebhook always dispatches against the
+// currently active configuration. Called from NewServer and again from
+// UpdateConfig whenever cfg.Registries may have changed.
+func (s *Server) rebuildDispatcher(cfg *config.Config) {
+	authenticator := auth.NewRequestAuthenticator(cfg, logrus.New())
+	s.dispatcher.Store(NewDispatcher(cfg, authenticator))
+}
+
+// Config returns the server's currently active configuration. Safe to
+// call concurrently with UpdateConfig from a config.Watch callback.
+func (s *Server) Config() *config.Config {
+	return s.cfg.Load()
+}
+
+// UpdateConfig validates cfg and, if valid, atomically swaps it in as the
+// server's active configuration. In-flight requests already dispatched
+// to a handler keep running against whichever config they read; only
+// requests accepted afterward see the new one, so a reload never
+// interrupts a scan already underway. Intended as the onChange callback
+// passed to config.Watch.
+func (s *Server) UpdateConfig(cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("refusing to apply invalid configuration: %w", err)
+	}
+
+	s.cfg.Store(cfg)
+	s.rebuildDispatcher(cfg)
+	s.logger.Info(context.Background(), "Webhook server configuration reloaded", "registries", len(cfg.Registries))
+	return nil
+}
+
+// SetTLSConfig configures the server to serve HTTPS, required before
+// Start/StartOnListener when any registry authenticates via auth.type
+// "mtls". Build tlsConfig with NewTLSConfig.
+func (s *Server) SetTLSConfig(tlsConfig *tls.Config) {
+	s.httpServer.TLSConfig = tlsConfig
+}
+
 // setupRoutes configures HTTP routes and middleware
 func (s *Server) setupRoutes() {
 	// Apply global middleware
+	s.router.Use(s.requestIDMiddleware)
+	s.router.Use(s.panicRecoveryMiddleware)
 	s.router.Use(s.loggingMiddleware)
 	s.router.Use(s.requestSizeLimitMiddleware)
 
-	// Webhook endpoint
-	s.router.HandleFunc("/webhook", s.handleWebhook).Methods(http.MethodPost)
+	// Webhook endpoint. idleTracker.Middleware wraps only this route
+	// rather than the whole router: WaitIdle expects in-flight requests
+	// to return promptly, but handleScanEvents below intentionally blocks
+	// for the life of its SSE connection and would otherwise keep the
+	// server looking "in-flight" indefinitely and defeat WaitIdle's
+	// shutdown fast-path.
+	s.router.Handle("/webhook", s.idleTracker.Middleware(http.HandlerFunc(s.handleWebhook))).Methods(http.MethodPost)
+
+	// Scan progress/log event stream
+	s.router.HandleFunc("/scans/{request_id}/events", s.handleScanEvents).Methods(http.MethodGet)
 
 	// Health endpoint
 	s.router.HandleFunc("/health", s.handleHealth).Methods(http.MethodGet)
@@ -63,31 +179,71 @@ func (s *Server) setupRoutes() {
 	s.router.HandleFunc("/ready", s.handleReadiness).Methods(http.MethodGet)
 }
 
-// Start starts the HTTP server
+// Start starts the HTTP server, binding a fresh listener on the configured
+// port. Use StartOnListener instead when taking over a listener inherited
+// from a graceful restart.
 func (s *Server) Start() error {
-	s.logger.WithFields(logrus.Fields{
-		"port": s.config.Server.Port,
-	}).Info("Starting HTTP server")
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind listener: %w", err)
+	}
+
+	return s.StartOnListener(ln)
+}
+
+// StartOnListener serves the webhook HTTP server on an already-bound
+// listener, e.g. one reconstructed by restart.TakeOverListeners() after a
+// SIGHUP-triggered graceful restart.
+func (s *Server) StartOnListener(ln net.Listener) error {
+	s.logger.Info(context.Background(), "Starting HTTP server", "addr", ln.Addr().String())
 
+	s.listener = ln
 	s.ready = true
 
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	var err error
+	if s.httpServer.TLSConfig != nil {
+		// Certificates are already loaded into TLSConfig, so no
+		// cert/key file paths are needed here.
+		err = s.httpServer.ServeTLS(ln, "", "")
+	} else {
+		err = s.httpServer.Serve(ln)
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("server error: %w", err)
 	}
 
 	return nil
 }
 
-// Shutdown gracefully shuts down the HTTP server
+// Listener returns the listener the server is currently bound to, or nil if
+// the server has not been started yet. Used to register the listener with
+// the shutdown manager for graceful restart handoff.
+func (s *Server) Listener() net.Listener {
+	return s.listener
+}
+
+// Shutdown gracefully shuts down the HTTP server: stops accepting new
+// webhooks and waits for in-flight HTTP handlers to return, then waits
+// for any scan a handler registered with s.idleTracker.RegisterScan to
+// finish, since those can outlive the HTTP response that kicked them
+// off. Returns once both have drained or ctx is done.
 func (s *Server) Shutdown(ctx context.Context) error {
-	s.logger.Info("Shutting down HTTP server")
+	s.logger.Info(ctx, "Shutting down HTTP server")
 	s.ready = false
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("server shutdown error: %w", err)
 	}
 
-	s.logger.Info("HTTP server stopped")
+	if err := s.idleTracker.WaitIdle(ctx); err != nil {
+		return fmt.Errorf("timed out waiting for in-flight scans to finish: %w", err)
+	}
+
+	if s.resultProcessor != nil {
+		s.resultProcessor.Close()
+	}
+
+	s.logger.Info(ctx, "HTTP server stopped")
 	return nil
 }
 
@@ -96,20 +252,120 @@ func (s *Server) SetReady(ready bool) {
 	s.ready = ready
 }
 
-// handleWebhook processes incoming webhook requests
+// handleWebhook processes incoming webhook requests: identifies which
+// configured registry sent them, authenticates against that registry's
+// credentials, parses the resulting scan requests, confirms each is
+// still pullable (when s.pullChecker is set), and enqueues the survivors
+// on s.queueBackend for a worker pool to pick up.
 func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement webhook processing
-	// This will be implemented with authentication and registry parsing
+	// Registered for the lifetime of the scan this webhook dispatches,
+	// not just this handler call, so Shutdown's WaitIdle keeps waiting
+	// if the scan is handed off to run past the HTTP response below.
+	s.idleTracker.RegisterScan()
+	defer s.idleTracker.Done()
 
-	s.logger.WithFields(logrus.Fields{
-		"method":     r.Method,
-		"remote_addr": r.RemoteAddr,
-	}).Debug("Webhook received")
+	s.logger.Debug(r.Context(), "Webhook received", "method", r.Method, "remote_addr", r.RemoteAddr)
+
+	dispatcher := s.dispatcher.Load()
+	if dispatcher == nil {
+		http.Error(w, "webhook dispatcher not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	registryName, requests, err := dispatcher.Dispatch(r)
+	if err != nil {
+		var authErr *AuthenticationError
+		if errors.As(err, &authErr) {
+			s.logger.Warn(r.Context(), "Webhook authentication failed", "error", err.Error())
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		s.logger.Warn(r.Context(), "Webhook rejected", "error", err.Error())
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	logger := s.logger.With("registry", registryName)
+
+	enqueued := 0
+	for _, req := range requests {
+		if s.queueBackend == nil {
+			continue
+		}
+		if s.pullChecker != nil {
+			if err := s.pullChecker.CheckPullable(r.Context(), req); err != nil {
+				logger.Warn(r.Context(), "Image not pullable, dropping scan request", "image_ref", req.ImageRef, "error", err.Error())
+				continue
+			}
+		}
+		if err := s.queueBackend.Enqueue(r.Context(), req, time.Time{}); err != nil {
+			logger.Error(r.Context(), "Failed to enqueue scan request", "image_ref", req.ImageRef, "error", err.Error())
+			continue
+		}
+		enqueued++
+	}
+
+	logger.Info(r.Context(), "Webhook dispatched", "parsed", len(requests), "enqueued", enqueued)
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"status":"accepted"}`))
 }
 
+// handleScanEvents serves GET /scans/{request_id}/events as Server-Sent
+// Events: the scan's lifecycle events (events.TypeStarted/TypeCompleted/
+// TypeFailed/TypeTimeout) and incremental scanner log lines
+// (events.TypeLog), published to s.eventBus by whichever scanner backend
+// ran the scan (see scanner.CLIScanner's EventPublisher implementation).
+// A subscriber connecting after the scan already started - or even
+// completed - still sees its recent history, replayed from the bus's
+// per-scan ring buffer, before live events resume.
+func (s *Server) handleScanEvents(w http.ResponseWriter, r *http.Request) {
+	requestID := mux.Vars(r)["request_id"]
+
+	if s.eventBus == nil {
+		http.Error(w, "event streaming is not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	eventCh, unsubscribe := s.eventBus.Subscribe(requestID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-eventCh:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
 // handleHealth returns the health status
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -143,20 +399,25 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		s.logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"path":        r.URL.Path,
-			"remote_addr": r.RemoteAddr,
-			"status_code": rw.statusCode,
-			"duration_ms": duration.Milliseconds(),
-		}).Info("HTTP request")
+		logger := s.logger
+		if requestID, ok := models.RequestIDFromContext(r.Context()); ok {
+			logger = logger.WithRequestID(requestID)
+		}
+
+		logger.Info(r.Context(), "HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"remote_addr", r.RemoteAddr,
+			"status_code", rw.statusCode,
+			"duration_ms", duration.Milliseconds(),
+		)
 	})
 }
 
 // requestSizeLimitMiddleware enforces maximum request size
 func (s *Server) requestSizeLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		r.Body = http.MaxBytesReader(w, r.Body, s.config.Server.MaxRequestSize)
+		r.Body = http.MaxBytesReader(w, r.Body, s.Config().Server.MaxRequestSize)
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+)
+
+// NewTLSConfig builds the TLS configuration the webhook listener should
+// serve with, or returns (nil, nil) when cfg.Server.TLS isn't configured
+// so the caller falls back to plain HTTP. When any registry uses
+// auth.type "mtls", it also loads the client CA trust bundle and
+// requires a verified client certificate at the handshake -- the
+// identity itself is then checked per-request by auth.VerifyMTLS.
+func NewTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if cfg.Server.TLS.CertFile == "" && cfg.Server.TLS.KeyFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if requiresMTLS(cfg) {
+		pool, err := loadClientCAPool(cfg.Server.TLS)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// requiresMTLS reports whether any registry authenticates via client
+// certificates, meaning the listener must request and verify one.
+func requiresMTLS(cfg *config.Config) bool {
+	for _, reg := range cfg.Registries {
+		if reg.Auth.Type == "mtls" {
+			return true
+		}
+	}
+	return false
+}
+
+// loadClientCAPool builds the pool of CAs trusted to sign client
+// certificates from tlsCfg.ClientCAFile or, if unset, the inline
+// ClientCAPEM bundle.
+func loadClientCAPool(tlsCfg config.TLSConfig) (*x509.CertPool, error) {
+	pemData := []byte(tlsCfg.ClientCAPEM)
+	if tlsCfg.ClientCAFile != "" {
+		data, err := os.ReadFile(tlsCfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pemData = data
+	}
+
+	if len(pemData) == 0 {
+		return nil, fmt.Errorf("mtls auth requires server.tls.client_ca_file or client_ca_pem")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("failed to parse client CA bundle")
+	}
+
+	return pool, nil
+}
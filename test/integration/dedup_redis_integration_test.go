@@ -0,0 +1,112 @@
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/sysdig/registry-webhook-scanner/internal/models"
+	"github.com/sysdig/registry-webhook-scanner/pkg/queue"
+)
+
+// miniredisClient adapts a miniredis-backed *goredis.Client to
+// queue.RedisClient for these tests, mirroring the production adapter
+// without depending on its unexported type.
+type miniredisClient struct {
+	client *goredis.Client
+}
+
+func (c *miniredisClient) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(context.Background(), key, value, ttl).Result()
+}
+
+func (c *miniredisClient) Exists(key string) (bool, error) {
+	n, err := c.client.Exists(context.Background(), key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (c *miniredisClient) Incr(key string) (int64, error) {
+	return c.client.Incr(context.Background(), key).Result()
+}
+
+func (c *miniredisClient) GetInt64(key string) (int64, error) {
+	n, err := c.client.Get(context.Background(), key).Int64()
+	if err == goredis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Test task 2.4: Redis-backed dedup store against a real (embedded) Redis
+func TestRedisDedupStore_SeenAndMark(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := &miniredisClient{client: goredis.NewClient(&goredis.Options{Addr: mr.Addr()})}
+	store := queue.NewRedisDedupStore(client, "test:dedup:")
+
+	seen, err := store.Seen("digest:sha256:abc")
+	if err != nil {
+		t.Fatalf("Seen() error = %v", err)
+	}
+	if seen {
+		t.Fatal("Seen() = true before any Mark, want false")
+	}
+
+	if err := store.Mark("digest:sha256:abc", time.Minute); err != nil {
+		t.Fatalf("Mark() error = %v", err)
+	}
+
+	seen, err = store.Seen("digest:sha256:abc")
+	if err != nil {
+		t.Fatalf("Seen() error = %v", err)
+	}
+	if !seen {
+		t.Fatal("Seen() = false after Mark, want true")
+	}
+
+	mr.FastForward(2 * time.Minute)
+
+	seen, err = store.Seen("digest:sha256:abc")
+	if err != nil {
+		t.Fatalf("Seen() error = %v", err)
+	}
+	if seen {
+		t.Fatal("Seen() = true after TTL expiry, want false")
+	}
+}
+
+// Test task 2.4: StoreBackedDeduplicator.IsDuplicate against Redis,
+// verifying digest-preferring keys and singleflight coalescing still
+// match the in-memory cache's documented behavior.
+func TestStoreBackedDeduplicator_IsDuplicate_Redis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := &miniredisClient{client: goredis.NewClient(&goredis.Options{Addr: mr.Addr()})}
+	store := queue.NewRedisDedupStore(client, "test:dedup:")
+	dedup := queue.NewStoreBackedDeduplicator(store, time.Minute, logrus.New())
+
+	req := &models.ScanRequest{ImageRef: "example.com/repo:v1", Digest: "sha256:digestvalue"}
+
+	if dedup.IsDuplicate(req) {
+		t.Fatal("first IsDuplicate() = true, want false")
+	}
+	if !dedup.IsDuplicate(req) {
+		t.Fatal("second IsDuplicate() = false, want true (same digest)")
+	}
+
+	// A different tag with the same digest is still a duplicate.
+	sameDigestDifferentTag := &models.ScanRequest{ImageRef: "example.com/repo:v2", Digest: "sha256:digestvalue"}
+	if !dedup.IsDuplicate(sameDigestDifferentTag) {
+		t.Fatal("IsDuplicate() with same digest, different tag = false, want true")
+	}
+}
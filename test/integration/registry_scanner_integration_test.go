@@ -5,12 +5,14 @@ package integration
 import (
 	"context"
 	"fmt"
+	"io"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/sysdig/registry-webhook-scanner/internal/models"
 	"github.com/sysdig/registry-webhook-scanner/pkg/config"
+	"github.com/sysdig/registry-webhook-scanner/pkg/queue"
 	"github.com/sysdig/registry-webhook-scanner/pkg/scanner"
 	"github.com/sysdig/registry-webhook-scanner/test/mocks"
 )
@@ -360,6 +362,77 @@ func TestRegistryScanner_RetryLogic(t *testing.T) {
 	})
 }
 
+// Test task 9.8: RetryManager's 401 clock-skew retry window recovers a
+// scan that fails its first attempt because the mock API's registry
+// auth rejects everything with 401, then starts accepting requests
+// again (simulating the JWT clock skew clearing up).
+func TestRegistryScanner_UnauthorizedRetryWindow(t *testing.T) {
+	mockAPI := mocks.NewMockRegistryScannerAPI()
+	defer mockAPI.Close()
+
+	// Reject the first request with 401, then behave normally.
+	mockAPI.SetBehavior(mocks.APIBehavior{
+		UnauthorizedRequests: true,
+		CompletionPollCount:  1,
+	})
+
+	cfg := &config.Config{
+		Scanner: config.ScannerConfig{
+			Type:           config.ScannerTypeRegistry,
+			SysdigToken:    "test-token",
+			DefaultTimeout: "10s",
+			RegistryScanner: &config.RegistryScannerConfig{
+				APIURL:       mockAPI.URL(),
+				ProjectID:    "test-project",
+				VerifyTLS:    false,
+				PollInterval: "100ms",
+			},
+		},
+	}
+
+	testScanner := scanner.NewRegistryScanner(cfg, logrus.New())
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	backend := queue.NewMemoryBackend(100, logger)
+	retryConfig := queue.DefaultRetryConfig()
+	retryConfig.MaxRetries = 1 // Unauthorized retries are tracked separately from this.
+	retry := queue.NewRetryManager(retryConfig, backend, logger)
+
+	req := &models.ScanRequest{
+		ImageRef:     "registry.example.com/myapp:v1.0.0",
+		RequestID:    "unauthorized-window-test-001",
+		RegistryName: "test-registry",
+	}
+	req.FirstAttemptAt = time.Now()
+
+	ctx := context.Background()
+	_, scanErr := testScanner.Scan(ctx, req)
+	if scanErr == nil {
+		t.Fatal("Scan() error = nil, want 401 error on first attempt")
+	}
+
+	if !retry.ShouldRetry(req, scanErr) {
+		t.Fatal("ShouldRetry() = false, want true for a 401 within UnauthorizedRetryWindow")
+	}
+
+	// The mock's clock-skew clears up: any request after the first is
+	// accepted.
+	mockAPI.SetBehavior(mocks.APIBehavior{
+		UnauthorizedRequests: false,
+		CompletionPollCount:  1,
+	})
+
+	result, err := testScanner.Scan(ctx, req)
+	if err != nil {
+		t.Fatalf("Scan() error after unauthorized window retry = %v, want nil", err)
+	}
+	if result.Status != models.ScanStatusSuccess {
+		t.Errorf("Scan() status = %v, want %v", result.Status, models.ScanStatusSuccess)
+	}
+}
+
 // Test concurrent scans
 func TestRegistryScanner_ConcurrentScans(t *testing.T) {
 	mockAPI := mocks.NewMockRegistryScannerAPI()